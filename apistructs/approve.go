@@ -33,6 +33,7 @@ const (
 	ApproveCeritficate       ApproveType = "certificate"
 	ApproveLibReference      ApproveType = "lib-reference"
 	ApproveUnblockAppication ApproveType = "unblock-application"
+	ApproveReleasePromotion  ApproveType = "release-promotion"
 )
 
 // ApproveCreateRequest POST /api/approves 创建审批请求结构
@@ -178,6 +178,17 @@ type ClusterResourceInfoData struct {
 	StagingMEMOverCommit float64 `json:"stagingMemOverCommit"`
 
 	Nodes map[string]*NodeResourceInfo `json:"nodes"`
+	// key: namespace, only namespaces with project quota
+	Namespaces map[string]*NamespaceResourceInfo `json:"namespaces,omitempty"`
+}
+
+// NamespaceResourceInfo namespace 资源配额及使用量
+type NamespaceResourceInfo struct {
+	CPUQuota float64 `json:"cpuQuota"`
+	CPUUsed  float64 `json:"cpuUsed"`
+	// 单位: byte
+	MemQuota int64 `json:"memQuota"`
+	MemUsed  int64 `json:"memUsed"`
 }
 
 type NodeResourceInfo struct {
@@ -41,6 +41,9 @@ const (
 	LabelBindPipelineQueueID             = "__bind_queue_id"
 	LabelBindPipelineQueueCustomPriority = "__bind_queue_custom_priority"
 
+	// LabelConcurrencyGroup 渲染后的并发组，用于重启或切换 leader 后恢复并发组成员
+	LabelConcurrencyGroup = "__concurrency_group"
+
 	LabelUserID = "userID"
 
 	// ---------------------- snippet some global labels
@@ -51,6 +54,9 @@ const (
 	// dice
 	LabelDiceSnippetScopeID   = "scopeID"
 	LabelChooseSnippetVersion = "chooseVersion"
+	// 引用 dice 模板的流水线，用于记录模板的依赖方
+	LabelSnippetReferrerSource = "snippetReferrerSource"
+	LabelSnippetReferrerName   = "snippetReferrerName"
 	// snippet
 	LabelSnippetScope     = "snippet_scope"
 	LabelActionEnv        = "action_env"
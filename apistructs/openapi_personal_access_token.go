@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import "time"
+
+// PersonalAccessTokenPrefix 个人访问令牌的固定前缀，用于和其他类型的 token 区分
+const PersonalAccessTokenPrefix = "erda_pat_"
+
+// 个人访问令牌的权限集合
+const (
+	// PersonalAccessTokenPermissionRead 只允许 GET/HEAD 请求
+	PersonalAccessTokenPermissionRead = "read"
+	// PersonalAccessTokenPermissionWrite 允许所有请求
+	PersonalAccessTokenPermissionWrite = "write"
+)
+
+// PersonalAccessToken 用户创建的个人访问令牌，令牌本身只在创建时返回一次
+type PersonalAccessToken struct {
+	ID     uint64 `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"userId"`
+	// TokenPrefix 令牌的前几位，用于辨认令牌
+	TokenPrefix string `json:"tokenPrefix"`
+	// OrgIDs 为空表示不限制企业
+	OrgIDs []uint64 `json:"orgIds"`
+	// ProjectIDs 为空表示不限制项目
+	ProjectIDs  []uint64   `json:"projectIds"`
+	Permissions []string   `json:"permissions"`
+	ExpiredAt   *time.Time `json:"expiredAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// PersonalAccessTokenCreateRequest POST /api/openapi/personal-access-tokens
+type PersonalAccessTokenCreateRequest struct {
+	Name        string   `json:"name"`
+	OrgIDs      []uint64 `json:"orgIds"`
+	ProjectIDs  []uint64 `json:"projectIds"`
+	Permissions []string `json:"permissions"`
+	// ExpiresInDays 有效天数，0 表示永不过期
+	ExpiresInDays int `json:"expiresInDays"`
+}
+
+// PersonalAccessTokenCreateResponse POST /api/openapi/personal-access-tokens
+type PersonalAccessTokenCreateResponse struct {
+	Header
+	Data PersonalAccessTokenCreateResult `json:"data"`
+}
+
+type PersonalAccessTokenCreateResult struct {
+	PersonalAccessToken
+	// Token 令牌明文，只在创建时返回
+	Token string `json:"token"`
+}
+
+// PersonalAccessTokenListResponse GET /api/openapi/personal-access-tokens
+type PersonalAccessTokenListResponse struct {
+	Header
+	Data []PersonalAccessToken `json:"data"`
+}
+
+// PersonalAccessTokenRevokeRequest DELETE /api/openapi/personal-access-tokens/{id}
+type PersonalAccessTokenRevokeRequest struct {
+	ID uint64 `path:"id"`
+}
+
+// PersonalAccessTokenRevokeResponse DELETE /api/openapi/personal-access-tokens/{id}
+type PersonalAccessTokenRevokeResponse struct {
+	Header
+	Data PersonalAccessToken `json:"data"`
+}
+
+// PersonalAccessTokenVerifyRequest POST /api/personal-access-tokens/actions/verify 内部接口
+type PersonalAccessTokenVerifyRequest struct {
+	// Token 令牌明文
+	Token string `json:"token"`
+}
+
+// PersonalAccessTokenVerifyResponse POST /api/personal-access-tokens/actions/verify 内部接口
+type PersonalAccessTokenVerifyResponse struct {
+	Header
+	Data PersonalAccessToken `json:"data"`
+}
@@ -141,6 +141,8 @@ type OrgDTO struct {
 
 	// 开关：制品是否允许跨集群部署
 	EnableReleaseCrossCluster bool `json:"enableReleaseCrossCluster"`
+	// 开关：部署至 PROD 的制品是否须已晋级至 STAGING
+	EnableReleasePromotionGate bool `json:"enableReleasePromotionGate"`
 
 	// 用户是否选中当前企业
 	Selected bool `json:"selected"`
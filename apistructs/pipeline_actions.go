@@ -109,6 +109,9 @@ type ActionCallback struct {
 	// machine stat
 	MachineStat *PipelineTaskMachineStat `json:"machineStat,omitempty"`
 
+	// artifacts uploaded by action-agent
+	Artifacts []ActionCallbackArtifact `json:"artifacts,omitempty"`
+
 	// behind
 	PipelineID     uint64 `json:"pipelineID"`
 	PipelineTaskID uint64 `json:"pipelineTaskID"`
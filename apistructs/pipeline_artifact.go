@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import (
+	"time"
+)
+
+// ActionArtifact action 声明的制品
+type ActionArtifact struct {
+	Name     string   `json:"name"`
+	Paths    []string `json:"paths"`
+	ExpireIn string   `json:"expireIn,omitempty"`
+}
+
+// ActionCallbackArtifact action-agent 上传制品后回调的制品信息
+type ActionCallbackArtifact struct {
+	Name      string     `json:"name"`
+	FileUUID  string     `json:"fileUUID"`
+	FileName  string     `json:"fileName"`
+	Size      int64      `json:"size"`
+	ExpiredAt *time.Time `json:"expiredAt,omitempty"`
+}
+
+// PipelineArtifact 流水线制品
+type PipelineArtifact struct {
+	ID          uint64     `json:"id"`
+	PipelineID  uint64     `json:"pipelineID"`
+	TaskID      uint64     `json:"taskID"`
+	TaskName    string     `json:"taskName"`
+	Name        string     `json:"name"`
+	FileUUID    string     `json:"fileUUID"`
+	FileName    string     `json:"fileName"`
+	Size        int64      `json:"size"`
+	DownloadURL string     `json:"downloadURL"`
+	ExpiredAt   *time.Time `json:"expiredAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// PipelineArtifactListRequest 查询流水线制品列表
+type PipelineArtifactListRequest struct {
+	PipelineID uint64 `schema:"-"`
+	TaskID     uint64 `schema:"taskID"`
+}
+
+type PipelineArtifactListResponse struct {
+	Header
+	Data []PipelineArtifact `json:"data"`
+}
@@ -100,6 +100,7 @@ type (
 	StorageConfig struct {
 		EnableNFS   bool `json:"enableNfs"`
 		EnableLocal bool `json:"enableLocal"`
+		EnableOSS   bool `json:"enableOss"`
 	}
 )
 
@@ -115,6 +116,12 @@ func (conf *StorageConfig) EnableShareVolume() bool {
 	return conf.EnableLocal
 }
 
+// whether to store context and caches in object storage instead of network storage
+// used by clusters without shared nfs, such as edge clusters
+func (conf StorageConfig) EnableOSSVolume() bool {
+	return conf.EnableOSS
+}
+
 // PipelineDetailDTO contains pipeline, stages, tasks and others
 type PipelineDetailDTO struct {
 	PipelineDTO
@@ -53,12 +53,17 @@ type PipelineQueue struct {
 	MaxCPU           float64                             `json:"maxCPU"`
 	MaxMemoryMB      float64                             `json:"maxMemoryMB"`
 
+	// FairShare is the group config used by FAIR_SHARE schedule strategy.
+	FairShare *PipelineQueueFairShare `json:"fairShare,omitempty"`
+
 	Labels map[string]string `json:"labels,omitempty"`
 
 	TimeCreated *time.Time `json:"timeCreated,omitempty"`
 	TimeUpdated *time.Time `json:"timeUpdated,omitempty"`
 
 	Usage *pb.QueueUsage `json:"usage"`
+	// GroupShares is the per-group usage of FAIR_SHARE queue.
+	GroupShares []*PipelineQueueGroupShare `json:"groupShares,omitempty"`
 }
 
 // ScheduleStrategyInsidePipelineQueue represents the schedule strategy of workflows inside a queue.
@@ -66,6 +71,9 @@ type ScheduleStrategyInsidePipelineQueue string
 
 var (
 	ScheduleStrategyInsidePipelineQueueOfFIFO ScheduleStrategyInsidePipelineQueue = "FIFO"
+	// ScheduleStrategyInsidePipelineQueueOfFairShare round-robin between groups by weight,
+	// priority is still honoured before groups.
+	ScheduleStrategyInsidePipelineQueueOfFairShare ScheduleStrategyInsidePipelineQueue = "FAIR_SHARE"
 )
 
 func (strategy ScheduleStrategyInsidePipelineQueue) String() string {
@@ -74,13 +82,77 @@ func (strategy ScheduleStrategyInsidePipelineQueue) String() string {
 
 func (strategy ScheduleStrategyInsidePipelineQueue) IsValid() bool {
 	switch strategy {
-	case ScheduleStrategyInsidePipelineQueueOfFIFO:
+	case ScheduleStrategyInsidePipelineQueueOfFIFO, ScheduleStrategyInsidePipelineQueueOfFairShare:
 		return true
 	default:
 		return false
 	}
 }
 
+// PipelineQueueFairShareGroupBy represents how pipelines are grouped in a FAIR_SHARE queue.
+type PipelineQueueFairShareGroupBy string
+
+var (
+	PipelineQueueFairShareGroupByProject PipelineQueueFairShareGroupBy = "project"
+	PipelineQueueFairShareGroupByUser    PipelineQueueFairShareGroupBy = "user"
+	PipelineQueueFairShareGroupByLabel   PipelineQueueFairShareGroupBy = "label"
+)
+
+func (g PipelineQueueFairShareGroupBy) String() string { return string(g) }
+func (g PipelineQueueFairShareGroupBy) IsValid() bool {
+	switch g {
+	case PipelineQueueFairShareGroupByProject, PipelineQueueFairShareGroupByUser, PipelineQueueFairShareGroupByLabel:
+		return true
+	default:
+		return false
+	}
+}
+
+// PipelineQueueFairShare is the group config of FAIR_SHARE queue.
+type PipelineQueueFairShare struct {
+	// GroupBy defines how pipelines are grouped, default is project.
+	GroupBy PipelineQueueFairShareGroupBy `json:"groupBy,omitempty"`
+
+	// LabelKey is the pipeline label key used as group when GroupBy is label.
+	LabelKey string `json:"labelKey,omitempty"`
+
+	// Weights defines weight of groups, key is group value, default weight is 1.
+	Weights map[string]int64 `json:"weights,omitempty"`
+}
+
+// Validate validate and set default values.
+func (fs *PipelineQueueFairShare) Validate() error {
+	if fs.GroupBy == "" {
+		fs.GroupBy = PipelineQueueFairShareGroupByProject
+	}
+	if !fs.GroupBy.IsValid() {
+		return fmt.Errorf("invalid fair share groupBy: %s", fs.GroupBy)
+	}
+	if fs.GroupBy == PipelineQueueFairShareGroupByLabel && fs.LabelKey == "" {
+		return fmt.Errorf("missing fair share labelKey")
+	}
+	for group, weight := range fs.Weights {
+		if weight <= 0 {
+			return fmt.Errorf("fair share weight of group %s must > 0", group)
+		}
+	}
+	return nil
+}
+
+// PipelineQueueGroupShare represents usage of one group inside a FAIR_SHARE queue.
+type PipelineQueueGroupShare struct {
+	Group           string  `json:"group"`
+	Weight          int64   `json:"weight"`
+	ProcessingCount int64   `json:"processingCount"`
+	PendingCount    int64   `json:"pendingCount"`
+	InUseCPU        float64 `json:"inUseCPU"`
+	InUseMemoryMB   float64 `json:"inUseMemoryMB"`
+	// Share is the proportion of processing count.
+	Share float64 `json:"share"`
+	// ExpectedShare is the proportion of weight between active groups.
+	ExpectedShare float64 `json:"expectedShare"`
+}
+
 var (
 	PipelineQueueDefaultPriority         int64 = 10
 	PipelineQueueDefaultScheduleStrategy       = ScheduleStrategyInsidePipelineQueueOfFIFO
@@ -132,6 +204,10 @@ type PipelineQueueCreateRequest struct {
 	// +optional
 	MaxMemoryMB float64 `json:"maxMemoryMB,omitempty"`
 
+	// FairShare is the group config used by FAIR_SHARE schedule strategy.
+	// +optional
+	FairShare *PipelineQueueFairShare `json:"fairShare,omitempty"`
+
 	// Labels contains the other infos for this queue.
 	// Labels can be used to query and filter queues.
 	// +optional
@@ -172,6 +248,17 @@ func (req *PipelineQueueCreateRequest) Validate() error {
 	if !req.ScheduleStrategy.IsValid() {
 		return fmt.Errorf("invalid schedule strategy: %s", req.ScheduleStrategy)
 	}
+	// fair share
+	if req.ScheduleStrategy == ScheduleStrategyInsidePipelineQueueOfFairShare {
+		if req.FairShare == nil {
+			req.FairShare = &PipelineQueueFairShare{}
+		}
+		if err := req.FairShare.Validate(); err != nil {
+			return err
+		}
+	} else {
+		req.FairShare = nil
+	}
 	// priority
 	if req.Priority == 0 {
 		req.Priority = PipelineQueueDefaultPriority
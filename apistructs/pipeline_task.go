@@ -78,6 +78,39 @@ type PipelineTaskGetBootstrapInfoResponseData struct {
 	Data []byte `json:"data"`
 }
 
+// agent 读写对象存储中 context 和 caches 的操作，由 pipeline 签名或代为完成分片上传
+const (
+	PipelineTaskOSSActionGet               = "get"
+	PipelineTaskOSSActionPut               = "put"
+	PipelineTaskOSSActionCreateMultipart   = "create-multipart"
+	PipelineTaskOSSActionUploadPart        = "upload-part"
+	PipelineTaskOSSActionCompleteMultipart = "complete-multipart"
+	PipelineTaskOSSActionAbortMultipart    = "abort-multipart"
+)
+
+type PipelineTaskOSSRequest struct {
+	Action     string                `json:"action"`
+	Key        string                `json:"key"`
+	UploadID   string                `json:"uploadID,omitempty"`
+	PartNumber int                   `json:"partNumber,omitempty"`
+	Parts      []PipelineTaskOSSPart `json:"parts,omitempty"`
+}
+
+type PipelineTaskOSSPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+type PipelineTaskOSSResponse struct {
+	Header
+	Data *PipelineTaskOSSResponseData `json:"data"`
+}
+
+type PipelineTaskOSSResponseData struct {
+	URL      string `json:"url,omitempty"` // 临时地址，get、put、upload-part 时返回
+	UploadID string `json:"uploadID,omitempty"`
+}
+
 type PipelineTaskMachineStat struct {
 	Host PipelineTaskMachineHostStat `json:"host,omitempty"`
 	Pod  PipelineTaskMachinePodStat  `json:"pod,omitempty"`
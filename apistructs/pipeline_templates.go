@@ -15,6 +15,10 @@ package apistructs
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"time"
 )
 
@@ -160,6 +164,30 @@ type PipelineTemplate struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	Version   string    `json:"version"`
+	// Dependents 发布新版本时返回引用该模板的流水线
+	Dependents []PipelineTemplateDependent `json:"dependents,omitempty"`
+}
+
+// PipelineTemplateDependent 引用模板的流水线
+type PipelineTemplateDependent struct {
+	ReferrerSource    string    `json:"referrerSource"`    // 引用方流水线 source
+	ReferrerName      string    `json:"referrerName"`      // 引用方流水线 ymlName
+	VersionConstraint string    `json:"versionConstraint"` // 引用时声明的版本约束
+	ResolvedVersion   string    `json:"resolvedVersion"`   // 最近一次解析得到的版本
+	Matched           bool      `json:"matched"`           // 指定版本是否满足该版本约束
+	LastResolvedAt    time.Time `json:"lastResolvedAt"`
+}
+
+type PipelineTemplateDependentQueryRequest struct {
+	ScopeType string `json:"scopeType"`
+	ScopeID   string `json:"scopeID"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+}
+
+type PipelineTemplateDependentQueryResponse struct {
+	Header
+	Data []PipelineTemplateDependent `json:"data"`
 }
 
 type PipelineTemplateVersion struct {
@@ -198,10 +226,15 @@ func (p *PipelineTemplateSpec) Check() error {
 	//}
 
 	if p.Params != nil {
+		names := make(map[string]struct{}, len(p.Params))
 		for _, v := range p.Params {
 			if err := v.Check(); err != nil {
 				return err
 			}
+			if _, ok := names[v.Name]; ok {
+				return fmt.Errorf("params %s is duplicated", v.Name)
+			}
+			names[v.Name] = struct{}{}
 		}
 	}
 
@@ -231,9 +264,105 @@ func (params *PipelineParam) Check() error {
 		return errors.New("params name can not empty")
 	}
 
+	switch params.Type {
+	case "", PipelineParamStringType, PipelineParamIntType, PipelineParamNumberType,
+		PipelineParamBoolType, PipelineParamBoolAliasType, PipelineParamListType:
+	case PipelineParamEnumType:
+		if len(params.Options) == 0 {
+			return fmt.Errorf("params %s with type enum must have options", params.Name)
+		}
+	default:
+		return fmt.Errorf("params %s type %s not support", params.Name, params.Type)
+	}
+
+	if params.Default != nil {
+		if _, err := params.CheckValue(params.Default); err != nil {
+			return fmt.Errorf("params %s default value invalid: %v", params.Name, err)
+		}
+	}
+
 	return nil
 }
 
+// CheckValue 根据参数类型校验值，并返回转换后的值
+func (params *PipelineParam) CheckValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, fmt.Errorf("value is empty")
+	}
+	kind := reflect.TypeOf(value).Kind()
+
+	switch params.Type {
+	case PipelineParamIntType, PipelineParamNumberType:
+		var f float64
+		switch v := reflect.ValueOf(value); kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return int(v.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int(v.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+		case reflect.String:
+			parsed, err := strconv.ParseFloat(v.String(), 64)
+			if err != nil {
+				return nil, fmt.Errorf("value %v is not a number", value)
+			}
+			f = parsed
+		default:
+			return nil, fmt.Errorf("value %v is not a number", value)
+		}
+		if f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int(f), nil
+		}
+		if params.Type == PipelineParamIntType {
+			return nil, fmt.Errorf("value %v is not an integer", value)
+		}
+		return f, nil
+
+	case PipelineParamBoolType, PipelineParamBoolAliasType:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			// 保留原值，避免渲染结果与用户输入不一致
+			if _, err := strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("value %v is not a bool", value)
+			}
+			return v, nil
+		}
+		return nil, fmt.Errorf("value %v is not a bool", value)
+
+	case PipelineParamEnumType:
+		for _, option := range params.Options {
+			if fmt.Sprintf("%v", option) == fmt.Sprintf("%v", value) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("value %v not in options %v", value, params.Options)
+
+	case PipelineParamListType:
+		if kind != reflect.Slice && kind != reflect.Array {
+			return nil, fmt.Errorf("value %v is not a list", value)
+		}
+		v := reflect.ValueOf(value)
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, v.Index(i).Interface())
+		}
+		return list, nil
+
+	case "":
+		// 未声明类型的参数不做校验
+		return value, nil
+
+	default:
+		switch kind {
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Float32, reflect.Float64, reflect.Bool:
+			return value, nil
+		}
+		return nil, fmt.Errorf("value type %v not support", kind)
+	}
+}
+
 type PipelineTemplateSpecOutput struct {
 	Name string `json:"name" yaml:"name"`
 	Desc string `json:"desc" yaml:"desc"`
@@ -245,8 +374,12 @@ func (output *PipelineOutput) Check() error {
 		return errors.New("outputs name can not empty")
 	}
 
-	if output.Ref == "" {
-		return errors.New("outputs ref can not empty")
+	if output.Ref == "" && output.Computed == "" {
+		return fmt.Errorf("outputs %s ref or computed can not empty", output.Name)
+	}
+
+	if output.Ref != "" && output.Computed != "" {
+		return fmt.Errorf("outputs %s can not have both ref and computed", output.Name)
 	}
 
 	return nil
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineParam_CheckValue(t *testing.T) {
+	number := PipelineParam{Name: "replicas", Type: PipelineParamNumberType}
+	v, err := number.CheckValue("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = number.CheckValue(float64(1.5))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, v)
+	_, err = number.CheckValue("three")
+	assert.Error(t, err)
+
+	integer := PipelineParam{Name: "replicas", Type: PipelineParamIntType}
+	_, err = integer.CheckValue(1.5)
+	assert.Error(t, err)
+
+	boolean := PipelineParam{Name: "debug", Type: PipelineParamBoolAliasType}
+	_, err = boolean.CheckValue("yes")
+	assert.Error(t, err)
+	v, err = boolean.CheckValue(true)
+	assert.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	enum := PipelineParam{Name: "env", Type: PipelineParamEnumType, Options: []interface{}{"dev", "prod"}}
+	_, err = enum.CheckValue("test")
+	assert.Error(t, err)
+	v, err = enum.CheckValue("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", v)
+
+	list := PipelineParam{Name: "hosts", Type: PipelineParamListType}
+	v, err = list.CheckValue([]string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, v)
+	_, err = list.CheckValue("a")
+	assert.Error(t, err)
+
+	// 未声明类型时不校验
+	untyped := PipelineParam{Name: "config"}
+	v, err = untyped.CheckValue(map[string]interface{}{"a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1}, v)
+}
+
+func TestPipelineTemplateSpec_Check(t *testing.T) {
+	spec := PipelineTemplateSpec{
+		Name:     "deploy",
+		Template: "version: 1.1",
+		Params: []*PipelineParam{
+			{Name: "env", Type: PipelineParamEnumType, Options: []interface{}{"dev"}, Default: "dev"},
+		},
+		Outputs: []*PipelineOutput{{Name: "target", Computed: "{{ env }}"}},
+	}
+	assert.NoError(t, spec.Check())
+
+	spec.Params = append(spec.Params, &PipelineParam{Name: "env"})
+	assert.Error(t, spec.Check())
+
+	spec.Params = []*PipelineParam{{Name: "env", Type: PipelineParamEnumType}}
+	assert.Error(t, spec.Check())
+
+	spec.Params = []*PipelineParam{{Name: "replicas", Type: PipelineParamNumberType, Default: "x"}}
+	assert.Error(t, spec.Check())
+
+	spec.Params = []*PipelineParam{{Name: "replicas", Type: "object"}}
+	assert.Error(t, spec.Check())
+
+	spec.Params = []*PipelineParam{{Name: "hosts", Default: []interface{}{"a"}}, {Name: "config", Default: map[string]interface{}{"a": 1}}}
+	assert.NoError(t, spec.Check())
+
+	spec.Params = nil
+	spec.Outputs = []*PipelineOutput{{Name: "target", Ref: "${a:OUTPUT:b}", Computed: "{{ env }}"}}
+	assert.Error(t, spec.Check())
+}
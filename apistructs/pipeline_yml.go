@@ -24,9 +24,13 @@ const (
 )
 
 const (
-	PipelineParamStringType = "string"
-	PipelineParamIntType    = "int"
-	PipelineParamBoolType   = "boolean"
+	PipelineParamStringType    = "string"
+	PipelineParamIntType       = "int"
+	PipelineParamNumberType    = "number"
+	PipelineParamBoolType      = "boolean"
+	PipelineParamBoolAliasType = "bool"
+	PipelineParamEnumType      = "enum"
+	PipelineParamListType      = "list"
 )
 
 type PipelineYml struct {
@@ -35,6 +39,8 @@ type PipelineYml struct {
 	Envs            map[string]string      `json:"envs,omitempty"`            // 环境变量
 	Cron            string                 `json:"cron,omitempty"`            // 定时配置
 	CronCompensator *CronCompensator       `json:"cronCompensator,omitempty"` // 定时补偿配置
+	CronBlackouts   []CronBlackout         `json:"cronBlackouts,omitempty"`   // 定时禁止触发窗口
+	Concurrency     *PipelineConcurrency   `json:"concurrency,omitempty"`     // 并发组配置
 	Stages          [][]*PipelineYmlAction `json:"stages"`                    // 流水线
 	FlatActions     []*PipelineYmlAction   `json:"flatActions"`               // 展平了的流水线
 
@@ -93,6 +99,7 @@ type PipelineYmlAction struct {
 	DisplayName   string                 `json:"displayName,omitempty"`                                    // 中文名称
 	LogoUrl       string                 `json:"logoUrl,omitempty"`                                        // logo
 	Caches        []ActionCache          `json:"caches,omitempty"`                                         // 缓存
+	Artifacts     []ActionArtifact       `json:"artifacts,omitempty"`                                      // 制品
 	SnippetConfig *SnippetConfig         `json:"snippet_config,omitempty" yaml:"snippet_config,omitempty"` // snippet 的配置
 	If            string                 `json:"if,omitempty"`                                             // 条件执行
 	Loop          *PipelineTaskLoop      `json:"loop,omitempty"`                                           // 循环执行
@@ -117,17 +124,19 @@ type BatchSnippetConfigYml struct {
 }
 
 type PipelineParam struct {
-	Name     string      `json:"name" yaml:"name,omitempty"`         // 名称
-	Required bool        `json:"required" yaml:"required,omitempty"` // 是否必须
-	Default  interface{} `json:"default" yaml:"default,omitempty"`   // 默认值
-	Desc     string      `json:"desc" yaml:"desc,omitempty"`         // 描述
-	Type     string      `json:"type" yaml:"type,omitempty"`         // 类型
+	Name     string        `json:"name" yaml:"name,omitempty"`                 // 名称
+	Required bool          `json:"required" yaml:"required,omitempty"`         // 是否必须
+	Default  interface{}   `json:"default" yaml:"default,omitempty"`           // 默认值
+	Desc     string        `json:"desc" yaml:"desc,omitempty"`                 // 描述
+	Type     string        `json:"type" yaml:"type,omitempty"`                 // 类型
+	Options  []interface{} `json:"options,omitempty" yaml:"options,omitempty"` // enum 类型的可选值
 }
 
 type PipelineOutput struct {
-	Name string `json:"name" yaml:"name,omitempty"` // 名称
-	Desc string `json:"desc" yaml:"desc,omitempty"` // 描述
-	Ref  string `json:"ref" yaml:"ref,omitempty"`   // 引用那个 action 的值
+	Name     string `json:"name" yaml:"name,omitempty"`                   // 名称
+	Desc     string `json:"desc" yaml:"desc,omitempty"`                   // 描述
+	Ref      string `json:"ref" yaml:"ref,omitempty"`                     // 引用那个 action 的值
+	Computed string `json:"computed,omitempty" yaml:"computed,omitempty"` // 基于模板入参计算的值，与 ref 二选一
 }
 
 type PipelineOutputWithValue struct {
@@ -149,6 +158,19 @@ type CronCompensator struct {
 	StopIfLatterExecuted bool `json:"stopIfLatterExecuted"`
 }
 
+// PipelineConcurrency 并发组配置
+type PipelineConcurrency struct {
+	Group            string `json:"group"`
+	CancelInProgress bool   `json:"cancelInProgress"`
+}
+
+// CronBlackout 定时禁止触发窗口 [start, end)
+type CronBlackout struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
 type PipelineYmlParseGraphRequest struct {
 	PipelineYmlContent        string            `json:"pipelineYmlContent"`
 	GlobalSnippetConfigLabels map[string]string `json:"globalSnippetConfigLabels"`
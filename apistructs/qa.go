@@ -249,6 +249,45 @@ type TestCallBackRequest struct {
 	// Totals is the aggregated results of all tests.
 	Totals *TestTotals  `json:"totals"`
 	Suites []*TestSuite `json:"suites,omitempty"`
+
+	// Coverage is the code coverage of the same commit, optional.
+	Coverage *TestCoverage `json:"coverage,omitempty"`
+}
+
+// TestCoverage 代码覆盖率
+// go coverprofile 以语句为单位统计，对应到 Lines 字段
+type TestCoverage struct {
+	Format          string                 `json:"format"`
+	LinesCovered    int64                  `json:"linesCovered"`
+	LinesValid      int64                  `json:"linesValid"`
+	LineRate        float64                `json:"lineRate"`
+	BranchesCovered int64                  `json:"branchesCovered"`
+	BranchesValid   int64                  `json:"branchesValid"`
+	BranchRate      float64                `json:"branchRate"`
+	Packages        []*TestCoveragePackage `json:"packages,omitempty"`
+}
+
+// TestCoveragePackage 包级别覆盖率
+type TestCoveragePackage struct {
+	Name         string              `json:"name"`
+	LinesCovered int64               `json:"linesCovered"`
+	LinesValid   int64               `json:"linesValid"`
+	LineRate     float64             `json:"lineRate"`
+	Files        []*TestCoverageFile `json:"files,omitempty"`
+}
+
+// TestCoverageFile 文件级别覆盖率
+type TestCoverageFile struct {
+	Name         string  `json:"name"`
+	LinesCovered int64   `json:"linesCovered"`
+	LinesValid   int64   `json:"linesValid"`
+	LineRate     float64 `json:"lineRate"`
+}
+
+// TestCoverageGetRequest 查询应用某次提交的覆盖率
+type TestCoverageGetRequest struct {
+	ApplicationID int64  `schema:"applicationId,required"`
+	CommitID      string `schema:"commitId"`
 }
 
 type SonarIssueResponse struct {
@@ -49,3 +49,22 @@ type RegistryUserInfo struct {
 type RegistryAuthJson struct {
 	Auths map[string]RegistryUserInfo `json:"auths"`
 }
+
+// RegistryManifestsSizeRequest 查询指定集群Registry镜像大小请求
+// POST /api/clusters/{idOrName}/registry/manifests/actions/size
+type RegistryManifestsSizeRequest struct {
+	Images      []string `json:"images"`      // 待查询的镜像列表
+	RegistryURL string   `json:"registryURL"` // Registry地址, 接口自动根据集群配置赋值
+}
+
+// RegistryManifestsSizeResponse 查询指定集群Registry镜像大小响应
+type RegistryManifestsSizeResponse struct {
+	Header
+	Data RegistryManifestsSizeResponseData `json:"data"`
+}
+
+// RegistryManifestsSizeResponseData 镜像大小(所有 layer 与 config 之和，字节)和失败信息
+type RegistryManifestsSizeResponseData struct {
+	Sizes  map[string]int64  `json:"sizes"`
+	Failed map[string]string `json:"failed"`
+}
@@ -160,6 +160,11 @@ type ReleaseUpdateResponse struct {
 type ReleaseReferenceUpdateRequest struct {
 	ReleaseID string `json:"-" path:"releaseId"`
 	Increase  bool   `json:"increase"` // true:reference+1  false:reference-1
+
+	// 以下为部署信息，选填，用于记录 release 部署在哪些环境
+	RuntimeID    uint64 `json:"runtimeId,omitempty"`
+	DeploymentID uint64 `json:"deploymentId,omitempty"`
+	Workspace    string `json:"workspace,omitempty"`
 }
 
 // ReleaseDeleteRequest 删除 release API(DELETE /api/releases/{releaseId})使用
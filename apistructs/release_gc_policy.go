@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import "time"
+
+// ReleaseGCPolicy 项目级 release 镜像回收策略, 打标签(version)或被引用的 release 始终保留
+type ReleaseGCPolicy struct {
+	ProjectID uint64 `json:"projectId"`
+	// Enabled 是否启用，未启用的项目沿用全局回收规则
+	Enabled bool `json:"enabled"`
+	// KeepLastPerBranch 每个应用每个分支保留最近的 release 数, 0 表示不按数量保留
+	KeepLastPerBranch int `json:"keepLastPerBranch"`
+	// KeepDeployedDays 最近 N 天内部署过的 release 保留, 0 表示不按部署时间保留
+	KeepDeployedDays int       `json:"keepDeployedDays"`
+	Operator         string    `json:"operator"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ReleaseGCPolicyGetRequest GET /api/release-gc-policies/{projectId}
+type ReleaseGCPolicyGetRequest struct {
+	ProjectID uint64 `json:"-" path:"projectId"`
+}
+
+// ReleaseGCPolicyGetResponse 查询回收策略响应
+type ReleaseGCPolicyGetResponse struct {
+	Header
+	Data ReleaseGCPolicy `json:"data"`
+}
+
+// ReleaseGCPolicyUpdateRequest PUT /api/release-gc-policies/{projectId}
+type ReleaseGCPolicyUpdateRequest struct {
+	ProjectID         uint64 `json:"-" path:"projectId"`
+	Enabled           bool   `json:"enabled"`
+	KeepLastPerBranch int    `json:"keepLastPerBranch"`
+	KeepDeployedDays  int    `json:"keepDeployedDays"`
+}
+
+// ReleaseGCPolicyUpdateResponse 更新回收策略响应
+type ReleaseGCPolicyUpdateResponse struct {
+	Header
+	Data ReleaseGCPolicy `json:"data"`
+}
+
+// ReleaseGCDryRunRequest GET /api/release-gc-policies/{projectId}/actions/dry-run
+// 未指定参数时使用已保存的策略, 否则按参数覆盖后的策略预演
+type ReleaseGCDryRunRequest struct {
+	ProjectID         uint64 `json:"-" path:"projectId"`
+	KeepLastPerBranch *int   `json:"-" query:"keepLastPerBranch"`
+	KeepDeployedDays  *int   `json:"-" query:"keepDeployedDays"`
+}
+
+// ReleaseGCDryRunResponse 回收预演响应
+type ReleaseGCDryRunResponse struct {
+	Header
+	Data ReleaseGCReport `json:"data"`
+}
+
+// ReleaseGCReport 回收预演报告, 列出将被删除的 release 及可释放的空间
+type ReleaseGCReport struct {
+	ProjectID uint64          `json:"projectId"`
+	Policy    ReleaseGCPolicy `json:"policy"`
+	// Total 项目下 release 总数
+	Total int `json:"total"`
+	// Releases 将被删除的 release
+	Releases []ReleaseGCCandidate `json:"releases"`
+	// FreedBytes 预计释放的镜像空间(字节), 仅统计不被其他 release 引用的镜像
+	FreedBytes int64 `json:"freedBytes"`
+	// SizeUnknown 存在无法获取大小的镜像时为 true, 此时 FreedBytes 偏小
+	SizeUnknown bool `json:"sizeUnknown"`
+}
+
+// ReleaseGCCandidate 待回收 release
+type ReleaseGCCandidate struct {
+	ReleaseID       string           `json:"releaseId"`
+	ReleaseName     string           `json:"releaseName"`
+	ApplicationID   int64            `json:"applicationId"`
+	ApplicationName string           `json:"applicationName"`
+	Branch          string           `json:"branch"`
+	ClusterName     string           `json:"clusterName"`
+	CreatedAt       time.Time        `json:"createdAt"`
+	LastDeployedAt  *time.Time       `json:"lastDeployedAt,omitempty"` // 没有部署记录时为空
+	Reason          string           `json:"reason"`
+	Images          []ReleaseGCImage `json:"images"`
+}
+
+// ReleaseGCImage 待回收镜像
+type ReleaseGCImage struct {
+	Image string `json:"image"`
+	// Size 镜像大小(字节), -1 表示未知
+	Size int64 `json:"size"`
+	// KeepManifest 被其他 release 引用或不在集群 registry 中的镜像只删除元信息, 不删除 manifest, 不计入释放空间
+	KeepManifest bool `json:"keepManifest"`
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import "time"
+
+// ReleasePromotionStatus release 晋级状态
+type ReleasePromotionStatus string
+
+const (
+	// ReleasePromotionStatusPending 等待审批
+	ReleasePromotionStatusPending ReleasePromotionStatus = "PENDING"
+	// ReleasePromotionStatusPromoted 已晋级
+	ReleasePromotionStatusPromoted ReleasePromotionStatus = "PROMOTED"
+	// ReleasePromotionStatusRejected 审批拒绝
+	ReleasePromotionStatusRejected ReleasePromotionStatus = "REJECTED"
+)
+
+// ReleasePromotionWorkspaces release 晋级顺序, 晋级至某环境前须已晋级至前一环境
+var ReleasePromotionWorkspaces = []DiceWorkspace{DevWorkspace, TestWorkspace, StagingWorkspace, ProdWorkspace}
+
+// ReleasePromotion release 晋级记录
+type ReleasePromotion struct {
+	ID            uint64                 `json:"id"`
+	ReleaseID     string                 `json:"releaseId"`
+	OrgID         int64                  `json:"orgId"`
+	ProjectID     int64                  `json:"projectId"`
+	ApplicationID int64                  `json:"applicationId"`
+	Workspace     string                 `json:"workspace"`
+	Status        ReleasePromotionStatus `json:"status"`
+	// Overlay 该环境的 dice.yml 覆盖配置, 格式同 dice.yml environments 下的单个环境
+	Overlay      string    `json:"overlay"`
+	NeedApproval bool      `json:"needApproval"`
+	ApprovalID   uint64    `json:"approvalId"`
+	Desc         string    `json:"desc"`
+	Operator     string    `json:"operator"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ReleasePromotionCreateRequest POST /api/releases/{releaseId}/promotions
+type ReleasePromotionCreateRequest struct {
+	ReleaseID    string `json:"-" path:"releaseId"`
+	Workspace    string `json:"workspace"`
+	Overlay      string `json:"overlay"`
+	NeedApproval bool   `json:"needApproval"`
+	Desc         string `json:"desc"`
+}
+
+// ReleasePromotionCreateResponse 创建晋级记录响应
+type ReleasePromotionCreateResponse struct {
+	Header
+	Data ReleasePromotion `json:"data"`
+}
+
+// ReleasePromotionListRequest GET /api/releases/{releaseId}/promotions
+type ReleasePromotionListRequest struct {
+	ReleaseID string `json:"-" path:"releaseId"`
+}
+
+// ReleasePromotionListResponse 晋级记录列表响应, 按创建时间倒序
+type ReleasePromotionListResponse struct {
+	Header
+	Data []ReleasePromotion `json:"data"`
+}
+
+// ReleaseDeployment release 部署信息
+type ReleaseDeployment struct {
+	ReleaseID    string    `json:"releaseId"`
+	RuntimeID    uint64    `json:"runtimeId"`
+	DeploymentID uint64    `json:"deploymentId"`
+	Workspace    string    `json:"workspace"`
+	DeployedAt   time.Time `json:"deployedAt"`
+}
+
+// ReleaseDeploymentListRequest GET /api/releases/{releaseId}/deployments
+type ReleaseDeploymentListRequest struct {
+	ReleaseID string `json:"-" path:"releaseId"`
+}
+
+// ReleaseDeploymentListResponse release 当前部署的 runtime 列表
+type ReleaseDeploymentListResponse struct {
+	Header
+	Data []ReleaseDeployment `json:"data"`
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import "time"
+
+// ReleaseScanStatus release 镜像扫描状态
+type ReleaseScanStatus string
+
+const (
+	ReleaseScanStatusRunning ReleaseScanStatus = "RUNNING"
+	ReleaseScanStatusSuccess ReleaseScanStatus = "SUCCESS"
+	ReleaseScanStatusFailed  ReleaseScanStatus = "FAILED"
+)
+
+// 漏洞等级, 由低到高
+const (
+	VulnSeverityUnknown  = "UNKNOWN"
+	VulnSeverityLow      = "LOW"
+	VulnSeverityMedium   = "MEDIUM"
+	VulnSeverityHigh     = "HIGH"
+	VulnSeverityCritical = "CRITICAL"
+)
+
+// SBOM 包类型
+const (
+	SBOMPackageTypeDeb   = "deb"
+	SBOMPackageTypeApk   = "apk"
+	SBOMPackageTypeGo    = "go"
+	SBOMPackageTypeMaven = "maven"
+	SBOMPackageTypeNpm   = "npm"
+)
+
+// SBOMPackage 镜像中的软件包
+type SBOMPackage struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"` // maven 为 groupId:artifactId
+	Version string `json:"version"`
+	Path    string `json:"path"` // 包所在文件
+}
+
+// ReleaseVulnerability 镜像漏洞
+type ReleaseVulnerability struct {
+	ID           string `json:"id"`
+	Severity     string `json:"severity"`
+	Type         string `json:"type"`
+	Package      string `json:"package"`
+	Version      string `json:"version"`
+	FixedVersion string `json:"fixedVersion"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	Path         string `json:"path"`
+}
+
+// ReleaseImageScan 单个镜像的扫描结果
+type ReleaseImageScan struct {
+	Image           string                 `json:"image"`
+	OS              string                 `json:"os"`
+	Packages        []SBOMPackage          `json:"packages,omitempty"`
+	Vulnerabilities []ReleaseVulnerability `json:"vulnerabilities"`
+	Error           string                 `json:"error,omitempty"`
+}
+
+// ReleaseScanReport release 扫描报告
+type ReleaseScanReport struct {
+	ReleaseID string            `json:"releaseId"`
+	Status    ReleaseScanStatus `json:"status"`
+	// Summary 各等级漏洞数
+	Summary    map[string]int     `json:"summary"`
+	Images     []ReleaseImageScan `json:"images"`
+	DBVersion  string             `json:"dbVersion"` // 漏洞库版本
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt *time.Time         `json:"finishedAt"`
+}
+
+// ReleaseScanCreateRequest POST /api/releases/{releaseId}/actions/scan
+type ReleaseScanCreateRequest struct {
+	ReleaseID string `json:"-" path:"releaseId"`
+}
+
+// ReleaseScanCreateResponse 触发扫描响应
+type ReleaseScanCreateResponse struct {
+	Header
+	Data ReleaseScanReport `json:"data"`
+}
+
+// ReleaseScanReportGetRequest GET /api/releases/{releaseId}/scan-report
+type ReleaseScanReportGetRequest struct {
+	ReleaseID string `json:"-" path:"releaseId"`
+	// WithSBOM 是否返回 SBOM
+	WithSBOM bool `json:"-" query:"withSBOM"`
+}
+
+// ReleaseScanReportGetResponse 扫描报告响应
+type ReleaseScanReportGetResponse struct {
+	Header
+	Data ReleaseScanReport `json:"data"`
+}
+
+// ReleaseScanGateRequest GET /api/releases/{releaseId}/scan-report/actions/check-gate
+type ReleaseScanGateRequest struct {
+	ReleaseID string `json:"-" path:"releaseId"`
+	// Severity 阻断等级, 存在该等级及以上的漏洞时不通过, 为空时使用 dicehub 默认配置
+	Severity string `json:"-" query:"severity"`
+	// Workspace 部署环境, PROD 环境下未扫描、扫描中或扫描失败的 release 不通过
+	Workspace string `json:"-" query:"workspace"`
+}
+
+// ReleaseScanGateResponse 漏洞门禁检查响应
+type ReleaseScanGateResponse struct {
+	Header
+	Data ReleaseScanGateResult `json:"data"`
+}
+
+// ReleaseScanGateResult 漏洞门禁检查结果
+type ReleaseScanGateResult struct {
+	ReleaseID string            `json:"releaseId"`
+	Severity  string            `json:"severity"`
+	Workspace string            `json:"workspace,omitempty"`
+	Status    ReleaseScanStatus `json:"status"` // 未扫描时为空
+	Passed    bool              `json:"passed"`
+	Enforced  bool              `json:"enforced"` // 卡点未开启时始终通过
+	Summary   map[string]int    `json:"summary"`
+	Message   string            `json:"message"`
+}
@@ -13,6 +13,12 @@
 
 package apistructs
 
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
 const (
 	RunnerTaskStatusPending  = "pending"
 	RunnerTaskStatusRunning  = "running"
@@ -31,6 +37,9 @@ type RunnerTask struct {
 	Commands       []string `json:"commands"`
 	Targets        []string `json:"targets"`
 	WorkDir        string   `json:"workdir"`
+	// Labels is the label selector, only runners matching all labels can fetch the task
+	Labels   map[string]string `json:"labels,omitempty"`
+	RunnerID uint64            `json:"runner_id,omitempty"`
 }
 
 type QueryRunnerTaskRequest struct {
@@ -43,11 +52,12 @@ type QueryRunnerTaskResponse struct {
 }
 
 type CreateRunnerTaskRequest struct {
-	JobID          string   `json:"job_id"`
-	ContextDataUrl string   `json:"context_data_url"`
-	Commands       []string `json:"commands"`
-	Targets        []string `json:"targets"`
-	WorkDir        string   `json:"workdir"`
+	JobID          string            `json:"job_id"`
+	ContextDataUrl string            `json:"context_data_url"`
+	Commands       []string          `json:"commands"`
+	Targets        []string          `json:"targets"`
+	WorkDir        string            `json:"workdir"`
+	Labels         map[string]string `json:"labels"`
 }
 
 type CreateRunnerTaskResponse struct {
@@ -62,3 +72,109 @@ type UpdateRunnerTaskRequest struct {
 	ContextDataUrl string `json:"context_data_url"`
 	ResultDataUrl  string `json:"result_data_url"`
 }
+
+const (
+	RunnerStatusOnline  = "online"
+	RunnerStatusOffline = "offline"
+
+	// well-known runner labels, values of the same key are separated by comma, e.g. tools=xcode,cocoapods
+	RunnerLabelOS    = "os"
+	RunnerLabelArch  = "arch"
+	RunnerLabelTools = "tools"
+	// RunnerLabelPool runners with pool label are dedicated, only tasks selecting the same pool will be dispatched
+	RunnerLabelPool = "pool"
+
+	// ActionLabelRunnerPrefix action labels with this prefix are used as runner task label selector, e.g. runner.os: darwin
+	ActionLabelRunnerPrefix = "runner."
+	// EnvPipelineRunnerLabels runner label selector of the action, format: os=darwin,arch=arm64
+	EnvPipelineRunnerLabels = "PIPELINE_RUNNER_LABELS"
+)
+
+type Runner struct {
+	ID              uint64            `json:"id"`
+	Name            string            `json:"name"`
+	Labels          map[string]string `json:"labels"`
+	MaxTask         int               `json:"max_task"`
+	RunningTasks    int               `json:"running_tasks"`
+	Status          string            `json:"status"` // online offline
+	LastHeartbeatAt time.Time         `json:"last_heartbeat_at"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+type RegisterRunnerRequest struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels"`
+	MaxTask int               `json:"max_task"`
+}
+
+type RegisterRunnerResponse struct {
+	Header
+	Data Runner `json:"data"`
+}
+
+type RunnerHeartbeatRequest struct {
+	ID           uint64 `json:"-"`
+	RunningTasks int    `json:"running_tasks"`
+}
+
+type ListRunnersRequest struct {
+	Status string `schema:"status"`
+	// Labels label selector, format: os=darwin,arch=arm64
+	Labels string `schema:"labels"`
+}
+
+type ListRunnersResponse struct {
+	Header
+	Data []*Runner `json:"data"`
+}
+
+type FetchRunnerTaskRequest struct {
+	RunnerID uint64 `schema:"runnerID"`
+}
+
+// ParseRunnerLabels parse labels from format: os=darwin,arch=arm64,tools=xcode,cocoapods
+// segments without `=` belong to the previous label value
+func ParseRunnerLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	var lastKey string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			if lastKey != "" {
+				labels[lastKey] += "," + item
+			}
+			continue
+		}
+		lastKey = strings.TrimSpace(kv[0])
+		if lastKey == "" {
+			continue
+		}
+		labels[lastKey] = strings.TrimSpace(kv[1])
+	}
+	return labels
+}
+
+// FormatRunnerLabels format labels to string which can be parsed by ParseRunnerLabels
+func FormatRunnerLabels(labels map[string]string) string {
+	var items []string
+	for k, v := range labels {
+		items = append(items, k+"="+v)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// RunnerLabelsFromActionLabels get runner label selector from action labels with prefix `runner.`
+func RunnerLabelsFromActionLabels(actionLabels map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range actionLabels {
+		if strings.HasPrefix(k, ActionLabelRunnerPrefix) && len(k) > len(ActionLabelRunnerPrefix) {
+			labels[strings.TrimPrefix(k, ActionLabelRunnerPrefix)] = v
+		}
+	}
+	return labels
+}
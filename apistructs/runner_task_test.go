@@ -0,0 +1,42 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistructs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRunnerLabels(t *testing.T) {
+	labels := ParseRunnerLabels("os=darwin, arch=arm64,tools=xcode,cocoapods,pool=ios")
+	assert.Equal(t, map[string]string{
+		"os":    "darwin",
+		"arch":  "arm64",
+		"tools": "xcode,cocoapods",
+		"pool":  "ios",
+	}, labels)
+	assert.Equal(t, labels, ParseRunnerLabels(FormatRunnerLabels(labels)))
+	assert.Empty(t, ParseRunnerLabels(""))
+}
+
+func TestRunnerLabelsFromActionLabels(t *testing.T) {
+	labels := RunnerLabelsFromActionLabels(map[string]string{
+		"runner.os":   "darwin",
+		"runner.pool": "ios",
+		"runner.":     "invalid",
+		"other":       "value",
+	})
+	assert.Equal(t, map[string]string{"os": "darwin", "pool": "ios"}, labels)
+}
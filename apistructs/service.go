@@ -204,6 +204,17 @@ type Dice struct {
 
 	// Namespace indicates namespace for kubernetes
 	ProjectNamespace string `json:"projectNamespace"`
+	// NamespaceQuota is reconciled as ResourceQuota and LimitRange of the namespace, nil means leaving them untouched
+	NamespaceQuota *NamespaceQuota `json:"namespaceQuota,omitempty"`
+}
+
+// NamespaceQuota 项目配额，同步为 namespace 上的 k8s ResourceQuota(limits.cpu, limits.memory)
+// CPU 与 Mem 都为 0 时删除 ResourceQuota 与 LimitRange
+type NamespaceQuota struct {
+	// 单位: core
+	CPU float64 `json:"cpu"`
+	// 单位: MB
+	Mem float64 `json:"mem"`
 }
 
 // ServicePort support service set port and protocol
@@ -283,6 +294,8 @@ type Service struct {
 	MeshEnable *bool `json:"mesh_enable,omitempty"`
 	// 对应 istio 的流量加密策略
 	TrafficSecurity diceyml.TrafficSecurity `json:"traffic_security,omitempty"`
+	// 对应 istio 的流量管理策略：权重、路由、重试、超时、故障注入、熔断
+	TrafficManagement *diceyml.TrafficManagement `json:"traffic_management,omitempty"`
 	// TODO: status should not show in Service spec, Service spec should only contains static description
 
 	// WorkLoad indicates the type of service，
@@ -422,6 +435,8 @@ type ServiceGroupCreateV2Request struct {
 	// map[servicename]volumeinfo
 	Volumes          map[string]RequestVolumeInfo `json:"volumes"`
 	ProjectNamespace string                       `json:"projectNamespace"`
+	// 项目配额
+	NamespaceQuota *NamespaceQuota `json:"namespaceQuota,omitempty"`
 }
 type RequestVolumeInfo struct {
 	ID            string `json:"id"`
@@ -538,6 +553,48 @@ type ServiceGroupPrecheckNodeData struct {
 	Info   string `json:"info"`
 }
 
+type ServiceGroupPlanRequest ServiceGroupUpdateV2Request
+type ServiceGroupPlanResponse struct {
+	Header
+	Data ServiceGroupPlanData `json:"data"`
+}
+
+// ServiceGroupPlanData servicegroup 更新的 dry-run 结果
+type ServiceGroupPlanData struct {
+	Items []ServiceGroupPlanItem `json:"items"`
+	// 将会触发重启的服务
+	Restarts []string `json:"restarts"`
+}
+
+type ServiceGroupPlanAction string
+
+const (
+	ServiceGroupPlanCreate ServiceGroupPlanAction = "create"
+	ServiceGroupPlanUpdate ServiceGroupPlanAction = "update"
+	ServiceGroupPlanDelete ServiceGroupPlanAction = "delete"
+)
+
+// ServiceGroupPlanItem 单个 k8s 对象的变更
+type ServiceGroupPlanItem struct {
+	// enum: Deployment, DaemonSet, Service
+	Kind      string                 `json:"kind"`
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Service   string                 `json:"service"`
+	Action    ServiceGroupPlanAction `json:"action"`
+	// 是否会重建 pod
+	Restart bool                        `json:"restart"`
+	Diffs   []ServiceGroupPlanFieldDiff `json:"diffs,omitempty"`
+}
+
+type ServiceGroupPlanFieldDiff struct {
+	// e.g. spec.template.spec.containers[web].env.FOO
+	// env 的值可能包含 secret，只展示摘要
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
 type ServiceGroupConfigUpdateResponse struct {
 	Header
 }
@@ -208,6 +208,9 @@ type StatusDesc struct {
 	UnScheduledReasons ResourceInsufficientInfo `json:"unScheduledReasons,omitempty"`
 }
 
+// StatusReasonQuotaExceeded 超出 namespace 资源配额导致 pod 无法创建
+const StatusReasonQuotaExceeded = "QuotaExceeded"
+
 // Bind 定义宿主机上的路径挂载到容器中
 type Bind struct {
 	// ContainerPath 指容器路径
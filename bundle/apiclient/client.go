@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package apiclient 是根据 openapi 中的 API 定义生成的 client，
+// 类型化的方法由 modules/openapi/api/generate 生成到 zz_generated_client.go，bundle 可以逐步委托给它。
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle/apierrors"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/httputil"
+)
+
+// Route 描述一个生成的 client 方法对应的 API
+type Route struct {
+	Name   string
+	Method string
+	Path   string
+}
+
+// Client 调用某个服务的 API
+type Client struct {
+	hc     *httpclient.HTTPClient
+	host   string
+	header http.Header
+}
+
+// Option 定义 Client 的配置选项
+type Option func(*Client)
+
+// New 创建访问 host 的 Client
+func New(host string, options ...Option) *Client {
+	c := &Client{host: host, header: http.Header{}}
+	for _, op := range options {
+		op(c)
+	}
+	if c.hc == nil {
+		c.hc = httpclient.New(httpclient.WithTimeout(time.Second, time.Second*3))
+	}
+	return c
+}
+
+// WithHTTPClient 配置 http 客户端对象
+func WithHTTPClient(hc *httpclient.HTTPClient) Option {
+	return func(c *Client) {
+		c.hc = hc
+	}
+}
+
+// WithHeader 配置每个请求都会带上的 header，比如 User-ID
+func WithHeader(k, v string) Option {
+	return func(c *Client) {
+		c.header.Set(k, v)
+	}
+}
+
+// Do 调用 API，req 中 path tag 的字段填充 path 变量，query/schema tag 的字段作为 query 参数，
+// 对于有 body 的请求，req 整体作为 json body 发送；应答解析到 resp 中。
+func (c *Client) Do(method, path string, req, resp interface{}) error {
+	realPath, query, err := BuildRequest(method, path, req)
+	if err != nil {
+		return apierrors.ErrInvoke.InvalidParameter(err)
+	}
+	r := c.hc.Method(method, c.host).Path(realPath).Params(query).
+		Header(httputil.InternalHeader, "bundle").Headers(c.header)
+	if methodHasBody(method) && req != nil {
+		r = r.JSONBody(req)
+	}
+	var buf bytes.Buffer
+	httpResp, err := r.Do().Body(&buf)
+	if err != nil {
+		return apierrors.ErrInvoke.InternalError(err)
+	}
+	var header apistructs.Header
+	if err := json.Unmarshal(buf.Bytes(), &header); err != nil {
+		return apierrors.ErrInvoke.InternalError(fmt.Errorf("failed to decode response, status: %d, err: %v", httpResp.StatusCode(), err))
+	}
+	if !httpResp.IsOK() || !header.Success {
+		return errorresp.New(errorresp.WithCode(httpResp.StatusCode(), header.Error.Code), errorresp.WithMessage(header.Error.Msg))
+	}
+	if resp != nil {
+		if err := json.Unmarshal(buf.Bytes(), resp); err != nil {
+			return apierrors.ErrInvoke.InternalError(err)
+		}
+	}
+	return nil
+}
+
+// BuildRequest 根据 req 填充 path 中的 <xxx> 变量并生成 query 参数
+func BuildRequest(method, path string, req interface{}) (string, url.Values, error) {
+	query := url.Values{}
+	pathVars := map[string]string{}
+	if req != nil {
+		collectFields(reflect.ValueOf(req), !methodHasBody(method), pathVars, query)
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "<") || !strings.HasSuffix(part, ">") {
+			continue
+		}
+		name := part[1 : len(part)-1]
+		v, ok := pathVars[name]
+		if !ok || v == "" {
+			return "", nil, fmt.Errorf("missing path variable %s of %s", name, path)
+		}
+		if name == "*" {
+			parts[i] = v
+			continue
+		}
+		parts[i] = url.PathEscape(v)
+	}
+	return strings.Join(parts, "/"), query, nil
+}
+
+func collectFields(v reflect.Value, withQuery bool, pathVars map[string]string, query url.Values) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, fv := t.Field(i), v.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if name, ok := field.Tag.Lookup("path"); ok {
+			pathVars[name] = fmt.Sprint(fv.Interface())
+			continue
+		}
+		if field.Anonymous {
+			collectFields(fv, withQuery, pathVars, query)
+			continue
+		}
+		name, ok := field.Tag.Lookup("query")
+		if !ok {
+			if !withQuery {
+				continue
+			}
+			if name, ok = field.Tag.Lookup("schema"); !ok {
+				continue
+			}
+		}
+		name = strings.Split(name, ",")[0]
+		if name == "" || name == "-" || fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array {
+			for j := 0; j < fv.Len(); j++ {
+				query.Add(name, fmt.Sprint(fv.Index(j).Interface()))
+			}
+			continue
+		}
+		query.Add(name, fmt.Sprint(reflect.Indirect(fv).Interface()))
+	}
+}
+
+func methodHasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+type testListRequest struct {
+	OrgID    uint64   `path:"orgID"`
+	Name     string   `schema:"name"`
+	PageNo   int      `query:"pageNo"`
+	Statuses []string `schema:"status"`
+	Body     string   `json:"body"`
+}
+
+func TestBuildRequest(t *testing.T) {
+	req := testListRequest{OrgID: 1, Name: "erda", Statuses: []string{"a", "b"}, Body: "x"}
+	path, query, err := BuildRequest("GET", "/api/orgs/<orgID>/projects", &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/orgs/1/projects", path)
+	assert.Equal(t, "erda", query.Get("name"))
+	assert.Equal(t, []string{"a", "b"}, query["status"])
+	assert.Empty(t, query.Get("pageNo"))
+
+	// 有 body 的请求只有 query tag 的字段作为 query 参数
+	req.PageNo = 2
+	_, query, err = BuildRequest("POST", "/api/orgs/<orgID>/projects", req)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", query.Get("pageNo"))
+	assert.Empty(t, query.Get("name"))
+
+	_, _, err = BuildRequest("GET", "/api/orgs/<orgID>", struct{}{})
+	assert.Error(t, err)
+}
+
+func TestClientDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.Header.Get("User-ID"))
+		if strings.HasSuffix(r.URL.Path, "/2") {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apistructs.Header{Error: apistructs.ErrorResponse{Code: "NotFound", Msg: "not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": r.URL.Path})
+	}))
+	defer srv.Close()
+
+	c := New(strings.TrimPrefix(srv.URL, "http://"), WithHeader("User-ID", "1"))
+	var resp struct {
+		apistructs.Header
+		Data string `json:"data"`
+	}
+	assert.NoError(t, c.Do("GET", "/api/orgs/<orgID>/projects", testListRequest{OrgID: 1}, &resp))
+	assert.Equal(t, "/api/orgs/1/projects", resp.Data)
+
+	err := c.Do("GET", "/api/orgs/<orgID>/projects/2", testListRequest{OrgID: 1}, &resp)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package bundle
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle/apierrors"
+	"github.com/erda-project/erda/pkg/httputil"
+)
+
+// VerifyPersonalAccessToken 校验个人访问令牌明文，返回令牌信息
+// 令牌不存在、已吊销或已过期时返回 http code 为 401 的 APIError
+func (b *Bundle) VerifyPersonalAccessToken(token string) (*apistructs.PersonalAccessToken, error) {
+	host, err := b.urls.CMDB()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var verifyResp apistructs.PersonalAccessTokenVerifyResponse
+	resp, err := hc.Post(host).Path("/api/personal-access-tokens/actions/verify").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(apistructs.PersonalAccessTokenVerifyRequest{Token: token}).
+		Do().JSON(&verifyResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !resp.IsOK() || !verifyResp.Success {
+		return nil, toAPIError(resp.StatusCode(), verifyResp.Error)
+	}
+	return &verifyResp.Data, nil
+}
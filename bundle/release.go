@@ -34,10 +34,14 @@ func (b *Bundle) GetDiceYAML(releaseID string, workspace ...string) (*diceyml.Di
 	hc := b.hc
 
 	var buf bytes.Buffer
-	r, err := hc.Get(host).Path(fmt.Sprintf("/api/releases/%s/actions/get-dice", releaseID)).
+	req := hc.Get(host).Path(fmt.Sprintf("/api/releases/%s/actions/get-dice", releaseID)).
 		Header("Accept", "application/x-yaml").
-		Header("Internal-Client", "true").
-		Do().Body(&buf)
+		Header("Internal-Client", "true")
+	if len(workspace) > 0 {
+		// 使用该环境晋级时的 dice.yml 覆盖配置
+		req = req.Param("workspace", workspace[0])
+	}
+	r, err := req.Do().Body(&buf)
 	if err != nil {
 		return nil, apierrors.ErrInvoke.InternalError(err)
 	}
@@ -107,22 +111,26 @@ func (b *Bundle) ListReleases(req apistructs.ReleaseListRequest) (*apistructs.Re
 
 // UpdateReference 更新 release 引用
 func (b *Bundle) UpdateReference(releaseID string, increase ...bool) error {
-	host, err := b.urls.DiceHub()
-	if err != nil {
-		return err
-	}
-	hc := b.hc
-
 	doIncrease := true // default is increase
 	if len(increase) > 0 && !increase[0] {
 		doIncrease = false
 	}
-	req := apistructs.ReleaseReferenceUpdateRequest{
+	return b.UpdateReferenceWithDeployment(apistructs.ReleaseReferenceUpdateRequest{
 		ReleaseID: releaseID,
 		Increase:  doIncrease,
+	})
+}
+
+// UpdateReferenceWithDeployment 更新 release 引用并记录部署信息
+func (b *Bundle) UpdateReferenceWithDeployment(req apistructs.ReleaseReferenceUpdateRequest) error {
+	host, err := b.urls.DiceHub()
+	if err != nil {
+		return err
 	}
+	hc := b.hc
+
 	var resp httpserver.Resp
-	r, err := hc.Put(host).Path(fmt.Sprintf("/api/releases/%s/reference/actions/change", releaseID)).
+	r, err := hc.Put(host).Path(fmt.Sprintf("/api/releases/%s/reference/actions/change", req.ReleaseID)).
 		Header("Internal-Client", "true").
 		JSONBody(&req).Do().JSON(&resp)
 	if err != nil {
@@ -143,3 +151,47 @@ func (b *Bundle) IncreaseReference(releaseID string) error {
 func (b *Bundle) DecreaseReference(releaseID string) error {
 	return b.UpdateReference(releaseID, false)
 }
+
+// ListReleasePromotions 获取 release 晋级记录
+func (b *Bundle) ListReleasePromotions(releaseID string) ([]apistructs.ReleasePromotion, error) {
+	host, err := b.urls.DiceHub()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var resp apistructs.ReleasePromotionListResponse
+	r, err := hc.Get(host).Path(fmt.Sprintf("/api/releases/%s/promotions", releaseID)).
+		Header("Internal-Client", "true").
+		Do().JSON(&resp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !r.IsOK() || !resp.Success {
+		return nil, toAPIError(r.StatusCode(), resp.Error)
+	}
+	return resp.Data, nil
+}
+
+// CheckReleaseScanGate 检查 release 镜像漏洞卡点, severity 为空时使用 dicehub 默认等级
+func (b *Bundle) CheckReleaseScanGate(releaseID, severity, workspace string) (*apistructs.ReleaseScanGateResult, error) {
+	host, err := b.urls.DiceHub()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var resp apistructs.ReleaseScanGateResponse
+	r, err := hc.Get(host).Path(fmt.Sprintf("/api/releases/%s/scan-report/actions/check-gate", releaseID)).
+		Header("Internal-Client", "true").
+		Param("severity", severity).
+		Param("workspace", workspace).
+		Do().JSON(&resp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !r.IsOK() || !resp.Success {
+		return nil, toAPIError(r.StatusCode(), resp.Error)
+	}
+	return &resp.Data, nil
+}
@@ -406,6 +406,19 @@ func (b *Bundle) PrecheckServiceGroup(sg apistructs.ServiceGroupPrecheckRequest)
 	return &resp.Data, nil
 }
 
+// PlanServiceGroupUpdate dry-run of UpdateServiceGroup, return the k8s objects which would be changed
+func (b *Bundle) PlanServiceGroupUpdate(sg apistructs.ServiceGroupPlanRequest) (
+	*apistructs.ServiceGroupPlanData, error) {
+	var resp apistructs.ServiceGroupPlanResponse
+	if err := callScheduler(b, sg, &resp, "/api/servicegroup/actions/plan", b.hc.Post); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, toAPIError(200, resp.Error)
+	}
+	return &resp.Data, nil
+}
+
 func callScheduler(b *Bundle, req, resp interface{}, path string,
 	httpfunc func(host string, retry ...httpclient.RetryOption) *httpclient.Request) error {
 	host, err := b.urls.Scheduler()
@@ -18,11 +18,13 @@ import (
 	"flag"
 	"io/ioutil"
 	"os"
+	"runtime"
 	"strconv"
 
 	"github.com/sirupsen/logrus"
 
 	_ "github.com/erda-project/erda-infra/base/version"
+	"github.com/erda-project/erda/apistructs"
 	actionrunner "github.com/erda-project/erda/modules/action-runner"
 )
 
@@ -66,6 +68,23 @@ func readConfig(path string) *actionrunner.Conf {
 		conf.FailedTaskKeepHours = 3
 	}
 	conf.MaxTask = convInt(getEnv("MAX_TASK", strconv.Itoa(conf.MaxTask)))
+	hostname, _ := os.Hostname()
+	conf.Name = getEnv("RUNNER_NAME", conf.Name)
+	if len(conf.Name) <= 0 {
+		conf.Name = hostname
+	}
+	if conf.Labels == nil {
+		conf.Labels = make(map[string]string)
+	}
+	for k, v := range apistructs.ParseRunnerLabels(os.Getenv("RUNNER_LABELS")) {
+		conf.Labels[k] = v
+	}
+	if _, ok := conf.Labels[apistructs.RunnerLabelOS]; !ok {
+		conf.Labels[apistructs.RunnerLabelOS] = runtime.GOOS
+	}
+	if _, ok := conf.Labels[apistructs.RunnerLabelArch]; !ok {
+		conf.Labels[apistructs.RunnerLabelArch] = runtime.GOARCH
+	}
 	return &conf
 }
 
@@ -14,6 +14,8 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/erda-project/erda/pkg/envconf"
 )
 
@@ -24,6 +26,11 @@ type Conf struct {
 	ClientID     string `env:"CLIENT_ID" default:"action-runner"`
 	ClientSecret string `env:"CLIENT_SECRET" default:"devops/action-runner"`
 	RunnerUserID string `env:"RUNNER_USER_ID" default:"1111"`
+
+	// runner is offline if no heartbeat within the timeout
+	RunnerHeartbeatTimeout time.Duration `env:"RUNNER_HEARTBEAT_TIMEOUT" default:"1m"`
+	// page size of pending tasks scanned to find a matched task for each fetch
+	FetchTaskScanLimit int `env:"FETCH_TASK_SCAN_LIMIT" default:"100"`
 }
 
 var cfg Conf
@@ -49,3 +56,11 @@ func ClientSecret() string {
 func RunnerUserID() string {
 	return cfg.RunnerUserID
 }
+
+func RunnerHeartbeatTimeout() time.Duration {
+	return cfg.RunnerHeartbeatTimeout
+}
+
+func FetchTaskScanLimit() int {
+	return cfg.FetchTaskScanLimit
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/dbengine"
+)
+
+type Runner struct {
+	dbengine.BaseModel
+	Name            string    `json:"name"`
+	Labels          string    `json:"labels"`
+	MaxTask         int       `json:"max_task"`
+	RunningTasks    int       `json:"running_tasks"`
+	LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+}
+
+// TableName set module's corresponding tableName.
+func (Runner) TableName() string {
+	return "dice_runners"
+}
+
+func (runner Runner) ToApiData() *apistructs.Runner {
+	result := &apistructs.Runner{
+		ID:              runner.ID,
+		Name:            runner.Name,
+		Labels:          map[string]string{},
+		MaxTask:         runner.MaxTask,
+		RunningTasks:    runner.RunningTasks,
+		LastHeartbeatAt: runner.LastHeartbeatAt,
+		CreatedAt:       runner.CreatedAt,
+	}
+	json.Unmarshal([]byte(runner.Labels), &result.Labels)
+	return result
+}
+
+// RegisterRunner create or update runner by name, the same machine keeps the same runner id after restart
+func (db *DBClient) RegisterRunner(request apistructs.RegisterRunnerRequest) (*Runner, error) {
+	labels, _ := json.Marshal(request.Labels)
+	var list []Runner
+	if err := db.Model(&Runner{}).Where("name =?", request.Name).Limit(1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	runner := &Runner{}
+	if len(list) > 0 {
+		runner = &list[0]
+	}
+	runner.Name = request.Name
+	runner.Labels = string(labels)
+	runner.MaxTask = request.MaxTask
+	runner.RunningTasks = 0
+	runner.LastHeartbeatAt = time.Now()
+	if err := db.Save(runner).Error; err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+func (db *DBClient) UpdateRunnerHeartbeat(id uint64, runningTasks int) error {
+	return db.Model(&Runner{}).Where("id =?", id).Updates(map[string]interface{}{
+		"running_tasks":     runningTasks,
+		"last_heartbeat_at": time.Now(),
+	}).Error
+}
+
+func (db *DBClient) GetRunner(id uint64) (*Runner, error) {
+	var result Runner
+	err := db.Model(&Runner{}).Where("id =?", id).Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (db *DBClient) ListRunners() ([]Runner, error) {
+	var list []Runner
+	if err := db.Model(&Runner{}).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
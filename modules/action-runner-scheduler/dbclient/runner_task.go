@@ -30,6 +30,8 @@ type RunnerTask struct {
 	WorkDir        string `json:"workdir"`
 	Commands       string `json:"commands"`
 	Targets        string `json:"targets"`
+	Labels         string `json:"labels"`
+	RunnerID       uint64 `json:"runner_id"`
 }
 
 // TableName set module's corresponding tableName.
@@ -48,15 +50,18 @@ func (task RunnerTask) ToApiData() *apistructs.RunnerTask {
 		Commands:       []string{},
 		Targets:        []string{},
 		WorkDir:        task.WorkDir,
+		RunnerID:       task.RunnerID,
 	}
 	json.Unmarshal([]byte(task.Commands), &result.Commands)
 	json.Unmarshal([]byte(task.Targets), &result.Targets)
+	json.Unmarshal([]byte(task.Labels), &result.Labels)
 	return result
 }
 
 func (db *DBClient) CreateRunnerTask(request apistructs.CreateRunnerTaskRequest) (uint64, error) {
 	commands, _ := json.Marshal(request.Commands)
 	targets, _ := json.Marshal(request.Targets)
+	labels, _ := json.Marshal(request.Labels)
 	task := &RunnerTask{
 		JobID:          request.JobID,
 		Status:         apistructs.RunnerTaskStatusPending,
@@ -65,6 +70,7 @@ func (db *DBClient) CreateRunnerTask(request apistructs.CreateRunnerTaskRequest)
 		Commands:       string(commands),
 		Targets:        string(targets),
 		WorkDir:        request.WorkDir,
+		Labels:         string(labels),
 	}
 	err := db.Save(task).Error
 	if err != nil {
@@ -88,6 +94,65 @@ func (db *DBClient) GetFirstPendingTask() (*RunnerTask, error) {
 	return &result, nil
 }
 
+// ListPendingTasks list a page of pending tasks with id greater than afterID in order of creation
+func (db *DBClient) ListPendingTasks(afterID uint64, limit int) ([]RunnerTask, error) {
+	var list []RunnerTask
+	err := db.Model(&RunnerTask{}).
+		Where("status =? AND id >?", apistructs.RunnerTaskStatusPending, afterID).
+		Order("id").
+		Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountRunningTasks count tasks dispatched to the runner and not finished yet
+func (db *DBClient) CountRunningTasks(runnerID uint64) (int, error) {
+	var count int
+	err := db.Model(&RunnerTask{}).
+		Where("runner_id =? AND status =?", runnerID, apistructs.RunnerTaskStatusRunning).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FailRunningTasksOfRunners set tasks running on the runners failed, returns the number of failed tasks
+func (db *DBClient) FailRunningTasksOfRunners(runnerIDs []uint64) (int64, error) {
+	if len(runnerIDs) == 0 {
+		return 0, nil
+	}
+	result := db.Model(&RunnerTask{}).
+		Where("runner_id IN (?) AND status =?", runnerIDs, apistructs.RunnerTaskStatusRunning).
+		Update("status", apistructs.RunnerTaskStatusFailed)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// AssignPendingTask set task running on the runner, returns false if the task is already fetched by other runner
+func (db *DBClient) AssignPendingTask(task *RunnerTask, runnerID uint64) (bool, error) {
+	result := db.Model(&RunnerTask{}).
+		Where("id =? AND status =?", task.ID, apistructs.RunnerTaskStatusPending).
+		Updates(map[string]interface{}{
+			"status":        apistructs.RunnerTaskStatusRunning,
+			"runner_id":     runnerID,
+			"openapi_token": task.OpenApiToken,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	task.Status = apistructs.RunnerTaskStatusRunning
+	task.RunnerID = runnerID
+	return true, nil
+}
+
 func (db *DBClient) UpdateRunnerTask(task *RunnerTask) error {
 	return db.Save(task).Error
 }
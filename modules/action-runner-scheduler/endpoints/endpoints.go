@@ -16,14 +16,17 @@ package endpoints
 import (
 	"net/http"
 
+	"github.com/gorilla/schema"
+
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/action-runner-scheduler/services/runnertask"
 	"github.com/erda-project/erda/pkg/httpserver"
 )
 
 type Endpoints struct {
-	runnerTask *runnertask.RunnerTask
-	bundle     *bundle.Bundle
+	runnerTask         *runnertask.RunnerTask
+	bundle             *bundle.Bundle
+	queryStringDecoder *schema.Decoder
 }
 
 type Option func(*Endpoints)
@@ -31,6 +34,8 @@ type Option func(*Endpoints)
 // New return an new Endpoints .
 func New(options ...Option) *Endpoints {
 	e := &Endpoints{}
+	e.queryStringDecoder = schema.NewDecoder()
+	e.queryStringDecoder.IgnoreUnknownKeys(true)
 
 	for _, op := range options {
 		op(e)
@@ -63,5 +68,10 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/runner/tasks/{id}", Method: http.MethodGet, Handler: e.GetRunnerTask},
 		{Path: "/api/runner/fetch-task", Method: http.MethodGet, Handler: e.FetchRunnerTask},
 		{Path: "/api/runner/collect/logs/{source}", Method: http.MethodPost, Handler: e.CollectLogs},
+
+		{Path: "/api/runner/runners", Method: http.MethodPost, Handler: e.RegisterRunner},
+		{Path: "/api/runner/runners", Method: http.MethodGet, Handler: e.ListRunners},
+		{Path: "/api/runner/runners/{id}", Method: http.MethodGet, Handler: e.GetRunner},
+		{Path: "/api/runner/runners/{id}/actions/heartbeat", Method: http.MethodPut, Handler: e.RunnerHeartbeat},
 	}
 }
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/action-runner-scheduler/services/apierrors"
+	"github.com/erda-project/erda/pkg/httpserver"
+)
+
+func (e *Endpoints) RegisterRunner(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var request apistructs.RegisterRunnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return apierrors.ErrRegisterRunner.InvalidParameter(err).ToResp(), nil
+	}
+
+	runner, err := e.runnerTask.RegisterRunner(request)
+	if err != nil {
+		return apierrors.ErrRegisterRunner.InternalError(err).ToResp(), nil
+	}
+	return httpserver.OkResp(runner)
+}
+
+func (e *Endpoints) RunnerHeartbeat(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRunnerHeartbeat.InvalidParameter(err).ToResp(), nil
+	}
+	var request apistructs.RunnerHeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return apierrors.ErrRunnerHeartbeat.InvalidParameter(err).ToResp(), nil
+	}
+	request.ID = id
+
+	if err := e.runnerTask.RunnerHeartbeat(request); err != nil {
+		return apierrors.ErrRunnerHeartbeat.InternalError(err).ToResp(), nil
+	}
+	return httpserver.OkResp("")
+}
+
+func (e *Endpoints) GetRunner(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetRunner.InvalidParameter(err).ToResp(), nil
+	}
+
+	runner, err := e.runnerTask.GetRunner(id)
+	if err != nil {
+		return apierrors.ErrGetRunner.InternalError(err).ToResp(), nil
+	}
+	return httpserver.OkResp(runner)
+}
+
+func (e *Endpoints) ListRunners(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var request apistructs.ListRunnersRequest
+	if err := e.queryStringDecoder.Decode(&request, r.URL.Query()); err != nil {
+		return apierrors.ErrListRunners.InvalidParameter(err).ToResp(), nil
+	}
+
+	runners, err := e.runnerTask.ListRunners(request)
+	if err != nil {
+		return apierrors.ErrListRunners.InternalError(err).ToResp(), nil
+	}
+	return httpserver.OkResp(runners)
+}
@@ -73,7 +73,12 @@ func (e *Endpoints) GetRunnerTask(ctx context.Context, r *http.Request, vars map
 }
 
 func (e *Endpoints) FetchRunnerTask(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
-	task, err := e.runnerTask.FetchRunnerTask()
+	var request apistructs.FetchRunnerTaskRequest
+	if err := e.queryStringDecoder.Decode(&request, r.URL.Query()); err != nil {
+		return apierrors.ErrFetchRunnerTask.InvalidParameter(err).ToResp(), nil
+	}
+
+	task, err := e.runnerTask.FetchRunnerTask(request.RunnerID)
 	if err != nil {
 		return apierrors.ErrFetchRunnerTask.InternalError(err).ToResp(), nil
 	}
@@ -35,6 +35,7 @@ func Initialize() error {
 
 	bdl := bundle.New(bundle.WithCollector(), bundle.WithCMDB(), bundle.WithOpenapi())
 	runnerTask := runnertask.New(runnertask.WithDBClient(db), runnertask.WithBundle(bdl))
+	runnerTask.StartDeadRunnerReaper(conf.RunnerHeartbeatTimeout())
 	ep := endpoints.New(
 		endpoints.WithRunnerTask(runnerTask),
 		endpoints.WithBundle(bdl),
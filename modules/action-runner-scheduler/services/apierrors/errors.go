@@ -23,6 +23,10 @@ var (
 	ErrUpdateRunnerTask  = err("ErrUpdateRunnerTask", "更新runner任务失败")
 	ErrFetchRunnerTask   = err("ErrFetchRunnerTask", "获取runner任务失败")
 	ErrCollectRunnerLogs = err("ErrCollectRunnerLogs", "收集runner日志失败")
+	ErrRegisterRunner    = err("ErrRegisterRunner", "注册runner失败")
+	ErrRunnerHeartbeat   = err("ErrRunnerHeartbeat", "上报runner心跳失败")
+	ErrGetRunner         = err("ErrGetRunner", "获取runner失败")
+	ErrListRunners       = err("ErrListRunners", "获取runner列表失败")
 )
 
 func err(template, defaultValue string) *errorresp.APIError {
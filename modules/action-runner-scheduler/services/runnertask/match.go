@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package runnertask
+
+import (
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// MatchLabels check whether the runner can run the task with the selector.
+// Every selector label must be satisfied, values are comma separated and all selector values must be
+// contained in runner values, e.g. selector tools=xcode matches runner tools=xcode,cocoapods.
+// Runners with pool label are dedicated and only match selectors with the same pool.
+func MatchLabels(selector, runnerLabels map[string]string) bool {
+	if runnerLabels[apistructs.RunnerLabelPool] != "" && selector[apistructs.RunnerLabelPool] == "" {
+		return false
+	}
+	return matchSelector(selector, runnerLabels)
+}
+
+// matchSelector is also used to filter runner inventory, dedicated pool is not considered here
+func matchSelector(selector, runnerLabels map[string]string) bool {
+	for k, v := range selector {
+		values := splitLabelValues(runnerLabels[k])
+		for want := range splitLabelValues(v) {
+			if !values[want] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func splitLabelValues(v string) map[string]bool {
+	values := make(map[string]bool)
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			values[item] = true
+		}
+	}
+	return values
+}
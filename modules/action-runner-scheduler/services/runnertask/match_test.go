@@ -0,0 +1,43 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package runnertask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchLabels(t *testing.T) {
+	mac := map[string]string{"os": "darwin", "arch": "arm64", "tools": "xcode,cocoapods", "pool": "ios"}
+	linux := map[string]string{"os": "linux", "arch": "amd64"}
+
+	cases := []struct {
+		selector map[string]string
+		runner   map[string]string
+		match    bool
+	}{
+		{nil, linux, true},
+		{nil, mac, false}, // dedicated pool
+		{map[string]string{"pool": "ios"}, mac, true},
+		{map[string]string{"pool": "ios", "tools": "xcode"}, mac, true},
+		{map[string]string{"pool": "ios", "tools": "xcode,fastlane"}, mac, false},
+		{map[string]string{"os": "darwin"}, linux, false},
+		{map[string]string{"arch": "amd64"}, linux, true},
+		{map[string]string{"pool": "ios"}, linux, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, MatchLabels(c.selector, c.runner), "selector: %v, runner: %v", c.selector, c.runner)
+	}
+}
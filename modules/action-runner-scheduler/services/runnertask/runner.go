@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package runnertask
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/action-runner-scheduler/conf"
+	"github.com/erda-project/erda/modules/action-runner-scheduler/dbclient"
+)
+
+func (f *RunnerTask) RegisterRunner(request apistructs.RegisterRunnerRequest) (*apistructs.Runner, error) {
+	if request.Name == "" {
+		return nil, errors.New("missing runner name")
+	}
+	if request.MaxTask < 1 {
+		request.MaxTask = 1
+	}
+	runner, err := f.db.RegisterRunner(request)
+	if err != nil {
+		return nil, err
+	}
+	// the runner restarts, tasks running on it before are lost
+	if n, err := f.db.FailRunningTasksOfRunners([]uint64{runner.ID}); err != nil {
+		logrus.Warnf("failed to fail lost tasks of restarted runner, runnerID: %d, err: %v", runner.ID, err)
+	} else if n > 0 {
+		logrus.Infof("failed %d lost tasks of restarted runner, runnerID: %d", n, runner.ID)
+	}
+	return withStatus(runner), nil
+}
+
+func (f *RunnerTask) RunnerHeartbeat(request apistructs.RunnerHeartbeatRequest) error {
+	if _, err := f.db.GetRunner(request.ID); err != nil {
+		return err
+	}
+	return f.db.UpdateRunnerHeartbeat(request.ID, request.RunningTasks)
+}
+
+func (f *RunnerTask) GetRunner(id uint64) (*apistructs.Runner, error) {
+	runner, err := f.db.GetRunner(id)
+	if err != nil {
+		return nil, err
+	}
+	return withStatus(runner), nil
+}
+
+// ListRunners list runners filtered by status and label selector
+func (f *RunnerTask) ListRunners(request apistructs.ListRunnersRequest) ([]*apistructs.Runner, error) {
+	runners, err := f.db.ListRunners()
+	if err != nil {
+		return nil, err
+	}
+	selector := apistructs.ParseRunnerLabels(request.Labels)
+	result := make([]*apistructs.Runner, 0, len(runners))
+	for i := range runners {
+		runner := withStatus(&runners[i])
+		if request.Status != "" && runner.Status != request.Status {
+			continue
+		}
+		if !matchSelector(selector, runner.Labels) {
+			continue
+		}
+		result = append(result, runner)
+	}
+	return result, nil
+}
+
+// StartDeadRunnerReaper periodically fail tasks running on runners without heartbeat,
+// so they are not counted against MaxTask forever and pipelines waiting for them can go on
+func (f *RunnerTask) StartDeadRunnerReaper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := f.reapDeadRunnerTasks(time.Now()); err != nil {
+				logrus.Warnf("failed to reap tasks of dead runners, err: %v", err)
+			}
+		}
+	}()
+}
+
+func (f *RunnerTask) reapDeadRunnerTasks(now time.Time) error {
+	runners, err := f.db.ListRunners()
+	if err != nil {
+		return err
+	}
+	runnerIDs := deadRunnerIDs(runners, now, conf.RunnerHeartbeatTimeout())
+	n, err := f.db.FailRunningTasksOfRunners(runnerIDs)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logrus.Infof("failed %d tasks of dead runners %v", n, runnerIDs)
+	}
+	return nil
+}
+
+// deadRunnerIDs runners without heartbeat within the timeout
+func deadRunnerIDs(runners []dbclient.Runner, now time.Time, timeout time.Duration) []uint64 {
+	var ids []uint64
+	for _, runner := range runners {
+		if now.Sub(runner.LastHeartbeatAt) > timeout {
+			ids = append(ids, runner.ID)
+		}
+	}
+	return ids
+}
+
+func withStatus(runner *dbclient.Runner) *apistructs.Runner {
+	result := runner.ToApiData()
+	result.Status = apistructs.RunnerStatusOffline
+	if time.Since(runner.LastHeartbeatAt) <= conf.RunnerHeartbeatTimeout() {
+		result.Status = apistructs.RunnerStatusOnline
+	}
+	return result
+}
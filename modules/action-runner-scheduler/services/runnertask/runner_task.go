@@ -16,6 +16,8 @@ package runnertask
 import (
 	"errors"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/action-runner-scheduler/conf"
@@ -79,15 +81,79 @@ func (f *RunnerTask) UpdateRunnerTask(request *apistructs.UpdateRunnerTaskReques
 	return f.db.UpdateRunnerTask(task)
 }
 
-func (f *RunnerTask) FetchRunnerTask() ([]*apistructs.RunnerTask, error) {
-	task, err := f.db.GetFirstPendingTask()
-	if err != nil {
-		return nil, err
+// FetchRunnerTask dispatch the first pending task matching the runner labels.
+// runnerID is 0 for runners without registration, which can only run tasks without label selector.
+// Nothing is dispatched once the in-flight tasks of the runner reach its MaxTask.
+func (f *RunnerTask) FetchRunnerTask(runnerID uint64) ([]*apistructs.RunnerTask, error) {
+	runnerLabels := map[string]string{}
+	if runnerID > 0 {
+		runner, err := f.db.GetRunner(runnerID)
+		if err != nil {
+			return nil, err
+		}
+		runnerLabels = runner.ToApiData().Labels
+		running, err := f.db.CountRunningTasks(runnerID)
+		if err != nil {
+			return nil, err
+		}
+		// fetching also means the runner is alive
+		if err := f.db.UpdateRunnerHeartbeat(runnerID, running); err != nil {
+			logrus.Warnf("failed to update runner heartbeat when fetch task, runnerID: %d, err: %v", runnerID, err)
+		}
+		if reachMaxTask(runner.MaxTask, running) {
+			return []*apistructs.RunnerTask{}, nil
+		}
+	}
+
+	// page through pending tasks, so tasks behind many unmatched ones are not starved
+	pageSize := conf.FetchTaskScanLimit()
+	if pageSize <= 0 {
+		pageSize = defaultFetchTaskPageSize
 	}
-	if task == nil {
-		return []*apistructs.RunnerTask{}, nil
+	var afterID uint64
+	for {
+		pendingTasks, err := f.db.ListPendingTasks(afterID, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pendingTasks {
+			task := &pendingTasks[i]
+			afterID = task.ID
+			if !MatchLabels(task.ToApiData().Labels, runnerLabels) {
+				continue
+			}
+
+			token, err := f.getOpenapiToken()
+			if err != nil {
+				return nil, err
+			}
+			task.OpenApiToken = token
+			ok, err := f.db.AssignPendingTask(task, runnerID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				// fetched by other runner
+				continue
+			}
+			return []*apistructs.RunnerTask{task.ToApiData()}, nil
+		}
+		if len(pendingTasks) < pageSize {
+			break
+		}
 	}
 
+	return []*apistructs.RunnerTask{}, nil
+}
+
+const defaultFetchTaskPageSize = 100
+
+// reachMaxTask runners registered before MaxTask was introduced have no limit
+func reachMaxTask(maxTask, running int) bool {
+	return maxTask > 0 && running >= maxTask
+}
+
+func (f *RunnerTask) getOpenapiToken() (string, error) {
 	token, err := f.bundle.GetOpenapiOAuth2Token(apistructs.OpenapiOAuth2TokenGetRequest{
 		ClientID:     conf.ClientID(),
 		ClientSecret: conf.ClientSecret(),
@@ -105,14 +171,7 @@ func (f *RunnerTask) FetchRunnerTask() ([]*apistructs.RunnerTask, error) {
 		},
 	})
 	if err != nil {
-		return nil, err
-	}
-	task.OpenApiToken = token.AccessToken
-	task.Status = apistructs.RunnerTaskStatusRunning
-	err = f.db.UpdateRunnerTask(task)
-	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return []*apistructs.RunnerTask{task.ToApiData()}, nil
+	return token.AccessToken, nil
 }
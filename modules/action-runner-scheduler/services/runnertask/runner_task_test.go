@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package runnertask
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/action-runner-scheduler/dbclient"
+)
+
+func TestReachMaxTask(t *testing.T) {
+	cases := []struct {
+		maxTask int
+		running int
+		reach   bool
+	}{
+		{0, 5, false},
+		{1, 0, false},
+		{1, 1, true},
+		{3, 2, false},
+		{3, 4, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.reach, reachMaxTask(c.maxTask, c.running), "maxTask: %d, running: %d", c.maxTask, c.running)
+	}
+}
+
+func TestDeadRunnerIDs(t *testing.T) {
+	now := time.Now()
+	runners := []dbclient.Runner{
+		{LastHeartbeatAt: now.Add(-10 * time.Second)},
+		{LastHeartbeatAt: now.Add(-2 * time.Minute)},
+		{LastHeartbeatAt: now.Add(-time.Minute)},
+	}
+	for i := range runners {
+		runners[i].ID = uint64(i + 1)
+	}
+	assert.Equal(t, []uint64{2}, deadRunnerIDs(runners, now, time.Minute))
+	assert.Empty(t, deadRunnerIDs(runners[:1], now, time.Minute))
+}
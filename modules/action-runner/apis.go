@@ -37,6 +37,7 @@ type TaskListResponse struct {
 func (r *Runner) fetchTasks() []*Task {
 	request := httpclient.New(httpclient.WithCompleteRedirect()).Get(r.Conf.OpenAPI).
 		Path("/api/runner/fetch-task").
+		Param("runnerID", strconv.FormatUint(r.id, 10)).
 		Header("Content-Type", "application/json").
 		Header("Authorization", r.Conf.Token)
 	var resp TaskListResponse
@@ -60,6 +61,52 @@ func (r *Runner) fetchTasks() []*Task {
 	return resp.Data
 }
 
+// registerRunner invoke HTTP API to register runner with labels.
+func (r *Runner) registerRunner() (uint64, error) {
+	request := httpclient.New(httpclient.WithCompleteRedirect()).Post(r.Conf.OpenAPI).
+		Path("/api/runner/runners").
+		Header("Content-Type", "application/json").
+		Header("Authorization", r.Conf.Token)
+	var resp apistructs.RegisterRunnerResponse
+	httpResp, err := request.JSONBody(apistructs.RegisterRunnerRequest{
+		Name:    r.Conf.Name,
+		Labels:  r.Conf.Labels,
+		MaxTask: r.Conf.MaxTask,
+	}).Do().JSON(&resp)
+	if err != nil {
+		return 0, err
+	}
+	if !httpResp.IsOK() {
+		return 0, fmt.Errorf("fail to register runner, status code: %d, body: %s", httpResp.StatusCode(), string(httpResp.Body()))
+	}
+	if !resp.Success {
+		return 0, fmt.Errorf(resp.Error.Msg)
+	}
+	return resp.Data.ID, nil
+}
+
+// reportHeartbeat invoke HTTP API to report runner heartbeat.
+func (r *Runner) reportHeartbeat(runningTasks int) error {
+	request := httpclient.New(httpclient.WithCompleteRedirect()).Put(r.Conf.OpenAPI).
+		Path(fmt.Sprintf("/api/runner/runners/%d/actions/heartbeat", r.id)).
+		Header("Content-Type", "application/json").
+		Header("Authorization", r.Conf.Token)
+	var resp apistructs.Header
+	httpResp, err := request.JSONBody(apistructs.RunnerHeartbeatRequest{
+		RunningTasks: runningTasks,
+	}).Do().JSON(&resp)
+	if err != nil {
+		return err
+	}
+	if !httpResp.IsOK() {
+		return fmt.Errorf("fail to report heartbeat, status code: %d, body: %s", httpResp.StatusCode(), string(httpResp.Body()))
+	}
+	if !resp.Success {
+		return fmt.Errorf(resp.Error.Msg)
+	}
+	return nil
+}
+
 func (w *worker) taskResultCallback(id int, status, fileURL string) error {
 	request := httpclient.New(httpclient.WithCompleteRedirect()).Put(w.r.Conf.OpenAPI).
 		Path("/api/runner/tasks/"+strconv.Itoa(id)).
@@ -22,4 +22,6 @@ type Conf struct {
 	FailedTaskKeepHours int               `json:"failed_task_keep_hours"`
 	Params              map[string]string `json:"params"`
 	StartupCommands     []string          `json:"startup_commands"`
+	Name                string            `json:"name"`
+	Labels              map[string]string `json:"labels"`
 }
@@ -32,6 +32,8 @@ type Runner struct {
 	Conf  *Conf
 	queue chan *Task
 	tasks int32
+	// id is assigned by runner-scheduler after registration
+	id uint64
 }
 
 // New .
@@ -48,6 +50,8 @@ func (r *Runner) Run() error {
 	if err != nil {
 		return err
 	}
+	r.register()
+	go r.heartbeat()
 	for i := 0; i < r.Conf.MaxTask; i++ {
 		go r.worker()
 	}
@@ -108,6 +112,30 @@ func (r *Runner) reloadTasks() {
 	}
 }
 
+// register register runner with labels, keep retrying until success.
+func (r *Runner) register() {
+	for {
+		id, err := r.registerRunner()
+		if err == nil {
+			r.id = id
+			logrus.Infof("register runner success, id: %d, name: %s, labels: %v", id, r.Conf.Name, r.Conf.Labels)
+			return
+		}
+		logrus.Errorf("fail to register runner: %s", err)
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func (r *Runner) heartbeat() {
+	interval := 15 * time.Second
+	for {
+		time.Sleep(interval)
+		if err := r.reportHeartbeat(int(atomic.LoadInt32(&r.tasks))); err != nil {
+			logrus.Errorf("fail to report heartbeat: %s", err)
+		}
+	}
+}
+
 func (r *Runner) worker() {
 	log := r.newLogger()
 	for task := range r.queue {
@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package agenttool
+
+import (
+	gotar "archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TarStream 将 srcDir 打包写入 w，包内路径以 srcDir 的目录名开头，和 Tar 保持一致
+// 用于直接上传到对象存储，不在本地生成 tar 文件
+func TarStream(w io.Writer, srcDir string) error {
+	srcDir = filepath.Clean(srcDir)
+	return TarPathsStream(w, filepath.Dir(srcDir), srcDir)
+}
+
+// TarPathsStream 将多个文件或目录打包写入 w，包内路径为相对 baseDir 的路径
+// 不在 baseDir 下的路径使用去掉前导 / 的绝对路径
+func TarPathsStream(w io.Writer, baseDir string, paths ...string) error {
+	baseDir = filepath.Clean(baseDir)
+
+	tw := gotar.NewWriter(w)
+	for _, p := range paths {
+		if err := filepath.Walk(filepath.Clean(p), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return writeTarEntry(tw, baseDir, path, info)
+		}); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeTarEntry(tw *gotar.Writer, baseDir, path string, info os.FileInfo) error {
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	}
+	hdr, err := gotar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	name, err := filepath.Rel(baseDir, path)
+	if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(os.PathSeparator)) {
+		name = strings.TrimPrefix(filepath.Clean(path), string(os.PathSeparator))
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// UnTarStream 将 r 中的 tar 内容解压到 destDir 下
+// 解析软链接后位于 destDir 之外的路径和指向 destDir 之外的软链接都会被拒绝
+func UnTarStream(r io.Reader, destDir string) error {
+	destDir = filepath.Clean(destDir)
+	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return err
+	}
+	realDestDir, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return err
+	}
+
+	tr := gotar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(destDir, hdr.Name)
+		if target != destDir && !strings.HasPrefix(target, destDir+string(os.PathSeparator)) {
+			return errors.Errorf("invalid tar entry: %s", hdr.Name)
+		}
+
+		// 软链接条目会被替换，只校验其所在目录；其他条目写入时会跟随已存在的软链接，需要校验自身
+		checkPath := target
+		if hdr.Typeflag == gotar.TypeSymlink {
+			checkPath = filepath.Dir(target)
+		}
+		if err := checkInDir(realDestDir, checkPath); err != nil {
+			return errors.Errorf("invalid tar entry: %s, err: %v", hdr.Name, err)
+		}
+
+		switch hdr.Typeflag {
+		case gotar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)|0700); err != nil {
+				return err
+			}
+		case gotar.TypeSymlink:
+			linkTarget := hdr.Linkname
+			if !filepath.IsAbs(linkTarget) {
+				linkTarget = filepath.Join(filepath.Dir(target), linkTarget)
+			}
+			if err := checkInDir(realDestDir, linkTarget); err != nil {
+				return errors.Errorf("invalid tar entry: %s -> %s, err: %v", hdr.Name, hdr.Linkname, err)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case gotar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+			_ = os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+		default:
+			// ignore other types, such as devices
+		}
+	}
+}
+
+// checkInDir 校验 path 解析软链接后位于 realRoot 下，path 中尚不存在的部分按字面拼接
+func checkInDir(realRoot, path string) error {
+	existing := filepath.Clean(path)
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	real = filepath.Join(append([]string{real}, rest...)...)
+	if real != realRoot && !strings.HasPrefix(real, realRoot+string(os.PathSeparator)) {
+		return errors.Errorf("%s resolves outside %s", path, realRoot)
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// 不受 umask 影响
+	return os.Chmod(path, mode)
+}
+
+// DirDigest 根据目录下所有文件的路径、大小、权限和修改时间及软链接指向计算指纹
+// 不读取文件内容，用于快速判断缓存目录是否发生变化
+func DirDigest(dir string) (string, error) {
+	var lines []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, fmt.Sprintf("%s\t->\t%s", filepath.ToSlash(rel), link))
+		case info.Mode().IsRegular():
+			// tar 中修改时间会四舍五入到秒
+			lines = append(lines, fmt.Sprintf("%s\t%d\t%s\t%d", filepath.ToSlash(rel), info.Size(), info.Mode(), info.ModTime().Round(time.Second).Unix()))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+
+	hasher := sha256.New()
+	for _, line := range lines {
+		hasher.Write([]byte(line))
+		hasher.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
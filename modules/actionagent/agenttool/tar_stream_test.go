@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package agenttool
+
+import (
+	gotar "archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarStream(t *testing.T) {
+	src, err := ioutil.TempDir("", "tar-stream-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(src)
+	dest, err := ioutil.TempDir("", "tar-stream-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dest)
+
+	ns := filepath.Join(src, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(ns, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(ns, "sub", "a.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.Symlink("sub/a.txt", filepath.Join(ns, "link")))
+
+	digest, err := DirDigest(ns)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, TarStream(&buf, ns))
+	require.NoError(t, UnTarStream(&buf, dest))
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "repo", "link"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	restoredDigest, err := DirDigest(filepath.Join(dest, "repo"))
+	require.NoError(t, err)
+	require.Equal(t, digest, restoredDigest)
+}
+
+func TestTarPathsStream(t *testing.T) {
+	src, err := ioutil.TempDir("", "tar-paths-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(src)
+	dest, err := ioutil.TempDir("", "tar-paths-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dest)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "target", "classes"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "target", "app.jar"), []byte("jar"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "target", "classes", "A.class"), []byte("class"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "README.md"), []byte("readme"), 0644))
+
+	var buf bytes.Buffer
+	require.NoError(t, TarPathsStream(&buf, src, filepath.Join(src, "target", "app.jar"), filepath.Join(src, "target", "classes")))
+	require.NoError(t, UnTarStream(&buf, dest))
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "target", "app.jar"))
+	require.NoError(t, err)
+	require.Equal(t, "jar", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dest, "target", "classes", "A.class"))
+	require.NoError(t, err)
+	require.Equal(t, "class", string(b))
+	_, err = os.Stat(filepath.Join(dest, "README.md"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestUnTarStreamRejectOutside(t *testing.T) {
+	dest, err := ioutil.TempDir("", "tar-stream-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dest)
+	outside, err := ioutil.TempDir("", "tar-stream-outside")
+	require.NoError(t, err)
+	defer os.RemoveAll(outside)
+
+	makeTar := func(hdrs ...*gotar.Header) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := gotar.NewWriter(&buf)
+		for _, hdr := range hdrs {
+			require.NoError(t, tw.WriteHeader(hdr))
+		}
+		require.NoError(t, tw.Close())
+		return &buf
+	}
+
+	// 指向 destDir 之外的软链接
+	require.Error(t, UnTarStream(makeTar(&gotar.Header{Name: "repo/out", Typeflag: gotar.TypeSymlink, Linkname: outside}), dest))
+	require.Error(t, UnTarStream(makeTar(&gotar.Header{Name: "repo/out", Typeflag: gotar.TypeSymlink, Linkname: "../../out"}), dest))
+
+	// 通过已存在的软链接写到 destDir 之外
+	require.NoError(t, os.Symlink(outside, filepath.Join(dest, "escape")))
+	require.Error(t, UnTarStream(makeTar(&gotar.Header{Name: "escape/a.txt", Typeflag: gotar.TypeReg, Mode: 0644}), dest))
+	_, err = os.Stat(filepath.Join(outside, "a.txt"))
+	require.True(t, os.IsNotExist(err))
+
+	// 指向 destDir 内的软链接允许
+	require.NoError(t, UnTarStream(makeTar(&gotar.Header{Name: "repo/link", Typeflag: gotar.TypeSymlink, Linkname: "../repo"}), dest))
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package actionagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/actionagent/agenttool"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	logUploadArtifactPrefix   = "[upload artifacts] "
+	logRestoreArtifactPrefix  = "[restore artifacts] "
+	artifactTarFileNameSuffix = ".tar"
+)
+
+// uploadArtifacts 打包并上传 action 声明的制品，上传结果回调给 pipeline 记录
+func (agent *Agent) uploadArtifacts() {
+	if len(agent.Arg.Artifacts) == 0 {
+		return
+	}
+
+	logrus.Println(logUploadArtifactPrefix + "begin")
+	defer logrus.Println(logUploadArtifactPrefix + "done")
+
+	var uploaded []apistructs.ActionCallbackArtifact
+	for _, artifact := range agent.Arg.Artifacts {
+		cbArtifact, err := agent.uploadArtifact(artifact)
+		if err != nil {
+			agent.AppendError(errors.Errorf("failed to upload artifact %s, err: %v", artifact.Name, err))
+			continue
+		}
+		if cbArtifact == nil {
+			continue
+		}
+		logrus.Printf(logUploadArtifactPrefix+"upload success, name: %s, size: %s\n",
+			artifact.Name, datasize.ByteSize(cbArtifact.Size).HumanReadable())
+		uploaded = append(uploaded, *cbArtifact)
+	}
+	if len(uploaded) == 0 {
+		return
+	}
+	if err := agent.callbackToPipelinePlatform(&Callback{Artifacts: uploaded}); err != nil {
+		agent.AppendError(err)
+	}
+}
+
+// uploadArtifact 返回 nil 表示没有匹配到需要上传的文件
+func (agent *Agent) uploadArtifact(artifact apistructs.ActionArtifact) (*apistructs.ActionCallbackArtifact, error) {
+	paths, err := matchArtifactPaths(agent.EasyUse.ContainerWd, artifact.Paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		logrus.Printf(logUploadArtifactPrefix+"ignore artifact, no files matched, name: %s, paths: %v\n", artifact.Name, artifact.Paths)
+		return nil, nil
+	}
+
+	expireIn := artifact.ExpireIn
+	if expireIn == "" {
+		expireIn = defaultFileExpireIn
+	}
+	expireDuration, err := time.ParseDuration(expireIn)
+	if err != nil {
+		return nil, errors.Errorf("invalid expireIn: %s", expireIn)
+	}
+
+	// 打包到本地文件，上传失败时可以重试
+	tarPath := filepath.Join(agent.EasyUse.ContainerTempTarUploadDir, artifact.Name+artifactTarFileNameSuffix)
+	f, err := os.Create(tarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(tarPath)
+	}()
+	if err := agenttool.TarPathsStream(f, agent.EasyUse.ContainerWd, paths...); err != nil {
+		return nil, err
+	}
+
+	diceFile, err := agent.uploadFile(f, expireIn)
+	if err != nil {
+		return nil, err
+	}
+	expiredAt := diceFile.ExpiredAt
+	if expiredAt == nil {
+		t := time.Now().Add(expireDuration)
+		expiredAt = &t
+	}
+	return &apistructs.ActionCallbackArtifact{
+		Name:      artifact.Name,
+		FileUUID:  diceFile.UUID,
+		FileName:  diceFile.DisplayName,
+		Size:      diceFile.ByteSize,
+		ExpiredAt: expiredAt,
+	}, nil
+}
+
+// matchArtifactPaths 按通配符匹配制品路径，相对路径基于 workdir
+func matchArtifactPaths(workdir string, patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(workdir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Errorf("invalid path pattern: %s, err: %v", pattern, err)
+		}
+		paths = append(paths, matches...)
+	}
+	return strutil.DedupSlice(paths), nil
+}
+
+// restoreArtifacts 下载引用的制品并解压到指定目录
+func (agent *Agent) restoreArtifacts() {
+	for _, a := range agent.Arg.Context.Artifacts {
+		fileUUID := a.Labels[pvolumes.VoLabelKeyDiceFileUUID]
+		if err := agent.downloadArtifact(fileUUID, a.Value); err != nil {
+			agent.AppendError(errors.Errorf("failed to restore artifact %s, uuid: %s, err: %v", a.Name, fileUUID, err))
+			continue
+		}
+		logrus.Printf(logRestoreArtifactPrefix+"restore success, name: %s, path: %s\n", a.Name, a.Value)
+	}
+}
+
+func (agent *Agent) downloadArtifact(fileUUID, destDir string) error {
+	respBody, resp, err := httpclient.New(httpclient.WithCompleteRedirect()).
+		Get(agent.EasyUse.OpenAPIAddr).
+		Path("/api/files").
+		Param("file", fileUUID).
+		Header("Authorization", agent.EasyUse.TokenForBootstrap).
+		Do().StreamBody()
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	if !resp.IsOK() {
+		return fmt.Errorf("statusCode: %d", resp.StatusCode())
+	}
+	return agenttool.UnTarStream(respBody, destDir)
+}
@@ -20,6 +20,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,10 +34,21 @@ import (
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
+// MetaKeySecretRedactions 回调 metadata 中记录 secret 脱敏次数的 key
+const MetaKeySecretRedactions = "secret_redactions"
+
 func (agent *Agent) Callback() {
 	cb := &Callback{}
 	defer func() {
 		cb.Errors = append(cb.Errors, agent.MergeErrors()...)
+		// 审计: 日志中 secret 被脱敏的次数
+		if n := agent.EasyUse.SecretMasker.Redactions(); n > 0 {
+			cb.Metadata = append(cb.Metadata, apistructs.MetadataField{
+				Name:  MetaKeySecretRedactions,
+				Value: strconv.FormatUint(n, 10),
+				Level: apistructs.MetadataLevelWarn,
+			})
+		}
 		agent.LockPushedMetaFileMap.Lock()
 		defer agent.LockPushedMetaFileMap.Unlock()
 		if err := agent.callbackToPipelinePlatform(cb); err != nil {
@@ -92,7 +104,7 @@ func (agent *Agent) callbackToPipelinePlatform(cb *Callback) (err error) {
 	}
 
 	// 如果全部为空，则不需要回调
-	if len(cb.Metadata) == 0 && len(cb.Errors) == 0 && cb.MachineStat == nil {
+	if len(cb.Metadata) == 0 && len(cb.Errors) == 0 && cb.MachineStat == nil && len(cb.Artifacts) == 0 {
 		return nil
 	}
 
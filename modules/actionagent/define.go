@@ -20,6 +20,7 @@ import (
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/actionagent/filewatch"
+	"github.com/erda-project/erda/modules/actionagent/masker"
 	"github.com/erda-project/erda/modules/pipeline/spec"
 )
 
@@ -57,6 +58,10 @@ type AgentArg struct {
 
 	PrivateEnvs map[string]string `json:"privateEnvs,omitempty"`
 
+	SecretValues []string `json:"secretValues,omitempty"` // 需要在日志中脱敏的 secret 值
+
+	Artifacts []apistructs.ActionArtifact `json:"artifacts,omitempty"` // 执行结束后需要上传的制品
+
 	PipelineID     uint64 `json:"pipelineID"`
 	PipelineTaskID uint64 `json:"pipelineTaskID"`
 }
@@ -88,6 +93,8 @@ type EasyUse struct {
 
 	// Machine stat
 	MachineStat apistructs.PipelineTaskMachineStat
+
+	SecretMasker *masker.Masker // 日志 secret 脱敏
 }
 
 type RunningEnvironment struct {
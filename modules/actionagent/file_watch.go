@@ -89,7 +89,7 @@ func stdoutTailHandler(agent *Agent) filewatch.TailHandler {
 
 		// push to collector
 		if agent.EasyUse.EnablePushLog2Collector {
-			tailHandlerForPushCollectorLog(line, apistructs.CollectorLogPushStreamStdout, stdoutLogs, agent.EasyUse.TaskLogID, &stdoutLock)
+			tailHandlerForPushCollectorLog(line, apistructs.CollectorLogPushStreamStdout, stdoutLogs, agent.EasyUse.TaskLogID, &stdoutLock, agent.EasyUse.SecretMasker)
 		}
 
 		return nil
@@ -107,7 +107,7 @@ func stderrTailHandler(agent *Agent) filewatch.TailHandler {
 
 		// push to collector
 		if agent.EasyUse.EnablePushLog2Collector {
-			tailHandlerForPushCollectorLog(line, apistructs.CollectorLogPushStreamStderr, stderrLogs, agent.EasyUse.TaskLogID, &stderrLock)
+			tailHandlerForPushCollectorLog(line, apistructs.CollectorLogPushStreamStderr, stderrLogs, agent.EasyUse.TaskLogID, &stderrLock, agent.EasyUse.SecretMasker)
 		}
 
 		return nil
@@ -23,6 +23,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/actionagent/masker"
 	"github.com/erda-project/erda/pkg/httpclient"
 )
 
@@ -32,7 +33,8 @@ var stdoutLock sync.Mutex
 var stderrLock sync.Mutex
 
 // tailHandlerForPushCollectorLog push tailed log to collector
-func tailHandlerForPushCollectorLog(line string, stream string, existLogLines *[]apistructs.LogPushLine, logID string, lock *sync.Mutex) {
+func tailHandlerForPushCollectorLog(line string, stream string, existLogLines *[]apistructs.LogPushLine, logID string, lock *sync.Mutex, m *masker.Masker) {
+	line = m.Mask(line)
 	lock.Lock()
 	defer lock.Unlock()
 	*existLogLines = append(*existLogLines, apistructs.LogPushLine{
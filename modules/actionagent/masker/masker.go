@@ -0,0 +1,176 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package masker 对 action 日志中出现的 secret 值进行脱敏
+package masker
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// Placeholder 替换 secret 的占位符
+	Placeholder = "******"
+
+	// minSecretLength 过短的值脱敏后会让日志不可读，且极易误伤，忽略
+	minSecretLength = 4
+)
+
+// base64 折行宽度，分别对应 base64(1) 与 openssl 的默认输出
+var base64WrapWidths = []int{76, 64}
+
+// Masker 对日志行中的 secret 值(包括 base64、url 编码形式及多行 secret 的单行)进行替换
+type Masker struct {
+	// patterns 按首字节索引，同一首字节下按长度降序，保证优先匹配最长值
+	patterns map[byte][]string
+	// maxLen 最长 secret 的长度，分段输出时需要保留的重叠长度据此计算
+	maxLen int
+
+	redactions uint64
+}
+
+// New 根据 secret 值生成 Masker
+func New(secrets []string) *Masker {
+	set := make(map[string]struct{})
+	add := func(s string) {
+		if len(s) >= minSecretLength && s != Placeholder {
+			set[s] = struct{}{}
+		}
+	}
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if len(secret) < minSecretLength {
+			continue
+		}
+		for _, form := range encodedForms(secret) {
+			add(form)
+		}
+		// 多行 secret(如证书、私钥)按行输出，每行单独匹配
+		if strings.Contains(secret, "\n") {
+			for _, line := range strings.Split(secret, "\n") {
+				add(strings.TrimSpace(line))
+			}
+		}
+	}
+
+	m := &Masker{patterns: make(map[byte][]string)}
+	for s := range set {
+		m.patterns[s[0]] = append(m.patterns[s[0]], s)
+		if len(s) > m.maxLen {
+			m.maxLen = len(s)
+		}
+	}
+	for _, ps := range m.patterns {
+		sort.Slice(ps, func(i, j int) bool {
+			if len(ps[i]) != len(ps[j]) {
+				return len(ps[i]) > len(ps[j])
+			}
+			return ps[i] < ps[j]
+		})
+	}
+	return m
+}
+
+// encodedForms 返回 secret 原值及其常见编码形式
+func encodedForms(secret string) []string {
+	forms := []string{
+		secret,
+		url.QueryEscape(secret),
+		url.PathEscape(secret),
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.URLEncoding,
+		base64.RawStdEncoding,
+		base64.RawURLEncoding,
+	} {
+		encoded := enc.EncodeToString([]byte(secret))
+		forms = append(forms, encoded)
+		// 折行输出的 base64，每行单独匹配
+		for _, width := range base64WrapWidths {
+			if len(encoded) <= width {
+				continue
+			}
+			for i := 0; i < len(encoded); i += width {
+				end := i + width
+				if end > len(encoded) {
+					end = len(encoded)
+				}
+				forms = append(forms, encoded[i:end])
+			}
+		}
+	}
+	return forms
+}
+
+// Empty 是否无需脱敏
+func (m *Masker) Empty() bool {
+	return m == nil || len(m.patterns) == 0
+}
+
+// Mask 替换 line 中出现的所有 secret
+func (m *Masker) Mask(line string) string {
+	masked, _ := m.maskPrefix(line, len(line))
+	return masked
+}
+
+// maskPrefix 替换 line 中起始位置在 limit 之前的 secret，返回 line[:consumed] 脱敏后的结果
+// 跨过 limit 的 secret 会被完整替换，consumed 可能大于 limit
+func (m *Masker) maskPrefix(line string, limit int) (string, int) {
+	if m.Empty() {
+		return line[:limit], limit
+	}
+	var b strings.Builder
+	var hits uint64
+	last := 0
+	i := 0
+	for i < limit {
+		matched := ""
+		for _, p := range m.patterns[line[i]] {
+			if strings.HasPrefix(line[i:], p) {
+				matched = p
+				break
+			}
+		}
+		if matched == "" {
+			i++
+			continue
+		}
+		if hits == 0 {
+			b.Grow(len(line))
+		}
+		b.WriteString(line[last:i])
+		b.WriteString(Placeholder)
+		i += len(matched)
+		last = i
+		hits++
+	}
+	if hits == 0 {
+		return line[:i], i
+	}
+	b.WriteString(line[last:i])
+	atomic.AddUint64(&m.redactions, hits)
+	return b.String(), i
+}
+
+// Redactions 返回累计替换次数，用于审计
+func (m *Masker) Redactions() uint64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&m.redactions)
+}
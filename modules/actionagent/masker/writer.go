@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package masker
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxBufferedLine 单行超过该长度时不再等待换行或回车，脱敏后输出
+// 末尾保留最长 secret 长度减一的内容，避免 secret 被截断在两次输出之间
+const maxBufferedLine = 64 * 1024
+
+type writer struct {
+	m   *Masker
+	w   io.Writer
+	buf []byte
+	mu  sync.Mutex
+}
+
+// NewWriter 返回按行（\n 或 \r）脱敏后写入 w 的 writer，结束时需要 Close 以输出最后不完整的行
+func NewWriter(w io.Writer, m *Masker) io.WriteCloser {
+	return &writer{m: m, w: w}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		// 进度条等输出只用 \r 刷新当前行，也需要及时输出
+		idx := bytes.IndexAny(w.buf, "\r\n")
+		if idx < 0 {
+			break
+		}
+		if err := w.flush(idx + 1); err != nil {
+			return len(p), err
+		}
+	}
+	if len(w.buf) >= maxBufferedLine {
+		if err := w.flushPrefix(len(w.buf) - w.overlap()); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.flush(len(w.buf))
+}
+
+// flush 脱敏并输出 buf[:n]
+func (w *writer) flush(n int) error {
+	line := w.m.Mask(string(w.buf[:n]))
+	w.buf = w.buf[n:]
+	_, err := io.WriteString(w.w, line)
+	return err
+}
+
+// flushPrefix 脱敏并输出 buf 中起始位置在 limit 之前的内容，跨过 limit 的 secret 会被完整替换输出
+func (w *writer) flushPrefix(limit int) error {
+	line, consumed := w.m.maskPrefix(string(w.buf), limit)
+	w.buf = w.buf[consumed:]
+	_, err := io.WriteString(w.w, line)
+	return err
+}
+
+// overlap 强制输出时末尾保留的长度，保证起始位置在输出范围内的 secret 完整出现在 buf 中
+func (w *writer) overlap() int {
+	if w.m.Empty() || w.m.maxLen <= 1 {
+		return 0
+	}
+	return w.m.maxLen - 1
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package actionagent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/actionagent/agenttool"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/httpclient"
+)
+
+// ossHTTPClient 使用 pipeline 签发的临时地址读写对象存储，agent 不持有对象存储凭证
+var ossHTTPClient = &http.Client{}
+
+// ossPartSize 分片上传时每个分片的大小，单次 PUT 有 5GB 的上限，超过一个分片的 tar 使用分片上传
+var ossPartSize = 16 << 20
+
+// ossClient 通过 openapi 向 pipeline 按需获取对象的临时地址，context 和 caches 中只保存对象 key
+type ossClient struct {
+	openapiAddr string
+	token       string
+	pipelineID  uint64
+	taskID      uint64
+}
+
+func (agent *Agent) newOSSClient() *ossClient {
+	return &ossClient{
+		openapiAddr: agent.EasyUse.OpenAPIAddr,
+		token:       os.Getenv(apistructs.EnvOpenapiToken),
+		pipelineID:  agent.Arg.PipelineID,
+		taskID:      agent.Arg.PipelineTaskID,
+	}
+}
+
+// ossObjectKey 返回 volume 对应的对象 key
+func ossObjectKey(vo apistructs.MetadataField) (string, error) {
+	key := strings.TrimPrefix(vo.Value, spec.StoreTypeOSSProto)
+	if key == "" {
+		return "", errors.Errorf("missing object storage key: %s", vo.Name)
+	}
+	return key, nil
+}
+
+func (c *ossClient) do(req apistructs.PipelineTaskOSSRequest) (*apistructs.PipelineTaskOSSResponseData, error) {
+	var resp apistructs.PipelineTaskOSSResponse
+	r, err := httpclient.New(httpclient.WithCompleteRedirect()).
+		Post(c.openapiAddr).
+		Path(fmt.Sprintf("/api/pipelines/%d/tasks/%d/actions/oss", c.pipelineID, c.taskID)).
+		Header("Authorization", c.token).
+		JSONBody(&req).
+		Do().
+		JSON(&resp)
+	if err != nil {
+		return nil, errors.Errorf("failed to %s object %s, err: %v", req.Action, req.Key, err)
+	}
+	if !r.IsOK() || !resp.Success || resp.Data == nil {
+		return nil, errors.Errorf("failed to %s object %s, status-code: %d, err: %s", req.Action, req.Key, r.StatusCode(), resp.Error.Msg)
+	}
+	return resp.Data, nil
+}
+
+func (c *ossClient) presign(action, key string) (string, error) {
+	data, err := c.do(apistructs.PipelineTaskOSSRequest{Action: action, Key: key})
+	if err != nil {
+		return "", err
+	}
+	return data.URL, nil
+}
+
+// uploadDir 将 dir 打包后流式上传，不在本地生成 tar 文件，只打包一次
+// 不足一个分片时直接 PUT，否则按分片上传，失败时取消分片上传
+func (c *ossClient) uploadDir(key, dir string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(agenttool.TarStream(pw, dir))
+	}()
+	defer pr.Close()
+
+	buf := make([]byte, ossPartSize)
+	n, err := io.ReadFull(pr, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if err := c.putObject(key, buf[:n]); err != nil {
+			return errors.Errorf("failed to upload %s, err: %v", dir, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Errorf("failed to tar %s, err: %v", dir, err)
+	}
+
+	upload, err := c.do(apistructs.PipelineTaskOSSRequest{Action: apistructs.PipelineTaskOSSActionCreateMultipart, Key: key})
+	if err != nil {
+		return err
+	}
+	abort := func(err error) error {
+		if _, aerr := c.do(apistructs.PipelineTaskOSSRequest{
+			Action: apistructs.PipelineTaskOSSActionAbortMultipart, Key: key, UploadID: upload.UploadID}); aerr != nil {
+			logrus.Printf("failed to abort multipart upload of %s, err: %v", key, aerr)
+		}
+		return errors.Errorf("failed to upload %s, err: %v", dir, err)
+	}
+
+	var parts []apistructs.PipelineTaskOSSPart
+	for partNumber := 1; n > 0; partNumber++ {
+		if partNumber > pvolumes.MaxOSSPartNumber {
+			return abort(errors.Errorf("exceeds max part number %d", pvolumes.MaxOSSPartNumber))
+		}
+		etag, err := c.uploadPart(key, upload.UploadID, partNumber, buf[:n])
+		if err != nil {
+			return abort(err)
+		}
+		parts = append(parts, apistructs.PipelineTaskOSSPart{PartNumber: partNumber, ETag: etag})
+		n, err = io.ReadFull(pr, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return abort(err)
+		}
+	}
+	if _, err := c.do(apistructs.PipelineTaskOSSRequest{Action: apistructs.PipelineTaskOSSActionCompleteMultipart,
+		Key: key, UploadID: upload.UploadID, Parts: parts}); err != nil {
+		return abort(err)
+	}
+	return nil
+}
+
+func (c *ossClient) uploadPart(key, uploadID string, partNumber int, b []byte) (string, error) {
+	data, err := c.do(apistructs.PipelineTaskOSSRequest{Action: apistructs.PipelineTaskOSSActionUploadPart,
+		Key: key, UploadID: uploadID, PartNumber: partNumber})
+	if err != nil {
+		return "", err
+	}
+	resp, err := putOSSObject(data.URL, b)
+	if err != nil {
+		return "", err
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", errors.Errorf("missing etag of part %d", partNumber)
+	}
+	return etag, nil
+}
+
+func (c *ossClient) putObject(key string, b []byte) error {
+	putURL, err := c.presign(apistructs.PipelineTaskOSSActionPut, key)
+	if err != nil {
+		return err
+	}
+	_, err = putOSSObject(putURL, b)
+	return err
+}
+
+// downloadDir 流式下载并解压到 destDir，对象不存在时返回 os.ErrNotExist
+func (c *ossClient) downloadDir(key, destDir string) error {
+	r, err := c.getObject(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := agenttool.UnTarStream(r, destDir); err != nil {
+		return errors.Errorf("failed to untar into %s, err: %v", destDir, err)
+	}
+	return nil
+}
+
+func (c *ossClient) getObject(key string) (io.ReadCloser, error) {
+	getURL, err := c.presign(apistructs.PipelineTaskOSSActionGet, key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ossHTTPClient.Get(getURL)
+	if err != nil {
+		return nil, stripOSSURL(err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, os.ErrNotExist
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, ossStatusError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *ossClient) readString(key string) (string, error) {
+	r, err := c.getObject(key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(r, 1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// storeCache 缓存目录未变化时跳过上传，只刷新指纹文件的修改时间用于过期清理
+func (c *ossClient) storeCache(key, cacheDir string) error {
+	digestKey := key + pvolumes.TaskCacheDigestSuffix
+	digest, err := agenttool.DirDigest(cacheDir)
+	if err != nil {
+		return err
+	}
+	if old, err := c.readString(digestKey); err == nil && old == digest {
+		logrus.Printf("action cache %s not changed, skip upload", cacheDir)
+		return c.putObject(digestKey, []byte(digest))
+	}
+	if err := c.uploadDir(key, cacheDir); err != nil {
+		return err
+	}
+	return c.putObject(digestKey, []byte(digest))
+}
+
+// restoreCache 缓存命中后刷新指纹文件，避免常用缓存过期被清理
+func (c *ossClient) restoreCache(key, cacheDir string) error {
+	if err := c.downloadDir(key, filepath.Dir(cacheDir)); err != nil {
+		return err
+	}
+	digestKey := key + pvolumes.TaskCacheDigestSuffix
+	if digest, err := c.readString(digestKey); err == nil {
+		if err := c.putObject(digestKey, []byte(digest)); err != nil {
+			logrus.Printf("failed to refresh action cache %s, err: %v", cacheDir, err)
+		}
+	}
+	return nil
+}
+
+func putOSSObject(putURL string, b []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPut, putURL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		req.Body = http.NoBody
+	}
+	resp, err := ossHTTPClient.Do(req)
+	if err != nil {
+		return nil, stripOSSURL(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, ossStatusError(resp)
+	}
+	return resp, nil
+}
+
+// stripOSSURL 错误中去掉临时地址，避免签名出现在日志里
+func stripOSSURL(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return fmt.Errorf("%s object: %v", ue.Op, ue.Err)
+	}
+	return err
+}
+
+func ossStatusError(resp *http.Response) error {
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("object storage responded %s: %s", resp.Status, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package actionagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// fakeOSSServer 同时模拟 pipeline 签发临时地址的接口和对象存储，临时地址只按 path 读写对象
+type fakeOSSServer struct {
+	mu      sync.Mutex
+	url     string
+	objects map[string][]byte
+	parts   map[int][]byte
+	puts    int
+}
+
+func (s *fakeOSSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/actions/oss"):
+		var req apistructs.PipelineTaskOSSRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		var data apistructs.PipelineTaskOSSResponseData
+		switch req.Action {
+		case apistructs.PipelineTaskOSSActionGet, apistructs.PipelineTaskOSSActionPut:
+			data.URL = s.url + "/objects/" + req.Key
+		case apistructs.PipelineTaskOSSActionCreateMultipart:
+			s.parts = make(map[int][]byte)
+			data.UploadID = "upload"
+		case apistructs.PipelineTaskOSSActionUploadPart:
+			data.URL = fmt.Sprintf("%s/parts/%d", s.url, req.PartNumber)
+		case apistructs.PipelineTaskOSSActionCompleteMultipart:
+			var b []byte
+			for _, part := range req.Parts {
+				b = append(b, s.parts[part.PartNumber]...)
+			}
+			s.objects[req.Key] = b
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(apistructs.PipelineTaskOSSResponse{Header: apistructs.Header{Success: true}, Data: &data})
+	case strings.HasPrefix(r.URL.Path, "/parts/"):
+		var partNumber int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/parts/"), "%d", &partNumber)
+		b, _ := ioutil.ReadAll(r.Body)
+		s.parts[partNumber] = b
+		w.Header().Set("ETag", fmt.Sprintf("etag-%d", partNumber))
+	case r.Method == http.MethodPut:
+		b, _ := ioutil.ReadAll(r.Body)
+		s.objects[strings.TrimPrefix(r.URL.Path, "/objects/")] = b
+		s.puts++
+	case r.Method == http.MethodGet:
+		b, ok := s.objects[strings.TrimPrefix(r.URL.Path, "/objects/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	}
+}
+
+func newFakeOSSClient(t *testing.T) (*ossClient, *fakeOSSServer, func()) {
+	fake := &fakeOSSServer{objects: make(map[string][]byte)}
+	ts := httptest.NewServer(fake)
+	fake.url = ts.URL
+	return &ossClient{openapiAddr: strings.TrimPrefix(ts.URL, "http://"), pipelineID: 1, taskID: 2}, fake, ts.Close
+}
+
+func TestOSSCacheStoreAndRestore(t *testing.T) {
+	c, fake, closeFn := newFakeOSSClient(t)
+	defer closeFn()
+
+	tmp, err := ioutil.TempDir("", "oss")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	// 缓存不存在
+	cacheDir := filepath.Join(tmp, "restore", ".m2")
+	assert.True(t, os.IsNotExist(c.restoreCache("cache.tar", cacheDir)))
+
+	srcDir := filepath.Join(tmp, "store", ".m2")
+	assert.NoError(t, os.MkdirAll(srcDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "a.jar"), []byte("jar"), 0644))
+	assert.NoError(t, c.storeCache("cache.tar", srcDir))
+	assert.NotEmpty(t, fake.objects["cache.tar.sha256"])
+
+	// 未变化时只刷新指纹文件
+	puts := fake.puts
+	assert.NoError(t, c.storeCache("cache.tar", srcDir))
+	assert.Equal(t, puts+1, fake.puts)
+
+	assert.NoError(t, c.restoreCache("cache.tar", cacheDir))
+	b, err := ioutil.ReadFile(filepath.Join(cacheDir, "a.jar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "jar", string(b))
+}
+
+func TestOSSMultipartUpload(t *testing.T) {
+	c, fake, closeFn := newFakeOSSClient(t)
+	defer closeFn()
+
+	old := ossPartSize
+	ossPartSize = 1024
+	defer func() { ossPartSize = old }()
+
+	tmp, err := ioutil.TempDir("", "oss")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	srcDir := filepath.Join(tmp, "store", "repo")
+	assert.NoError(t, os.MkdirAll(srcDir, 0755))
+	content := strings.Repeat("a", 5000)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "a.txt"), []byte(content), 0644))
+	assert.NoError(t, c.uploadDir("context.tar", srcDir))
+	assert.Equal(t, 0, fake.puts)
+	assert.True(t, len(fake.parts) > 1)
+
+	destDir := filepath.Join(tmp, "restore")
+	assert.NoError(t, c.downloadDir("context.tar", destDir))
+	b, err := ioutil.ReadFile(filepath.Join(destDir, "repo", "a.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(b))
+}
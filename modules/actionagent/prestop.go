@@ -23,4 +23,7 @@ func (agent *Agent) PreStop() {
 
 	// 打包目录并上传
 	agent.uploadDir()
+
+	// 上传声明的制品
+	agent.uploadArtifacts()
 }
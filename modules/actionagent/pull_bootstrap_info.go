@@ -73,6 +73,8 @@ func (agent *Agent) pullBootstrapInfo() {
 	agent.Arg.Commands = bootstrapArg.Commands
 	agent.Arg.Context = bootstrapArg.Context
 	agent.Arg.PrivateEnvs = bootstrapArg.PrivateEnvs
+	agent.Arg.SecretValues = bootstrapArg.SecretValues
+	agent.Arg.Artifacts = bootstrapArg.Artifacts
 
 	// set envs to current process, so `run` and other scripts can inherit
 	for k, v := range agent.Arg.PrivateEnvs {
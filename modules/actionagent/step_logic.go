@@ -20,6 +20,8 @@ import (
 	"os/exec"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/actionagent/masker"
 )
 
 func (agent *Agent) logic() {
@@ -31,8 +33,18 @@ func (agent *Agent) logic() {
 	}
 
 	actionRun := exec.Command(agent.EasyUse.RunScript)
-	actionRun.Stdout = io.MultiWriter(os.Stdout, agent.EasyUse.RunMultiStdout)
-	actionRun.Stderr = io.MultiWriter(os.Stderr, agent.EasyUse.RunMultiStderr)
+	// secret 在写出到 stdout/stderr 及日志文件前脱敏
+	stdout := masker.NewWriter(io.MultiWriter(os.Stdout, agent.EasyUse.RunMultiStdout), agent.EasyUse.SecretMasker)
+	stderr := masker.NewWriter(io.MultiWriter(os.Stderr, agent.EasyUse.RunMultiStderr), agent.EasyUse.SecretMasker)
+	defer func() {
+		_ = stdout.Close()
+		_ = stderr.Close()
+		if n := agent.EasyUse.SecretMasker.Redactions(); n > 0 {
+			logrus.Printf("secret masker: %d secret occurrence(s) redacted from logs", n)
+		}
+	}()
+	actionRun.Stdout = stdout
+	actionRun.Stderr = stderr
 	if err := actionRun.Start(); err != nil {
 		agent.AppendError(err)
 		return
@@ -23,6 +23,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/erda-project/erda/modules/actionagent/masker"
 	"github.com/erda-project/erda/pkg/filehelper"
 )
 
@@ -87,6 +88,9 @@ func (agent *Agent) prepare() {
 	} else {
 		agent.EasyUse.RunMultiStderr = f
 	}
+
+	// 5. secret masker for stdout/stderr
+	agent.EasyUse.SecretMasker = masker.New(agent.Arg.SecretValues)
 }
 
 func (agent *Agent) setupScript() error {
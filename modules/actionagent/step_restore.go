@@ -15,6 +15,7 @@ package actionagent
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -46,8 +47,21 @@ func (agent *Agent) restore() {
 			}
 		case string(spec.StoreTypeDiceVolumeLocal), string(spec.StoreTypeDiceVolumeFake):
 			// nothing
+		// OSS 类型，restore 时将对象存储中的 tar 流式下载解压到 containerContext 下
 		case string(spec.StoreTypeOSS):
-			agent.AppendError(errors.New("[restore] not implemented now for OSS storage"))
+			tarDir := agent.EasyUse.ContainerContext
+			key, err := ossObjectKey(in)
+			if err == nil {
+				err = agent.newOSSClient().downloadDir(key, tarDir)
+			}
+			if err != nil {
+				if in.Optional {
+					logrus.Printf("[restore] ignore optional restore, type: %s, (prepare to untar [%s] into [%s]).\n",
+						spec.StoreTypeOSS, in.Value, tarDir)
+					continue
+				}
+				agent.AppendError(err)
+			}
 
 		// dice-nfs-volume 类型，restore 时将 volume.path 下的 data (.tar) 解压到 containerContext 下
 		case string(spec.StoreTypeDiceVolumeNFS):
@@ -73,6 +87,20 @@ func (agent *Agent) restore() {
 				logrus.Printf("StoreTypeDiceCacheNFS untar error: %v", err)
 			}
 			logrus.Printf("get action cache: %s success", in.Labels[pvolumes.TaskCachePath])
+		case string(spec.StoreTypeDiceCacheOSS):
+			key, err := ossObjectKey(in)
+			if err == nil {
+				err = agent.newOSSClient().restoreCache(key, in.Labels[pvolumes.TaskCachePath])
+			}
+			if err != nil {
+				if os.IsNotExist(err) {
+					logrus.Printf("not get action cache: %s", in.Labels[pvolumes.TaskCachePath])
+				} else {
+					logrus.Printf("StoreTypeDiceCacheOSS download error: %v", err)
+				}
+				continue
+			}
+			logrus.Printf("get action cache: %s success", in.Labels[pvolumes.TaskCachePath])
 		default:
 			agent.AppendError(errors.Errorf("[restore] unsupported store type: %s", in.Type))
 		}
@@ -102,4 +130,6 @@ func (agent *Agent) restore() {
 			continue
 		}
 	}
+
+	agent.restoreArtifacts()
 }
@@ -39,8 +39,16 @@ func (agent *Agent) store() {
 			}
 		case string(spec.StoreTypeDiceVolumeLocal), string(spec.StoreTypeDiceVolumeFake):
 			// nothing
+		// OSS 类型，store 时将对应 containerContext 下的 task namespace 打包流式上传至对象存储
 		case string(spec.StoreTypeOSS):
-			agent.AppendError(errors.New("[store] not implemented now for OSS storage"))
+			tarDir := filepath.Join(agent.EasyUse.ContainerContext, out.Name)
+			key, err := ossObjectKey(out)
+			if err == nil {
+				err = agent.newOSSClient().uploadDir(key, tarDir)
+			}
+			if err != nil {
+				agent.AppendError(err)
+			}
 
 		// dice-nfs-volume 类型，store 时将对应 containerContext 下的 task namespace 整个压缩为 volume.path 下的 data (.tar)
 		case string(spec.StoreTypeDiceVolumeNFS):
@@ -60,6 +68,20 @@ func (agent *Agent) store() {
 				logrus.Printf("StoreTypeDiceCacheNFS tar error: %v", err)
 			}
 			logrus.Printf("upload action cache %s success", out.Labels[pvolumes.TaskCachePath])
+		case string(spec.StoreTypeDiceCacheOSS):
+			if filehelper.CheckExist(out.Labels[pvolumes.TaskCachePath], true) != nil {
+				logrus.Printf("upload action cache error: %s is not dir", out.Labels[pvolumes.TaskCachePath])
+				continue
+			}
+			key, err := ossObjectKey(out)
+			if err == nil {
+				err = agent.newOSSClient().storeCache(key, out.Labels[pvolumes.TaskCachePath])
+			}
+			if err != nil {
+				logrus.Printf("StoreTypeDiceCacheOSS upload error: %v", err)
+				continue
+			}
+			logrus.Printf("upload action cache %s success", out.Labels[pvolumes.TaskCachePath])
 		default:
 			agent.AppendError(errors.Errorf("[store] unsupported store type: %s", out.Type))
 		}
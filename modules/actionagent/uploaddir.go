@@ -15,6 +15,7 @@ package actionagent
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -32,6 +33,9 @@ import (
 
 const (
 	logUploadFilePrefix = "[upload files] "
+
+	// defaultFileExpireIn 上传文件和制品未声明过期时间时的默认值
+	defaultFileExpireIn = "168h"
 )
 
 func (agent *Agent) uploadDir() {
@@ -116,7 +120,7 @@ func (agent *Agent) uploadDir() {
 			continue
 		}
 		// 上传
-		diceFile, err := agent.uploadFile(f)
+		diceFile, err := agent.uploadFile(f, defaultFileExpireIn)
 		if err != nil {
 			logrus.Printf(logUploadFilePrefix+"upload failed, fileName: %s, size: %s, err: %v\n", fileInfo.Name(), currentFileSize.HumanReadable(), err)
 			continue
@@ -140,15 +144,19 @@ func (agent *Agent) uploadDir() {
 	}
 }
 
-func (agent *Agent) uploadFile(file *os.File) (*apistructs.File, error) {
+func (agent *Agent) uploadFile(file *os.File, expiredIn string) (*apistructs.File, error) {
 	var uploadResp apistructs.FileUploadResponse
 
 	err := retry.DoWithInterval(func() error {
+		// 重试时需要从头读取文件
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
 		resp, err := httpclient.New(httpclient.WithCompleteRedirect()).
 			Post(agent.EasyUse.OpenAPIAddr).
 			Path("/api/files").
 			Param("fileFrom", fmt.Sprintf("action-upload-%d-%d", agent.Arg.PipelineID, agent.Arg.PipelineTaskID)).
-			Param("expiredIn", "168h").
+			Param("expiredIn", expiredIn).
 			Header("Authorization", agent.EasyUse.TokenForBootstrap).
 			MultipartFormDataBody(map[string]httpclient.MultipartItem{
 				"file": {Reader: file},
@@ -89,6 +89,18 @@ type Conf struct {
 	OSSAccessSecret string `env:"OSS_ACCESS_SECRET"`
 	OSSBucket       string `env:"OSS_BUCKET"`
 	OSSPathPrefix   string `env:"OSS_PATH_PREFIX" default:"/dice/cmdb/files"`
+
+	// s3 compatible storage, e.g. minio
+	S3Endpoint        string `env:"S3_ENDPOINT"` // http://minio:9000 or https://s3.amazonaws.com
+	S3AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	S3AccessKeySecret string `env:"S3_ACCESS_KEY_SECRET"`
+	S3Bucket          string `env:"S3_BUCKET"`
+	S3Region          string `env:"S3_REGION"`
+	S3PathPrefix      string `env:"S3_PATH_PREFIX" default:"/dice/cmdb/files"`
+	// 下载时重定向到预签名链接的有效期，为 0 时由 cmdb 代理下载
+	S3PresignExpires time.Duration `env:"S3_PRESIGN_EXPIRES" default:"10m"`
+	// 生成预签名链接使用的对外地址，S3_ENDPOINT 为集群内地址时需要配置，为空时使用 S3_ENDPOINT
+	S3PresignEndpoint string `env:"S3_PRESIGN_ENDPOINT"` // https://minio.example.com
 	// --- 文件管理 end ---
 
 	CentralNexusPublicURL string `env:"NEXUS_PUBLIC_URL" required:"true"`
@@ -473,6 +485,46 @@ func OSSPathPrefix() string {
 	return cfg.OSSPathPrefix
 }
 
+// S3Endpoint 返回 s3 endpoint.
+func S3Endpoint() string {
+	return cfg.S3Endpoint
+}
+
+// S3AccessKeyID 返回 s3 access key id.
+func S3AccessKeyID() string {
+	return cfg.S3AccessKeyID
+}
+
+// S3AccessKeySecret 返回 s3 access key secret.
+func S3AccessKeySecret() string {
+	return cfg.S3AccessKeySecret
+}
+
+// S3Bucket 返回 s3 bucket.
+func S3Bucket() string {
+	return cfg.S3Bucket
+}
+
+// S3Region 返回 s3 region.
+func S3Region() string {
+	return cfg.S3Region
+}
+
+// S3PathPrefix 返回 文件在指定 bucket 下的路径前缀.
+func S3PathPrefix() string {
+	return cfg.S3PathPrefix
+}
+
+// S3PresignExpires 返回 预签名下载链接的有效期.
+func S3PresignExpires() time.Duration {
+	return cfg.S3PresignExpires
+}
+
+// S3PresignEndpoint 返回 生成预签名链接使用的 s3 对外地址.
+func S3PresignEndpoint() string {
+	return cfg.S3PresignEndpoint
+}
+
 // CentralNexusPublicURL 返回 中心集群 nexus 公网地址
 func CentralNexusPublicURL() string {
 	return cfg.CentralNexusPublicURL
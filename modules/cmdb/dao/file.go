@@ -40,6 +40,7 @@ type File struct {
 
 type FileExtra struct {
 	OSSSnapshot OSSSnapshot `json:"ossSnapshot,omitempty"`
+	S3Snapshot  S3Snapshot  `json:"s3Snapshot,omitempty"`
 	IsPublic    bool        `json:"isPublic,omitempty"`
 
 	Encrypt             bool   `json:"encrypt,omitempty"`
@@ -52,6 +53,11 @@ type OSSSnapshot struct {
 	OSSBucket   string `json:"ossBucket,omitempty"`
 }
 
+type S3Snapshot struct {
+	S3Endpoint string `json:"s3Endpoint,omitempty"`
+	S3Bucket   string `json:"s3Bucket,omitempty"`
+}
+
 func (File) TableName() string {
 	return "dice_files"
 }
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/modules/cmdb/model"
+)
+
+// CreatePersonalAccessToken 创建个人访问令牌
+func (client *DBClient) CreatePersonalAccessToken(token *model.PersonalAccessToken) error {
+	return client.Create(token).Error
+}
+
+// ListPersonalAccessTokens 列出用户的个人访问令牌
+func (client *DBClient) ListPersonalAccessTokens(userID string) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	if err := client.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetPersonalAccessToken 查询用户的个人访问令牌，不存在时返回 nil
+func (client *DBClient) GetPersonalAccessToken(id int64, userID string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := client.Where("id = ?", id).Where("user_id = ?", userID).First(&token).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetPersonalAccessTokenByHash 根据令牌摘要查询个人访问令牌，不存在时返回 nil
+func (client *DBClient) GetPersonalAccessTokenByHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := client.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokePersonalAccessToken 吊销个人访问令牌
+func (client *DBClient) RevokePersonalAccessToken(id int64, revokedAt time.Time) error {
+	return client.Model(&model.PersonalAccessToken{}).Where("id = ?", id).UpdateColumn("revoked_at", revokedAt).Error
+}
+
+// UpdatePersonalAccessTokenLastUsedAt 更新个人访问令牌的最后使用时间
+func (client *DBClient) UpdatePersonalAccessTokenLastUsedAt(id int64, lastUsedAt time.Time) error {
+	return client.Model(&model.PersonalAccessToken{}).Where("id = ?", id).UpdateColumn("last_used_at", lastUsedAt).Error
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/cmdb/dao"
+	"github.com/erda-project/erda/modules/cmdb/services/accesstoken"
 	"github.com/erda-project/erda/modules/cmdb/services/activity"
 	"github.com/erda-project/erda/modules/cmdb/services/appcertificate"
 	"github.com/erda-project/erda/modules/cmdb/services/application"
@@ -96,6 +97,7 @@ type Endpoints struct {
 	license            *license.License
 	notifyGroup        *notify.NotifyGroup
 	mbox               *mbox.MBox
+	accessToken        *accesstoken.AccessToken
 	label              *label.Label
 	branchRule         *branchrule.BranchRule
 	iteration          *iteration.Iteration
@@ -295,6 +297,13 @@ func WithNotify(notifyGroup *notify.NotifyGroup) Option {
 	}
 }
 
+// WithAccessToken 配置个人访问令牌 service
+func WithAccessToken(accessToken *accesstoken.AccessToken) Option {
+	return func(e *Endpoints) {
+		e.accessToken = accessToken
+	}
+}
+
 // WithMBox 配置 mbox service
 func WithMBox(mbox *mbox.MBox) Option {
 	return func(e *Endpoints) {
@@ -480,6 +489,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/orgs/{orgID}/actions/create-publisher", Method: http.MethodPost, Handler: e.CreateOrgPublisher},
 		{Path: "/api/orgs/{orgID}/actions/create-publisher", Method: http.MethodGet, Handler: e.CreateOrgPublisher},
 		{Path: "/api/orgs/{orgID}/actions/set-release-cross-cluster", Method: http.MethodPost, Handler: e.SetReleaseCrossCluster},
+		{Path: "/api/orgs/{orgID}/actions/set-release-promotion-gate", Method: http.MethodPost, Handler: e.SetReleasePromotionGate},
 		{Path: "/api/orgs/{orgID}/actions/get-nexus-docker-credential-by-image", Method: http.MethodGet, Handler: e.GetNexusOrgDockerCredentialByImage},
 		{Path: "/api/orgs/actions/gen-verify-code", Method: http.MethodPost, Handler: e.GenVerifiCode},
 		{Path: "/api/orgs/{orgID}/actions/set-notify-config", Method: http.MethodPost, Handler: e.SetNotifyConfig},
@@ -644,6 +654,12 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/mboxs/actions/set-read", Method: http.MethodPost, Handler: e.SetMBoxReadStatus},
 		{Path: "/api/mboxs/{mboxID}", Method: http.MethodGet, Handler: e.GetMBox},
 
+		// 个人访问令牌
+		{Path: "/api/personal-access-tokens", Method: http.MethodPost, Handler: e.CreatePersonalAccessToken},
+		{Path: "/api/personal-access-tokens", Method: http.MethodGet, Handler: e.ListPersonalAccessTokens},
+		{Path: "/api/personal-access-tokens/actions/verify", Method: http.MethodPost, Handler: e.VerifyPersonalAccessToken},
+		{Path: "/api/personal-access-tokens/{id}", Method: http.MethodDelete, Handler: e.RevokePersonalAccessToken},
+
 		// 其他
 		{Path: "/api/images/actions/upload", Method: http.MethodPost, Handler: e.UploadImage},
 
@@ -167,6 +167,16 @@ func (e *Endpoints) DownloadFile(ctx context.Context, w http.ResponseWriter, r *
 		}
 	}
 
+	// 存储支持预签名时直接重定向，不再由 cmdb 代理下载
+	presignedURL, err := e.fileSvc.PresignedDownloadURL(file)
+	if err != nil {
+		return err
+	}
+	if presignedURL != "" {
+		http.Redirect(w, r, presignedURL, http.StatusFound)
+		return nil
+	}
+
 	if _, err := e.fileSvc.DownloadFile(w, file); err != nil {
 		return err
 	}
@@ -579,9 +579,10 @@ func (e *Endpoints) convertToOrgDTO(org model.Org, domains ...string) apistructs
 			BlockStage: org.BlockoutConfig.BlockStage,
 			BlockProd:  org.BlockoutConfig.BlockProd,
 		},
-		EnableReleaseCrossCluster: org.Config.EnableReleaseCrossCluster,
-		CreatedAt:                 org.CreatedAt,
-		UpdatedAt:                 org.UpdatedAt,
+		EnableReleaseCrossCluster:  org.Config.EnableReleaseCrossCluster,
+		EnableReleasePromotionGate: org.Config.EnableReleasePromotionGate,
+		CreatedAt:                  org.CreatedAt,
+		UpdatedAt:                  org.UpdatedAt,
 	}
 	if orgDto.DisplayName == "" {
 		orgDto.DisplayName = orgDto.Name
@@ -1001,6 +1002,36 @@ func (e *Endpoints) SetReleaseCrossCluster(ctx context.Context, r *http.Request,
 	return httpserver.OkResp(nil)
 }
 
+func (e *Endpoints) SetReleasePromotionGate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrSetReleasePromotionGate.NotLogin().ToResp(), nil
+	}
+	if !identityInfo.IsInternalClient() {
+		req := apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.SysScope,
+			Resource: apistructs.OrgResource,
+			Action:   apistructs.UpdateAction,
+		}
+		if access, err := e.permission.CheckPermission(&req); err != nil || !access {
+			return apierrors.ErrSetReleasePromotionGate.AccessDenied().ToResp(), nil
+		}
+	}
+	enable, err := strconv.ParseBool(r.URL.Query().Get("enable"))
+	if err != nil {
+		return apierrors.ErrSetReleasePromotionGate.InvalidParameter("invalid bool query: enable").ToResp(), nil
+	}
+	orgID, err := strconv.ParseUint(vars["orgID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrSetReleasePromotionGate.InvalidParameter("orgID").ToResp(), nil
+	}
+	if err := e.org.SetReleasePromotionGate(orgID, enable); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(nil)
+}
+
 // GenVerifiCode 生成邀请成员加入企业的验证码
 func (e *Endpoints) GenVerifiCode(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	// 鉴权
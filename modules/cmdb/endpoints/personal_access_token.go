@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/cmdb/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/httpserver"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+)
+
+// CreatePersonalAccessToken 创建个人访问令牌
+func (e *Endpoints) CreatePersonalAccessToken(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	userID, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrCreatePersonalAccessToken.NotLogin().ToResp(), nil
+	}
+	if r.Body == nil {
+		return apierrors.ErrCreatePersonalAccessToken.MissingParameter("body").ToResp(), nil
+	}
+	var req apistructs.PersonalAccessTokenCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreatePersonalAccessToken.InvalidParameter(err).ToResp(), nil
+	}
+	result, err := e.accessToken.Create(userID.String(), req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// ListPersonalAccessTokens 列出当前用户的个人访问令牌
+func (e *Endpoints) ListPersonalAccessTokens(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	userID, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrListPersonalAccessToken.NotLogin().ToResp(), nil
+	}
+	tokens, err := e.accessToken.List(userID.String())
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(tokens)
+}
+
+// RevokePersonalAccessToken 吊销个人访问令牌
+func (e *Endpoints) RevokePersonalAccessToken(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	userID, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrRevokePersonalAccessToken.NotLogin().ToResp(), nil
+	}
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRevokePersonalAccessToken.InvalidParameter(err).ToResp(), nil
+	}
+	token, err := e.accessToken.Revoke(userID.String(), id)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(token)
+}
+
+// VerifyPersonalAccessToken 校验个人访问令牌 内部接口
+func (e *Endpoints) VerifyPersonalAccessToken(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrVerifyPersonalAccessToken.NotLogin().ToResp(), nil
+	}
+	if !identityInfo.IsInternalClient() {
+		return apierrors.ErrVerifyPersonalAccessToken.AccessDenied().ToResp(), nil
+	}
+	if r.Body == nil {
+		return apierrors.ErrVerifyPersonalAccessToken.MissingParameter("body").ToResp(), nil
+	}
+	var req apistructs.PersonalAccessTokenVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrVerifyPersonalAccessToken.InvalidParameter(err).ToResp(), nil
+	}
+	if req.Token == "" {
+		return apierrors.ErrVerifyPersonalAccessToken.MissingParameter("token").ToResp(), nil
+	}
+	token, err := e.accessToken.Verify(req.Token)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(token)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/erda-project/erda/modules/cmdb/conf"
 	"github.com/erda-project/erda/modules/cmdb/dao"
 	"github.com/erda-project/erda/modules/cmdb/endpoints"
+	"github.com/erda-project/erda/modules/cmdb/services/accesstoken"
 	"github.com/erda-project/erda/modules/cmdb/services/activity"
 	"github.com/erda-project/erda/modules/cmdb/services/appcertificate"
 	"github.com/erda-project/erda/modules/cmdb/services/application"
@@ -350,6 +351,10 @@ func initEndpoints() (*endpoints.Endpoints, error) {
 		environment.WithBundle(bdl),
 	)
 
+	accessTokenService := accesstoken.New(
+		accesstoken.WithDBClient(db),
+	)
+
 	mboxService := mbox.New(
 		mbox.WithDBClient(db),
 		mbox.WithBundle(bdl),
@@ -494,6 +499,7 @@ func initEndpoints() (*endpoints.Endpoints, error) {
 		endpoints.WithLicense(license),
 		endpoints.WithLabel(l),
 		endpoints.WithMBox(mboxService),
+		endpoints.WithAccessToken(accessTokenService),
 		endpoints.WithIteration(itr),
 		endpoints.WithIssue(issue),
 		endpoints.WithIssueRelated(issueRelated),
@@ -68,6 +68,8 @@ type OrgConfig struct {
 
 	// 开关：制品是否可以跨集群部署
 	EnableReleaseCrossCluster bool `json:"enableReleaseCrossCluster"`
+	// 开关：部署至 PROD 的制品是否须已晋级至 STAGING，默认关闭
+	EnableReleasePromotionGate bool `json:"enableReleasePromotionGate"`
 }
 
 func (cfg OrgConfig) Value() (driver.Value, error) {
@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// PersonalAccessToken 用户的个人访问令牌，只保存令牌的 sha256 摘要
+type PersonalAccessToken struct {
+	BaseModel
+	Name        string
+	UserID      string
+	TokenHash   string
+	TokenPrefix string
+	OrgIDs      string `gorm:"column:org_ids"`     // json 数组
+	ProjectIDs  string `gorm:"column:project_ids"` // json 数组
+	Permissions string // 逗号分隔
+	ExpiredAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// TableName 设置模型对应数据库表名称
+func (PersonalAccessToken) TableName() string {
+	return "openapi_personal_access_tokens"
+}
+
+// ToApiData 转换为 apistructs.PersonalAccessToken
+func (t PersonalAccessToken) ToApiData() *apistructs.PersonalAccessToken {
+	token := &apistructs.PersonalAccessToken{
+		ID:          uint64(t.ID),
+		Name:        t.Name,
+		UserID:      t.UserID,
+		TokenPrefix: t.TokenPrefix,
+		Permissions: strutil.Split(t.Permissions, ",", true),
+		ExpiredAt:   t.ExpiredAt,
+		LastUsedAt:  t.LastUsedAt,
+		RevokedAt:   t.RevokedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+	if t.OrgIDs != "" {
+		_ = json.Unmarshal([]byte(t.OrgIDs), &token.OrgIDs)
+	}
+	if t.ProjectIDs != "" {
+		_ = json.Unmarshal([]byte(t.ProjectIDs), &token.ProjectIDs)
+	}
+	return token
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package accesstoken 用户的个人访问令牌 (personal access token)，令牌只保存 sha256 摘要
+package accesstoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/cmdb/dao"
+	"github.com/erda-project/erda/modules/cmdb/model"
+	"github.com/erda-project/erda/modules/cmdb/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// 展示给用户用于辨认令牌的长度
+	displayPrefixLen = len(apistructs.PersonalAccessTokenPrefix) + 4
+	// last_used_at 更新的最小间隔，避免每个请求都写数据库
+	lastUsedUpdateInterval = time.Minute
+)
+
+// AccessToken 个人访问令牌操作封装
+type AccessToken struct {
+	db *dao.DBClient
+}
+
+// Option 定义 AccessToken 对象的配置选项
+type Option func(*AccessToken)
+
+// New 新建 AccessToken 实例
+func New(options ...Option) *AccessToken {
+	t := &AccessToken{}
+	for _, op := range options {
+		op(t)
+	}
+	return t
+}
+
+// WithDBClient 配置 db client
+func WithDBClient(db *dao.DBClient) Option {
+	return func(t *AccessToken) {
+		t.db = db
+	}
+}
+
+// Create 为 userID 创建令牌，返回令牌信息和令牌明文
+func (t *AccessToken) Create(userID string, req apistructs.PersonalAccessTokenCreateRequest) (*apistructs.PersonalAccessTokenCreateResult, error) {
+	if err := validateCreateRequest(&req); err != nil {
+		return nil, apierrors.ErrCreatePersonalAccessToken.InvalidParameter(err)
+	}
+	plain, err := GenerateToken()
+	if err != nil {
+		return nil, apierrors.ErrCreatePersonalAccessToken.InternalError(err)
+	}
+	orgIDs, _ := json.Marshal(req.OrgIDs)
+	projectIDs, _ := json.Marshal(req.ProjectIDs)
+	token := model.PersonalAccessToken{
+		Name:        req.Name,
+		UserID:      userID,
+		TokenHash:   HashToken(plain),
+		TokenPrefix: plain[:displayPrefixLen],
+		OrgIDs:      string(orgIDs),
+		ProjectIDs:  string(projectIDs),
+		Permissions: strutil.Join(req.Permissions, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		expiredAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		token.ExpiredAt = &expiredAt
+	}
+	if err := t.db.CreatePersonalAccessToken(&token); err != nil {
+		return nil, apierrors.ErrCreatePersonalAccessToken.InternalError(err)
+	}
+	return &apistructs.PersonalAccessTokenCreateResult{PersonalAccessToken: *token.ToApiData(), Token: plain}, nil
+}
+
+// List 列出用户的所有令牌
+func (t *AccessToken) List(userID string) ([]apistructs.PersonalAccessToken, error) {
+	tokens, err := t.db.ListPersonalAccessTokens(userID)
+	if err != nil {
+		return nil, apierrors.ErrListPersonalAccessToken.InternalError(err)
+	}
+	result := make([]apistructs.PersonalAccessToken, 0, len(tokens))
+	for _, v := range tokens {
+		result = append(result, *v.ToApiData())
+	}
+	return result, nil
+}
+
+// Revoke 吊销用户的令牌
+func (t *AccessToken) Revoke(userID string, id int64) (*apistructs.PersonalAccessToken, error) {
+	token, err := t.db.GetPersonalAccessToken(id, userID)
+	if err != nil {
+		return nil, apierrors.ErrRevokePersonalAccessToken.InternalError(err)
+	}
+	if token == nil {
+		return nil, apierrors.ErrRevokePersonalAccessToken.NotFound()
+	}
+	if token.RevokedAt == nil {
+		now := time.Now()
+		if err := t.db.RevokePersonalAccessToken(token.ID, now); err != nil {
+			return nil, apierrors.ErrRevokePersonalAccessToken.InternalError(err)
+		}
+		token.RevokedAt = &now
+	}
+	return token.ToApiData(), nil
+}
+
+// Verify 校验令牌明文，返回令牌信息，并异步更新最后使用时间
+// 令牌不存在、已吊销或已过期时返回 NotLogin
+func (t *AccessToken) Verify(plain string) (*apistructs.PersonalAccessToken, error) {
+	token, err := t.db.GetPersonalAccessTokenByHash(HashToken(plain))
+	if err != nil {
+		return nil, apierrors.ErrVerifyPersonalAccessToken.InternalError(err)
+	}
+	now := time.Now()
+	if err := checkValid(token, now); err != nil {
+		logrus.Infof("failed to verify personal access token, err: %v", err)
+		return nil, apierrors.ErrVerifyPersonalAccessToken.NotLogin()
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedUpdateInterval {
+		go func(id int64) {
+			if err := t.db.UpdatePersonalAccessTokenLastUsedAt(id, now); err != nil {
+				logrus.Errorf("failed to update last used time of personal access token %d, err: %v", id, err)
+			}
+		}(token.ID)
+		token.LastUsedAt = &now
+	}
+	return token.ToApiData(), nil
+}
+
+// checkValid 检查令牌是否存在、未吊销且未过期
+func checkValid(token *model.PersonalAccessToken, now time.Time) error {
+	if token == nil {
+		return errors.New("personal access token not found")
+	}
+	if token.RevokedAt != nil {
+		return errors.New("personal access token has been revoked")
+	}
+	if token.ExpiredAt != nil && !now.Before(*token.ExpiredAt) {
+		return errors.New("personal access token has expired")
+	}
+	return nil
+}
+
+// HashToken 返回令牌的 sha256 摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateToken 生成一个新的令牌明文
+func GenerateToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apistructs.PersonalAccessTokenPrefix + hex.EncodeToString(b), nil
+}
+
+func validateCreateRequest(req *apistructs.PersonalAccessTokenCreateRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("missing token name")
+	}
+	if req.ExpiresInDays < 0 {
+		return errors.Errorf("invalid expiresInDays: %d", req.ExpiresInDays)
+	}
+	if len(req.Permissions) == 0 {
+		req.Permissions = []string{apistructs.PersonalAccessTokenPermissionRead}
+	}
+	for _, p := range req.Permissions {
+		switch p {
+		case apistructs.PersonalAccessTokenPermissionRead, apistructs.PersonalAccessTokenPermissionWrite:
+		default:
+			return errors.Errorf("invalid permission: %s", p)
+		}
+	}
+	req.Permissions = strutil.DedupSlice(req.Permissions)
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accesstoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/cmdb/model"
+)
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, apistructs.PersonalAccessTokenPrefix))
+
+	another, err := GenerateToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, another)
+	assert.Len(t, HashToken(token), 64)
+	assert.NotEqual(t, HashToken(token), HashToken(another))
+}
+
+func TestValidateCreateRequest(t *testing.T) {
+	req := apistructs.PersonalAccessTokenCreateRequest{Name: " ci "}
+	assert.NoError(t, validateCreateRequest(&req))
+	assert.Equal(t, "ci", req.Name)
+	assert.Equal(t, []string{apistructs.PersonalAccessTokenPermissionRead}, req.Permissions)
+
+	assert.Error(t, validateCreateRequest(&apistructs.PersonalAccessTokenCreateRequest{}))
+	assert.Error(t, validateCreateRequest(&apistructs.PersonalAccessTokenCreateRequest{Name: "a", Permissions: []string{"admin"}}))
+	assert.Error(t, validateCreateRequest(&apistructs.PersonalAccessTokenCreateRequest{Name: "a", ExpiresInDays: -1}))
+}
+
+func TestCheckValid(t *testing.T) {
+	now := time.Now()
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+	assert.Error(t, checkValid(nil, now))
+	assert.NoError(t, checkValid(&model.PersonalAccessToken{}, now))
+	assert.NoError(t, checkValid(&model.PersonalAccessToken{ExpiredAt: &after}, now))
+	assert.Error(t, checkValid(&model.PersonalAccessToken{ExpiredAt: &before}, now))
+	assert.Error(t, checkValid(&model.PersonalAccessToken{RevokedAt: &before}, now))
+}
+
+func TestToApiData(t *testing.T) {
+	token := model.PersonalAccessToken{Name: "ci", OrgIDs: "[1,2]", ProjectIDs: "null", Permissions: "read,write"}
+	token.ID = 1
+	data := token.ToApiData()
+	assert.Equal(t, uint64(1), data.ID)
+	assert.Equal(t, []uint64{1, 2}, data.OrgIDs)
+	assert.Empty(t, data.ProjectIDs)
+	assert.Equal(t, []string{"read", "write"}, data.Permissions)
+}
@@ -22,6 +22,7 @@ var (
 	ErrUpdateOrg                            = err("ErrUpdateOrg", "更新企业失败")
 	ErrUpdateOrgIngress                     = err("ErrUpdateOrgIngress", "更新企业入口失败")
 	ErrSetReleaseCrossCluster               = err("ErrSetReleaseCrossCluster", "设置制品跨集群部署失败")
+	ErrSetReleasePromotionGate              = err("ErrSetReleasePromotionGate", "设置制品晋级卡点失败")
 	ErrListOrg                              = err("ErrListOrg", "获取企业列表失败")
 	ErrListPublicOrg                        = err("ErrListPublicOrg", "获取公开企业列表失败")
 	ErrGetOrg                               = err("ErrGetOrg", "获取企业失败")
@@ -196,6 +197,11 @@ var (
 	ErrGetMBoxStats      = err("ErrGetMBoxStats", "获取站内信统计信息失败")
 	ErrSetMBoxReadStatus = err("ErrSetMBoxReadStatus", "设置站内信已读失败")
 
+	ErrCreatePersonalAccessToken = err("ErrCreatePersonalAccessToken", "创建个人访问令牌失败")
+	ErrListPersonalAccessToken   = err("ErrListPersonalAccessToken", "获取个人访问令牌列表失败")
+	ErrRevokePersonalAccessToken = err("ErrRevokePersonalAccessToken", "吊销个人访问令牌失败")
+	ErrVerifyPersonalAccessToken = err("ErrVerifyPersonalAccessToken", "校验个人访问令牌失败")
+
 	ErrCreateIssueStream = err("ErrCreateIssueStream", "创建活动记录列表失败")
 	ErrPagingIssueStream = err("ErrPagingIssueStream", "分页查询活动记录失败")
 	ErrListIssueStream   = err("ErrListIssueStream", "获取活动记录列表失败")
@@ -24,11 +24,13 @@ import (
 	"time"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/cmdb/conf"
 	"github.com/erda-project/erda/modules/cmdb/dao"
 	"github.com/erda-project/erda/modules/cmdb/services/apierrors"
 	"github.com/erda-project/erda/pkg/kms/kmscrypto"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 	"github.com/erda-project/erda/pkg/mimetype"
+	"github.com/erda-project/erda/pkg/storage"
 )
 
 const (
@@ -58,6 +60,9 @@ func (svc *FileService) DownloadFile(w io.Writer, file dao.File) (headers map[st
 		}
 		return nil, apierrors.ErrDownloadFile.InternalError(err)
 	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
 	// 解密 信封加密 文件数据
 	if file.Extra.Encrypt {
 		// 调用 KMS 解密 DEK
@@ -109,3 +114,26 @@ func (svc *FileService) DownloadFile(w io.Writer, file dao.File) (headers map[st
 
 	return
 }
+
+// PresignedDownloadURL 返回文件的预签名下载链接，调用方可以直接重定向，避免由 cmdb 代理下载。
+// 存储不支持预签名、文件加密存储或未开启预签名时返回空字符串。
+func (svc *FileService) PresignedDownloadURL(file dao.File) (string, error) {
+	if file.Extra.Encrypt || conf.S3PresignExpires() <= 0 {
+		return "", nil
+	}
+	if err := checkPath(file.FullRelativePath); err != nil {
+		return "", apierrors.ErrDownloadFile.InvalidParameter(err)
+	}
+	if file.ExpiredAt != nil && time.Now().After(*file.ExpiredAt) {
+		return "", apierrors.ErrDownloadFile.InvalidParameter("file already expired")
+	}
+	presigner, ok := getStorage(file.StorageType).(storage.Presigner)
+	if !ok {
+		return "", nil
+	}
+	u, err := presigner.PresignedURL(file.FullRelativePath, conf.S3PresignExpires(), file.DisplayName)
+	if err != nil {
+		return "", apierrors.ErrDownloadFile.InternalError(err)
+	}
+	return u, nil
+}
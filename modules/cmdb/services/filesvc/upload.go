@@ -125,17 +125,25 @@ func getStorage(typ ...storage.Type) storage.Storager {
 	}
 
 	switch storageType {
+	case storage.TypeS3:
+		goto createS3
 	case storage.TypeOSS:
 		goto createOSS
 	case storage.TypeFileSystem:
 		goto createFS
 	default:
+		if conf.S3Endpoint() != "" {
+			goto createS3
+		}
 		if conf.OSSEndpoint() != "" {
 			goto createOSS
 		}
 		goto createFS
 	}
 
+createS3:
+	return storage.NewS3(conf.S3Endpoint(), conf.S3AccessKeyID(), conf.S3AccessKeySecret(), conf.S3Bucket(),
+		storage.WithS3Region(conf.S3Region()), storage.WithS3PresignEndpoint(conf.S3PresignEndpoint()))
 createOSS:
 	// TODO 这里统一用环境变量中的 oss 配置初始化客户端，如果 oss endpoint 或 bucket 发生过改变，之前通过 oss 上传的文件会下载不到。
 	// 有两个方案：
@@ -168,6 +176,9 @@ func handleFilePath(path string) (string, error) {
 	case storage.TypeOSS:
 		path = filepath.Join(conf.OSSPathPrefix(), path)
 		path = strings.TrimPrefix(path, "/")
+	case storage.TypeS3:
+		path = filepath.Join(conf.S3PathPrefix(), path)
+		path = strings.TrimPrefix(path, "/")
 	}
 
 	return path, nil
@@ -183,6 +194,10 @@ func handleFileExtra(file dao.File) dao.FileExtra {
 		extra.OSSSnapshot.OSSEndpoint = conf.OSSEndpoint()
 		extra.OSSSnapshot.OSSBucket = conf.OSSBucket()
 	}
+	if file.StorageType == storage.TypeS3 {
+		extra.S3Snapshot.S3Endpoint = conf.S3Endpoint()
+		extra.S3Snapshot.S3Bucket = conf.S3Bucket()
+	}
 	return extra
 }
 
@@ -963,6 +963,16 @@ func (o *Org) SetReleaseCrossCluster(orgID uint64, enable bool) error {
 	return o.db.DB.Model(&model.Org{}).Update(org).Error
 }
 
+// SetReleasePromotionGate 设置企业是否开启制品晋级卡点
+func (o *Org) SetReleasePromotionGate(orgID uint64, enable bool) error {
+	org, err := o.db.GetOrg(int64(orgID))
+	if err != nil {
+		return apierrors.ErrSetReleasePromotionGate.InvalidParameter(err)
+	}
+	org.Config.EnableReleasePromotionGate = enable
+	return o.db.DB.Model(&model.Org{}).Update(org).Error
+}
+
 // GenVerifiCode 生成邀请成员加入企业的验证码
 func (o *Org) GenVerifiCode(identityInfo apistructs.IdentityInfo, orgID uint64) (string, error) {
 	now := time.Now()
@@ -15,6 +15,8 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/erda-project/erda/pkg/envconf"
 )
 
@@ -31,6 +33,16 @@ type Conf struct {
 	MaxTimeReserved string              `env:"RELEASE_MAX_TIME_RESERVED" default:"72"` // default: 72h
 	ExtensionMenu   map[string][]string `env:"EXTENSION_MENU" default:"{}"`
 	SiteUrl         string              `env:"SITE_URL"`
+
+	VulnDBPath        string        `env:"VULN_DB_PATH"`
+	VulnSeverityGate  string        `env:"VULN_SEVERITY_GATE" default:"CRITICAL"`
+	VulnGateEnforce   bool          `env:"VULN_GATE_ENFORCE" default:"false"`
+	RegistryUsername  string        `env:"REGISTRY_USERNAME"`
+	RegistryPassword  string        `env:"REGISTRY_PASSWORD"`
+	RegistryInsecure  bool          `env:"REGISTRY_INSECURE" default:"true"`
+	MaxConcurrentScan int           `env:"MAX_CONCURRENT_SCAN" default:"2"`
+	ScanTimeout       time.Duration `env:"RELEASE_SCAN_TIMEOUT" default:"1h"`
+	ScanOnCreate      bool          `env:"RELEASE_SCAN_ON_CREATE" default:"true"`
 }
 
 // Load 加载环境变量配置.
@@ -81,3 +93,48 @@ func ExtensionMenu() map[string][]string {
 func MonitorAddr() string {
 	return cfg.MonitorAddr
 }
+
+// VulnDBPath 离线漏洞库文件路径
+func VulnDBPath() string {
+	return cfg.VulnDBPath
+}
+
+// VulnSeverityGate 默认漏洞卡点等级, 存在该等级及以上的漏洞时不允许部署
+func VulnSeverityGate() string {
+	return cfg.VulnSeverityGate
+}
+
+// VulnGateEnforce 是否开启漏洞卡点, 默认关闭, 关闭时卡点只给出提示
+func VulnGateEnforce() bool {
+	return cfg.VulnGateEnforce
+}
+
+// RegistryUsername 拉取镜像的 registry 用户名
+func RegistryUsername() string {
+	return cfg.RegistryUsername
+}
+
+// RegistryPassword 拉取镜像的 registry 密码
+func RegistryPassword() string {
+	return cfg.RegistryPassword
+}
+
+// RegistryInsecure registry 是否使用 http
+func RegistryInsecure() bool {
+	return cfg.RegistryInsecure
+}
+
+// MaxConcurrentScan 同时扫描的 release 数
+func MaxConcurrentScan() int {
+	return cfg.MaxConcurrentScan
+}
+
+// ScanTimeout release 扫描超时时间, 超时的扫描中记录允许重新触发
+func ScanTimeout() time.Duration {
+	return cfg.ScanTimeout
+}
+
+// ScanOnCreate 创建 release 时是否自动触发镜像扫描
+func ScanOnCreate() bool {
+	return cfg.ScanOnCreate
+}
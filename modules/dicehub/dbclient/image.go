@@ -107,3 +107,13 @@ func (client *DBClient) GetImageCount(releaseID, image string) (int64, error) {
 	}
 	return count, nil
 }
+
+// GetReleaseIDsByImage 获取引用给定镜像的 releaseID 列表
+func (client *DBClient) GetReleaseIDsByImage(image string) ([]string, error) {
+	var releaseIDs []string
+	if err := client.Model(&Image{}).Where("image = ?", image).
+		Pluck("release_id", &releaseIDs).Error; err != nil {
+		return nil, err
+	}
+	return releaseIDs, nil
+}
@@ -14,6 +14,8 @@
 package dbclient
 
 import (
+	"time"
+
 	"github.com/jinzhu/gorm"
 
 	"github.com/erda-project/erda/apistructs"
@@ -165,3 +167,48 @@ func (client *DBClient) QueryPipelineTemplateVersions(version *DicePipelineTempl
 	}
 	return result, err
 }
+
+// DicePipelineTemplateReference 记录流水线通过 snippet_config 对模板的引用
+type DicePipelineTemplateReference struct {
+	dbengine.BaseModel
+	TemplateId        uint64    `json:"template_id" gorm:"unique_index:idx_template_referrer"`
+	ReferrerSource    string    `json:"referrer_source" gorm:"type:varchar(255);unique_index:idx_template_referrer"`
+	ReferrerName      string    `json:"referrer_name" gorm:"type:varchar(255);unique_index:idx_template_referrer"`
+	VersionConstraint string    `json:"version_constraint" gorm:"type:varchar(128)"`
+	ResolvedVersion   string    `json:"resolved_version" gorm:"type:varchar(128)"`
+	LastResolvedAt    time.Time `json:"last_resolved_at"`
+}
+
+func (ref *DicePipelineTemplateReference) ToApiData() *apistructs.PipelineTemplateDependent {
+	return &apistructs.PipelineTemplateDependent{
+		ReferrerSource:    ref.ReferrerSource,
+		ReferrerName:      ref.ReferrerName,
+		VersionConstraint: ref.VersionConstraint,
+		ResolvedVersion:   ref.ResolvedVersion,
+		LastResolvedAt:    ref.LastResolvedAt,
+	}
+}
+
+func (DicePipelineTemplateReference) TableName() string {
+	return "dice_pipeline_template_references"
+}
+
+// UpsertPipelineTemplateReference 同一引用方只保留一条记录，依赖 idx_template_referrer 唯一索引保证并发写入不重复
+func (client *DBClient) UpsertPipelineTemplateReference(ref *DicePipelineTemplateReference) error {
+	now := time.Now()
+	return client.Exec("INSERT INTO `"+ref.TableName()+"` "+
+		"(`created_at`,`updated_at`,`template_id`,`referrer_source`,`referrer_name`,`version_constraint`,`resolved_version`,`last_resolved_at`) "+
+		"VALUES(?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `updated_at`=VALUES(`updated_at`),"+
+		"`version_constraint`=VALUES(`version_constraint`),`resolved_version`=VALUES(`resolved_version`),`last_resolved_at`=VALUES(`last_resolved_at`)",
+		now, now, ref.TemplateId, ref.ReferrerSource, ref.ReferrerName, ref.VersionConstraint, ref.ResolvedVersion, ref.LastResolvedAt).Error
+}
+
+func (client *DBClient) ListPipelineTemplateReferences(templateId uint64) ([]DicePipelineTemplateReference, error) {
+	var result []DicePipelineTemplateReference
+	err := client.Model(&DicePipelineTemplateReference{}).Where("template_id = ?", templateId).
+		Order("last_resolved_at desc").Find(&result).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return result, err
+}
@@ -223,3 +223,13 @@ func (client *DBClient) GetUnReferedReleasesBefore(before time.Time) ([]Release,
 	}
 	return releases, nil
 }
+
+// GetReleasesByProject 获取项目下所有 Release, 按创建时间倒序
+func (client *DBClient) GetReleasesByProject(projectID int64) ([]Release, error) {
+	var releases []Release
+	if err := client.Where("project_id = ?", projectID).
+		Order("created_at DESC").Find(&releases).Error; err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
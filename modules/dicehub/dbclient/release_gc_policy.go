@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/dbengine"
+)
+
+// ReleaseGCPolicy 项目级 release 回收策略
+type ReleaseGCPolicy struct {
+	dbengine.BaseModel
+	ProjectID         uint64 `gorm:"unique_index:uk_project_id"`
+	Enabled           bool
+	KeepLastPerBranch int
+	KeepDeployedDays  int
+	Operator          string `gorm:"type:varchar(255)"`
+}
+
+// TableName 设置模型对应数据库表名称
+func (ReleaseGCPolicy) TableName() string {
+	return "dice_release_gc_policies"
+}
+
+// GetReleaseGCPolicy 查询项目回收策略, 不存在时返回 nil
+func (client *DBClient) GetReleaseGCPolicy(projectID uint64) (*ReleaseGCPolicy, error) {
+	var policy ReleaseGCPolicy
+	if err := client.Where("project_id = ?", projectID).Find(&policy).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SaveReleaseGCPolicy 创建或更新项目回收策略
+func (client *DBClient) SaveReleaseGCPolicy(policy *ReleaseGCPolicy) error {
+	return client.Save(policy).Error
+}
+
+// ListEnabledReleaseGCPolicies 查询所有启用的回收策略
+func (client *DBClient) ListEnabledReleaseGCPolicies() ([]ReleaseGCPolicy, error) {
+	var policies []ReleaseGCPolicy
+	if err := client.Where("enabled = ?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetReleasesLastDeployedAt 从 orchestrator 的部署记录 (ps_v2_deployments) 中获取 release 最近一次成功部署的时间,
+// 包括已被替换的部署, 从未成功部署过的 release 不在结果中
+func (client *DBClient) GetReleasesLastDeployedAt(releaseIDs []string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time)
+	if len(releaseIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		ReleaseID  string
+		DeployedAt time.Time
+	}
+	if err := client.Table("ps_v2_deployments").
+		Select("release_id, MAX(created_at) AS deployed_at").
+		Where("release_id IN (?) AND status = ?", releaseIDs, apistructs.DeploymentStatusOK).
+		Group("release_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.ReleaseID] = row.DeployedAt
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/pkg/dbengine"
+)
+
+// ReleasePromotion release 晋级记录
+type ReleasePromotion struct {
+	dbengine.BaseModel
+	ReleaseID     string `gorm:"type:varchar(64);index:idx_release_id"`
+	OrgID         int64
+	ProjectID     int64
+	ApplicationID int64
+	Workspace     string `gorm:"type:varchar(32)"`
+	Status        string `gorm:"type:varchar(32)"`
+	Overlay       string `gorm:"type:text"`
+	NeedApproval  bool
+	ApprovalID    uint64 `gorm:"index:idx_approval_id"`
+	Desc          string `gorm:"type:varchar(1000)"`
+	Operator      string `gorm:"type:varchar(255)"`
+}
+
+// TableName 设置模型对应数据库表名称
+func (ReleasePromotion) TableName() string {
+	return "dice_release_promotions"
+}
+
+// ReleaseDeployment release 在 runtime 上的部署记录, 每个 runtime 一条
+type ReleaseDeployment struct {
+	dbengine.BaseModel
+	ReleaseID    string `gorm:"type:varchar(64);unique_index:uk_release_runtime"`
+	RuntimeID    uint64 `gorm:"unique_index:uk_release_runtime"`
+	DeploymentID uint64
+	Workspace    string `gorm:"type:varchar(32)"`
+	Active       bool
+	DeployedAt   time.Time
+}
+
+// TableName 设置模型对应数据库表名称
+func (ReleaseDeployment) TableName() string {
+	return "dice_release_deployments"
+}
+
+// CreateReleasePromotion 创建晋级记录
+func (client *DBClient) CreateReleasePromotion(promotion *ReleasePromotion) error {
+	return client.Create(promotion).Error
+}
+
+// UpdateReleasePromotion 更新晋级记录
+func (client *DBClient) UpdateReleasePromotion(promotion *ReleasePromotion) error {
+	return client.Save(promotion).Error
+}
+
+// ListReleasePromotions 获取 release 的晋级记录, 按创建时间倒序
+func (client *DBClient) ListReleasePromotions(releaseID string) ([]ReleasePromotion, error) {
+	var promotions []ReleasePromotion
+	if err := client.Where("release_id = ?", releaseID).
+		Order("id DESC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// GetReleasePromotionByApprovalID 根据审批ID查询晋级记录, 不存在时返回 nil
+func (client *DBClient) GetReleasePromotionByApprovalID(approvalID uint64) (*ReleasePromotion, error) {
+	var promotion ReleasePromotion
+	if err := client.Where("approval_id = ?", approvalID).Find(&promotion).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetReleaseDeployment 查询 release 在 runtime 上的部署记录, 不存在时返回 nil
+func (client *DBClient) GetReleaseDeployment(releaseID string, runtimeID uint64) (*ReleaseDeployment, error) {
+	var deployment ReleaseDeployment
+	if err := client.Where("release_id = ? AND runtime_id = ?", releaseID, runtimeID).
+		Find(&deployment).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// SaveReleaseDeployment 创建或更新部署记录
+func (client *DBClient) SaveReleaseDeployment(deployment *ReleaseDeployment) error {
+	return client.Save(deployment).Error
+}
+
+// ListActiveReleaseDeployments 获取 release 当前的部署记录
+func (client *DBClient) ListActiveReleaseDeployments(releaseID string) ([]ReleaseDeployment, error) {
+	var deployments []ReleaseDeployment
+	if err := client.Where("release_id = ? AND active = ?", releaseID, true).
+		Order("deployed_at DESC").Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
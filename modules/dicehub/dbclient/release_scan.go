@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/pkg/dbengine"
+)
+
+// ReleaseScan release 镜像漏洞扫描记录, 每个 release 保留最近一次
+type ReleaseScan struct {
+	dbengine.BaseModel
+	ReleaseID  string `gorm:"type:varchar(64);unique_index:uk_release_id"`
+	Status     string `gorm:"type:varchar(32)"`
+	Summary    string `gorm:"type:varchar(255)"` // 各等级漏洞数, json
+	Report     string `gorm:"type:longtext"`     // 扫描结果, 包含 SBOM, json
+	DBVersion  string `gorm:"type:varchar(64)"`
+	Error      string `gorm:"type:text"`
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// TableName 设置模型对应数据库表名称
+func (ReleaseScan) TableName() string {
+	return "dice_release_scans"
+}
+
+// GetReleaseScan 获取 release 扫描记录, 不存在时返回 nil
+func (client *DBClient) GetReleaseScan(releaseID string) (*ReleaseScan, error) {
+	var scan ReleaseScan
+	if err := client.Where("release_id = ?", releaseID).Find(&scan).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// SaveReleaseScan 创建或更新扫描记录
+func (client *DBClient) SaveReleaseScan(scan *ReleaseScan) error {
+	return client.Save(scan).Error
+}
+
+// DeleteReleaseScan 删除 release 扫描记录
+func (client *DBClient) DeleteReleaseScan(releaseID string) error {
+	return client.Where("release_id = ?", releaseID).Delete(&ReleaseScan{}).Error
+}
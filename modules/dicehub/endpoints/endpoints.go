@@ -25,6 +25,7 @@ import (
 	"github.com/erda-project/erda/modules/dicehub/service/image"
 	"github.com/erda-project/erda/modules/dicehub/service/publish_item"
 	"github.com/erda-project/erda/modules/dicehub/service/release"
+	"github.com/erda-project/erda/modules/dicehub/service/scan"
 	"github.com/erda-project/erda/modules/dicehub/service/template"
 	"github.com/erda-project/erda/pkg/httpserver"
 )
@@ -38,6 +39,7 @@ type Endpoints struct {
 	extension          *extension.Extension
 	publishItem        *publish_item.PublishItem
 	pipelineTemplate   *template.PipelineTemplate
+	scan               *scan.Scan
 	queryStringDecoder *schema.Decoder
 }
 
@@ -102,6 +104,13 @@ func WithPipelineTemplate(pipelineTemplate *template.PipelineTemplate) Option {
 	}
 }
 
+// WithScan 配置 release 镜像扫描 service
+func WithScan(scan *scan.Scan) Option {
+	return func(e *Endpoints) {
+		e.scan = scan
+	}
+}
+
 // WithQueryStringDecoder 配置 queryStringDecoder
 func WithQueryStringDecoder(decoder *schema.Decoder) Option {
 	return func(e *Endpoints) {
@@ -129,8 +138,18 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/releases", Method: http.MethodGet, Handler: e.ListRelease},
 		{Path: "/api/releases/actions/get-name", Method: http.MethodGet, Handler: e.ListReleaseName},
 		{Path: "/api/releases/actions/get-latest", Method: http.MethodGet, Handler: e.GetLatestReleases},
+		{Path: "/api/releases/{releaseId}/promotions", Method: http.MethodPost, Handler: e.PromoteRelease},
+		{Path: "/api/releases/{releaseId}/promotions", Method: http.MethodGet, Handler: e.ListReleasePromotions},
+		{Path: "/api/releases/{releaseId}/deployments", Method: http.MethodGet, Handler: e.ListReleaseDeployments},
+		{Path: "/api/releases/promotions/actions/watch-approval", Method: http.MethodPost, Handler: e.WatchPromotionApproval},
+		{Path: "/api/releases/{releaseId}/actions/scan", Method: http.MethodPost, Handler: e.ScanRelease},
+		{Path: "/api/releases/{releaseId}/scan-report", Method: http.MethodGet, Handler: e.GetReleaseScanReport},
+		{Path: "/api/releases/{releaseId}/scan-report/actions/check-gate", Method: http.MethodGet, Handler: e.CheckReleaseScanGate},
 
 		{Path: "/gc", Method: http.MethodPost, Handler: e.ReleaseGC},
+		{Path: "/api/release-gc-policies/{projectId}", Method: http.MethodGet, Handler: e.GetReleaseGCPolicy},
+		{Path: "/api/release-gc-policies/{projectId}", Method: http.MethodPut, Handler: e.UpdateReleaseGCPolicy},
+		{Path: "/api/release-gc-policies/{projectId}/actions/dry-run", Method: http.MethodGet, Handler: e.ReleaseGCDryRun},
 
 		// 镜像相关
 		{Path: "/api/images/{imageIdOrImage}", Method: http.MethodGet, Handler: e.GetImage},
@@ -153,6 +172,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/pipeline-templates/local/actions/render-spec", Method: http.MethodPost, Handler: e.RenderPipelineTemplateBySpec},
 		{Path: "/api/pipeline-templates/{name}/actions/query-version", Method: http.MethodGet, Handler: e.GetPipelineTemplateVersion},
 		{Path: "/api/pipeline-templates/{name}/actions/query-versions", Method: http.MethodGet, Handler: e.QueryPipelineTemplateVersions},
+		{Path: "/api/pipeline-templates/{name}/actions/query-dependents", Method: http.MethodGet, Handler: e.QueryPipelineTemplateDependents},
 
 		{Path: "/api/pipeline-snippets/actions/query-snippet-yml", Method: http.MethodGet, Handler: e.querySnippetYml},
 
@@ -15,11 +15,17 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/httpserver"
 )
 
@@ -34,3 +40,109 @@ func (e *Endpoints) ReleaseGC(ctx context.Context, r *http.Request, vars map[str
 
 	return httpserver.OkResp("trigger release gc success")
 }
+
+// GetReleaseGCPolicy GET /api/release-gc-policies/{projectId} 获取项目 release 回收策略
+func (e *Endpoints) GetReleaseGCPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	projectID, err := strconv.ParseUint(vars["projectId"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetReleaseGCPolicy.InvalidParameter("projectId").ToResp(), nil
+	}
+	if err := e.checkReleaseGCPermission(r, projectID, apistructs.GetAction); err != nil {
+		return apierrors.ErrGetReleaseGCPolicy.AccessDenied().ToResp(), nil
+	}
+
+	policy, err := e.release.GetGCPolicy(projectID)
+	if err != nil {
+		return apierrors.ErrGetReleaseGCPolicy.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(policy)
+}
+
+// UpdateReleaseGCPolicy PUT /api/release-gc-policies/{projectId} 更新项目 release 回收策略
+func (e *Endpoints) UpdateReleaseGCPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	projectID, err := strconv.ParseUint(vars["projectId"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateReleaseGCPolicy.InvalidParameter("projectId").ToResp(), nil
+	}
+	if err := e.checkReleaseGCPermission(r, projectID, apistructs.UpdateAction); err != nil {
+		return apierrors.ErrUpdateReleaseGCPolicy.AccessDenied().ToResp(), nil
+	}
+
+	if r.Body == nil {
+		return apierrors.ErrUpdateReleaseGCPolicy.MissingParameter("body").ToResp(), nil
+	}
+	var req apistructs.ReleaseGCPolicyUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateReleaseGCPolicy.InvalidParameter(err).ToResp(), nil
+	}
+	req.ProjectID = projectID
+
+	policy, err := e.release.UpdateGCPolicy(&req, r.Header.Get("User-ID"))
+	if err != nil {
+		return apierrors.ErrUpdateReleaseGCPolicy.InvalidParameter(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(policy)
+}
+
+// ReleaseGCDryRun GET /api/release-gc-policies/{projectId}/actions/dry-run 预演回收, 列出将被删除的 release 及可释放的空间
+func (e *Endpoints) ReleaseGCDryRun(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	projectID, err := strconv.ParseUint(vars["projectId"], 10, 64)
+	if err != nil {
+		return apierrors.ErrReleaseGCDryRun.InvalidParameter("projectId").ToResp(), nil
+	}
+	if err := e.checkReleaseGCPermission(r, projectID, apistructs.GetAction); err != nil {
+		return apierrors.ErrReleaseGCDryRun.AccessDenied().ToResp(), nil
+	}
+
+	policy, err := e.release.GetGCPolicy(projectID)
+	if err != nil {
+		return apierrors.ErrReleaseGCDryRun.InternalError(err).ToResp(), nil
+	}
+	// 查询参数覆盖已保存的策略, 便于保存前预览
+	for key, value := range map[string]*int{
+		"keepLastPerBranch": &policy.KeepLastPerBranch,
+		"keepDeployedDays":  &policy.KeepDeployedDays,
+	} {
+		v := r.URL.Query().Get(key)
+		if v == "" {
+			continue
+		}
+		if *value, err = strconv.Atoi(v); err != nil || *value < 0 {
+			return apierrors.ErrReleaseGCDryRun.InvalidParameter(key).ToResp(), nil
+		}
+	}
+
+	report, err := e.release.GCDryRun(*policy, time.Now())
+	if err != nil {
+		return apierrors.ErrReleaseGCDryRun.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(report)
+}
+
+// checkReleaseGCPermission 内部调用不鉴权, 否则校验项目权限
+func (e *Endpoints) checkReleaseGCPermission(r *http.Request, projectID uint64, action string) error {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return err
+	}
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	resp, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.ProjectResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Access {
+		return errors.Errorf("no permission to %s release gc policy of project %d", action, projectID)
+	}
+	return nil
+}
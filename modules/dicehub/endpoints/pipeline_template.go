@@ -21,9 +21,6 @@ import (
 	"net/url"
 	"strconv"
 
-	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v3"
-
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
 	"github.com/erda-project/erda/pkg/httpserver"
@@ -149,25 +146,39 @@ func (e *Endpoints) querySnippetYml(ctx context.Context, r *http.Request, vars m
 		return nil, err
 	}
 
-	getPipelineTemplateRequest := apistructs.PipelineTemplateVersionGetRequest{
-		Name:      req.Name,
-		ScopeType: req.Source,
-		ScopeID:   req.Labels[apistructs.LabelDiceSnippetScopeID],
-		Version:   req.Labels[apistructs.LabelChooseSnippetVersion],
+	template, err := e.pipelineTemplate.QuerySnippetYml(&req)
+	if err != nil {
+		return nil, err
 	}
 
-	templateVersion, err := e.pipelineTemplate.GetPipelineTemplateVersion(&getPipelineTemplateRequest)
+	return httpserver.OkResp(template)
+}
+
+func (e *Endpoints) QueryPipelineTemplateDependents(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+
+	name, err := url.QueryUnescape(vars["name"])
 	if err != nil {
-		return nil, err
+		return apierrors.ErrQueryPipelineTemplateDependent.InvalidParameter("name").ToResp(), nil
 	}
 
-	var templateAction apistructs.PipelineTemplateSpec
-	if err := yaml.Unmarshal([]byte(templateVersion.Spec), &templateAction); err != nil {
-		logrus.Errorf("Unmarshal specYaml error: %v, yaml: %s", err, templateVersion.Spec)
-		return nil, err
+	scopeType, scopeId, resp := getScopeTypeAndScopeId(r, apierrors.ErrQueryPipelineTemplateDependent)
+	if resp != nil {
+		return resp, nil
+	}
+
+	request := apistructs.PipelineTemplateDependentQueryRequest{
+		ScopeType: scopeType,
+		ScopeID:   scopeId,
+		Name:      name,
+		Version:   r.URL.Query().Get("version"),
 	}
 
-	return httpserver.OkResp(templateAction.Template)
+	result, err := e.pipelineTemplate.QueryPipelineTemplateDependents(&request)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
 }
 
 func (e *Endpoints) QueryPipelineTemplateVersions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
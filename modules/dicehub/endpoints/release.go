@@ -27,6 +27,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/conf"
 	"github.com/erda-project/erda/modules/dicehub/errcode"
 	"github.com/erda-project/erda/modules/dicehub/response"
 	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
@@ -70,6 +71,15 @@ func (e *Endpoints) CreateRelease(ctx context.Context, r *http.Request, vars map
 		return apierrors.ErrCreateRelease.InternalError(err).ToResp(), nil
 	}
 
+	// 自动触发镜像漏洞扫描, 扫描失败不影响 release 创建
+	if conf.ScanOnCreate() && e.scan.Enabled() {
+		go func() {
+			if _, err := e.scan.Trigger(0, releaseID); err != nil {
+				logrus.Errorf("failed to trigger scan of release %s, err: %v", releaseID, err)
+			}
+		}()
+	}
+
 	respBody := &apistructs.ReleaseCreateResponseData{
 		ReleaseID: releaseID,
 	}
@@ -243,9 +253,11 @@ func (e *Endpoints) GetDiceYAML(w http.ResponseWriter, r *http.Request) {
 
 	logrus.Infof("getting dice.yml...releaseId: %s\n", releaseID)
 
-	diceYAML, err := e.release.GetDiceYAML(orgID, releaseID)
+	diceYAML, err := e.release.GetDiceYAML(orgID, releaseID, r.URL.Query().Get("workspace"))
 	if err != nil {
+		logrus.Errorf("get dice.yml error: %v", err)
 		response.Error(w, http.StatusNotFound, errcode.ResourceNotFound, "release not found")
+		return
 	}
 
 	if strings.Contains(r.Header.Get("Accept"), "application/x-yaml") {
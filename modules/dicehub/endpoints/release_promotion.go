@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/httpserver"
+)
+
+// PromoteRelease POST /api/releases/{releaseId}/promotions release 晋级
+func (e *Endpoints) PromoteRelease(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrPromoteRelease.NotLogin().ToResp(), nil
+	}
+	userID, err := user.GetUserID(r)
+	if err != nil {
+		return apierrors.ErrPromoteRelease.NotLogin().ToResp(), nil
+	}
+
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrPromoteRelease.MissingParameter("releaseId").ToResp(), nil
+	}
+	if r.Body == nil {
+		return apierrors.ErrPromoteRelease.MissingParameter("body").ToResp(), nil
+	}
+	var req apistructs.ReleasePromotionCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrPromoteRelease.InvalidParameter(err).ToResp(), nil
+	}
+	req.ReleaseID = releaseID
+	if req.Workspace == "" {
+		return apierrors.ErrPromoteRelease.MissingParameter("workspace").ToResp(), nil
+	}
+	if err := e.checkPromotePermission(userID.String(), releaseID, req.Workspace); err != nil {
+		return apierrors.ErrPromoteRelease.AccessDenied().ToResp(), nil
+	}
+
+	promotion, err := e.release.Promote(orgID, &req, userID.String())
+	if err != nil {
+		return apierrors.ErrPromoteRelease.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(promotion)
+}
+
+// ListReleasePromotions GET /api/releases/{releaseId}/promotions release 晋级记录
+func (e *Endpoints) ListReleasePromotions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrListReleasePromotion.NotLogin().ToResp(), nil
+	}
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrListReleasePromotion.MissingParameter("releaseId").ToResp(), nil
+	}
+
+	promotions, err := e.release.ListPromotions(orgID, releaseID)
+	if err != nil {
+		return apierrors.ErrListReleasePromotion.InternalError(err).ToResp(), nil
+	}
+	userIDs := make([]string, 0, len(promotions))
+	for _, v := range promotions {
+		userIDs = append(userIDs, v.Operator)
+	}
+
+	return httpserver.OkResp(promotions, userIDs)
+}
+
+// ListReleaseDeployments GET /api/releases/{releaseId}/deployments release 当前部署的 runtime
+func (e *Endpoints) ListReleaseDeployments(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrListReleaseDeployment.NotLogin().ToResp(), nil
+	}
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrListReleaseDeployment.MissingParameter("releaseId").ToResp(), nil
+	}
+
+	deployments, err := e.release.ListDeployments(orgID, releaseID)
+	if err != nil {
+		return apierrors.ErrListReleaseDeployment.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(deployments)
+}
+
+// WatchPromotionApproval 监听审批流状态变更，同步 release 晋级状态
+func (e *Endpoints) WatchPromotionApproval(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var event apistructs.ApprovalStatusChangedEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		return apierrors.ErrApprovalStatusChanged.InvalidParameter(err).ToResp(), nil
+	}
+	if event.Content.ApprovalType != apistructs.ApproveReleasePromotion {
+		return httpserver.OkResp("ignored")
+	}
+	logrus.Infof("approvalStatusChangedEvent: %+v", event)
+
+	if err := e.release.UpdatePromotionApproval(event.Content.ApprovalID, event.Content.ApprovalStatus); err != nil {
+		return apierrors.ErrApprovalStatusChanged.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp("handle success")
+}
+
+// checkPromotePermission 晋级至 STAGING/PROD 需要保护分支部署权限, 其余环境需要普通分支部署权限
+func (e *Endpoints) checkPromotePermission(userID, releaseID, workspace string) error {
+	release, err := e.release.Get(0, releaseID)
+	if err != nil {
+		return err
+	}
+	resource := apistructs.NormalBranchResource
+	switch apistructs.DiceWorkspace(strings.ToUpper(workspace)) {
+	case apistructs.StagingWorkspace, apistructs.ProdWorkspace:
+		resource = apistructs.ProtectedBranchResource
+	}
+	resp, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   userID,
+		Scope:    apistructs.AppScope,
+		ScopeID:  uint64(release.ApplicationID),
+		Resource: resource,
+		Action:   apistructs.OperateAction,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Access {
+		return errors.Errorf("no permission to promote release %s to %s", releaseID, workspace)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
+	"github.com/erda-project/erda/pkg/httpserver"
+)
+
+// ScanRelease POST /api/releases/{releaseId}/actions/scan 触发 release 镜像漏洞扫描
+func (e *Endpoints) ScanRelease(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrScanRelease.NotLogin().ToResp(), nil
+	}
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrScanRelease.MissingParameter("releaseId").ToResp(), nil
+	}
+
+	report, err := e.scan.Trigger(orgID, releaseID)
+	if err != nil {
+		return apierrors.ErrScanRelease.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(report)
+}
+
+// GetReleaseScanReport GET /api/releases/{releaseId}/scan-report 获取 release 扫描报告
+func (e *Endpoints) GetReleaseScanReport(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrGetReleaseScanReport.NotLogin().ToResp(), nil
+	}
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrGetReleaseScanReport.MissingParameter("releaseId").ToResp(), nil
+	}
+	var withSBOM bool
+	if v := r.URL.Query().Get("withSBOM"); v != "" {
+		if withSBOM, err = strconv.ParseBool(v); err != nil {
+			return apierrors.ErrGetReleaseScanReport.InvalidParameter(err).ToResp(), nil
+		}
+	}
+
+	report, err := e.scan.Get(orgID, releaseID, withSBOM)
+	if err != nil {
+		return apierrors.ErrGetReleaseScanReport.InternalError(err).ToResp(), nil
+	}
+	if report == nil {
+		return apierrors.ErrGetReleaseScanReport.NotFound().ToResp(), nil
+	}
+
+	return httpserver.OkResp(report)
+}
+
+// CheckReleaseScanGate GET /api/releases/{releaseId}/scan-report/actions/check-gate 检查 release 漏洞卡点
+func (e *Endpoints) CheckReleaseScanGate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	orgID, err := getPermissionHeader(r)
+	if err != nil {
+		return apierrors.ErrCheckReleaseScanGate.NotLogin().ToResp(), nil
+	}
+	releaseID := vars["releaseId"]
+	if releaseID == "" {
+		return apierrors.ErrCheckReleaseScanGate.MissingParameter("releaseId").ToResp(), nil
+	}
+
+	result, err := e.scan.CheckGate(orgID, releaseID, r.URL.Query().Get("severity"), r.URL.Query().Get("workspace"))
+	if err != nil {
+		return apierrors.ErrCheckReleaseScanGate.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(result)
+}
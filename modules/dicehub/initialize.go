@@ -20,18 +20,23 @@ import (
 	"github.com/gorilla/schema"
 	"github.com/sirupsen/logrus"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/dicehub/conf"
 	"github.com/erda-project/erda/modules/dicehub/dbclient"
 	"github.com/erda-project/erda/modules/dicehub/endpoints"
 	"github.com/erda-project/erda/modules/dicehub/recycle"
+	"github.com/erda-project/erda/modules/dicehub/scanner"
 	"github.com/erda-project/erda/modules/dicehub/service/extension"
 	"github.com/erda-project/erda/modules/dicehub/service/image"
 	"github.com/erda-project/erda/modules/dicehub/service/publish_item"
 	"github.com/erda-project/erda/modules/dicehub/service/release"
+	"github.com/erda-project/erda/modules/dicehub/service/scan"
 	"github.com/erda-project/erda/modules/dicehub/service/template"
+	"github.com/erda-project/erda/pkg/discover"
 	"github.com/erda-project/erda/pkg/httpserver"
 	"github.com/erda-project/erda/pkg/jsonstore/etcd"
+	"github.com/erda-project/erda/pkg/strutil"
 	// "terminus.io/dice/telemetry/promxp"
 )
 
@@ -80,6 +85,23 @@ func ReleaseGC(rl *release.Release) error {
 	return nil
 }
 
+func registerWebHook(bdl *bundle.Bundle) {
+	ev := apistructs.CreateHookRequest{
+		Name:   "dicehub_approve_status_changed",
+		Events: []string{bundle.ApprovalStatusChangedEvent},
+		URL:    strutil.Concat("http://", discover.DiceHub(), "/api/releases/promotions/actions/watch-approval"),
+		Active: true,
+		HookLocation: apistructs.HookLocation{
+			Org:         "-1",
+			Project:     "-1",
+			Application: "-1",
+		},
+	}
+	if err := bdl.CreateWebhook(ev); err != nil {
+		logrus.Warnf("failed to register approval status changed event, %v", err)
+	}
+}
+
 // 初始化 Endpoints
 func initEndpoints(p *provider) (*endpoints.Endpoints, error) {
 	// 数据库初始化
@@ -97,6 +119,9 @@ func initEndpoints(p *provider) (*endpoints.Endpoints, error) {
 		bundle.WithPipeline(),
 	}
 	bdl := bundle.New(bundleOpts...)
+	// 注册审批流状态变更监听, 同步 release 晋级审批状态
+	registerWebHook(bdl)
+
 	rl := release.New(
 		release.WithDBClient(db),
 		release.WithBundle(bdl),
@@ -124,6 +149,22 @@ func initEndpoints(p *provider) (*endpoints.Endpoints, error) {
 		template.WithDBClient(db),
 	)
 
+	imageScanner := scanner.New(
+		scanner.NewRegistryClient(
+			scanner.WithInsecure(conf.RegistryInsecure()),
+			scanner.WithBasicAuth(conf.RegistryUsername(), conf.RegistryPassword()),
+		),
+		scanner.NewDBLoader(conf.VulnDBPath()),
+	)
+	releaseScan := scan.New(
+		scan.WithDBClient(db),
+		scan.WithScanner(imageScanner),
+		scan.WithSeverityGate(conf.VulnSeverityGate()),
+		scan.WithGateEnforce(conf.VulnGateEnforce()),
+		scan.WithMaxConcurrent(conf.MaxConcurrentScan()),
+		scan.WithScanTimeout(conf.ScanTimeout()),
+	)
+
 	// queryStringDecoder
 	queryStringDecoder := schema.NewDecoder()
 	queryStringDecoder.IgnoreUnknownKeys(true)
@@ -137,6 +178,7 @@ func initEndpoints(p *provider) (*endpoints.Endpoints, error) {
 		endpoints.WithExtension(ext),
 		endpoints.WithPublishItem(publishItem),
 		endpoints.WithPipelineTemplate(pipelineTemplate),
+		endpoints.WithScan(releaseScan),
 		endpoints.WithQueryStringDecoder(queryStringDecoder),
 	)
 
@@ -53,3 +53,34 @@ func DeleteManifests(clusterName string, images []string) error {
 
 	return nil
 }
+
+// GetManifestsSize 查询镜像大小(字节)，返回成功查询的镜像大小，查询失败的镜像不在结果中
+func GetManifestsSize(clusterName string, images []string) (map[string]int64, error) {
+	if len(images) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	sizeReq := &apistructs.RegistryManifestsSizeRequest{
+		Images: images,
+	}
+
+	var sizeResp apistructs.RegistryManifestsSizeResponse
+	path := fmt.Sprintf("/api/clusters/%s/registry/manifests/actions/size", clusterName)
+	resp, err := httpclient.New().Post(discover.Ops()).
+		Path(path).
+		Header("Content-Type", "application/json").
+		JSONBody(sizeReq).
+		Do().
+		JSON(&sizeResp)
+	if err != nil {
+		return nil, errors.Errorf("get image size: %+v error: %v", images, err)
+	}
+	if !resp.IsOK() || !sizeResp.Success {
+		return nil, errors.Errorf("get image size: %+v fail, statusCode: %d, err: %+v", images, resp.StatusCode(), sizeResp.Error)
+	}
+	if sizeResp.Data.Sizes == nil {
+		return map[string]int64{}, nil
+	}
+
+	return sizeResp.Data.Sizes, nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"debug/elf"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+var jarFileNameRegexp = regexp.MustCompile(`^(.+?)-(\d[\w.\-]*)\.[jwe]ar$`)
+
+// parseJar 解析 jar/war/ear 中的 pom.properties, 包括 BOOT-INF/lib 和 WEB-INF/lib 下的嵌套 jar
+func parseJar(name string, data []byte) []apistructs.SBOMPackage {
+	return parseJarData(name, data, true)
+}
+
+func parseJarData(name string, data []byte, nested bool) []apistructs.SBOMPackage {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil
+	}
+	var pkgs []apistructs.SBOMPackage
+	for _, f := range zr.File {
+		switch {
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && strings.HasSuffix(f.Name, "/pom.properties"):
+			content, err := readZipFile(f)
+			if err != nil {
+				continue
+			}
+			props := parseControlFields(string(content), "=")
+			if props["groupId"] == "" || props["artifactId"] == "" || props["version"] == "" {
+				continue
+			}
+			pkgs = append(pkgs, apistructs.SBOMPackage{
+				Type:    apistructs.SBOMPackageTypeMaven,
+				Name:    props["groupId"] + ":" + props["artifactId"],
+				Version: props["version"],
+				Path:    name,
+			})
+		case nested && strings.HasSuffix(f.Name, ".jar") && f.UncompressedSize64 <= maxFileSize:
+			content, err := readZipFile(f)
+			if err != nil {
+				continue
+			}
+			pkgs = append(pkgs, parseJarData(name+"!/"+f.Name, content, false)...)
+		}
+	}
+	if len(pkgs) > 0 {
+		return pkgs
+	}
+
+	// 没有 pom.properties 时根据文件名推断, 只有 artifactId
+	matches := jarFileNameRegexp.FindStringSubmatch(path.Base(name))
+	if len(matches) != 3 {
+		return nil
+	}
+	return []apistructs.SBOMPackage{{
+		Type:    apistructs.SBOMPackageTypeMaven,
+		Name:    matches[1],
+		Version: matches[2],
+		Path:    name,
+	}}
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// parseNpmPackage 解析 node_modules 下的 package.json
+func parseNpmPackage(name string, data []byte) []apistructs.SBOMPackage {
+	var pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || pkg.Name == "" || pkg.Version == "" {
+		return nil
+	}
+	return []apistructs.SBOMPackage{{
+		Type:    apistructs.SBOMPackageTypeNpm,
+		Name:    pkg.Name,
+		Version: pkg.Version,
+		Path:    name,
+	}}
+}
+
+var buildInfoMagic = []byte("\xff Go buildinf:")
+
+// parseGoBinary 解析 go 可执行文件中的 buildinfo, 返回依赖的 module 及标准库版本
+func parseGoBinary(name string, data []byte) []apistructs.SBOMPackage {
+	if !bytes.HasPrefix(data, []byte(elf.ELFMAG)) {
+		return nil
+	}
+	goVersion, modInfo := readGoBuildInfo(data)
+	if goVersion == "" {
+		return nil
+	}
+	pkgs := []apistructs.SBOMPackage{{
+		Type:    apistructs.SBOMPackageTypeGo,
+		Name:    "stdlib",
+		Version: strings.TrimPrefix(goVersion, "go"),
+		Path:    name,
+	}}
+	for _, mod := range parseModInfo(modInfo) {
+		mod.Path = name
+		pkgs = append(pkgs, mod)
+	}
+	return pkgs
+}
+
+// readGoBuildInfo 读取 .go.buildinfo 段, 兼容 go1.18 之前的指针格式和之后的内联格式
+func readGoBuildInfo(data []byte) (goVersion, modInfo string) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return "", ""
+	}
+	sect := f.Section(".go.buildinfo")
+	if sect == nil {
+		return "", ""
+	}
+	info, err := sect.Data()
+	if err != nil || len(info) < 32 || !bytes.HasPrefix(info, buildInfoMagic) {
+		return "", ""
+	}
+	ptrSize := int(info[14])
+	flags := info[15]
+
+	if flags&2 != 0 {
+		rest := info[32:]
+		goVersion, rest = readVarintString(rest)
+		modInfo, _ = readVarintString(rest)
+	} else {
+		if ptrSize != 4 && ptrSize != 8 || len(info) < 16+2*ptrSize {
+			return "", ""
+		}
+		var order binary.ByteOrder = binary.LittleEndian
+		if flags&1 != 0 {
+			order = binary.BigEndian
+		}
+		readPtr := func(b []byte) uint64 {
+			if ptrSize == 4 {
+				return uint64(order.Uint32(b))
+			}
+			return order.Uint64(b)
+		}
+		readString := func(addr uint64) string {
+			hdr := readELFAddr(f, addr, uint64(2*ptrSize))
+			if hdr == nil {
+				return ""
+			}
+			return string(readELFAddr(f, readPtr(hdr), readPtr(hdr[ptrSize:])))
+		}
+		goVersion = readString(readPtr(info[16:]))
+		modInfo = readString(readPtr(info[16+ptrSize:]))
+	}
+	// modinfo 前后各有 16 字节的哨兵
+	if len(modInfo) >= 33 && modInfo[len(modInfo)-17] == '\n' {
+		modInfo = modInfo[16 : len(modInfo)-16]
+	}
+	return goVersion, modInfo
+}
+
+func readVarintString(b []byte) (string, []byte) {
+	n, size := binary.Uvarint(b)
+	if size <= 0 || uint64(len(b)-size) < n {
+		return "", nil
+	}
+	return string(b[size : size+int(n)]), b[size+int(n):]
+}
+
+func readELFAddr(f *elf.File, addr, size uint64) []byte {
+	if size > maxFileSize {
+		return nil
+	}
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD || addr < p.Vaddr || addr+size > p.Vaddr+p.Filesz {
+			continue
+		}
+		buf := make([]byte, size)
+		if _, err := p.ReadAt(buf, int64(addr-p.Vaddr)); err != nil {
+			return nil
+		}
+		return buf
+	}
+	return nil
+}
+
+// parseModInfo 解析 go modinfo, 格式: mod/dep/=> \t path \t version \t sum
+func parseModInfo(modInfo string) []apistructs.SBOMPackage {
+	var pkgs []apistructs.SBOMPackage
+	for _, line := range strings.Split(modInfo, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		switch fields[0] {
+		case "mod", "dep":
+			if fields[2] == "(devel)" {
+				continue
+			}
+			pkgs = append(pkgs, apistructs.SBOMPackage{
+				Type:    apistructs.SBOMPackageTypeGo,
+				Name:    fields[1],
+				Version: fields[2],
+			})
+		case "=>":
+			// replace 上一个依赖
+			if len(pkgs) > 0 {
+				pkgs[len(pkgs)-1].Name = fields[1]
+				pkgs[len(pkgs)-1].Version = fields[2]
+			}
+		}
+	}
+	return pkgs
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package scanner 镜像漏洞扫描: 从 registry 拉取镜像 layer, 生成 SBOM, 并与离线漏洞库匹配
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// ImageRef 镜像地址解析结果
+type ImageRef struct {
+	Registry   string
+	Repository string
+	Reference  string // tag 或 digest
+}
+
+// ParseImage 解析镜像地址, eg: registry:5000/ns/app:tag, registry/ns/app@sha256:xxx
+func ParseImage(image string) (*ImageRef, error) {
+	slash := strings.Index(image, "/")
+	if slash <= 0 {
+		return nil, errors.Errorf("invalid image %s, registry is missing", image)
+	}
+	ref := &ImageRef{Registry: image[:slash]}
+	remain := image[slash+1:]
+	if at := strings.Index(remain, "@"); at >= 0 {
+		ref.Repository, ref.Reference = remain[:at], remain[at+1:]
+	} else if colon := strings.LastIndex(remain, ":"); colon >= 0 && !strings.Contains(remain[colon:], "/") {
+		ref.Repository, ref.Reference = remain[:colon], remain[colon+1:]
+	} else {
+		ref.Repository, ref.Reference = remain, "latest"
+	}
+	if ref.Repository == "" || ref.Reference == "" {
+		return nil, errors.Errorf("invalid image %s", image)
+	}
+	return ref, nil
+}
+
+// Descriptor manifest 中的 layer 描述
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// RegistryClient docker registry v2 客户端, 只读
+type RegistryClient struct {
+	cli      *http.Client
+	scheme   string
+	username string
+	password string
+}
+
+// RegistryOption 定义 RegistryClient 的配置选项
+type RegistryOption func(*RegistryClient)
+
+// WithInsecure 使用 http 访问 registry
+func WithInsecure(insecure bool) RegistryOption {
+	return func(c *RegistryClient) {
+		if insecure {
+			c.scheme = "http"
+		}
+	}
+}
+
+// WithBasicAuth 配置 registry 认证信息
+func WithBasicAuth(username, password string) RegistryOption {
+	return func(c *RegistryClient) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// NewRegistryClient 新建 registry 客户端
+func NewRegistryClient(options ...RegistryOption) *RegistryClient {
+	c := &RegistryClient{
+		cli:    &http.Client{Timeout: 30 * time.Minute},
+		scheme: "https",
+	}
+	for _, op := range options {
+		op(c)
+	}
+	return c
+}
+
+// Layers 获取镜像的 layer 列表, manifest list 时选择 linux/amd64
+func (c *RegistryClient) Layers(ref *ImageRef) ([]Descriptor, error) {
+	m, err := c.manifest(ref, ref.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if m.MediaType == mediaTypeDockerManifestList || m.MediaType == mediaTypeOCIIndex || len(m.Manifests) > 0 {
+		digest := ""
+		for _, v := range m.Manifests {
+			if v.Platform == nil || (v.Platform.OS == "linux" && v.Platform.Architecture == "amd64") {
+				digest = v.Digest
+				break
+			}
+		}
+		if digest == "" {
+			return nil, errors.Errorf("no linux/amd64 manifest found in %s/%s:%s", ref.Registry, ref.Repository, ref.Reference)
+		}
+		if m, err = c.manifest(ref, digest); err != nil {
+			return nil, err
+		}
+	}
+	return m.Layers, nil
+}
+
+// Blob 获取 layer 内容, 调用方负责关闭
+func (c *RegistryClient) Blob(ref *ImageRef, digest string) (io.ReadCloser, error) {
+	resp, err := c.do(ref, fmt.Sprintf("/v2/%s/blobs/%s", ref.Repository, digest), "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *RegistryClient) manifest(ref *ImageRef, reference string) (*manifest, error) {
+	accept := strings.Join([]string{mediaTypeDockerManifest, mediaTypeDockerManifestList,
+		mediaTypeOCIManifest, mediaTypeOCIIndex}, ",")
+	resp, err := c.do(ref, fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, reference), accept)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var m manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "failed to decode manifest")
+	}
+	if m.MediaType == "" {
+		m.MediaType = resp.Header.Get("Content-Type")
+	}
+	return &m, nil
+}
+
+func (c *RegistryClient) do(ref *ImageRef, path, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.scheme+"://"+ref.Registry+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to request registry %s", ref.Registry)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, errors.Errorf("failed to request registry %s%s, statusCode: %d, body: %s",
+			ref.Registry, path, resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+
+	// maxFileSize 超过该大小的文件不解析
+	maxFileSize = 256 << 20
+)
+
+type fileEntry struct {
+	layer    int
+	packages []apistructs.SBOMPackage
+}
+
+// SBOMBuilder 按顺序叠加镜像 layer 生成 SBOM, 后面的 layer 覆盖或删除(whiteout)前面 layer 的文件
+type SBOMBuilder struct {
+	layer int
+	files map[string]*fileEntry
+	os    string
+}
+
+// NewSBOMBuilder 新建 SBOMBuilder
+func NewSBOMBuilder() *SBOMBuilder {
+	return &SBOMBuilder{files: make(map[string]*fileEntry)}
+}
+
+// AddLayer 解析一个 layer, 支持 gzip 压缩和未压缩的 tar
+func (b *SBOMBuilder) AddLayer(r io.Reader) error {
+	b.layer++
+	br := bufio.NewReader(r)
+	var reader io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return errors.Wrap(err, "failed to read gzip layer")
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read layer")
+		}
+		name := path.Clean("/" + hdr.Name)
+		dir, base := path.Split(name)
+		dir = path.Clean(dir)
+
+		switch {
+		case base == whiteoutOpaque:
+			b.remove(dir, true)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			b.remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), false)
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		delete(b.files, name)
+		parser := b.parserFor(name, hdr)
+		if parser == nil || hdr.Size > maxFileSize {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", name)
+		}
+		if pkgs := parser(name, data); len(pkgs) > 0 {
+			b.files[name] = &fileEntry{layer: b.layer, packages: pkgs}
+		}
+	}
+}
+
+// OS 镜像操作系统, eg: debian:10
+func (b *SBOMBuilder) OS() string {
+	return b.os
+}
+
+// Packages 返回去重排序后的软件包
+func (b *SBOMBuilder) Packages() []apistructs.SBOMPackage {
+	seen := make(map[string]struct{})
+	var pkgs []apistructs.SBOMPackage
+	for _, entry := range b.files {
+		for _, pkg := range entry.packages {
+			key := pkg.Type + "/" + pkg.Name + "@" + pkg.Version + "@" + pkg.Path
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Type != pkgs[j].Type {
+			return pkgs[i].Type < pkgs[j].Type
+		}
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		if pkgs[i].Version != pkgs[j].Version {
+			return pkgs[i].Version < pkgs[j].Version
+		}
+		return pkgs[i].Path < pkgs[j].Path
+	})
+	return pkgs
+}
+
+// remove 删除之前 layer 的文件, opaque 时只删除目录下的文件
+func (b *SBOMBuilder) remove(name string, opaque bool) {
+	for file, entry := range b.files {
+		if entry.layer >= b.layer {
+			continue
+		}
+		if (!opaque && file == name) || strings.HasPrefix(file, name+"/") {
+			delete(b.files, file)
+		}
+	}
+}
+
+type fileParser func(name string, data []byte) []apistructs.SBOMPackage
+
+func (b *SBOMBuilder) parserFor(name string, hdr *tar.Header) fileParser {
+	base := path.Base(name)
+	switch {
+	case name == "/etc/os-release" || name == "/usr/lib/os-release":
+		return func(_ string, data []byte) []apistructs.SBOMPackage {
+			b.os = parseOSRelease(data)
+			return nil
+		}
+	case name == "/var/lib/dpkg/status" || strings.HasPrefix(name, "/var/lib/dpkg/status.d/"):
+		return parseDpkgStatus
+	case name == "/lib/apk/db/installed":
+		return parseApkInstalled
+	case strings.HasSuffix(base, ".jar") || strings.HasSuffix(base, ".war") || strings.HasSuffix(base, ".ear"):
+		return parseJar
+	case base == "package.json" && isNodeModule(name):
+		return parseNpmPackage
+	case hdr.Mode&0111 != 0:
+		return parseGoBinary
+	}
+	return nil
+}
+
+// isNodeModule 判断 package.json 是否为 node_modules 下的依赖包, 支持 @scope/name
+func isNodeModule(name string) bool {
+	dir := path.Dir(name)
+	parent := path.Dir(dir)
+	if path.Base(parent) == "node_modules" {
+		return true
+	}
+	return strings.HasPrefix(path.Base(parent), "@") && path.Base(path.Dir(parent)) == "node_modules"
+}
+
+// parseOSRelease 解析 /etc/os-release, 返回 ID:VERSION_ID
+func parseOSRelease(data []byte) string {
+	var id, version string
+	for _, line := range strings.Split(string(data), "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.Trim(kv[1], `"'`)
+		switch kv[0] {
+		case "ID":
+			id = value
+		case "VERSION_ID":
+			version = value
+		}
+	}
+	if id == "" {
+		return ""
+	}
+	if version == "" {
+		return id
+	}
+	return id + ":" + version
+}
+
+// parseDpkgStatus 解析 dpkg status 文件, 只保留已安装的包
+func parseDpkgStatus(name string, data []byte) []apistructs.SBOMPackage {
+	var pkgs []apistructs.SBOMPackage
+	for _, paragraph := range strings.Split(string(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))), "\n\n") {
+		fields := parseControlFields(paragraph, ":")
+		if fields["Package"] == "" || fields["Version"] == "" {
+			continue
+		}
+		if status, ok := fields["Status"]; ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		pkgs = append(pkgs, apistructs.SBOMPackage{
+			Type:    apistructs.SBOMPackageTypeDeb,
+			Name:    fields["Package"],
+			Version: fields["Version"],
+			Path:    name,
+		})
+	}
+	return pkgs
+}
+
+// parseApkInstalled 解析 alpine apk 数据库
+func parseApkInstalled(name string, data []byte) []apistructs.SBOMPackage {
+	var pkgs []apistructs.SBOMPackage
+	for _, paragraph := range strings.Split(string(data), "\n\n") {
+		fields := parseControlFields(paragraph, ":")
+		if fields["P"] == "" || fields["V"] == "" {
+			continue
+		}
+		pkgs = append(pkgs, apistructs.SBOMPackage{
+			Type:    apistructs.SBOMPackageTypeApk,
+			Name:    fields["P"],
+			Version: fields["V"],
+			Path:    name,
+		})
+	}
+	return pkgs
+}
+
+// parseControlFields 解析 key: value 格式, 忽略续行
+func parseControlFields(paragraph, sep string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(paragraph, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		kv := strings.SplitN(line, sep, 2)
+		if len(kv) != 2 {
+			continue
+		}
+		fields[kv[0]] = strings.TrimSpace(kv[1])
+	}
+	return fields
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+type tarFile struct {
+	name string
+	mode int64
+	data []byte
+}
+
+func buildLayer(t *testing.T, gz bool, files ...tarFile) *bytes.Buffer {
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var zw *gzip.Writer
+	if gz {
+		zw = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(zw)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+	for _, f := range files {
+		mode := f.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: mode, Size: int64(len(f.data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	if zw != nil {
+		assert.NoError(t, zw.Close())
+	}
+	return &buf
+}
+
+func buildJar(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write(data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestSBOMBuilder(t *testing.T) {
+	dpkg := `Package: libc6
+Status: install ok installed
+Version: 2.28-10
+Description: GNU C Library
+ continuation line
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+`
+	apk := "P:musl\nV:1.1.24-r2\n\nP:busybox\nV:1.31.1-r9\n"
+	innerJar := buildJar(t, map[string][]byte{
+		"META-INF/maven/org.apache.logging.log4j/log4j-core/pom.properties": []byte("groupId=org.apache.logging.log4j\nartifactId=log4j-core\nversion=2.14.1\n"),
+	})
+	bootJar := buildJar(t, map[string][]byte{"BOOT-INF/lib/log4j-core-2.14.1.jar": innerJar})
+
+	b := NewSBOMBuilder()
+	assert.NoError(t, b.AddLayer(buildLayer(t, true,
+		tarFile{name: "etc/os-release", data: []byte("ID=debian\nVERSION_ID=\"10\"\n")},
+		tarFile{name: "var/lib/dpkg/status", data: []byte(dpkg)},
+		tarFile{name: "lib/apk/db/installed", data: []byte(apk)},
+		tarFile{name: "app/node_modules/lodash/package.json", data: []byte(`{"name":"lodash","version":"4.17.20"}`)},
+		tarFile{name: "app/node_modules/@babel/core/package.json", data: []byte(`{"name":"@babel/core","version":"7.0.0"}`)},
+		tarFile{name: "app/package.json", data: []byte(`{"name":"app","version":"1.0.0"}`)},
+		tarFile{name: "opt/lib/commons-text-1.9.jar", data: buildJar(t, map[string][]byte{"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\n")})},
+	)))
+	assert.NoError(t, b.AddLayer(buildLayer(t, false,
+		tarFile{name: "lib/apk/db/.wh.installed"},
+		tarFile{name: "app/node_modules/@babel/.wh..wh..opq"},
+		tarFile{name: "app.jar", data: bootJar},
+	)))
+
+	assert.Equal(t, "debian:10", b.OS())
+	assert.Equal(t, []apistructs.SBOMPackage{
+		{Type: "deb", Name: "libc6", Version: "2.28-10", Path: "/var/lib/dpkg/status"},
+		{Type: "maven", Name: "commons-text", Version: "1.9", Path: "/opt/lib/commons-text-1.9.jar"},
+		{Type: "maven", Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1", Path: "/app.jar!/BOOT-INF/lib/log4j-core-2.14.1.jar"},
+		{Type: "npm", Name: "lodash", Version: "4.17.20", Path: "/app/node_modules/lodash/package.json"},
+	}, b.Packages())
+}
+
+func TestParseGoBinary(t *testing.T) {
+	exe, err := os.Executable()
+	assert.NoError(t, err)
+	data, err := os.ReadFile(exe)
+	assert.NoError(t, err)
+	if !bytes.HasPrefix(data, []byte("\x7fELF")) {
+		t.Skip("not an elf binary")
+	}
+
+	pkgs := parseGoBinary("/test", data)
+	assert.NotEmpty(t, pkgs)
+	assert.Equal(t, "stdlib", pkgs[0].Name)
+	assert.Equal(t, strings.TrimPrefix(runtime.Version(), "go"), pkgs[0].Version)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// Scanner 镜像漏洞扫描器
+type Scanner struct {
+	registry *RegistryClient
+	dbLoader *DBLoader
+}
+
+// New 新建 Scanner
+func New(registry *RegistryClient, dbLoader *DBLoader) *Scanner {
+	return &Scanner{registry: registry, dbLoader: dbLoader}
+}
+
+// Enabled 是否配置了漏洞库
+func (s *Scanner) Enabled() bool {
+	return s.dbLoader != nil && s.dbLoader.path != ""
+}
+
+// DBVersion 当前漏洞库版本
+func (s *Scanner) DBVersion() (string, error) {
+	db, err := s.dbLoader.Get()
+	if err != nil {
+		return "", err
+	}
+	return db.Version, nil
+}
+
+// ScanImage 拉取镜像 layer 生成 SBOM 并匹配漏洞库
+func (s *Scanner) ScanImage(image string) (*apistructs.ReleaseImageScan, error) {
+	db, err := s.dbLoader.Get()
+	if err != nil {
+		return nil, err
+	}
+	ref, err := ParseImage(image)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := s.registry.Layers(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := NewSBOMBuilder()
+	for _, layer := range layers {
+		if err := s.addLayer(builder, ref, layer); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &apistructs.ReleaseImageScan{
+		Image:           image,
+		OS:              builder.OS(),
+		Packages:        builder.Packages(),
+		Vulnerabilities: []apistructs.ReleaseVulnerability{},
+	}
+	for _, pkg := range result.Packages {
+		result.Vulnerabilities = append(result.Vulnerabilities, db.Match(pkg)...)
+	}
+	SortVulnerabilities(result.Vulnerabilities)
+	return result, nil
+}
+
+func (s *Scanner) addLayer(builder *SBOMBuilder, ref *ImageRef, layer Descriptor) error {
+	blob, err := s.registry.Blob(ref, layer.Digest)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	return errors.Wrapf(builder.AddLayer(blob), "layer %s", layer.Digest)
+}
+
+// SortVulnerabilities 按等级从高到低, 再按包名和漏洞 ID 排序
+func SortVulnerabilities(vulns []apistructs.ReleaseVulnerability) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		ri, rj := SeverityRank(vulns[i].Severity), SeverityRank(vulns[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		if vulns[i].Package != vulns[j].Package {
+			return vulns[i].Package < vulns[j].Package
+		}
+		return vulns[i].ID < vulns[j].ID
+	})
+}
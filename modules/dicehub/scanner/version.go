@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// compareVersion 比较同一类型包的两个版本, 返回 -1, 0, 1
+func compareVersion(pkgType, a, b string) int {
+	switch pkgType {
+	case apistructs.SBOMPackageTypeGo, apistructs.SBOMPackageTypeNpm:
+		return compareSemver(a, b)
+	default:
+		return compareDebVersion(a, b)
+	}
+}
+
+// compareSemver 比较语义化版本, 忽略 build 元数据, 预发布版本低于正式版本
+func compareSemver(a, b string) int {
+	a, aPre := splitSemver(a)
+	b, bPre := splitSemver(b)
+	if c := verrevcmp(a, b); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return verrevcmp(aPre, bPre)
+}
+
+func splitSemver(v string) (string, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// compareDebVersion 比较 [epoch:]upstream[-revision] 格式的版本
+func compareDebVersion(a, b string) int {
+	aEpoch, aVer, aRev := splitDebVersion(a)
+	bEpoch, bVer, bRev := splitDebVersion(b)
+	switch {
+	case aEpoch < bEpoch:
+		return -1
+	case aEpoch > bEpoch:
+		return 1
+	}
+	if c := verrevcmp(aVer, bVer); c != 0 {
+		return c
+	}
+	return verrevcmp(aRev, bRev)
+}
+
+func splitDebVersion(v string) (int, string, string) {
+	v = strings.TrimSpace(v)
+	epoch := 0
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		epoch, _ = strconv.Atoi(v[:i])
+		v = v[i+1:]
+	}
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.LastIndexByte(v, '-'); i >= 0 {
+		return epoch, v[:i], v[i+1:]
+	}
+	return epoch, v, ""
+}
+
+// verrevcmp dpkg 的版本比较算法: 交替比较非数字段和数字段, '~' 排在最前
+func verrevcmp(a, b string) int {
+	for a != "" || b != "" {
+		firstDiff := 0
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			ac, bc := 0, 0
+			if a != "" {
+				ac = charOrder(a[0])
+			}
+			if b != "" {
+				bc = charOrder(b[0])
+			}
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			a, b = a[1:], b[1:]
+		}
+		for a != "" && a[0] == '0' {
+			a = a[1:]
+		}
+		for b != "" && b[0] == '0' {
+			b = b[1:]
+		}
+		for a != "" && isDigit(a[0]) && b != "" && isDigit(b[0]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[0]) - int(b[0])
+			}
+			a, b = a[1:], b[1:]
+		}
+		if a != "" && isDigit(a[0]) {
+			return 1
+		}
+		if b != "" && isDigit(b[0]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+func charOrder(c byte) int {
+	switch {
+	case isDigit(c):
+		return 0
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// VulnDB 离线漏洞库
+type VulnDB struct {
+	Version         string          `json:"version"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+
+	// index type/name -> vulnerabilities
+	index map[string][]*Vulnerability
+}
+
+// Vulnerability 漏洞库中的一条记录
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Package  string `json:"package"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	// Affected 受影响的版本区间
+	Affected []AffectedRange `json:"affected"`
+	// Versions 明确受影响的版本
+	Versions []string `json:"versions"`
+}
+
+// AffectedRange 受影响的版本区间 [Introduced, Fixed) 或 [Introduced, LastAffected]
+type AffectedRange struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"lastAffected"`
+}
+
+// LoadDB 从文件加载漏洞库
+func LoadDB(path string) (*VulnDB, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read vulnerability db")
+	}
+	var db VulnDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, errors.Wrap(err, "failed to parse vulnerability db")
+	}
+	db.buildIndex()
+	return &db, nil
+}
+
+func (db *VulnDB) buildIndex() {
+	db.index = make(map[string][]*Vulnerability)
+	for i := range db.Vulnerabilities {
+		v := &db.Vulnerabilities[i]
+		v.Severity = normalizeSeverity(v.Severity)
+		key := v.Type + "/" + v.Package
+		db.index[key] = append(db.index[key], v)
+	}
+}
+
+// Match 匹配软件包的漏洞
+func (db *VulnDB) Match(pkg apistructs.SBOMPackage) []apistructs.ReleaseVulnerability {
+	candidates := db.index[pkg.Type+"/"+pkg.Name]
+	// 根据 jar 文件名推断的 maven 包只有 artifactId
+	if pkg.Type == apistructs.SBOMPackageTypeMaven && !strings.Contains(pkg.Name, ":") {
+		for key, vulns := range db.index {
+			if strings.HasPrefix(key, pkg.Type+"/") && strings.HasSuffix(key, ":"+pkg.Name) {
+				candidates = append(candidates, vulns...)
+			}
+		}
+	}
+
+	var result []apistructs.ReleaseVulnerability
+	for _, v := range candidates {
+		affected, fixed := v.affects(pkg)
+		if !affected {
+			continue
+		}
+		result = append(result, apistructs.ReleaseVulnerability{
+			ID:           v.ID,
+			Severity:     v.Severity,
+			Type:         pkg.Type,
+			Package:      pkg.Name,
+			Version:      pkg.Version,
+			FixedVersion: fixed,
+			Title:        v.Title,
+			URL:          v.URL,
+			Path:         pkg.Path,
+		})
+	}
+	return result
+}
+
+// affects 判断包版本是否受影响, 同时返回修复版本
+func (v *Vulnerability) affects(pkg apistructs.SBOMPackage) (bool, string) {
+	for _, ver := range v.Versions {
+		if compareVersion(pkg.Type, pkg.Version, ver) == 0 {
+			return true, ""
+		}
+	}
+	for _, r := range v.Affected {
+		if r.Introduced != "" && compareVersion(pkg.Type, pkg.Version, r.Introduced) < 0 {
+			continue
+		}
+		switch {
+		case r.Fixed != "":
+			if compareVersion(pkg.Type, pkg.Version, r.Fixed) < 0 {
+				return true, r.Fixed
+			}
+		case r.LastAffected != "":
+			if compareVersion(pkg.Type, pkg.Version, r.LastAffected) <= 0 {
+				return true, ""
+			}
+		default:
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+var severityRanks = map[string]int{
+	apistructs.VulnSeverityUnknown:  0,
+	apistructs.VulnSeverityLow:      1,
+	apistructs.VulnSeverityMedium:   2,
+	apistructs.VulnSeverityHigh:     3,
+	apistructs.VulnSeverityCritical: 4,
+}
+
+func normalizeSeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if _, ok := severityRanks[severity]; !ok {
+		return apistructs.VulnSeverityUnknown
+	}
+	return severity
+}
+
+// SeverityRank 漏洞等级排序值, 未知等级为 0
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToUpper(severity)]
+}
+
+// ValidSeverity 是否为合法的漏洞等级
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[strings.ToUpper(severity)]
+	return ok
+}
+
+// DBLoader 加载漏洞库, 文件修改后自动重新加载
+type DBLoader struct {
+	path string
+
+	mu      sync.Mutex
+	db      *VulnDB
+	modTime time.Time
+}
+
+// NewDBLoader 新建 DBLoader
+func NewDBLoader(path string) *DBLoader {
+	return &DBLoader{path: path}
+}
+
+// Get 获取漏洞库
+func (l *DBLoader) Get() (*VulnDB, error) {
+	if l.path == "" {
+		return nil, errors.New("vulnerability db path is not configured")
+	}
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat vulnerability db")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.db != nil && info.ModTime().Equal(l.modTime) {
+		return l.db, nil
+	}
+	db, err := LoadDB(l.path)
+	if err != nil {
+		return nil, err
+	}
+	l.db, l.modTime = db, info.ModTime()
+	return db, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scanner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestCompareVersion(t *testing.T) {
+	cases := []struct {
+		typ  string
+		a, b string
+		want int
+	}{
+		{"deb", "2.28-10", "2.28-10", 0},
+		{"deb", "2.28-10", "2.28-9", 1},
+		{"deb", "1:1.0", "2.0", 1},
+		{"deb", "1.0~rc1", "1.0", -1},
+		{"deb", "1.0.10", "1.0.9", 1},
+		{"apk", "1.1.24-r2", "1.1.24-r10", -1},
+		{"maven", "2.14.1", "2.15.0", -1},
+		{"go", "v0.3.7", "v0.3.10", -1},
+		{"go", "v1.0.0-rc.1", "v1.0.0", -1},
+		{"go", "v1.0.0+incompatible", "v1.0.0", 0},
+		{"npm", "4.17.20", "4.17.21", -1},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, compareVersion(c.typ, c.a, c.b), "%s %s %s", c.typ, c.a, c.b)
+	}
+}
+
+func TestVulnDBMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vulndb")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{
+  "version": "v1",
+  "vulnerabilities": [
+    {"id": "CVE-2021-44228", "type": "maven", "package": "org.apache.logging.log4j:log4j-core", "severity": "critical",
+     "affected": [{"introduced": "2.0", "fixed": "2.15.0"}]},
+    {"id": "CVE-2021-23337", "type": "npm", "package": "lodash", "severity": "HIGH",
+     "affected": [{"fixed": "4.17.21"}]},
+    {"id": "CVE-TEST", "type": "deb", "package": "libc6", "severity": "other", "versions": ["2.28-10"]}
+  ]
+}`), 0644))
+
+	db, err := NewDBLoader(path).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", db.Version)
+
+	vulns := db.Match(apistructs.SBOMPackage{Type: "maven", Name: "log4j-core", Version: "2.14.1"})
+	assert.Equal(t, 1, len(vulns))
+	assert.Equal(t, apistructs.VulnSeverityCritical, vulns[0].Severity)
+	assert.Equal(t, "2.15.0", vulns[0].FixedVersion)
+	assert.Empty(t, db.Match(apistructs.SBOMPackage{Type: "maven", Name: "org.apache.logging.log4j:log4j-core", Version: "2.15.0"}))
+	assert.Empty(t, db.Match(apistructs.SBOMPackage{Type: "maven", Name: "org.apache.logging.log4j:log4j-core", Version: "1.2"}))
+
+	assert.Equal(t, 1, len(db.Match(apistructs.SBOMPackage{Type: "npm", Name: "lodash", Version: "4.17.20"})))
+	assert.Empty(t, db.Match(apistructs.SBOMPackage{Type: "npm", Name: "lodash", Version: "4.17.21"}))
+
+	vulns = db.Match(apistructs.SBOMPackage{Type: "deb", Name: "libc6", Version: "2.28-10"})
+	assert.Equal(t, 1, len(vulns))
+	assert.Equal(t, apistructs.VulnSeverityUnknown, vulns[0].Severity)
+}
@@ -23,6 +23,16 @@ var (
 	ErrDeleteRelease                   = err("ErrDeleteRelease", "删除Release失败")
 	ErrGetRelease                      = err("ErrGetRelease", "获取Release失败")
 	ErrListRelease                     = err("ErrListRelease", "获取Release列表失败")
+	ErrGetReleaseGCPolicy              = err("ErrGetReleaseGCPolicy", "获取Release回收策略失败")
+	ErrUpdateReleaseGCPolicy           = err("ErrUpdateReleaseGCPolicy", "更新Release回收策略失败")
+	ErrReleaseGCDryRun                 = err("ErrReleaseGCDryRun", "Release回收预演失败")
+	ErrPromoteRelease                  = err("ErrPromoteRelease", "Release晋级失败")
+	ErrListReleasePromotion            = err("ErrListReleasePromotion", "获取Release晋级记录失败")
+	ErrListReleaseDeployment           = err("ErrListReleaseDeployment", "获取Release部署信息失败")
+	ErrApprovalStatusChanged           = err("ErrApprovalStatusChanged", "同步审批状态失败")
+	ErrScanRelease                     = err("ErrScanRelease", "Release镜像扫描失败")
+	ErrGetReleaseScanReport            = err("ErrGetReleaseScanReport", "获取Release扫描报告失败")
+	ErrCheckReleaseScanGate            = err("ErrCheckReleaseScanGate", "检查Release漏洞卡点失败")
 	ErrGetYAML                         = err("ErrGetYAML", "获取Dice YAML失败")
 	ErrGetIosPlist                     = err("ErrGetIosPlist", "获取Ios Plist文件失败")
 	ErrCreateImage                     = err("ErrCreateImage", "添加镜像失败")
@@ -39,6 +49,7 @@ var (
 	ErrCreatePipelineTemplateVersion   = err("ErrCreateTemplateVersion", "添加模板版本失败")
 	ErrQueryPipelineTemplateVersion    = err("ErrQueryTemplateVersion", "查询模板版本失败")
 	ErrRenderPipelineTemplate          = err("ErrQueryTemplateVersion", "模板渲染失败")
+	ErrQueryPipelineTemplateDependent  = err("ErrQueryTemplateDependent", "查询模板依赖方失败")
 	ErrQueryPublishItem                = err("ErrQueryPublishItem", "查询发布内容失败")
 	ErrCreatePublishItem               = err("ErrCreatePublishItem", "创建发布内容失败")
 	ErrGetPublishItem                  = err("ErrGetPublishItem", "获取发布内容详情失败")
@@ -0,0 +1,303 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/modules/dicehub/registry"
+)
+
+// gcDecision release 在回收策略下的评估结果
+type gcDecision struct {
+	Release dbclient.Release
+	Branch  string
+	Delete  bool
+	Reason  string
+}
+
+// GetGCPolicy 获取项目回收策略, 未配置时返回未启用的默认策略
+func (r *Release) GetGCPolicy(projectID uint64) (*apistructs.ReleaseGCPolicy, error) {
+	policy, err := r.db.GetReleaseGCPolicy(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &apistructs.ReleaseGCPolicy{ProjectID: projectID}, nil
+	}
+	result := convertGCPolicy(*policy)
+	return &result, nil
+}
+
+// UpdateGCPolicy 更新项目回收策略
+func (r *Release) UpdateGCPolicy(req *apistructs.ReleaseGCPolicyUpdateRequest, operator string) (*apistructs.ReleaseGCPolicy, error) {
+	if err := validateGCPolicy(req.Enabled, req.KeepLastPerBranch, req.KeepDeployedDays); err != nil {
+		return nil, err
+	}
+	policy, err := r.db.GetReleaseGCPolicy(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &dbclient.ReleaseGCPolicy{ProjectID: req.ProjectID}
+	}
+	policy.Enabled = req.Enabled
+	policy.KeepLastPerBranch = req.KeepLastPerBranch
+	policy.KeepDeployedDays = req.KeepDeployedDays
+	policy.Operator = operator
+	if err := r.db.SaveReleaseGCPolicy(policy); err != nil {
+		return nil, err
+	}
+	result := convertGCPolicy(*policy)
+	return &result, nil
+}
+
+// GCDryRun 按策略预演回收, 返回将被删除的 release 及可释放的空间, 不做任何删除
+func (r *Release) GCDryRun(policy apistructs.ReleaseGCPolicy, now time.Time) (*apistructs.ReleaseGCReport, error) {
+	if err := validateGCPolicy(false, policy.KeepLastPerBranch, policy.KeepDeployedDays); err != nil {
+		return nil, err
+	}
+	releases, err := r.db.GetReleasesByProject(int64(policy.ProjectID))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &apistructs.ReleaseGCReport{
+		ProjectID: policy.ProjectID,
+		Policy:    policy,
+		Total:     len(releases),
+		Releases:  []apistructs.ReleaseGCCandidate{},
+	}
+	// 没有保留条件的策略不能启用, 不会回收任何 release
+	if policy.KeepLastPerBranch == 0 && policy.KeepDeployedDays == 0 {
+		return report, nil
+	}
+	lastDeployed, err := r.getLastDeployedAt(releases)
+	if err != nil {
+		return nil, err
+	}
+	decisions := evaluateGCPolicy(policy, releases, lastDeployed, now)
+	candidates := make(map[string]struct{})
+	for _, d := range decisions {
+		if d.Delete {
+			candidates[d.Release.ReleaseID] = struct{}{}
+		}
+	}
+
+	// 同一镜像只统计一次; 待查询大小的镜像按集群分组
+	counted := make(map[string]struct{})
+	clusterImages := make(map[string][]string)
+	for _, d := range decisions {
+		if !d.Delete {
+			continue
+		}
+		candidate := apistructs.ReleaseGCCandidate{
+			ReleaseID:       d.Release.ReleaseID,
+			ReleaseName:     d.Release.ReleaseName,
+			ApplicationID:   d.Release.ApplicationID,
+			ApplicationName: d.Release.ApplicationName,
+			Branch:          d.Branch,
+			ClusterName:     d.Release.ClusterName,
+			CreatedAt:       d.Release.CreatedAt,
+			Reason:          d.Reason,
+			Images:          []apistructs.ReleaseGCImage{},
+		}
+		if deployedAt, ok := lastDeployed[d.Release.ReleaseID]; ok {
+			candidate.LastDeployedAt = &deployedAt
+		}
+		images, err := r.db.GetImagesByRelease(d.Release.ReleaseID)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			keep, err := r.keepManifest(d.Release, image.Image, candidates)
+			if err != nil {
+				return nil, err
+			}
+			candidate.Images = append(candidate.Images, apistructs.ReleaseGCImage{
+				Image:        image.Image,
+				Size:         -1,
+				KeepManifest: keep,
+			})
+			if _, ok := counted[image.Image]; keep || ok {
+				continue
+			}
+			counted[image.Image] = struct{}{}
+			clusterImages[d.Release.ClusterName] = append(clusterImages[d.Release.ClusterName], image.Image)
+		}
+		report.Releases = append(report.Releases, candidate)
+	}
+
+	sizes := make(map[string]int64)
+	for cluster, images := range clusterImages {
+		clusterSizes, err := registry.GetManifestsSize(cluster, images)
+		if err != nil {
+			logrus.Warnf("failed to get image size of cluster %s, (%v)", cluster, err)
+			continue
+		}
+		for image, size := range clusterSizes {
+			sizes[image] = size
+		}
+	}
+	for i := range report.Releases {
+		for j := range report.Releases[i].Images {
+			image := &report.Releases[i].Images[j]
+			if image.KeepManifest {
+				continue
+			}
+			size, ok := sizes[image.Image]
+			if !ok {
+				report.SizeUnknown = true
+				continue
+			}
+			image.Size = size
+		}
+	}
+	for image := range counted {
+		report.FreedBytes += sizes[image]
+	}
+
+	return report, nil
+}
+
+// keepManifest 判断删除 release 时是否保留镜像 manifest: 镜像被待回收之外的 release 引用或不在集群 registry 中
+func (r *Release) keepManifest(release dbclient.Release, image string, candidates map[string]struct{}) (bool, error) {
+	if release.ClusterName == "" || strings.HasPrefix(image, AliYunRegistry) {
+		return true, nil
+	}
+	releaseIDs, err := r.db.GetReleaseIDsByImage(image)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range releaseIDs {
+		if _, ok := candidates[id]; !ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// removeReleasesByPolicy 按项目回收策略删除 release
+func (r *Release) removeReleasesByPolicy(policy apistructs.ReleaseGCPolicy, now time.Time) error {
+	if err := validateGCPolicy(policy.Enabled, policy.KeepLastPerBranch, policy.KeepDeployedDays); err != nil {
+		return err
+	}
+	releases, err := r.db.GetReleasesByProject(int64(policy.ProjectID))
+	if err != nil {
+		return err
+	}
+	lastDeployed, err := r.getLastDeployedAt(releases)
+	if err != nil {
+		return err
+	}
+	for _, d := range evaluateGCPolicy(policy, releases, lastDeployed, now) {
+		if !d.Delete {
+			continue
+		}
+		logrus.Infof("recycle release %s of project %d: %s", d.Release.ReleaseID, policy.ProjectID, d.Reason)
+		r.recycle(d.Release)
+	}
+	return nil
+}
+
+// getLastDeployedAt 从 orchestrator 的部署记录中获取 release 最近一次成功部署的时间
+func (r *Release) getLastDeployedAt(releases []dbclient.Release) (map[string]time.Time, error) {
+	releaseIDs := make([]string, 0, len(releases))
+	for _, release := range releases {
+		releaseIDs = append(releaseIDs, release.ReleaseID)
+	}
+	return r.db.GetReleasesLastDeployedAt(releaseIDs)
+}
+
+// evaluateGCPolicy 按策略评估 release 是否可回收
+// 打标签或被引用的 release 始终保留; lastDeployed 为部署记录中的最近部署时间, 没有记录视为未部署过
+func evaluateGCPolicy(policy apistructs.ReleaseGCPolicy, releases []dbclient.Release, lastDeployed map[string]time.Time, now time.Time) []gcDecision {
+	sorted := make([]dbclient.Release, len(releases))
+	copy(sorted, releases)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	deployedAfter := now.AddDate(0, 0, -policy.KeepDeployedDays)
+	ranks := make(map[string]int)
+	decisions := make([]gcDecision, 0, len(sorted))
+	for _, release := range sorted {
+		branch := releaseBranch(release)
+		key := fmt.Sprintf("%d/%s", release.ApplicationID, branch)
+		rank := ranks[key]
+		ranks[key]++
+
+		d := gcDecision{Release: release, Branch: branch}
+		switch {
+		case release.Version != "":
+			d.Reason = "tagged with version " + release.Version
+		case release.Reference > 0:
+			d.Reason = fmt.Sprintf("referenced by %d deployments", release.Reference)
+		case policy.KeepDeployedDays > 0 && lastDeployed[release.ReleaseID].After(deployedAfter):
+			d.Reason = fmt.Sprintf("deployed within %d days", policy.KeepDeployedDays)
+		case policy.KeepLastPerBranch > 0 && rank < policy.KeepLastPerBranch:
+			d.Reason = fmt.Sprintf("within latest %d releases of branch", policy.KeepLastPerBranch)
+		default:
+			d.Delete = true
+			d.Reason = fmt.Sprintf("not within latest %d releases of branch and not deployed within %d days",
+				policy.KeepLastPerBranch, policy.KeepDeployedDays)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// releaseBranch 从 labels 中获取 release 所属分支
+func releaseBranch(release dbclient.Release) string {
+	if release.Labels == "" {
+		return ""
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(release.Labels), &labels); err != nil {
+		return ""
+	}
+	return labels["gitBranch"]
+}
+
+// validateGCPolicy 启用的策略至少需要一个保留条件, 避免误删全部 release
+func validateGCPolicy(enabled bool, keepLastPerBranch, keepDeployedDays int) error {
+	if keepLastPerBranch < 0 || keepDeployedDays < 0 {
+		return errors.Errorf("keepLastPerBranch and keepDeployedDays must not be negative")
+	}
+	if enabled && keepLastPerBranch == 0 && keepDeployedDays == 0 {
+		return errors.Errorf("at least one of keepLastPerBranch and keepDeployedDays must be positive")
+	}
+	return nil
+}
+
+func convertGCPolicy(policy dbclient.ReleaseGCPolicy) apistructs.ReleaseGCPolicy {
+	return apistructs.ReleaseGCPolicy{
+		ProjectID:         policy.ProjectID,
+		Enabled:           policy.Enabled,
+		KeepLastPerBranch: policy.KeepLastPerBranch,
+		KeepDeployedDays:  policy.KeepDeployedDays,
+		Operator:          policy.Operator,
+		CreatedAt:         policy.CreatedAt,
+		UpdatedAt:         policy.UpdatedAt,
+	}
+}
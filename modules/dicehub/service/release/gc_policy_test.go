@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+)
+
+func TestEvaluateGCPolicy(t *testing.T) {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	lastDeployed := make(map[string]time.Time)
+	newRelease := func(id, branch string, age, deployedAgo time.Duration) dbclient.Release {
+		lastDeployed[id] = now.Add(-deployedAgo)
+		return dbclient.Release{
+			ReleaseID:     id,
+			ApplicationID: 1,
+			Labels:        `{"gitBranch":"` + branch + `"}`,
+			CreatedAt:     now.Add(-age),
+			// 修改 release 会更新 UpdatedAt, 不代表部署
+			UpdatedAt: now,
+		}
+	}
+	tagged := newRelease("tagged", "master", 50*day, 50*day)
+	tagged.Version = "1.0"
+	referenced := newRelease("referenced", "master", 40*day, 40*day)
+	referenced.Reference = 1
+	releases := []dbclient.Release{
+		newRelease("m3", "master", 3*day, 3*day),
+		newRelease("m1", "master", 1*day, 1*day),
+		newRelease("m2", "master", 2*day, 2*day),
+		newRelease("old-deployed", "master", 30*day, 1*day),
+		newRelease("m-old", "master", 30*day, 30*day),
+		newRelease("d1", "develop", 20*day, 20*day),
+		tagged,
+		referenced,
+		{ReleaseID: "never-deployed", ApplicationID: 1, Labels: `{"gitBranch":"master"}`, CreatedAt: now.Add(-35 * day), UpdatedAt: now},
+	}
+
+	decisions := evaluateGCPolicy(apistructs.ReleaseGCPolicy{KeepLastPerBranch: 2, KeepDeployedDays: 7}, releases, lastDeployed, now)
+	deleted := make(map[string]bool)
+	for _, d := range decisions {
+		deleted[d.Release.ReleaseID] = d.Delete
+	}
+	assert.Equal(t, map[string]bool{
+		"m1":             false,
+		"m2":             false,
+		"m3":             false, // 3 天内部署过
+		"old-deployed":   false, // 1 天内部署过
+		"m-old":          true,
+		"d1":             false, // develop 分支最新的 release
+		"tagged":         false,
+		"referenced":     false,
+		"never-deployed": true,
+	}, deleted)
+
+	decisions = evaluateGCPolicy(apistructs.ReleaseGCPolicy{KeepLastPerBranch: 1}, releases, lastDeployed, now)
+	for _, d := range decisions {
+		deleted[d.Release.ReleaseID] = d.Delete
+	}
+	assert.True(t, deleted["m2"])
+	assert.True(t, deleted["old-deployed"])
+	assert.False(t, deleted["m1"])
+	assert.False(t, deleted["d1"])
+}
+
+func TestValidateGCPolicy(t *testing.T) {
+	assert.NoError(t, validateGCPolicy(false, 0, 0))
+	assert.Error(t, validateGCPolicy(true, 0, 0))
+	assert.Error(t, validateGCPolicy(true, -1, 3))
+	assert.NoError(t, validateGCPolicy(true, 5, 0))
+}
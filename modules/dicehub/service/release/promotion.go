@@ -0,0 +1,291 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package release
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+// Promote 晋级 release 至指定环境, 需审批时晋级记录为 PENDING, 审批通过后为 PROMOTED
+func (r *Release) Promote(orgID int64, req *apistructs.ReleasePromotionCreateRequest, operator string) (*apistructs.ReleasePromotion, error) {
+	release, err := r.db.GetRelease(req.ReleaseID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != 0 && release.OrgID != orgID {
+		return nil, errors.Errorf("release not found")
+	}
+	req.Workspace = strings.ToUpper(req.Workspace)
+	if err := validateOverlay(req.Overlay); err != nil {
+		return nil, err
+	}
+	promotions, err := r.db.ListReleasePromotions(req.ReleaseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPromotion(promotions, req.Workspace); err != nil {
+		return nil, err
+	}
+
+	promotion := &dbclient.ReleasePromotion{
+		ReleaseID:     release.ReleaseID,
+		OrgID:         release.OrgID,
+		ProjectID:     release.ProjectID,
+		ApplicationID: release.ApplicationID,
+		Workspace:     req.Workspace,
+		Status:        string(apistructs.ReleasePromotionStatusPromoted),
+		Overlay:       req.Overlay,
+		NeedApproval:  req.NeedApproval,
+		Desc:          req.Desc,
+		Operator:      operator,
+	}
+	if req.NeedApproval {
+		promotion.Status = string(apistructs.ReleasePromotionStatusPending)
+	}
+	if err := r.db.CreateReleasePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	if req.NeedApproval {
+		approve, err := r.bdl.CreateApprove(&apistructs.ApproveCreateRequest{
+			OrgID:      uint64(release.OrgID),
+			TargetID:   uint64(release.ApplicationID),
+			EntityID:   promotion.ID,
+			TargetName: release.ApplicationName,
+			Type:       apistructs.ApproveReleasePromotion,
+			Extra: map[string]string{
+				"releaseId": release.ReleaseID,
+				"workspace": req.Workspace,
+			},
+			Title: release.ApplicationName + " 版本 " + release.ReleaseName + " 晋级至 " + req.Workspace,
+			Desc:  req.Desc,
+		})
+		if err != nil {
+			promotion.Status = string(apistructs.ReleasePromotionStatusRejected)
+			if err := r.db.UpdateReleasePromotion(promotion); err != nil {
+				logrus.Errorf("failed to reject release promotion %d, (%v)", promotion.ID, err)
+			}
+			return nil, errors.Wrap(err, "failed to create approve")
+		}
+		promotion.ApprovalID = approve.ID
+		if err := r.db.UpdateReleasePromotion(promotion); err != nil {
+			return nil, err
+		}
+	}
+
+	result := convertPromotion(*promotion)
+	return &result, nil
+}
+
+// ListPromotions 获取 release 晋级记录
+func (r *Release) ListPromotions(orgID int64, releaseID string) ([]apistructs.ReleasePromotion, error) {
+	promotions, err := r.db.ListReleasePromotions(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]apistructs.ReleasePromotion, 0, len(promotions))
+	for _, v := range promotions {
+		if orgID != 0 && v.OrgID != orgID {
+			continue
+		}
+		result = append(result, convertPromotion(v))
+	}
+	return result, nil
+}
+
+// UpdatePromotionApproval 审批流状态变更时同步晋级状态
+func (r *Release) UpdatePromotionApproval(approvalID uint64, status apistructs.ApprovalStatus) error {
+	promotion, err := r.db.GetReleasePromotionByApprovalID(approvalID)
+	if err != nil {
+		return err
+	}
+	if promotion == nil {
+		return errors.Errorf("release promotion of approval %d not found", approvalID)
+	}
+	switch status {
+	case apistructs.ApprovalStatusApproved:
+		promotion.Status = string(apistructs.ReleasePromotionStatusPromoted)
+	case apistructs.ApprovalStatusDeined:
+		promotion.Status = string(apistructs.ReleasePromotionStatusRejected)
+	default:
+		return nil
+	}
+	return r.db.UpdateReleasePromotion(promotion)
+}
+
+// ListDeployments 获取 release 当前部署的 runtime
+func (r *Release) ListDeployments(orgID int64, releaseID string) ([]apistructs.ReleaseDeployment, error) {
+	release, err := r.db.GetRelease(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != 0 && release.OrgID != orgID {
+		return nil, errors.Errorf("release not found")
+	}
+	deployments, err := r.db.ListActiveReleaseDeployments(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]apistructs.ReleaseDeployment, 0, len(deployments))
+	for _, v := range deployments {
+		result = append(result, apistructs.ReleaseDeployment{
+			ReleaseID:    v.ReleaseID,
+			RuntimeID:    v.RuntimeID,
+			DeploymentID: v.DeploymentID,
+			Workspace:    v.Workspace,
+			DeployedAt:   v.DeployedAt,
+		})
+	}
+	return result, nil
+}
+
+// recordDeployment 根据引用变更记录 release 部署在哪些 runtime 上
+func (r *Release) recordDeployment(releaseID string, req *apistructs.ReleaseReferenceUpdateRequest) error {
+	if req.RuntimeID == 0 {
+		return nil
+	}
+	deployment, err := r.db.GetReleaseDeployment(releaseID, req.RuntimeID)
+	if err != nil {
+		return err
+	}
+	if req.Increase {
+		if deployment == nil {
+			deployment = &dbclient.ReleaseDeployment{ReleaseID: releaseID, RuntimeID: req.RuntimeID}
+		}
+		deployment.DeploymentID = req.DeploymentID
+		deployment.Workspace = req.Workspace
+		deployment.Active = true
+		deployment.DeployedAt = time.Now()
+		return r.db.SaveReleaseDeployment(deployment)
+	}
+	// 同一 runtime 重复部署同一 release 时，旧部署过期不影响新部署
+	if deployment == nil || (req.DeploymentID != 0 && deployment.DeploymentID != req.DeploymentID) {
+		return nil
+	}
+	deployment.Active = false
+	return r.db.SaveReleaseDeployment(deployment)
+}
+
+// getPromotionOverlay 获取 release 在指定环境最近一次晋级的覆盖配置
+func (r *Release) getPromotionOverlay(releaseID, workspace string) (string, error) {
+	promotions, err := r.db.ListReleasePromotions(releaseID)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range promotions {
+		if v.Workspace == strings.ToUpper(workspace) && v.Status == string(apistructs.ReleasePromotionStatusPromoted) {
+			return v.Overlay, nil
+		}
+	}
+	return "", nil
+}
+
+// checkPromotion 校验晋级顺序: 目标环境须在晋级顺序中, 且已晋级至前一环境; 同一环境不能有待审批的晋级
+func checkPromotion(promotions []dbclient.ReleasePromotion, workspace string) error {
+	index := -1
+	for i, ws := range apistructs.ReleasePromotionWorkspaces {
+		if string(ws) == workspace {
+			index = i
+		}
+	}
+	if index < 0 {
+		return errors.Errorf("invalid workspace: %s", workspace)
+	}
+	for _, v := range promotions {
+		if v.Workspace == workspace && v.Status == string(apistructs.ReleasePromotionStatusPending) {
+			return errors.Errorf("promotion to %s is pending approval", workspace)
+		}
+	}
+	if index == 0 {
+		return nil
+	}
+	prev := string(apistructs.ReleasePromotionWorkspaces[index-1])
+	if !isPromoted(promotions, prev) {
+		return errors.Errorf("release must be promoted to %s before %s", prev, workspace)
+	}
+	return nil
+}
+
+func isPromoted(promotions []dbclient.ReleasePromotion, workspace string) bool {
+	for _, v := range promotions {
+		if v.Workspace == workspace && v.Status == string(apistructs.ReleasePromotionStatusPromoted) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOverlay 校验覆盖配置, 格式同 dice.yml environments 下的单个环境
+func validateOverlay(overlay string) error {
+	if overlay == "" {
+		return nil
+	}
+	var envObj diceyml.EnvObject
+	if err := yaml.Unmarshal([]byte(overlay), &envObj); err != nil {
+		return errors.Wrap(err, "invalid overlay")
+	}
+	return nil
+}
+
+// applyOverlay 合并环境配置后再合并覆盖配置, 返回的 dice.yml 不再包含 environments
+func applyOverlay(dice, workspace, overlay string) (string, error) {
+	d, err := diceyml.New([]byte(dice), false)
+	if err != nil {
+		return "", err
+	}
+	if err := d.MergeEnv(workspace); err != nil {
+		return "", err
+	}
+	var envObj diceyml.EnvObject
+	if err := yaml.Unmarshal([]byte(overlay), &envObj); err != nil {
+		return "", errors.Wrap(err, "invalid overlay")
+	}
+	obj := d.Obj()
+	obj.Environments = diceyml.EnvObjects{"overlay": &envObj}
+	diceyml.MergeEnv(obj, "overlay")
+	obj.Environments = nil
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func convertPromotion(promotion dbclient.ReleasePromotion) apistructs.ReleasePromotion {
+	return apistructs.ReleasePromotion{
+		ID:            promotion.ID,
+		ReleaseID:     promotion.ReleaseID,
+		OrgID:         promotion.OrgID,
+		ProjectID:     promotion.ProjectID,
+		ApplicationID: promotion.ApplicationID,
+		Workspace:     promotion.Workspace,
+		Status:        apistructs.ReleasePromotionStatus(promotion.Status),
+		Overlay:       promotion.Overlay,
+		NeedApproval:  promotion.NeedApproval,
+		ApprovalID:    promotion.ApprovalID,
+		Desc:          promotion.Desc,
+		Operator:      promotion.Operator,
+		CreatedAt:     promotion.CreatedAt,
+		UpdatedAt:     promotion.UpdatedAt,
+	}
+}
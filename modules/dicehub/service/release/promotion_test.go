@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func TestCheckPromotion(t *testing.T) {
+	promoted := string(apistructs.ReleasePromotionStatusPromoted)
+	pending := string(apistructs.ReleasePromotionStatusPending)
+	rejected := string(apistructs.ReleasePromotionStatusRejected)
+
+	assert.NoError(t, checkPromotion(nil, "DEV"))
+	assert.Error(t, checkPromotion(nil, "TEST"))
+	assert.Error(t, checkPromotion(nil, "UNKNOWN"))
+
+	promotions := []dbclient.ReleasePromotion{
+		{Workspace: "DEV", Status: promoted},
+		{Workspace: "TEST", Status: promoted},
+		{Workspace: "STAGING", Status: rejected},
+	}
+	assert.NoError(t, checkPromotion(promotions, "STAGING"))
+	assert.Error(t, checkPromotion(promotions, "PROD"))
+
+	promotions = append(promotions, dbclient.ReleasePromotion{Workspace: "STAGING", Status: pending})
+	assert.Error(t, checkPromotion(promotions, "STAGING"))
+
+	promotions = append(promotions, dbclient.ReleasePromotion{Workspace: "STAGING", Status: promoted})
+	assert.NoError(t, checkPromotion(promotions, "PROD"))
+}
+
+func TestApplyOverlay(t *testing.T) {
+	dice := `version: 2.0
+envs:
+  A: a
+services:
+  web:
+    image: nginx
+    deployments:
+      replicas: 1
+    resources:
+      cpu: 0.5
+      mem: 512
+environments:
+  production:
+    envs:
+      B: b
+    services:
+      web:
+        deployments:
+          replicas: 2
+`
+	overlay := `envs:
+  A: overlay
+services:
+  web:
+    resources:
+      cpu: 2
+      mem: 1024
+`
+	assert.NoError(t, validateOverlay(overlay))
+	assert.Error(t, validateOverlay("envs: [a"))
+
+	result, err := applyOverlay(dice, "PROD", overlay)
+	assert.NoError(t, err)
+	d, err := diceyml.New([]byte(result), false)
+	assert.NoError(t, err)
+	obj := d.Obj()
+	assert.Equal(t, "overlay", obj.Envs["A"])
+	assert.Equal(t, "b", obj.Envs["B"])
+	assert.Equal(t, 2, obj.Services["web"].Deployments.Replicas)
+	assert.Equal(t, 2.0, obj.Services["web"].Resources.CPU)
+	assert.Equal(t, 1024, obj.Services["web"].Resources.Mem)
+	assert.Nil(t, obj.Environments)
+}
@@ -184,6 +184,9 @@ func (r *Release) UpdateReference(orgID int64, releaseID string, req *apistructs
 	if err := r.db.UpdateRelease(release); err != nil {
 		return err
 	}
+	if err := r.recordDeployment(releaseID, req); err != nil {
+		logrus.Warnf("failed to record deployment of release %s, (%v)", releaseID, err)
+	}
 
 	return nil
 }
@@ -231,6 +234,9 @@ func (r *Release) Delete(orgID int64, releaseID string) error {
 	if err := r.db.DeleteRelease(releaseID); err != nil {
 		return err
 	}
+	if err := r.db.DeleteReleaseScan(releaseID); err != nil {
+		logrus.Errorf("[alert] delete release scan: %s fail, err: %v", releaseID, err)
+	}
 
 	// send release delete event to eventbox
 	event.SendReleaseEvent(event.ReleaseEventDelete, release)
@@ -286,7 +292,8 @@ func (r *Release) List(orgID int64, req *apistructs.ReleaseListRequest) (*apistr
 }
 
 // GetDiceYAML 获取dice.yml内容
-func (r *Release) GetDiceYAML(orgID int64, releaseID string) (string, error) {
+// 指定 workspace 且该环境晋级时配置了覆盖配置时，返回合并覆盖配置后的 dice.yml
+func (r *Release) GetDiceYAML(orgID int64, releaseID string, workspace ...string) (string, error) {
 	release, err := r.db.GetRelease(releaseID)
 	if err != nil {
 		return "", err
@@ -294,8 +301,18 @@ func (r *Release) GetDiceYAML(orgID int64, releaseID string) (string, error) {
 	if orgID != 0 && release.OrgID != orgID { // 内部调用时，orgID为0
 		return "", errors.Errorf("release not found")
 	}
+	if len(workspace) == 0 || workspace[0] == "" {
+		return release.Dice, nil
+	}
 
-	return release.Dice, nil
+	overlay, err := r.getPromotionOverlay(releaseID, workspace[0])
+	if err != nil {
+		return "", err
+	}
+	if overlay == "" {
+		return release.Dice, nil
+	}
+	return applyOverlay(release.Dice, workspace[0], overlay)
 }
 
 // GetIosPlist 读取ios类型release中下载地址plist
@@ -389,6 +406,7 @@ func (r *Release) GetLatestReleasesByProjectAndVersion(projectID int64, version
 }
 
 // RemoveDeprecatedsReleases 回收过期release具体逻辑
+// 配置了回收策略的项目按策略回收, 其余项目回收超过保留时间且未被引用的 release
 func (r *Release) RemoveDeprecatedsReleases(now time.Time) error {
 	d, err := time.ParseDuration(strutil.Concat("-", conf.MaxTimeReserved(), "h")) // one month before, eg: -720h
 	if err != nil {
@@ -396,6 +414,15 @@ func (r *Release) RemoveDeprecatedsReleases(now time.Time) error {
 	}
 	before := now.Add(d)
 
+	policies, err := r.db.ListEnabledReleaseGCPolicies()
+	if err != nil {
+		return err
+	}
+	policyProjects := make(map[int64]struct{}, len(policies))
+	for _, policy := range policies {
+		policyProjects[int64(policy.ProjectID)] = struct{}{}
+	}
+
 	releases, err := r.db.GetUnReferedReleasesBefore(before)
 	if err != nil {
 		return err
@@ -406,48 +433,64 @@ func (r *Release) RemoveDeprecatedsReleases(now time.Time) error {
 			logrus.Debugf("release %s have been tagged, can't be recycled", release.ReleaseID)
 			continue
 		}
+		if _, ok := policyProjects[release.ProjectID]; ok {
+			continue
+		}
+		r.recycle(release)
+	}
+
+	for _, policy := range policies {
+		if err := r.removeReleasesByPolicy(convertGCPolicy(policy), now); err != nil {
+			logrus.Warnf("remove releases by gc policy of project %d error: %v", policy.ProjectID, err)
+		}
+	}
+	return nil
+}
+
+// recycle 删除 release 及其镜像, 镜像 manifest 删除失败时保留 release
+func (r *Release) recycle(release dbclient.Release) {
+	images, err := r.db.GetImagesByRelease(release.ReleaseID)
+	if err != nil {
+		logrus.Warnf(err.Error())
+		return
+	}
 
-		images, err := r.db.GetImagesByRelease(release.ReleaseID)
+	deletable := true // 若release下的image manifest删除失败，release不可删除
+	for _, image := range images {
+		// 若有其他release引用此镜像，镜像manifest不可删，只删除DB元信息(多次构建，存在镜像相同的情况)
+		count, err := r.db.GetImageCount(release.ReleaseID, image.Image)
 		if err != nil {
-			logrus.Warnf(err.Error())
+			logrus.Errorf(err.Error())
 			continue
 		}
-
-		deletable := true // 若release下的image manifest删除失败，release不可删除
-		for _, image := range images {
-			// 若有其他release引用此镜像，镜像manifest不可删，只删除DB元信息(多次构建，存在镜像相同的情况)
-			count, err := r.db.GetImageCount(release.ReleaseID, image.Image)
-			if err != nil {
+		if count == 0 && release.ClusterName != "" && !strings.HasPrefix(image.Image, AliYunRegistry) {
+			if err := registry.DeleteManifests(release.ClusterName, []string{image.Image}); err != nil {
+				deletable = false
 				logrus.Errorf(err.Error())
 				continue
 			}
-			if count == 0 && release.ClusterName != "" && !strings.HasPrefix(image.Image, AliYunRegistry) {
-				if err := registry.DeleteManifests(release.ClusterName, []string{image.Image}); err != nil {
-					deletable = false
-					logrus.Errorf(err.Error())
-					continue
-				}
-			}
-
-			// Delete image info
-			if err := r.db.DeleteImage(int64(image.ID)); err != nil {
-				logrus.Errorf("[alert] delete image: %s fail, err: %v", image.Image, err)
-			}
-			logrus.Infof("deleted image: %s", image.Image)
 		}
 
-		if deletable {
-			// Delete release info
-			if err := r.db.DeleteRelease(release.ReleaseID); err != nil {
-				logrus.Errorf("[alert] delete release: %s fail, err: %v", release.ReleaseID, err)
-			}
-			logrus.Infof("deleted release: %s", release.ReleaseID)
+		// Delete image info
+		if err := r.db.DeleteImage(int64(image.ID)); err != nil {
+			logrus.Errorf("[alert] delete image: %s fail, err: %v", image.Image, err)
+		}
+		logrus.Infof("deleted image: %s", image.Image)
+	}
 
-			// Send release delete event to eventbox
-			event.SendReleaseEvent(event.ReleaseEventDelete, &release)
+	if deletable {
+		// Delete release info
+		if err := r.db.DeleteRelease(release.ReleaseID); err != nil {
+			logrus.Errorf("[alert] delete release: %s fail, err: %v", release.ReleaseID, err)
+		}
+		if err := r.db.DeleteReleaseScan(release.ReleaseID); err != nil {
+			logrus.Errorf("[alert] delete release scan: %s fail, err: %v", release.ReleaseID, err)
 		}
+		logrus.Infof("deleted release: %s", release.ReleaseID)
+
+		// Send release delete event to eventbox
+		event.SendReleaseEvent(event.ReleaseEventDelete, &release)
 	}
-	return nil
 }
 
 // Convert 从ReleaseRequest中提取Release元信息
@@ -0,0 +1,352 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package scan release 镜像漏洞扫描
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/modules/dicehub/scanner"
+)
+
+// defaultScanTimeout 扫描中状态的超时时间, 超时后认为扫描已中断, 允许重新触发
+const defaultScanTimeout = time.Hour
+
+// Scan release 镜像扫描操作封装
+type Scan struct {
+	db           *dbclient.DBClient
+	scanner      *scanner.Scanner
+	severityGate string
+	enforce      bool
+	timeout      time.Duration
+	sem          chan struct{}
+}
+
+// Option 定义 Scan 对象的配置选项
+type Option func(*Scan)
+
+// New 新建 Scan 实例
+func New(options ...Option) *Scan {
+	s := &Scan{severityGate: apistructs.VulnSeverityCritical, timeout: defaultScanTimeout}
+	for _, op := range options {
+		op(s)
+	}
+	if s.sem == nil {
+		s.sem = make(chan struct{}, 1)
+	}
+	return s
+}
+
+// WithDBClient 配置 db client
+func WithDBClient(db *dbclient.DBClient) Option {
+	return func(s *Scan) {
+		s.db = db
+	}
+}
+
+// WithScanner 配置镜像扫描器
+func WithScanner(sc *scanner.Scanner) Option {
+	return func(s *Scan) {
+		s.scanner = sc
+	}
+}
+
+// WithSeverityGate 配置默认的漏洞卡点等级
+func WithSeverityGate(severity string) Option {
+	return func(s *Scan) {
+		if severity != "" {
+			s.severityGate = strings.ToUpper(severity)
+		}
+	}
+}
+
+// WithGateEnforce 配置是否开启漏洞卡点, 未开启时卡点只给出提示, 不阻止部署
+func WithGateEnforce(enforce bool) Option {
+	return func(s *Scan) {
+		s.enforce = enforce
+	}
+}
+
+// WithMaxConcurrent 配置同时扫描的 release 数
+func WithMaxConcurrent(n int) Option {
+	return func(s *Scan) {
+		if n > 0 {
+			s.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithScanTimeout 配置扫描超时时间
+func WithScanTimeout(timeout time.Duration) Option {
+	return func(s *Scan) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// Enabled 是否配置了漏洞库, 未配置时不扫描
+func (s *Scan) Enabled() bool {
+	return s.scanner != nil && s.scanner.Enabled()
+}
+
+// Trigger 触发 release 扫描, 异步执行, 已在扫描中且未超时时直接返回当前状态
+func (s *Scan) Trigger(orgID int64, releaseID string) (*apistructs.ReleaseScanReport, error) {
+	if _, err := s.getRelease(orgID, releaseID); err != nil {
+		return nil, err
+	}
+	dbVersion, err := s.scanner.DBVersion()
+	if err != nil {
+		return nil, err
+	}
+	images, err := s.db.GetImagesByRelease(releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.db.GetReleaseScan(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		record = &dbclient.ReleaseScan{ReleaseID: releaseID}
+	} else if record.Status == string(apistructs.ReleaseScanStatusRunning) && !s.isStale(record, time.Now()) {
+		return convert(record, false)
+	}
+	record.Status = string(apistructs.ReleaseScanStatusRunning)
+	record.Summary, record.Report, record.Error = "", "", ""
+	record.DBVersion = dbVersion
+	record.StartedAt = time.Now()
+	record.FinishedAt = nil
+	if err := s.db.SaveReleaseScan(record); err != nil {
+		return nil, err
+	}
+
+	imageNames := make([]string, 0, len(images))
+	for _, v := range images {
+		imageNames = append(imageNames, v.Image)
+	}
+	go s.run(*record, imageNames)
+
+	return convert(record, false)
+}
+
+func (s *Scan) run(record dbclient.ReleaseScan, images []string) {
+	s.sem <- struct{}{}
+	defer func() { <-s.sem }()
+
+	results := make([]apistructs.ReleaseImageScan, 0, len(images))
+	var failed []string
+	for _, image := range images {
+		result, err := s.scanner.ScanImage(image)
+		if err != nil {
+			logrus.Errorf("failed to scan image %s of release %s, err: %v", image, record.ReleaseID, err)
+			failed = append(failed, image)
+			results = append(results, apistructs.ReleaseImageScan{Image: image, Error: err.Error()})
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	record.Status = string(apistructs.ReleaseScanStatusSuccess)
+	if len(failed) > 0 {
+		record.Status = string(apistructs.ReleaseScanStatusFailed)
+		record.Error = fmt.Sprintf("failed to scan images: %s", strings.Join(failed, ", "))
+	}
+	summary, _ := json.Marshal(summarize(results))
+	report, _ := json.Marshal(results)
+	record.Summary, record.Report = string(summary), string(report)
+	now := time.Now()
+	record.FinishedAt = &now
+
+	// 超时后被重新触发的扫描, 不覆盖新的扫描记录
+	current, err := s.db.GetReleaseScan(record.ReleaseID)
+	if err != nil {
+		logrus.Errorf("failed to get scan record of release %s, err: %v", record.ReleaseID, err)
+		return
+	}
+	if current == nil || current.StartedAt.Unix() != record.StartedAt.Unix() {
+		logrus.Warnf("scan of release %s started at %s is superseded, skip saving result", record.ReleaseID, record.StartedAt)
+		return
+	}
+	if err := s.db.SaveReleaseScan(&record); err != nil {
+		logrus.Errorf("failed to save scan result of release %s, err: %v", record.ReleaseID, err)
+	}
+}
+
+// isStale 扫描中的记录是否已超时, 服务重启或崩溃时扫描中的记录不会再被更新
+func (s *Scan) isStale(record *dbclient.ReleaseScan, now time.Time) bool {
+	return now.Sub(record.StartedAt) > s.timeout
+}
+
+// Get 获取 release 扫描报告, 未扫描时返回 nil
+func (s *Scan) Get(orgID int64, releaseID string, withSBOM bool) (*apistructs.ReleaseScanReport, error) {
+	if _, err := s.getRelease(orgID, releaseID); err != nil {
+		return nil, err
+	}
+	record, err := s.db.GetReleaseScan(releaseID)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return convert(record, withSBOM)
+}
+
+// CheckGate 检查 release 是否存在 severity 及以上等级的漏洞, severity 为空时使用默认配置
+// 卡点开启且 workspace 为 PROD 时, 未扫描、扫描中或扫描失败的 release 均不通过; 未配置漏洞库时直接放行
+func (s *Scan) CheckGate(orgID int64, releaseID, severity, workspace string) (*apistructs.ReleaseScanGateResult, error) {
+	if severity == "" {
+		severity = s.severityGate
+	}
+	severity = strings.ToUpper(severity)
+	if !scanner.ValidSeverity(severity) {
+		return nil, errors.Errorf("invalid severity: %s", severity)
+	}
+	if !s.Enabled() {
+		if _, err := s.getRelease(orgID, releaseID); err != nil {
+			return nil, err
+		}
+		return &apistructs.ReleaseScanGateResult{
+			ReleaseID: releaseID,
+			Severity:  severity,
+			Workspace: strings.ToUpper(workspace),
+			Passed:    true,
+			Message:   "vulnerability db is not configured, skip vulnerability gate",
+		}, nil
+	}
+	report, err := s.Get(orgID, releaseID, false)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateGate(releaseID, severity, workspace, s.enforce, report), nil
+}
+
+// evaluateGate 根据扫描报告判断是否通过卡点
+// 未扫描、扫描中或扫描失败时, PROD 环境不通过, 其他环境放行并给出提示
+// 卡点未开启 (enforce=false) 时始终放行, 只在 message 中给出结果
+func evaluateGate(releaseID, severity, workspace string, enforce bool, report *apistructs.ReleaseScanReport) *apistructs.ReleaseScanGateResult {
+	result := checkReport(releaseID, severity, workspace, report)
+	result.Enforced = enforce
+	if !enforce && !result.Passed {
+		result.Passed = true
+		result.Message = "vulnerability gate is not enforced, " + result.Message
+	}
+	return result
+}
+
+func checkReport(releaseID, severity, workspace string, report *apistructs.ReleaseScanReport) *apistructs.ReleaseScanGateResult {
+	failClosed := strings.EqualFold(workspace, string(apistructs.ProdWorkspace))
+	result := &apistructs.ReleaseScanGateResult{
+		ReleaseID: releaseID,
+		Severity:  severity,
+		Workspace: strings.ToUpper(workspace),
+		Passed:    true,
+	}
+	if report == nil {
+		result.Passed = !failClosed
+		result.Message = "release has not been scanned"
+		return result
+	}
+	result.Status = report.Status
+	result.Summary = report.Summary
+	if report.Status == apistructs.ReleaseScanStatusRunning {
+		result.Passed = !failClosed
+		result.Message = "release scan is running"
+		return result
+	}
+
+	var blocked []string
+	for _, level := range []string{apistructs.VulnSeverityCritical, apistructs.VulnSeverityHigh,
+		apistructs.VulnSeverityMedium, apistructs.VulnSeverityLow, apistructs.VulnSeverityUnknown} {
+		if scanner.SeverityRank(level) < scanner.SeverityRank(severity) {
+			break
+		}
+		if n := report.Summary[level]; n > 0 {
+			blocked = append(blocked, fmt.Sprintf("%s: %d", level, n))
+		}
+	}
+	if len(blocked) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("release has vulnerabilities at or above %s (%s)", severity, strings.Join(blocked, ", "))
+		return result
+	}
+	if report.Status == apistructs.ReleaseScanStatusFailed {
+		result.Passed = !failClosed
+		result.Message = fmt.Sprintf("release scan failed: %s", report.Error)
+	}
+	return result
+}
+
+// summarize 统计各等级漏洞数
+func summarize(results []apistructs.ReleaseImageScan) map[string]int {
+	summary := map[string]int{
+		apistructs.VulnSeverityCritical: 0,
+		apistructs.VulnSeverityHigh:     0,
+		apistructs.VulnSeverityMedium:   0,
+		apistructs.VulnSeverityLow:      0,
+		apistructs.VulnSeverityUnknown:  0,
+	}
+	for _, result := range results {
+		for _, v := range result.Vulnerabilities {
+			summary[v.Severity]++
+		}
+	}
+	return summary
+}
+
+func (s *Scan) getRelease(orgID int64, releaseID string) (*dbclient.Release, error) {
+	release, err := s.db.GetRelease(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID != 0 && release.OrgID != orgID {
+		return nil, errors.Errorf("release not found")
+	}
+	return release, nil
+}
+
+func convert(record *dbclient.ReleaseScan, withSBOM bool) (*apistructs.ReleaseScanReport, error) {
+	report := &apistructs.ReleaseScanReport{
+		ReleaseID:  record.ReleaseID,
+		Status:     apistructs.ReleaseScanStatus(record.Status),
+		Images:     []apistructs.ReleaseImageScan{},
+		DBVersion:  record.DBVersion,
+		Error:      record.Error,
+		StartedAt:  record.StartedAt,
+		FinishedAt: record.FinishedAt,
+	}
+	if record.Summary != "" {
+		if err := json.Unmarshal([]byte(record.Summary), &report.Summary); err != nil {
+			return nil, errors.Wrap(err, "failed to parse scan summary")
+		}
+	}
+	if record.Report != "" {
+		if err := json.Unmarshal([]byte(record.Report), &report.Images); err != nil {
+			return nil, errors.Wrap(err, "failed to parse scan report")
+		}
+	}
+	if !withSBOM {
+		for i := range report.Images {
+			report.Images[i].Packages = nil
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/modules/dicehub/scanner"
+)
+
+func TestEvaluateGate(t *testing.T) {
+	result := evaluateGate("r1", "HIGH", "DEV", true, nil)
+	assert.True(t, result.Passed)
+	assert.Equal(t, "release has not been scanned", result.Message)
+
+	report := &apistructs.ReleaseScanReport{
+		Status:  apistructs.ReleaseScanStatusSuccess,
+		Summary: map[string]int{"CRITICAL": 0, "HIGH": 2, "MEDIUM": 5},
+	}
+	assert.True(t, evaluateGate("r1", "CRITICAL", "DEV", true, report).Passed)
+	result = evaluateGate("r1", "MEDIUM", "DEV", true, report)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "release has vulnerabilities at or above MEDIUM (HIGH: 2, MEDIUM: 5)", result.Message)
+
+	report.Status = apistructs.ReleaseScanStatusRunning
+	assert.True(t, evaluateGate("r1", "LOW", "DEV", true, report).Passed)
+
+	report.Status = apistructs.ReleaseScanStatusFailed
+	assert.False(t, evaluateGate("r1", "HIGH", "DEV", true, report).Passed)
+}
+
+func TestEvaluateGateFailClosedInProd(t *testing.T) {
+	result := evaluateGate("r1", "HIGH", "PROD", true, nil)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "PROD", result.Workspace)
+
+	report := &apistructs.ReleaseScanReport{
+		Status:  apistructs.ReleaseScanStatusRunning,
+		Summary: map[string]int{"CRITICAL": 0, "HIGH": 0},
+	}
+	assert.True(t, evaluateGate("r1", "HIGH", "STAGING", true, report).Passed)
+	assert.False(t, evaluateGate("r1", "HIGH", "prod", true, report).Passed)
+
+	report.Status = apistructs.ReleaseScanStatusFailed
+	report.Error = "failed to scan images: app"
+	assert.True(t, evaluateGate("r1", "HIGH", "TEST", true, report).Passed)
+	result = evaluateGate("r1", "HIGH", "PROD", true, report)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "release scan failed: failed to scan images: app", result.Message)
+
+	report.Status = apistructs.ReleaseScanStatusSuccess
+	assert.True(t, evaluateGate("r1", "HIGH", "PROD", true, report).Passed)
+}
+
+func TestEvaluateGateNotEnforced(t *testing.T) {
+	result := evaluateGate("r1", "HIGH", "PROD", false, nil)
+	assert.True(t, result.Passed)
+	assert.False(t, result.Enforced)
+	assert.Equal(t, "vulnerability gate is not enforced, release has not been scanned", result.Message)
+
+	report := &apistructs.ReleaseScanReport{
+		Status:  apistructs.ReleaseScanStatusSuccess,
+		Summary: map[string]int{"CRITICAL": 1},
+	}
+	assert.True(t, evaluateGate("r1", "HIGH", "PROD", false, report).Passed)
+	result = evaluateGate("r1", "HIGH", "PROD", true, report)
+	assert.False(t, result.Passed)
+	assert.True(t, result.Enforced)
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, New().Enabled())
+	assert.False(t, New(WithScanner(scanner.New(nil, scanner.NewDBLoader("")))).Enabled())
+	assert.True(t, New(WithScanner(scanner.New(nil, scanner.NewDBLoader("/vulndb.json")))).Enabled())
+}
+
+func TestIsStale(t *testing.T) {
+	s := New(WithScanTimeout(30 * time.Minute))
+	now := time.Now()
+	assert.False(t, s.isStale(&dbclient.ReleaseScan{StartedAt: now.Add(-10 * time.Minute)}, now))
+	assert.True(t, s.isStale(&dbclient.ReleaseScan{StartedAt: now.Add(-time.Hour)}, now))
+	assert.Equal(t, defaultScanTimeout, New().timeout)
+}
+
+func TestSummarize(t *testing.T) {
+	summary := summarize([]apistructs.ReleaseImageScan{
+		{Vulnerabilities: []apistructs.ReleaseVulnerability{{Severity: "HIGH"}, {Severity: "LOW"}}},
+		{Vulnerabilities: []apistructs.ReleaseVulnerability{{Severity: "HIGH"}}},
+	})
+	assert.Equal(t, 2, summary["HIGH"])
+	assert.Equal(t, 1, summary["LOW"])
+	assert.Equal(t, 0, summary["CRITICAL"])
+}
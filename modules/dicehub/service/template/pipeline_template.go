@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Masterminds/semver"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 
@@ -145,6 +146,7 @@ func (p *PipelineTemplate) Create(request *apistructs.PipelineTemplateCreateRequ
 		return nil, err
 	}
 
+	newVersionPublished := dbPipelineTemplate != nil && dbPipelineTemplateVersion == nil
 	if dbPipelineTemplateVersion != nil {
 		dbPipelineTemplateVersion.Spec = request.Spec
 		dbPipelineTemplateVersion.Readme = request.Readme
@@ -168,7 +170,17 @@ func (p *PipelineTemplate) Create(request *apistructs.PipelineTemplateCreateRequ
 	data := saveTemplate.ToApiData()
 	data.Version = request.Version
 	tx.Commit()
-	return data, err
+
+	// 发布新版本时列出依赖方，便于评估影响范围
+	if newVersionPublished {
+		dependents, err := p.listPipelineTemplateDependents(saveTemplate.ID, request.Version)
+		if err != nil {
+			logrus.Errorf("failed to list pipeline template dependents, template: %s, err: %v", saveTemplate.Name, err)
+		} else {
+			data.Dependents = dependents
+		}
+	}
+	return data, nil
 }
 
 func (p *PipelineTemplate) QueryPipelineTemplates(request *apistructs.PipelineTemplateQueryRequest) ([]*apistructs.PipelineTemplate, int, error) {
@@ -218,9 +230,9 @@ func (p *PipelineTemplate) GetPipelineTemplateVersion(request *apistructs.Pipeli
 		return nil, nil
 	}
 
-	dbVersion, err := p.db.GetPipelineTemplateVersion(request.Version, dbTemplate.ID)
+	dbVersion, err := p.resolvePipelineTemplateVersion(dbTemplate, request.Version)
 	if err != nil {
-		return nil, apierrors.ErrCreateExtension.InternalError(err)
+		return nil, err
 	}
 
 	if dbVersion == nil {
@@ -230,6 +242,54 @@ func (p *PipelineTemplate) GetPipelineTemplateVersion(request *apistructs.Pipeli
 	return dbVersion.ToApiData(), nil
 }
 
+// resolvePipelineTemplateVersion 根据版本查找模板版本
+// 优先精确匹配，其次 latest 使用默认版本，最后作为 semver 约束（如 ^1.2、~1.2.3、>=1.0, <2.0）选取满足条件的最高版本
+func (p *PipelineTemplate) resolvePipelineTemplateVersion(template *dbclient.DicePipelineTemplate, version string) (*dbclient.DicePipelineTemplateVersion, error) {
+	dbVersion, err := p.db.GetPipelineTemplateVersion(version, template.ID)
+	if err != nil {
+		return nil, apierrors.ErrQueryPipelineTemplateVersion.InternalError(err)
+	}
+	if dbVersion != nil {
+		return dbVersion, nil
+	}
+
+	if version == "" || version == "latest" {
+		if template.DefaultVersion != "" && template.DefaultVersion != version {
+			dbVersion, err := p.db.GetPipelineTemplateVersion(template.DefaultVersion, template.ID)
+			if err != nil {
+				return nil, apierrors.ErrQueryPipelineTemplateVersion.InternalError(err)
+			}
+			return dbVersion, nil
+		}
+		version = "*"
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, apierrors.ErrQueryPipelineTemplateVersion.InvalidParameter(fmt.Sprintf("version %s: %v", version, err))
+	}
+
+	dbVersions, err := p.db.QueryPipelineTemplateVersions(&dbclient.DicePipelineTemplateVersion{TemplateId: template.ID})
+	if err != nil {
+		return nil, apierrors.ErrQueryPipelineTemplateVersion.InternalError(err)
+	}
+
+	var (
+		matched    *dbclient.DicePipelineTemplateVersion
+		matchedVer *semver.Version
+	)
+	for i := range dbVersions {
+		ver, err := semver.NewVersion(dbVersions[i].Version)
+		if err != nil || !constraint.Check(ver) {
+			continue
+		}
+		if matchedVer == nil || ver.GreaterThan(matchedVer) {
+			matched, matchedVer = &dbVersions[i], ver
+		}
+	}
+	return matched, nil
+}
+
 func (p *PipelineTemplate) QueryPipelineTemplateVersions(request apistructs.PipelineTemplateVersionQueryRequest) ([]*apistructs.PipelineTemplateVersion, error) {
 
 	if request.Name == "" {
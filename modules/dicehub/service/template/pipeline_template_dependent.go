@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package template
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/modules/dicehub/service/apierrors"
+)
+
+// QuerySnippetYml 根据 snippet_config 解析模板版本并返回模板内容，同时记录引用方
+func (p *PipelineTemplate) QuerySnippetYml(config *apistructs.SnippetConfig) (string, error) {
+	dbTemplate, err := p.db.GetPipelineTemplate(config.Name, config.Source, config.Labels[apistructs.LabelDiceSnippetScopeID])
+	if err != nil {
+		return "", err
+	}
+	if dbTemplate == nil {
+		return "", fmt.Errorf("not find template %s", config.Name)
+	}
+
+	constraint := config.Labels[apistructs.LabelChooseSnippetVersion]
+	dbVersion, err := p.resolvePipelineTemplateVersion(dbTemplate, constraint)
+	if err != nil {
+		return "", err
+	}
+	if dbVersion == nil {
+		return "", fmt.Errorf("not find template %s version matches %s", config.Name, constraint)
+	}
+
+	var templateAction apistructs.PipelineTemplateSpec
+	if err := yaml.Unmarshal([]byte(dbVersion.Spec), &templateAction); err != nil {
+		logrus.Errorf("Unmarshal specYaml error: %v, yaml: %s", err, dbVersion.Spec)
+		return "", err
+	}
+
+	referrerSource := config.Labels[apistructs.LabelSnippetReferrerSource]
+	referrerName := config.Labels[apistructs.LabelSnippetReferrerName]
+	if referrerSource != "" && referrerName != "" {
+		// 异步记录引用方，不阻塞模板解析
+		go p.recordPipelineTemplateReference(&dbclient.DicePipelineTemplateReference{
+			TemplateId:        dbTemplate.ID,
+			ReferrerSource:    referrerSource,
+			ReferrerName:      referrerName,
+			VersionConstraint: constraint,
+			ResolvedVersion:   dbVersion.Version,
+			LastResolvedAt:    time.Now(),
+		})
+	}
+
+	return templateAction.Template, nil
+}
+
+// recordPipelineTemplateReference 记录引用方，失败不影响模板解析
+func (p *PipelineTemplate) recordPipelineTemplateReference(ref *dbclient.DicePipelineTemplateReference) {
+	if err := p.db.UpsertPipelineTemplateReference(ref); err != nil {
+		logrus.Errorf("failed to record pipeline template reference, templateID: %d, referrer: %s/%s, err: %v",
+			ref.TemplateId, ref.ReferrerSource, ref.ReferrerName, err)
+	}
+}
+
+// QueryPipelineTemplateDependents 查询引用模板的流水线，version 不为空时标记依赖方的版本约束是否匹配该版本
+func (p *PipelineTemplate) QueryPipelineTemplateDependents(request *apistructs.PipelineTemplateDependentQueryRequest) ([]apistructs.PipelineTemplateDependent, error) {
+	if request.Name == "" {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.InvalidParameter("name")
+	}
+	if request.ScopeID == "" {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.InvalidParameter("scopeID")
+	}
+	if request.ScopeType == "" {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.InvalidParameter("scopeType")
+	}
+
+	dbTemplate, err := p.db.GetPipelineTemplate(request.Name, request.ScopeType, request.ScopeID)
+	if err != nil {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.InternalError(err)
+	}
+	if dbTemplate == nil {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.NotFound()
+	}
+
+	dependents, err := p.listPipelineTemplateDependents(dbTemplate.ID, request.Version)
+	if err != nil {
+		return nil, apierrors.ErrQueryPipelineTemplateDependent.InternalError(err)
+	}
+	return dependents, nil
+}
+
+func (p *PipelineTemplate) listPipelineTemplateDependents(templateID uint64, version string) ([]apistructs.PipelineTemplateDependent, error) {
+	refs, err := p.db.ListPipelineTemplateReferences(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newVersion *semver.Version
+	if version != "" {
+		newVersion, _ = semver.NewVersion(version)
+	}
+
+	dependents := make([]apistructs.PipelineTemplateDependent, 0, len(refs))
+	for _, ref := range refs {
+		dependent := ref.ToApiData()
+		dependent.Matched = isVersionMatched(ref.VersionConstraint, version, newVersion)
+		dependents = append(dependents, *dependent)
+	}
+	return dependents, nil
+}
+
+// isVersionMatched 依赖方的版本约束是否会解析到该版本
+func isVersionMatched(constraint, version string, semVersion *semver.Version) bool {
+	if constraint == version || constraint == "" || constraint == "latest" {
+		return true
+	}
+	if semVersion == nil {
+		return false
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	return c.Check(semVersion)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package template
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/Masterminds/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dicehub/dbclient"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+)
+
+func TestIsVersionMatched(t *testing.T) {
+	v := semver.MustParse("1.3.0")
+	assert.True(t, isVersionMatched("", "1.3.0", v))
+	assert.True(t, isVersionMatched("latest", "1.3.0", v))
+	assert.True(t, isVersionMatched("1.3.0", "1.3.0", v))
+	assert.True(t, isVersionMatched("^1.2", "1.3.0", v))
+	assert.False(t, isVersionMatched("~1.2.0", "1.3.0", v))
+	assert.False(t, isVersionMatched("1.2.0", "1.3.0", v))
+	assert.False(t, isVersionMatched("^1.2", "dev", nil))
+}
+
+func patchPipelineTemplateVersions(db *dbclient.DBClient, versions ...string) {
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetPipelineTemplateVersion", func(_ *dbclient.DBClient, version string, templateId uint64) (*dbclient.DicePipelineTemplateVersion, error) {
+		for _, v := range versions {
+			if v == version {
+				return &dbclient.DicePipelineTemplateVersion{TemplateId: templateId, Version: v, Spec: "template: 'version: \"1.1\"'"}, nil
+			}
+		}
+		return nil, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "QueryPipelineTemplateVersions", func(_ *dbclient.DBClient, version *dbclient.DicePipelineTemplateVersion) ([]dbclient.DicePipelineTemplateVersion, error) {
+		var result []dbclient.DicePipelineTemplateVersion
+		for _, v := range versions {
+			result = append(result, dbclient.DicePipelineTemplateVersion{TemplateId: version.TemplateId, Version: v})
+		}
+		return result, nil
+	})
+}
+
+func TestResolvePipelineTemplateVersion(t *testing.T) {
+	db := &dbclient.DBClient{}
+	patchPipelineTemplateVersions(db, "1.0.0", "1.2.0", "1.3.1", "2.0.0", "dev")
+	defer monkey.UnpatchAll()
+
+	p := New(WithDBClient(db))
+	template := &dbclient.DicePipelineTemplate{DefaultVersion: "1.2.0"}
+	template.ID = 1
+
+	cases := []struct {
+		version string
+		want    string
+	}{
+		{"dev", "dev"},
+		{"latest", "1.2.0"},
+		{"^1.2", "1.3.1"},
+		{"~1.2.0", "1.2.0"},
+		{">=1.0, <1.3", "1.2.0"},
+		{"^3", ""},
+	}
+	for _, c := range cases {
+		v, err := p.resolvePipelineTemplateVersion(template, c.version)
+		assert.NoError(t, err, c.version)
+		if c.want == "" {
+			assert.Nil(t, v, c.version)
+			continue
+		}
+		assert.Equal(t, c.want, v.Version, c.version)
+	}
+
+	// 没有默认版本时 latest 取最高版本
+	template.DefaultVersion = ""
+	v, err := p.resolvePipelineTemplateVersion(template, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0", v.Version)
+
+	_, err = p.resolvePipelineTemplateVersion(template, "not-a-version")
+	assert.Error(t, err)
+	apiErr, ok := err.(*errorresp.APIError)
+	assert.True(t, ok)
+	assert.Equal(t, 400, apiErr.HttpCode())
+}
+
+func TestQuerySnippetYmlRecordReference(t *testing.T) {
+	db := &dbclient.DBClient{}
+	patchPipelineTemplateVersions(db, "1.0.0", "1.2.0")
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetPipelineTemplate", func(_ *dbclient.DBClient, name string, scopeType string, scopeID string) (*dbclient.DicePipelineTemplate, error) {
+		template := &dbclient.DicePipelineTemplate{Name: name}
+		template.ID = 1
+		return template, nil
+	})
+	recorded := make(chan *dbclient.DicePipelineTemplateReference, 1)
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "UpsertPipelineTemplateReference", func(_ *dbclient.DBClient, ref *dbclient.DicePipelineTemplateReference) error {
+		recorded <- ref
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	p := New(WithDBClient(db))
+	_, err := p.QuerySnippetYml(&apistructs.SnippetConfig{
+		Name:   "deploy",
+		Source: "dice",
+		Labels: map[string]string{
+			apistructs.LabelDiceSnippetScopeID:    "1",
+			apistructs.LabelChooseSnippetVersion:  "^1.0",
+			apistructs.LabelSnippetReferrerSource: "dice",
+			apistructs.LabelSnippetReferrerName:   "pipeline.yml",
+		},
+	})
+	assert.NoError(t, err)
+	select {
+	case ref := <-recorded:
+		assert.Equal(t, uint64(1), ref.TemplateId)
+		assert.Equal(t, "^1.0", ref.VersionConstraint)
+		assert.Equal(t, "1.2.0", ref.ResolvedVersion)
+		assert.Equal(t, "pipeline.yml", ref.ReferrerName)
+	case <-time.After(time.Second):
+		t.Fatal("pipeline template reference was not recorded")
+	}
+
+	// 版本约束不合法
+	_, err = p.QuerySnippetYml(&apistructs.SnippetConfig{
+		Name:   "deploy",
+		Labels: map[string]string{apistructs.LabelChooseSnippetVersion: "not-a-version"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,556 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package adapt
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda-infra/providers/i18n"
+	"github.com/erda-project/erda/modules/monitor/core/metrics/metricq"
+	"github.com/erda-project/erda/modules/monitor/utils"
+	"github.com/mitchellh/mapstructure"
+)
+
+// preview triggers
+const (
+	PreviewTriggerAlert   = "alert"
+	PreviewTriggerRecover = "recover"
+)
+
+// maxPreviewBuckets limits the number of windows evaluated by one preview
+const maxPreviewBuckets = 2000
+
+type (
+	// AlertPreview .
+	AlertPreview struct {
+		Start  int64                `json:"start"`
+		End    int64                `json:"end"`
+		Rules  []*AlertPreviewRule  `json:"rules"`
+		Events []*AlertPreviewEvent `json:"events"`
+	}
+	// AlertPreviewRule .
+	AlertPreviewRule struct {
+		Name      string `json:"name"`
+		Metric    string `json:"metric"`
+		Window    int64  `json:"window"`
+		Statement string `json:"statement"`
+		Buckets   int    `json:"buckets"`
+		Alerts    int    `json:"alerts"`
+		Recovers  int    `json:"recovers"`
+	}
+	// AlertPreviewEvent .
+	AlertPreviewEvent struct {
+		Timestamp int64                  `json:"timestamp"`
+		Rule      string                 `json:"rule"`
+		Trigger   string                 `json:"trigger"`
+		Group     map[string]interface{} `json:"group"`
+		Values    map[string]interface{} `json:"values"`
+		Notifies  []*AlertPreviewNotify  `json:"notifies,omitempty"`
+	}
+	// AlertPreviewNotify .
+	AlertPreviewNotify struct {
+		Target  string `json:"target"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+
+	previewRule struct {
+		name       string
+		expression *CustomizeAlertRuleTemplate
+		attributes map[string]interface{}
+		templates  []*previewTemplate
+	}
+	previewTemplate struct {
+		target  string
+		trigger string
+		title   string
+		content string
+	}
+)
+
+// PreviewAlert evaluates the rules of alert over the stored metrics between start and end (in milliseconds),
+// and returns the alert and recover events it would have produced.
+func (a *Adapt) PreviewAlert(lang i18n.LanguageCodes, alert *Alert, start, end int64) (*AlertPreview, error) {
+	var indexes []string
+	for _, expression := range alert.Rules {
+		indexes = append(indexes, expression.AlertIndex)
+	}
+	ruleMap, err := a.getEnabledAlertRulesByScopeAndIndices(lang, alert.AlertScope, alert.AlertScopeID, indexes)
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for _, rule := range ruleMap {
+		types = append(types, rule.AlertType)
+	}
+	templates := make(map[string][]*previewTemplate)
+	if len(types) > 0 {
+		list, err := a.db.AlertNotifyTemplate.QueryEnabledByTypesAndIndexes(types, indexes)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			templates[item.AlertIndex] = append(templates[item.AlertIndex], &previewTemplate{
+				target:  item.Target,
+				trigger: item.Trigger,
+				title:   item.Title,
+				content: item.Template,
+			})
+		}
+	}
+	var rules []*previewRule
+	for _, expression := range alert.Rules {
+		rule, ok := ruleMap[expression.AlertIndex]
+		if !ok || rule.AlertScope != alert.AlertScope {
+			return nil, invalidParameter("rule %s is not scope: %s", expression.AlertIndex, alert.AlertScope)
+		}
+		exp, err := expression.ToModel(alert, rule)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := newPreviewRule(rule.Name, exp.Expression, exp.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		pr.templates = templates[expression.AlertIndex]
+		rules = append(rules, pr)
+	}
+	return a.previewRules(alert.AlertScope, alert.AlertScopeID, rules, start, end)
+}
+
+// PreviewCustomizeAlert evaluates the rules of a customize alert over the stored metrics between start and end (in milliseconds),
+// and returns the alert and recover events it would have produced.
+func (a *Adapt) PreviewCustomizeAlert(alert *CustomizeAlertDetail, start, end int64) (*AlertPreview, error) {
+	if alert.Attributes == nil {
+		alert.Attributes = make(map[string]interface{})
+	}
+	var templates []*previewTemplate
+	for _, notify := range alert.Notifies {
+		for _, target := range notify.Targets {
+			templates = append(templates, &previewTemplate{
+				target:  target,
+				trigger: PreviewTriggerAlert,
+				title:   notify.Title,
+				content: notify.Content,
+			})
+		}
+	}
+	var rules []*previewRule
+	for _, rule := range alert.Rules {
+		if rule.Name == "" {
+			rule.Name = alert.Name
+		}
+		model := rule.ToModel(alert, "preview")
+		pr, err := newPreviewRule(rule.Name, model.Template, model.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		pr.templates = templates
+		rules = append(rules, pr)
+	}
+	return a.previewRules(alert.AlertScope, alert.AlertScopeID, rules, start, end)
+}
+
+func newPreviewRule(name string, expression, attributes map[string]interface{}) (*previewRule, error) {
+	exp := &CustomizeAlertRuleTemplate{}
+	if err := mapstructure.WeakDecode(expression, exp); err != nil {
+		return nil, invalidParameter("invalid expression of rule %s: %s", name, err)
+	}
+	if len(exp.Metric) <= 0 {
+		return nil, invalidParameter("metric of rule %s must not be empty", name)
+	}
+	if exp.Window <= 0 {
+		return nil, invalidParameter("window of rule %s must be greater than 0", name)
+	}
+	if len(exp.Functions) <= 0 {
+		return nil, invalidParameter("functions of rule %s must not be empty", name)
+	}
+	return &previewRule{
+		name:       name,
+		expression: exp,
+		attributes: attributes,
+	}, nil
+}
+
+func (a *Adapt) previewRules(scope, scopeID string, rules []*previewRule, start, end int64) (*AlertPreview, error) {
+	if start >= end {
+		return nil, invalidParameter("start must be less than end")
+	}
+	preview := &AlertPreview{Start: start, End: end}
+	for _, rule := range rules {
+		if scope == "micro_service" {
+			rule.addDefaultFilter("terminus_key", scopeID)
+		}
+		window := int64(rule.expression.Window)
+		if (end-start)/(window*int64(time.Minute/time.Millisecond)) > maxPreviewBuckets {
+			return nil, invalidParameter("time range is too large for window %d minutes of rule %s", window, rule.name)
+		}
+		statement, params, err := buildPreviewStatement(rule.expression)
+		if err != nil {
+			return nil, err
+		}
+		options := url.Values{}
+		options.Set("start", strconv.FormatInt(start, 10))
+		options.Set("end", strconv.FormatInt(end, 10))
+		options.Set("epoch", "ms")
+		rs, err := a.metricq.Query(metricq.InfluxQL, statement, params, options)
+		if err != nil {
+			return nil, err
+		}
+		info := &AlertPreviewRule{
+			Name:      rule.name,
+			Metric:    rule.expression.Metric,
+			Window:    window,
+			Statement: statement,
+		}
+		var rows [][]interface{}
+		if rs != nil && rs.ResultSet != nil {
+			rows = rs.ResultSet.Rows
+		}
+		events, buckets := replayPreviewRule(rule, rows)
+		info.Buckets = buckets
+		for _, e := range events {
+			switch e.Trigger {
+			case PreviewTriggerAlert:
+				info.Alerts++
+			case PreviewTriggerRecover:
+				info.Recovers++
+			}
+		}
+		preview.Rules = append(preview.Rules, info)
+		preview.Events = append(preview.Events, events...)
+	}
+	sort.SliceStable(preview.Events, func(i, j int) bool {
+		return preview.Events[i].Timestamp < preview.Events[j].Timestamp
+	})
+	return preview, nil
+}
+
+func (r *previewRule) addDefaultFilter(tag, value string) {
+	for _, f := range r.expression.Filters {
+		if f.Tag == tag {
+			return
+		}
+	}
+	r.expression.Filters = append(r.expression.Filters, &CustomizeAlertRuleFilter{
+		Tag:      tag,
+		Operator: "eq",
+		Value:    value,
+	})
+}
+
+func previewFieldKey(field string) string {
+	if strings.HasPrefix(field, "tags.") {
+		return field[len("tags."):] + "::tag"
+	}
+	return strings.TrimPrefix(field, "fields.") + "::field"
+}
+
+func previewValueKey(f *CustomizeAlertRuleFunction) string {
+	if len(f.Alias) > 0 {
+		return f.Alias
+	}
+	field := strings.TrimPrefix(strings.TrimPrefix(f.Field, "fields."), "tags.")
+	return field + "_" + f.Aggregator
+}
+
+func previewAggregation(f *CustomizeAlertRuleFunction) (string, error) {
+	field := previewFieldKey(f.Field)
+	switch f.Aggregator {
+	case "sum", "avg", "max", "min", "distinct", "count", "value":
+		return f.Aggregator + "(" + field + ")", nil
+	case "values":
+		return "last(" + field + ")", nil
+	case "p99", "p95", "p90", "p75", "p50":
+		return "percentiles(" + field + ", " + f.Aggregator[1:] + ")", nil
+	}
+	return "", invalidParameter("not support aggregator %s", f.Aggregator)
+}
+
+// buildPreviewStatement converts the alert expression into an influxql statement,
+// which selects timestamp, the value of each function and the group tags for every window.
+func buildPreviewStatement(exp *CustomizeAlertRuleTemplate) (string, map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	sb := &strings.Builder{}
+	sb.WriteString("SELECT timestamp()")
+	for _, f := range exp.Functions {
+		agg, err := previewAggregation(f)
+		if err != nil {
+			return "", nil, err
+		}
+		sb.WriteString(", ")
+		sb.WriteString(agg)
+	}
+	for _, g := range exp.Group {
+		sb.WriteString(", ")
+		sb.WriteString(g + "::tag")
+	}
+	sb.WriteString(fmt.Sprintf(" FROM %q", exp.Metric))
+
+	var where []string
+	for i, f := range exp.Filters {
+		cond, err := previewFilterCondition(f, fmt.Sprintf("f%d", i), params)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(cond) > 0 {
+			where = append(where, cond)
+		}
+	}
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
+	sb.WriteString(fmt.Sprintf(" GROUP BY time(%dm)", exp.Window))
+	for _, g := range exp.Group {
+		sb.WriteString(", ")
+		sb.WriteString(g + "::tag")
+	}
+	return sb.String(), params, nil
+}
+
+func previewFilterCondition(f *CustomizeAlertRuleFilter, name string, params map[string]interface{}) (string, error) {
+	key := f.Tag + "::tag"
+	switch f.Operator {
+	case "any", "null", "false":
+		return "", nil
+	case "eq", "neq":
+		value, _ := utils.ConvertString(f.Value)
+		params[name] = value
+		if f.Operator == "eq" {
+			return key + "=$" + name, nil
+		}
+		return key + "!=$" + name, nil
+	case "in":
+		values, ok := utils.ConvertStringArr(f.Value)
+		if !ok {
+			value, _ := utils.ConvertString(f.Value)
+			values = strings.Split(value, ",")
+		}
+		var conds []string
+		for i, v := range values {
+			pn := fmt.Sprintf("%s_%d", name, i)
+			params[pn] = strings.TrimSpace(v)
+			conds = append(conds, key+"=$"+pn)
+		}
+		if len(conds) <= 0 {
+			return "", nil
+		}
+		return "(" + strings.Join(conds, " OR ") + ")", nil
+	case "like", "match", "notMatch":
+		value, _ := utils.ConvertString(f.Value)
+		pattern := value
+		if f.Operator == "like" {
+			pattern = ".*" + regexp.QuoteMeta(value) + ".*"
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			return "", invalidParameter("invalid pattern of filter %s: %s", f.Tag, err)
+		}
+		pattern = strings.ReplaceAll(pattern, "/", "\\/")
+		if f.Operator == "notMatch" {
+			return key + "!~/" + pattern + "/", nil
+		}
+		return key + "=~/" + pattern + "/", nil
+	}
+	return "", invalidParameter("not support filter operator %s", f.Operator)
+}
+
+// replayPreviewRule walks through the windows in time order and tracks the state of each group,
+// it returns the events and the number of windows.
+func replayPreviewRule(rule *previewRule, rows [][]interface{}) ([]*AlertPreviewEvent, int) {
+	exp := rule.expression
+	nf := len(exp.Functions)
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, _ := convertFloat64(rows[i][0])
+		tj, _ := convertFloat64(rows[j][0])
+		return ti < tj
+	})
+	var (
+		events  []*AlertPreviewEvent
+		buckets = make(map[int64]bool)
+		firing  = make(map[string]bool)
+	)
+	for _, row := range rows {
+		if len(row) < 1+nf+len(exp.Group) {
+			continue
+		}
+		t, _ := convertFloat64(row[0])
+		timestamp := int64(t)
+		buckets[timestamp] = true
+
+		group := make(map[string]interface{}, len(exp.Group))
+		var keys []string
+		for i, g := range exp.Group {
+			v := row[1+nf+i]
+			group[g] = v
+			keys = append(keys, fmt.Sprint(v))
+		}
+		key := strings.Join(keys, "-")
+
+		values := make(map[string]interface{}, nf)
+		matched := true
+		for i, f := range exp.Functions {
+			v := row[1+i]
+			values[previewValueKey(f)] = v
+			if !evaluatePreviewFunction(f.Operator, v, f.Value) {
+				matched = false
+			}
+		}
+
+		var trigger string
+		if matched && !firing[key] {
+			trigger = PreviewTriggerAlert
+		} else if !matched && firing[key] {
+			trigger = PreviewTriggerRecover
+		}
+		firing[key] = matched
+		if len(trigger) <= 0 {
+			continue
+		}
+		event := &AlertPreviewEvent{
+			Timestamp: timestamp,
+			Rule:      rule.name,
+			Trigger:   trigger,
+			Group:     group,
+			Values:    values,
+		}
+		event.Notifies = rule.renderNotifies(event)
+		events = append(events, event)
+	}
+	return events, len(buckets)
+}
+
+func (r *previewRule) renderNotifies(event *AlertPreviewEvent) []*AlertPreviewNotify {
+	vars := make(map[string]interface{})
+	for k, v := range r.attributes {
+		vars[k] = v
+	}
+	for k, v := range event.Group {
+		vars[k] = v
+	}
+	for k, v := range event.Values {
+		vars[k] = v
+	}
+	vars["timestamp"] = time.Unix(0, event.Timestamp*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
+	vars["trigger"] = event.Trigger
+	var list []*AlertPreviewNotify
+	for _, t := range r.templates {
+		trigger := t.trigger
+		if len(trigger) <= 0 {
+			trigger = PreviewTriggerAlert
+		}
+		if trigger != event.Trigger {
+			continue
+		}
+		list = append(list, &AlertPreviewNotify{
+			Target:  t.target,
+			Title:   renderPreviewTemplate(t.title, vars),
+			Content: renderPreviewTemplate(t.content, vars),
+		})
+	}
+	return list
+}
+
+var previewTemplateVarRegexp = regexp.MustCompile(`{{\s*([\w.]+)\s*}}`)
+
+// renderPreviewTemplate replaces the {{key}} placeholders of notify template with vars,
+// unknown placeholders are kept as they are.
+func renderPreviewTemplate(tmpl string, vars map[string]interface{}) string {
+	return previewTemplateVarRegexp.ReplaceAllStringFunc(tmpl, func(s string) string {
+		key := previewTemplateVarRegexp.FindStringSubmatch(s)[1]
+		v, ok := vars[key]
+		if !ok || v == nil {
+			return s
+		}
+		if f, ok := convertFloat64(v); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprint(v)
+	})
+}
+
+func evaluatePreviewFunction(operator string, value, target interface{}) bool {
+	if operator == "any" {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	switch operator {
+	case "gt", "gte", "lt", "lte":
+		v, ok1 := convertPreviewNumber(value)
+		t, ok2 := convertPreviewNumber(target)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch operator {
+		case "gt":
+			return v > t
+		case "gte":
+			return v >= t
+		case "lt":
+			return v < t
+		default:
+			return v <= t
+		}
+	case "eq":
+		return equalPreviewValue(value, target)
+	case "neq":
+		return !equalPreviewValue(value, target)
+	case "like", "contains":
+		return strings.Contains(fmt.Sprint(value), fmt.Sprint(target))
+	case "all":
+		if list, ok := value.([]interface{}); ok {
+			for _, item := range list {
+				if !equalPreviewValue(item, target) {
+					return false
+				}
+			}
+			return len(list) > 0
+		}
+		return equalPreviewValue(value, target)
+	}
+	return false
+}
+
+func equalPreviewValue(a, b interface{}) bool {
+	fa, ok1 := convertPreviewNumber(a)
+	fb, ok2 := convertPreviewNumber(b)
+	if ok1 && ok2 {
+		return fa == fb
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func convertPreviewNumber(obj interface{}) (float64, bool) {
+	if f, ok := convertFloat64(obj); ok {
+		return f, true
+	}
+	if s, ok := convertString(obj); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	if b, ok := obj.(bool); ok {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
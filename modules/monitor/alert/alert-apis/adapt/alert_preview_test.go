@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package adapt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_buildPreviewStatement(t *testing.T) {
+	exp := &CustomizeAlertRuleTemplate{
+		Metric: "host_summary",
+		Window: 5,
+		Functions: []*CustomizeAlertRuleFunction{
+			{Field: "load5", Aggregator: "avg", Operator: "gt", Value: 10},
+			{Field: "mem_used", Aggregator: "p99", Operator: "gt", Value: 100},
+		},
+		Filters: []*CustomizeAlertRuleFilter{
+			{Tag: "cluster_name", Operator: "eq", Value: "terminus"},
+			{Tag: "host_ip", Operator: "in", Value: []interface{}{"1.1.1.1", "2.2.2.2"}},
+			{Tag: "org_name", Operator: "any"},
+		},
+		Group: []string{"cluster_name", "host_ip"},
+	}
+	statement, params, err := buildPreviewStatement(exp)
+	if err != nil {
+		t.Fatalf("buildPreviewStatement() error = %v", err)
+	}
+	want := `SELECT timestamp(), avg(load5::field), percentiles(mem_used::field, 99), cluster_name::tag, host_ip::tag ` +
+		`FROM "host_summary" WHERE cluster_name::tag=$f0 AND (host_ip::tag=$f1_0 OR host_ip::tag=$f1_1) ` +
+		`GROUP BY time(5m), cluster_name::tag, host_ip::tag`
+	if statement != want {
+		t.Errorf("buildPreviewStatement() statement = %q, want %q", statement, want)
+	}
+	wantParams := map[string]interface{}{"f0": "terminus", "f1_0": "1.1.1.1", "f1_1": "2.2.2.2"}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("buildPreviewStatement() params = %v, want %v", params, wantParams)
+	}
+
+	exp.Functions[0].Aggregator = "unknown"
+	if _, _, err := buildPreviewStatement(exp); err == nil {
+		t.Errorf("buildPreviewStatement() expect error for unknown aggregator")
+	}
+}
+
+func Test_replayPreviewRule(t *testing.T) {
+	rule := &previewRule{
+		name: "load",
+		expression: &CustomizeAlertRuleTemplate{
+			Metric: "host_summary",
+			Window: 1,
+			Functions: []*CustomizeAlertRuleFunction{
+				{Field: "load5", Aggregator: "avg", Operator: "gte", Value: 10},
+			},
+			Group: []string{"host_ip"},
+		},
+		attributes: map[string]interface{}{"cluster_name": "terminus"},
+		templates: []*previewTemplate{
+			{target: "dingding", trigger: "alert", title: "load of {{host_ip}}", content: "{{cluster_name}}: {{load5_avg}} {{unknown}}"},
+			{target: "dingding", trigger: "recover", title: "recovered {{host_ip}}", content: "{{load5_avg}}"},
+		},
+	}
+	rows := [][]interface{}{
+		{float64(180000), float64(12), "1.1.1.1"},
+		{float64(0), float64(1), "1.1.1.1"},
+		{float64(60000), float64(11), "1.1.1.1"},
+		{float64(60000), float64(3), "2.2.2.2"},
+		{float64(120000), float64(2), "1.1.1.1"},
+	}
+	events, buckets := replayPreviewRule(rule, rows)
+	if buckets != 4 {
+		t.Errorf("replayPreviewRule() buckets = %d, want 4", buckets)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Trigger+"@"+e.Group["host_ip"].(string))
+	}
+	want := []string{"alert@1.1.1.1", "recover@1.1.1.1", "alert@1.1.1.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayPreviewRule() events = %v, want %v", got, want)
+	}
+	if len(events[0].Notifies) != 1 || events[0].Notifies[0].Title != "load of 1.1.1.1" ||
+		events[0].Notifies[0].Content != "terminus: 11 {{unknown}}" {
+		t.Errorf("replayPreviewRule() alert notifies = %+v", events[0].Notifies[0])
+	}
+	if len(events[1].Notifies) != 1 || events[1].Notifies[0].Title != "recovered 1.1.1.1" {
+		t.Errorf("replayPreviewRule() recover notifies = %+v", events[1].Notifies)
+	}
+}
+
+func Test_evaluatePreviewFunction(t *testing.T) {
+	tests := []struct {
+		operator string
+		value    interface{}
+		target   interface{}
+		want     bool
+	}{
+		{"gt", float64(2), 1, true},
+		{"gt", float64(1), "1", false},
+		{"lte", float64(1), "1", true},
+		{"eq", "abc", "abc", true},
+		{"neq", float64(1), 1, false},
+		{"like", "hello world", "world", true},
+		{"all", []interface{}{true, true}, true, true},
+		{"all", []interface{}{true, false}, true, false},
+		{"any", nil, nil, true},
+		{"gt", nil, 1, false},
+	}
+	for _, tt := range tests {
+		if got := evaluatePreviewFunction(tt.operator, tt.value, tt.target); got != tt.want {
+			t.Errorf("evaluatePreviewFunction(%q, %v, %v) = %v, want %v", tt.operator, tt.value, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apis
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/erda-project/erda-infra/modcom/api"
+	"github.com/erda-project/erda/modules/monitor/alert/alert-apis/adapt"
+	"github.com/erda-project/erda/modules/monitor/utils"
+)
+
+// previewTimeRange returns the preview range in milliseconds, the last day by default.
+func previewTimeRange(start, end int64) (int64, int64) {
+	if end <= 0 {
+		end = utils.ConvertTimeToMS(time.Now())
+	}
+	if start <= 0 {
+		start = end - int64(24*time.Hour/time.Millisecond)
+	}
+	return start, end
+}
+
+func (p *provider) previewAlert(r *http.Request, params struct {
+	Start int64 `query:"start"`
+	End   int64 `query:"end"`
+}, alert adapt.Alert) interface{} {
+	if alert.AlertScope == "" {
+		return api.Errors.MissingParameter("alert scope")
+	}
+	if alert.AlertScopeID == "" {
+		return api.Errors.MissingParameter("alert scopeId")
+	}
+	if len(alert.Rules) == 0 {
+		return api.Errors.MissingParameter("alert rules")
+	}
+	start, end := previewTimeRange(params.Start, params.End)
+	data, err := p.a.PreviewAlert(api.Language(r), &alert, start, end)
+	if err != nil {
+		if adapt.IsInvalidParameterError(err) {
+			return api.Errors.InvalidParameter(err)
+		}
+		return api.Errors.Internal(err)
+	}
+	return api.Success(data)
+}
+
+func (p *provider) previewCustomizeAlert(r *http.Request, params struct {
+	Start int64 `query:"start"`
+	End   int64 `query:"end"`
+}, alert adapt.CustomizeAlertDetail) interface{} {
+	alert.Lang = api.Language(r)
+	if alert.AlertScope == "" {
+		return api.Errors.MissingParameter("alert scope")
+	}
+	if alert.AlertScopeID == "" {
+		return api.Errors.MissingParameter("alert scopeId")
+	}
+	if len(alert.Rules) == 0 {
+		return api.Errors.MissingParameter("alert rules")
+	}
+	start, end := previewTimeRange(params.Start, params.End)
+	data, err := p.a.PreviewCustomizeAlert(&alert, start, end)
+	if err != nil {
+		if adapt.IsInvalidParameterError(err) {
+			return api.Errors.InvalidParameter(err)
+		}
+		return api.Errors.Internal(err)
+	}
+	return api.Success(data)
+}
@@ -64,6 +64,10 @@ func (p *provider) intRoutes(routes httpserver.Router) error {
 		common.ResourceOrgAlert, permission.ActionCreate,
 	))
 
+	// Back-testing alarm rules against historical metrics
+	routes.POST("/api/customize/alerts/preview", p.previewCustomizeAlert)
+	routes.POST("/api/alerts/preview", p.previewAlert)
+
 	// alert
 	routes.GET("/api/alerts/rules", p.queryAlertRule)
 	routes.GET("/api/alerts", p.queryAlert)
@@ -23,6 +23,8 @@ import (
 	"github.com/erda-project/erda-infra/providers/httpserver"
 	"github.com/erda-project/erda-infra/providers/httpserver/interceptors"
 	"github.com/gocql/gocql"
+
+	"github.com/erda-project/erda/bundle"
 )
 
 type define struct{}
@@ -45,12 +47,28 @@ type config struct {
 	Download  struct {
 		TimeSpan time.Duration `file:"time_span" default:"5m"`
 	} `file:"download"`
+	Tail struct {
+		Interval      time.Duration `file:"interval" default:"1s"`
+		PingInterval  time.Duration `file:"ping_interval" default:"30s"`
+		WriteTimeout  time.Duration `file:"write_timeout" default:"10s"`
+		BackfillRange time.Duration `file:"backfill_range" default:"1h"`
+		BatchSize     int           `file:"batch_size" default:"200"`
+		BufferSize    int           `file:"buffer_size" default:"1000"`
+		MaxInstances  int           `file:"max_instances" default:"20"`
+		// MaxMetas 按 runtime 查询实例元数据的条数上限，已销毁的实例也在其中
+		MaxMetas int `file:"max_metas" default:"500"`
+		// IngestionLag 日志从产生到写入存储的延迟，查询起点落后该时长以接收延迟入库的日志
+		IngestionLag time.Duration `file:"ingestion_lag" default:"5s"`
+		// ResolveInterval 按 runtime 跟踪时重新获取实例列表的间隔
+		ResolveInterval time.Duration `file:"resolve_interval" default:"10s"`
+	} `file:"tail"`
 }
 
 type provider struct {
 	Cfg              *config
 	Logger           logs.Logger
 	session          *gocql.Session
+	bdl              *bundle.Bundle
 	checkOrgCluster  func(ctx httpserver.Context) (string, error)
 	getApplicationID func(ctx httpserver.Context) (string, error)
 }
@@ -62,6 +80,7 @@ func (p *provider) Init(ctx servicehub.Context) error {
 		return fmt.Errorf("fail to create cassandra session: %s", err)
 	}
 	p.session = session
+	p.bdl = bundle.New(bundle.WithScheduler())
 	routes := ctx.Service("http-server", interceptors.Recover(p.Logger)).(httpserver.Router)
 	err = p.intRoutes(routes)
 	if err != nil {
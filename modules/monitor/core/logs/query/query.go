@@ -33,7 +33,11 @@ const (
 )
 
 func (p *provider) queryBaseLogMetaWithFilters(filters map[string]interface{}) (res []*LogMeta, err error) {
-	cqlBuilder := qb.Select(LogMetaTableName).Limit(10)
+	return p.queryBaseLogMetas(filters, 10)
+}
+
+func (p *provider) queryBaseLogMetas(filters map[string]interface{}, limit uint) (res []*LogMeta, err error) {
+	cqlBuilder := qb.Select(LogMetaTableName).Limit(limit)
 	for key := range filters {
 		cqlBuilder = cqlBuilder.Where(qb.Eq(key))
 	}
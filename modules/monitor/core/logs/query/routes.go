@@ -40,6 +40,10 @@ func (p *provider) intRoutes(routes httpserver.Router) error {
 		permission.ScopeApp, p.getApplicationID,
 		common.ResourceRuntime, permission.ActionGet,
 	))
+	routes.GET("/api/runtime/logs/actions/tail", p.tailRuntimeLog, permission.Intercepter(
+		permission.ScopeApp, p.getApplicationID,
+		common.ResourceRuntime, permission.ActionGet,
+	))
 	// org
 	p.checkOrgCluster = permission.OrgIDByCluster("clusterName")
 	routes.GET("/api/orgCenter/logs", p.queryOrgLog, permission.Intercepter(
@@ -0,0 +1,685 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda-infra/modcom/api"
+	"github.com/gorilla/websocket"
+	"github.com/scylladb/gocqlx"
+	"github.com/scylladb/gocqlx/qb"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/monitor/core/logs/schema"
+)
+
+const (
+	diceRuntimeIDKey   = "dice_runtime_id"
+	diceServiceNameKey = "dice_service_name"
+)
+
+// TailRequest 实时日志请求，id 与 runtimeId 二选一
+type TailRequest struct {
+	Source        string `form:"source" default:"container"`
+	ID            string `form:"id"` // 多个实例用逗号分隔
+	Stream        string `form:"stream" default:"stdout"`
+	RuntimeID     string `form:"runtimeId"`
+	ServiceName   string `form:"serviceName"`
+	ApplicationID string `form:"applicationId"`
+	Keyword       string `form:"keyword"`
+	Level         string `form:"level"` // 多个级别用逗号分隔
+	Count         int64  `form:"count"` // 建立连接时每个实例先返回的历史日志条数
+}
+
+// TailLine 多个实例的日志复用同一连接，通过 instance 区分
+type TailLine struct {
+	Instance string `json:"instance"`
+	Service  string `json:"service,omitempty"`
+	*Log
+}
+
+type tailCursor struct {
+	timestamp int64
+	offset    int64
+}
+
+// after 日志是否在游标之后
+func (c *tailCursor) after(l *SavedLog) bool {
+	return l.Timestamp > c.timestamp || (l.Timestamp == c.timestamp && l.Offset > c.offset)
+}
+
+type tailFilter struct {
+	keyword string
+	levels  map[string]struct{}
+}
+
+func newTailFilter(keyword, level string) *tailFilter {
+	f := &tailFilter{keyword: strings.ToLower(keyword)}
+	for _, lv := range strings.Split(level, ",") {
+		lv = strings.ToUpper(strings.TrimSpace(lv))
+		if len(lv) <= 0 {
+			continue
+		}
+		if f.levels == nil {
+			f.levels = make(map[string]struct{})
+		}
+		f.levels[lv] = struct{}{}
+	}
+	return f
+}
+
+func (f *tailFilter) match(l *Log) bool {
+	if f.levels != nil {
+		if _, ok := f.levels[strings.ToUpper(l.Level)]; !ok {
+			return false
+		}
+	}
+	if len(f.keyword) > 0 && !strings.Contains(strings.ToLower(l.Content), f.keyword) {
+		return false
+	}
+	return true
+}
+
+func normalizeTailRequest(r *TailRequest) error {
+	if len(r.ID) <= 0 && len(r.RuntimeID) <= 0 {
+		return fmt.Errorf("missing parameter id or runtimeId")
+	}
+	if len(r.Source) <= 0 {
+		r.Source = "container"
+	}
+	if len(r.Stream) <= 0 {
+		r.Stream = defaultStream
+	}
+	if r.Count < 0 {
+		r.Count = 0
+	} else if r.Count > maxCount {
+		r.Count = maxCount
+	}
+	return nil
+}
+
+// resolveTailInstances 获取需要 tail 的实例，并校验实例属于该应用；
+// 按 runtime 跟踪时只保留 runtime 当前存活的实例
+func (p *provider) resolveTailInstances(r *TailRequest) ([]*LogMeta, error) {
+	if len(r.ID) > 0 {
+		var metas []*LogMeta
+		for _, id := range strings.Split(r.ID, ",") {
+			id = strings.TrimSpace(id)
+			if len(id) <= 0 {
+				continue
+			}
+			list, err := p.queryBaseLogMetas(map[string]interface{}{
+				"source": r.Source,
+				"id":     id,
+			}, 1)
+			if err != nil {
+				return nil, err
+			}
+			metas = append(metas, list...)
+		}
+		return selectTailInstances(metas, nil, r, p.Cfg.Tail.MaxInstances), nil
+	}
+
+	tags := map[string]string{diceRuntimeIDKey: r.RuntimeID}
+	if len(r.ServiceName) > 0 {
+		tags[diceServiceNameKey] = r.ServiceName
+	}
+	metas, err := p.queryLogMetasByTags(tags, uint(p.Cfg.Tail.MaxMetas))
+	if err != nil {
+		return nil, err
+	}
+	live, err := p.liveContainerIDs(r)
+	if err != nil {
+		return nil, err
+	}
+	return selectTailInstances(metas, live, r, p.Cfg.Tail.MaxInstances), nil
+}
+
+// queryLogMetasByTags 按 tags 查询实例元数据，多个 tags 条件需要 ALLOW FILTERING
+func (p *provider) queryLogMetasByTags(tags map[string]string, limit uint) (res []*LogMeta, err error) {
+	cqlBuilder := qb.Select(LogMetaTableName).Limit(limit)
+	filters := make(map[string]interface{}, len(tags))
+	for key, val := range tags {
+		name := "tags['" + key + "']"
+		cqlBuilder = cqlBuilder.Where(qb.Eq(name))
+		filters[name] = val
+	}
+	if len(tags) > 1 {
+		cqlBuilder = cqlBuilder.AllowFiltering()
+	}
+	stmt, names := cqlBuilder.ToCql()
+	cql := gocqlx.Query(p.session.Query(stmt), names).BindMap(filters)
+	p.Logger.Debugf("cql=%+v", cql)
+	if err := cql.SelectRelease(&res); err != nil {
+		return nil, fmt.Errorf("query cassandra failed. err=%s", err)
+	}
+	return
+}
+
+// liveContainerIDs runtime 当前存活的实例
+func (p *provider) liveContainerIDs(r *TailRequest) (map[string]struct{}, error) {
+	resp, err := p.bdl.GetInstanceInfo(apistructs.InstanceInfoRequest{
+		RuntimeID:   r.RuntimeID,
+		ServiceName: r.ServiceName,
+		Phases:      []string{apistructs.InstanceStatusRunning, apistructs.InstanceStatusHealthy, apistructs.InstanceStatusUnHealthy},
+	})
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]struct{}, len(resp.Data))
+	for _, ins := range resp.Data {
+		if len(ins.ContainerID) > 0 {
+			live[ins.ContainerID] = struct{}{}
+		}
+	}
+	return live, nil
+}
+
+// selectTailInstances 过滤出需要 tail 的实例，live 为 nil 时不校验实例是否存活
+func selectTailInstances(metas []*LogMeta, live map[string]struct{}, r *TailRequest, max int) []*LogMeta {
+	var result []*LogMeta
+	for _, meta := range metas {
+		if meta.Source != r.Source {
+			continue
+		}
+		// 与 checkLogMeta 一致，仅 container 日志校验所属应用
+		if meta.Source == "container" && meta.Tags["dice_application_id"] != r.ApplicationID {
+			continue
+		}
+		if len(r.ServiceName) > 0 && meta.Tags[diceServiceNameKey] != r.ServiceName {
+			continue
+		}
+		if live != nil {
+			if _, ok := live[meta.ID]; !ok {
+				continue
+			}
+		}
+		result = append(result, meta)
+		if len(result) >= max {
+			break
+		}
+	}
+	return result
+}
+
+// tailWriter 实时日志输出，支持 WebSocket 与 SSE
+type tailWriter interface {
+	WriteLine(line *TailLine) error
+	Ping() error
+	Done() <-chan struct{}
+	Close() error
+}
+
+type wsTailWriter struct {
+	conn         *websocket.Conn
+	writeTimeout time.Duration
+	done         chan struct{}
+}
+
+var tailUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+func newWSTailWriter(w http.ResponseWriter, r *http.Request, writeTimeout time.Duration) (*wsTailWriter, error) {
+	conn, err := tailUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	tw := &wsTailWriter{conn: conn, writeTimeout: writeTimeout, done: make(chan struct{})}
+	// 客户端只会发送 close 等控制消息，读取失败即认为连接断开
+	go func() {
+		defer close(tw.done)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	return tw, nil
+}
+
+func (tw *wsTailWriter) WriteLine(line *TailLine) error {
+	tw.conn.SetWriteDeadline(time.Now().Add(tw.writeTimeout))
+	return tw.conn.WriteJSON(line)
+}
+
+func (tw *wsTailWriter) Ping() error {
+	return tw.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(tw.writeTimeout))
+}
+
+func (tw *wsTailWriter) Done() <-chan struct{} { return tw.done }
+
+func (tw *wsTailWriter) Close() error {
+	tw.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(tw.writeTimeout))
+	return tw.conn.Close()
+}
+
+type sseTailWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	done    <-chan struct{}
+}
+
+func newSSETailWriter(w http.ResponseWriter, r *http.Request) (*sseTailWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming unsupported")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseTailWriter{w: w, flusher: flusher, done: r.Context().Done()}, nil
+}
+
+func (tw *sseTailWriter) WriteLine(line *TailLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(tw.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	tw.flusher.Flush()
+	return nil
+}
+
+func (tw *sseTailWriter) Ping() error {
+	if _, err := tw.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	tw.flusher.Flush()
+	return nil
+}
+
+func (tw *sseTailWriter) Done() <-chan struct{} { return tw.done }
+
+func (tw *sseTailWriter) Close() error { return nil }
+
+func (p *provider) tailRuntimeLog(r *http.Request, w http.ResponseWriter, params *TailRequest) interface{} {
+	if err := normalizeTailRequest(params); err != nil {
+		return api.Errors.InvalidParameter(err)
+	}
+	instances, err := p.resolveTailInstances(params)
+	if err != nil {
+		return api.Errors.Internal(err)
+	}
+	if len(instances) == 0 {
+		return api.Errors.NotFound("log instances")
+	}
+
+	var tw tailWriter
+	if websocket.IsWebSocketUpgrade(r) {
+		tw, err = newWSTailWriter(w, r, p.Cfg.Tail.WriteTimeout)
+	} else {
+		tw, err = newSSETailWriter(w, r)
+	}
+	if err != nil {
+		p.Logger.Errorf("fail to start log tail: %s", err)
+		return nil
+	}
+	defer tw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-tw.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	var resolve func() ([]*LogMeta, error)
+	if len(params.ID) <= 0 {
+		resolve = func() ([]*LogMeta, error) { return p.resolveTailInstances(params) }
+	}
+	filter := newTailFilter(params.Keyword, params.Level)
+	p.tailLogs(ctx, tw, instances, resolve, func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error {
+		return p.tailInstance(ctx, meta, backfill, params, filter, lines)
+	})
+	return nil
+}
+
+// tailInstanceFunc 跟踪单个实例的日志并写入 lines，ctx 结束时返回；backfill 表示是否首次跟踪该实例
+type tailInstanceFunc func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error
+
+// tailLogs 每个实例单独轮询，通过有界 channel 汇聚后输出；
+// 客户端消费慢时 channel 写满，轮询随之阻塞，游标不前进，不会在服务端堆积日志；
+// resolve 不为空时定期重新获取实例，跟踪新实例并停止跟踪已消失的实例；
+// 出错退出的实例重新跟踪时不再回填历史日志，避免重复发送
+func (p *provider) tailLogs(ctx context.Context, tw tailWriter, instances []*LogMeta,
+	resolve func() ([]*LogMeta, error), tail tailInstanceFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	lines := make(chan *TailLine, p.Cfg.Tail.BufferSize)
+	exited := make(chan string)
+	running := make(map[string]context.CancelFunc)
+	started := make(map[string]struct{})
+	start := func(meta *LogMeta) {
+		ictx, icancel := context.WithCancel(ctx)
+		running[meta.ID] = icancel
+		_, restarted := started[meta.ID]
+		started[meta.ID] = struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := tail(ictx, meta, !restarted, lines); err != nil && ictx.Err() == nil {
+				p.Logger.Errorf("fail to tail log of %s/%s: %s", meta.Source, meta.ID, err)
+			}
+			select {
+			case exited <- meta.ID:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	for _, meta := range instances {
+		start(meta)
+	}
+
+	var resolveC <-chan time.Time
+	if resolve != nil {
+		ticker := time.NewTicker(p.Cfg.Tail.ResolveInterval)
+		defer ticker.Stop()
+		resolveC = ticker.C
+	}
+	ticker := time.NewTicker(p.Cfg.Tail.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := tw.Ping(); err != nil {
+				return
+			}
+		case <-resolveC:
+			list, err := resolve()
+			if err != nil {
+				p.Logger.Warnf("fail to resolve log tail instances: %s", err)
+				continue
+			}
+			current := make(map[string]struct{}, len(list))
+			for _, meta := range list {
+				current[meta.ID] = struct{}{}
+				if _, ok := running[meta.ID]; !ok {
+					start(meta)
+				}
+			}
+			for id, icancel := range running {
+				if _, ok := current[id]; !ok {
+					icancel()
+				}
+			}
+		case id := <-exited:
+			if icancel, ok := running[id]; ok {
+				icancel()
+				delete(running, id)
+			}
+			if len(running) == 0 && resolve == nil {
+				return
+			}
+		case line := <-lines:
+			if err := tw.WriteLine(line); err != nil {
+				p.Logger.Debugf("fail to write log tail line: %s", err)
+				return
+			}
+		}
+	}
+}
+
+// tailWindow 轮询窗口，查询起点落后当前时间 lag 以接收延迟入库的日志，窗口内按 (timestamp, offset) 去重；
+// 一批取满说明仍有积压，此时按 (timestamp, offset) 从该批最后一条之后分页
+type tailWindow struct {
+	floor tailCursor
+	lag   int64
+	start int64
+	page  *tailCursor
+	seen  map[tailCursor]struct{}
+}
+
+func newTailWindow(floor tailCursor, lag time.Duration, sent []*SavedLog) *tailWindow {
+	w := &tailWindow{floor: floor, lag: int64(lag), start: floor.timestamp, seen: make(map[tailCursor]struct{})}
+	for _, sl := range sent {
+		w.seen[tailCursor{timestamp: sl.Timestamp, offset: sl.Offset}] = struct{}{}
+	}
+	return w
+}
+
+// fresh 返回未发送过的日志并记录
+func (w *tailWindow) fresh(list []*SavedLog) []*SavedLog {
+	var result []*SavedLog
+	for _, sl := range list {
+		if !w.floor.after(sl) {
+			continue
+		}
+		key := tailCursor{timestamp: sl.Timestamp, offset: sl.Offset}
+		if _, ok := w.seen[key]; ok {
+			continue
+		}
+		w.seen[key] = struct{}{}
+		result = append(result, sl)
+	}
+	return result
+}
+
+// advance 移动下次查询的起点；一批取满时从该批最后一条之后分页，否则回退 lag；
+// 去重记录只清理早于 now - lag 与起点的部分，之后的查询起点不会早于该位置，回退 lag 时不会重复发送
+func (w *tailWindow) advance(list []*SavedLog, full bool, now int64) {
+	prune := now - w.lag
+	if full && len(list) > 0 {
+		last := list[len(list)-1]
+		w.page = &tailCursor{timestamp: last.Timestamp, offset: last.Offset}
+		w.start = last.Timestamp
+	} else {
+		w.page = nil
+		w.start = now - w.lag
+	}
+	if w.start < w.floor.timestamp {
+		w.start = w.floor.timestamp
+	}
+	if prune > w.start {
+		prune = w.start
+	}
+	for key := range w.seen {
+		if key.timestamp < prune {
+			delete(w.seen, key)
+		}
+	}
+}
+
+// tailInstance 跟踪单个实例的日志，backfill 为 true 时先返回 Count 条历史日志
+func (p *provider) tailInstance(ctx context.Context, meta *LogMeta, backfill bool, r *TailRequest, filter *tailFilter, lines chan<- *TailLine) error {
+	table := p.getTableNameWithFilters(map[string]interface{}{
+		"source": meta.Source,
+		"id":     meta.ID,
+	})
+	send := func(list []*SavedLog) error {
+		for _, sl := range list {
+			l, err := wrapLogData(sl)
+			if err != nil {
+				return err
+			}
+			if !filter.match(l) {
+				continue
+			}
+			select {
+			case lines <- &TailLine{Instance: meta.ID, Service: meta.Tags[diceServiceNameKey], Log: l}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	floor := tailCursor{timestamp: now}
+	var sent []*SavedLog
+	if backfill && r.Count > 0 {
+		start := now - int64(p.Cfg.Tail.BackfillRange)
+		list, err := p.querySavedLogs(table, meta.Source, meta.ID, r.Stream, start, now, qb.DESC, uint(r.Count))
+		if err != nil {
+			return err
+		}
+		if len(list) > 0 {
+			// 回填的日志可能尚未完整入库，从第一条开始进入去重窗口
+			floor = tailCursor{timestamp: list[0].Timestamp, offset: list[0].Offset - 1}
+		}
+		if err := send(list); err != nil {
+			return err
+		}
+		sent = list
+	}
+	window := newTailWindow(floor, p.Cfg.Tail.IngestionLag, sent)
+
+	limit := uint(p.Cfg.Tail.BatchSize)
+	for {
+		end := time.Now().UnixNano()
+		list, err := p.queryTailPage(table, meta.Source, meta.ID, r.Stream, window, end, limit)
+		if err != nil {
+			return err
+		}
+		if err := send(window.fresh(list)); err != nil {
+			return err
+		}
+		full := uint(len(list)) >= limit
+		window.advance(list, full, end)
+		// 一批取满说明仍有积压，立即继续
+		if full {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.Cfg.Tail.Interval):
+		}
+	}
+}
+
+// queryTailPage 查询窗口起点之后的一批日志；分页时先取游标所在时间戳内 offset 更大的日志，再取之后时间戳的日志
+func (p *provider) queryTailPage(table, source, id, stream string, w *tailWindow, end int64, limit uint) ([]*SavedLog, error) {
+	if w.page == nil {
+		return p.querySavedLogs(table, source, id, stream, w.start, end, qb.ASC, limit)
+	}
+	logs, err := p.queryBaseLogAfterOffset(table, source, id, stream, *w.page, limit)
+	if err != nil {
+		return nil, err
+	}
+	sortSavedLogs(logs)
+	if uint(len(logs)) >= limit {
+		return logs[:limit], nil
+	}
+	rest, err := p.querySavedLogs(table, source, id, stream, w.page.timestamp+1, end, qb.ASC, limit-uint(len(logs)))
+	if err != nil {
+		return nil, err
+	}
+	return append(logs, rest...), nil
+}
+
+// queryBaseLogAfterOffset 查询与游标同一时间戳且 offset 在游标之后的日志
+func (p *provider) queryBaseLogAfterOffset(table, source, id, stream string, cursor tailCursor, limit uint) ([]*SavedLog, error) {
+	stmt, names := qb.Select(table).
+		Where(
+			qb.Eq("source"),
+			qb.Eq("id"),
+			qb.Eq("stream"),
+			qb.Eq("time_bucket"),
+			qb.Eq("timestamp"),
+			qb.Gt("offset")).
+		OrderBy("timestamp", qb.ASC).OrderBy("offset", qb.ASC).
+		Limit(limit).ToCql()
+	var logs []*SavedLog
+	cql := gocqlx.Query(p.session.Query(stmt), names).BindMap(qb.M{
+		"source":      source,
+		"id":          id,
+		"stream":      stream,
+		"time_bucket": trncateDate(cursor.timestamp),
+		"timestamp":   cursor.timestamp,
+		"offset":      cursor.offset,
+	})
+	p.Logger.Debugf("log query. cql=%+v", cql.String())
+	if err := cql.SelectRelease(&logs); err != nil {
+		return nil, err
+	}
+
+	// 与 queryBaseLogInBucket 一致，兼容旧表
+	if table == schema.DefaultBaseLogTable {
+		return logs, nil
+	}
+	oldLogs, err := p.queryBaseLogAfterOffset(schema.DefaultBaseLogTable, source, id, stream, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return append(logs, oldLogs...), nil
+}
+
+// querySavedLogs 跨天查询 [start, end] 内的日志，结果按时间升序
+func (p *provider) querySavedLogs(table, source, id, stream string, start, end int64, order qb.Order, limit uint) ([]*SavedLog, error) {
+	var logs []*SavedLog
+	for start <= end {
+		var bucket int64
+		if order == qb.ASC {
+			bucket = trncateDate(start)
+		} else {
+			bucket = trncateDate(end)
+		}
+		list, err := p.queryBaseLogInBucket(table, source, id, stream, bucket, start, end+1, order, limit)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, list...)
+		if uint(len(logs)) >= limit {
+			break
+		}
+		if order == qb.ASC {
+			start = bucket + int64(time.Hour)*24
+		} else {
+			end = bucket - 1
+		}
+	}
+	sortSavedLogs(logs)
+	if uint(len(logs)) > limit {
+		if order == qb.ASC {
+			logs = logs[:limit]
+		} else {
+			logs = logs[uint(len(logs))-limit:]
+		}
+	}
+	return logs, nil
+}
+
+func sortSavedLogs(logs []*SavedLog) {
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].Timestamp != logs[j].Timestamp {
+			return logs[i].Timestamp < logs[j].Timestamp
+		}
+		return logs[i].Offset < logs[j].Offset
+	})
+}
@@ -0,0 +1,383 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erda-project/erda-infra/base/logs/logrusx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailFilter(t *testing.T) {
+	f := newTailFilter("Timeout", "error, warn")
+	assert.True(t, f.match(&Log{Level: "ERROR", Content: "request timeout after 3s"}))
+	assert.True(t, f.match(&Log{Level: "warn", Content: "TIMEOUT"}))
+	assert.False(t, f.match(&Log{Level: "INFO", Content: "request timeout after 3s"}))
+	assert.False(t, f.match(&Log{Level: "ERROR", Content: "connection refused"}))
+
+	f = newTailFilter("", "")
+	assert.True(t, f.match(&Log{Level: "DEBUG", Content: "anything"}))
+}
+
+func TestTailCursor(t *testing.T) {
+	c := &tailCursor{timestamp: 100, offset: 5}
+	assert.False(t, c.after(&SavedLog{Timestamp: 99, Offset: 10}))
+	assert.False(t, c.after(&SavedLog{Timestamp: 100, Offset: 5}))
+	assert.True(t, c.after(&SavedLog{Timestamp: 100, Offset: 6}))
+	assert.True(t, c.after(&SavedLog{Timestamp: 101, Offset: 0}))
+}
+
+func TestSortSavedLogs(t *testing.T) {
+	logs := []*SavedLog{
+		{Timestamp: 2, Offset: 1},
+		{Timestamp: 1, Offset: 9},
+		{Timestamp: 2, Offset: 0},
+	}
+	sortSavedLogs(logs)
+	assert.Equal(t, []*SavedLog{
+		{Timestamp: 1, Offset: 9},
+		{Timestamp: 2, Offset: 0},
+		{Timestamp: 2, Offset: 1},
+	}, logs)
+}
+
+func TestNormalizeTailRequest(t *testing.T) {
+	assert.Error(t, normalizeTailRequest(&TailRequest{}))
+
+	r := &TailRequest{RuntimeID: "1", Count: 1000}
+	assert.NoError(t, normalizeTailRequest(r))
+	assert.Equal(t, "container", r.Source)
+	assert.Equal(t, defaultStream, r.Stream)
+	assert.Equal(t, int64(maxCount), r.Count)
+}
+
+func TestSelectTailInstances(t *testing.T) {
+	newMeta := func(id, app, service string) *LogMeta {
+		return &LogMeta{Source: "container", ID: id, Tags: map[string]string{
+			"dice_application_id": app,
+			diceServiceNameKey:    service,
+		}}
+	}
+	metas := []*LogMeta{
+		newMeta("c1", "1", "web"),
+		newMeta("c2", "1", "web"),
+		newMeta("c3", "2", "web"),
+		newMeta("c4", "1", "api"),
+		newMeta("c5", "1", "web"),
+	}
+	r := &TailRequest{Source: "container", ApplicationID: "1", ServiceName: "web"}
+
+	result := selectTailInstances(metas, nil, r, 10)
+	assert.Equal(t, []*LogMeta{metas[0], metas[1], metas[4]}, result)
+
+	// 已销毁的实例不再跟踪
+	live := map[string]struct{}{"c2": {}, "c3": {}, "c5": {}}
+	result = selectTailInstances(metas, live, r, 10)
+	assert.Equal(t, []*LogMeta{metas[1], metas[4]}, result)
+
+	result = selectTailInstances(metas, live, r, 1)
+	assert.Equal(t, []*LogMeta{metas[1]}, result)
+}
+
+func TestTailWindow(t *testing.T) {
+	second := int64(time.Second)
+	now := 100 * second
+	w := newTailWindow(tailCursor{timestamp: now}, 5*time.Second, nil)
+	assert.Equal(t, now, w.start)
+
+	list := []*SavedLog{{Timestamp: now + 1, Offset: 1}, {Timestamp: now + 2, Offset: 1}}
+	assert.Equal(t, list, w.fresh(list))
+	w.advance(list, false, now+2*second)
+	// 起点回退 lag，但不早于建立连接的时间
+	assert.Equal(t, now, w.start)
+
+	// 延迟入库的日志在窗口内补发，已发送的日志不重复
+	late := &SavedLog{Timestamp: now + 1, Offset: 2}
+	list = []*SavedLog{list[0], late, list[1], {Timestamp: now + 9*second, Offset: 1}}
+	assert.Equal(t, []*SavedLog{late, list[3]}, w.fresh(list))
+	w.advance(list, false, now+10*second)
+	assert.Equal(t, now+5*second, w.start)
+	assert.Len(t, w.seen, 1)
+
+	// 积压时从该批最后一条之后分页
+	list = []*SavedLog{{Timestamp: now + 6*second, Offset: 1}, {Timestamp: now + 7*second, Offset: 1}}
+	assert.Len(t, w.fresh(list), 2)
+	w.advance(list, true, now+20*second)
+	assert.Equal(t, now+7*second, w.start)
+	assert.Equal(t, &tailCursor{timestamp: now + 7*second, offset: 1}, w.page)
+	assert.Empty(t, w.fresh(list[1:]))
+
+	// 同一时间戳超过一批时在该时间戳内按 offset 继续分页
+	same := []*SavedLog{{Timestamp: now + 7*second, Offset: 2}, {Timestamp: now + 7*second, Offset: 3}}
+	assert.Equal(t, same, w.fresh(same))
+	w.advance(same, true, now+20*second)
+	assert.Equal(t, now+7*second, w.start)
+	assert.Equal(t, &tailCursor{timestamp: now + 7*second, offset: 3}, w.page)
+}
+
+func TestTailWindowFullThenPartial(t *testing.T) {
+	second := int64(time.Second)
+	now := 100 * second
+	w := newTailWindow(tailCursor{timestamp: now}, 5*time.Second, nil)
+
+	// 积压的一批取满，起点前进到该批最后一条
+	full := []*SavedLog{
+		{Timestamp: now + 1*second, Offset: 1},
+		{Timestamp: now + 3*second, Offset: 1},
+		{Timestamp: now + 4*second, Offset: 1},
+	}
+	assert.Equal(t, full, w.fresh(full))
+	w.advance(full, true, now+5*second)
+
+	partial := []*SavedLog{{Timestamp: now + 4*second, Offset: 2}}
+	assert.Equal(t, partial, w.fresh(partial))
+	w.advance(partial, false, now+7*second)
+	assert.Nil(t, w.page)
+	// 起点回退 lag 到积压起点之前，重新查到的日志不重复发送
+	assert.Equal(t, now+2*second, w.start)
+	again := []*SavedLog{full[1], full[2], partial[0], {Timestamp: now + 6*second, Offset: 1}}
+	assert.Equal(t, again[3:], w.fresh(again))
+}
+
+func TestTailWindowBackfill(t *testing.T) {
+	backfill := []*SavedLog{{Timestamp: 10, Offset: 3}, {Timestamp: 12, Offset: 1}}
+	w := newTailWindow(tailCursor{timestamp: 10, offset: 2}, time.Second, backfill)
+	list := []*SavedLog{{Timestamp: 10, Offset: 2}, backfill[0], {Timestamp: 11, Offset: 1}, backfill[1]}
+	assert.Equal(t, []*SavedLog{list[2]}, w.fresh(list))
+}
+
+type fakeTailWriter struct {
+	mu      sync.Mutex
+	lines   []*TailLine
+	release chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+func newFakeTailWriter() *fakeTailWriter {
+	return &fakeTailWriter{release: make(chan struct{}), done: make(chan struct{})}
+}
+
+// WriteLine 每写一行需要 release 放行，模拟消费慢的客户端
+func (tw *fakeTailWriter) WriteLine(line *TailLine) error {
+	select {
+	case <-tw.release:
+	case <-tw.done:
+		return errors.New("closed")
+	}
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.lines = append(tw.lines, line)
+	return nil
+}
+
+func (tw *fakeTailWriter) Ping() error           { return nil }
+func (tw *fakeTailWriter) Done() <-chan struct{} { return tw.done }
+func (tw *fakeTailWriter) Close() error          { return nil }
+
+func (tw *fakeTailWriter) written() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return len(tw.lines)
+}
+
+func newTailTestProvider(bufferSize int) *provider {
+	p := &provider{Cfg: &config{}, Logger: logrusx.New()}
+	p.Cfg.Tail.BufferSize = bufferSize
+	p.Cfg.Tail.PingInterval = time.Hour
+	p.Cfg.Tail.ResolveInterval = 10 * time.Millisecond
+	return p
+}
+
+// infiniteTail 不断产生日志，记录产生条数与退出的实例数
+func infiniteTail(produced, exited *int64) tailInstanceFunc {
+	return func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error {
+		defer atomic.AddInt64(exited, 1)
+		for i := 0; ; i++ {
+			select {
+			case lines <- &TailLine{Instance: meta.ID, Log: &Log{Content: fmt.Sprint(i)}}:
+				atomic.AddInt64(produced, 1)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func TestTailLogsBackpressure(t *testing.T) {
+	p := newTailTestProvider(4)
+	tw := newFakeTailWriter()
+	var produced, exited int64
+	instances := []*LogMeta{{Source: "container", ID: "c1"}, {Source: "container", ID: "c2"}}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		p.tailLogs(context.Background(), tw, instances, nil, infiniteTail(&produced, &exited))
+	}()
+
+	for i := 0; i < 10; i++ {
+		tw.release <- struct{}{}
+	}
+	time.Sleep(50 * time.Millisecond)
+	// 客户端消费慢时生产者阻塞：已产生 <= 已写出 + 缓冲 + 正在写的一条
+	written := tw.written()
+	assert.True(t, written >= 9, "written: %d", written)
+	assert.True(t, atomic.LoadInt64(&produced) <= int64(written+4+1), "produced: %d, written: %d", produced, written)
+
+	// 客户端断开后所有实例停止跟踪
+	close(tw.done)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("tailLogs did not return after client disconnected")
+	}
+	assert.Equal(t, int64(len(instances)), atomic.LoadInt64(&exited))
+}
+
+func TestTailLogsShutdown(t *testing.T) {
+	p := newTailTestProvider(1)
+	tw := newFakeTailWriter()
+	var produced, exited int64
+	instances := []*LogMeta{{Source: "container", ID: "c1"}, {Source: "container", ID: "c2"}, {Source: "container", ID: "c3"}}
+
+	// 客户端消费不受限，服务端取消时停止
+	close(tw.release)
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		p.tailLogs(ctx, tw, instances, nil, infiniteTail(&produced, &exited))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("tailLogs did not return after context canceled")
+	}
+	// 返回时所有实例的轮询都已退出
+	assert.Equal(t, int64(len(instances)), atomic.LoadInt64(&exited))
+	assert.True(t, tw.written() > 0)
+}
+
+func TestTailLogsAllInstancesExited(t *testing.T) {
+	p := newTailTestProvider(10)
+	tw := newFakeTailWriter()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		p.tailLogs(context.Background(), tw, []*LogMeta{{ID: "c1"}}, nil,
+			func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error {
+				return fmt.Errorf("instance %s not found", meta.ID)
+			})
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("tailLogs did not return after all instances exited")
+	}
+}
+
+func TestTailLogsResolve(t *testing.T) {
+	p := newTailTestProvider(10)
+	tw := newFakeTailWriter()
+
+	var mu sync.Mutex
+	initial := []*LogMeta{{ID: "c1"}}
+	current := initial
+	resolve := func() ([]*LogMeta, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, nil
+	}
+	var started sync.Map
+	stopped := make(chan string, 10)
+	tail := func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error {
+		started.Store(meta.ID, true)
+		<-ctx.Done()
+		stopped <- meta.ID
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		p.tailLogs(ctx, tw, initial, resolve, tail)
+	}()
+
+	// 实例被替换：新实例开始跟踪，旧实例停止跟踪
+	mu.Lock()
+	current = []*LogMeta{{ID: "c2"}}
+	mu.Unlock()
+	select {
+	case id := <-stopped:
+		assert.Equal(t, "c1", id)
+	case <-time.After(time.Second):
+		t.Fatal("stale instance was not stopped")
+	}
+	assert.Eventually(t, func() bool {
+		_, ok := started.Load("c2")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-finished
+	assert.Equal(t, "c2", <-stopped)
+}
+
+func TestTailLogsBackfillOnce(t *testing.T) {
+	p := newTailTestProvider(10)
+	tw := newFakeTailWriter()
+	instances := []*LogMeta{{ID: "c1"}}
+	resolve := func() ([]*LogMeta, error) { return instances, nil }
+
+	backfills := make(chan bool, 10)
+	var calls int64
+	tail := func(ctx context.Context, meta *LogMeta, backfill bool, lines chan<- *TailLine) error {
+		backfills <- backfill
+		if atomic.AddInt64(&calls, 1) == 1 {
+			return errors.New("query failed")
+		}
+		<-ctx.Done()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		p.tailLogs(ctx, tw, instances, resolve, tail)
+	}()
+
+	// 出错退出的实例重新跟踪时不再回填历史日志
+	for _, want := range []bool{true, false} {
+		select {
+		case backfill := <-backfills:
+			assert.Equal(t, want, backfill)
+		case <-time.After(time.Second):
+			t.Fatal("instance was not tailed")
+		}
+	}
+	cancel()
+	<-finished
+}
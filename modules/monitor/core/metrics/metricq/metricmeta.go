@@ -14,10 +14,12 @@
 package metricq
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/erda-project/erda-infra/modcom/api"
+	"github.com/erda-project/erda/modules/monitor/core/metrics"
 	"github.com/erda-project/erda/modules/monitor/core/metrics/metricq/metricmeta"
 )
 
@@ -48,6 +50,70 @@ func (p *provider) listMetricMeta(r *http.Request, params struct {
 	return api.Success(metrics)
 }
 
+func (p *provider) registerMetricMeta(params struct {
+	Scope   string `query:"scope" validate:"required"`
+	ScopeID string `query:"scopeId" validate:"required"`
+	Group   string `query:"group"`
+}, list []*metrics.MetricMeta) interface{} {
+	if err := checkMetricMetas(list); err != nil {
+		return api.Errors.InvalidParameter(err)
+	}
+	err := p.q.RegeistMetricMeta(params.Scope, params.ScopeID, params.Group, list...)
+	if err != nil {
+		return api.Errors.Internal(err)
+	}
+	return api.Success(nil)
+}
+
+func (p *provider) registerMetricGroups(params struct {
+	Scope   string `query:"scope" validate:"required"`
+	ScopeID string `query:"scopeId" validate:"required"`
+}, groups []*metricmeta.GroupDefine) interface{} {
+	if err := checkGroupDefines(groups); err != nil {
+		return api.Errors.InvalidParameter(err)
+	}
+	err := p.q.RegeistMetricGroup(params.Scope, params.ScopeID, groups...)
+	if err != nil {
+		return api.Errors.Internal(err)
+	}
+	return api.Success(nil)
+}
+
+func checkMetricMetas(list []*metrics.MetricMeta) error {
+	for _, m := range list {
+		if m == nil {
+			continue
+		}
+		if len(m.Name.Key) <= 0 {
+			return fmt.Errorf("metric name must not be empty")
+		}
+		for k, f := range m.Fields {
+			if f == nil {
+				return fmt.Errorf("field %q of metric %q is empty", k, m.Name.Key)
+			}
+			if len(f.Type) > 0 && !metricmeta.IsValidFieldType(f.Type) {
+				return fmt.Errorf("invalid type %q of field %q in metric %q", f.Type, k, m.Name.Key)
+			}
+		}
+	}
+	return nil
+}
+
+func checkGroupDefines(groups []*metricmeta.GroupDefine) error {
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		if len(g.ID) <= 0 {
+			return fmt.Errorf("group id must not be empty")
+		}
+		if strings.Contains(g.ID, "@") {
+			return fmt.Errorf("group id %q must not contain '@'", g.ID)
+		}
+	}
+	return nil
+}
+
 func (p *provider) listMetricGroups(r *http.Request, params struct {
 	Scope   string `query:"scope" validate:"required"`
 	ScopeID string `query:"scopeId" validate:"required"`
@@ -271,3 +271,11 @@ func appendMetricToGroup(groups []*Group, sep string, metricmeta map[string]*met
 	}
 	return groups
 }
+
+func (m *Manager) RegeistMetricGroup(scope, scopeID string, groups ...*GroupDefine) error {
+	return m.regeistMetricGroup(scope, scopeID, groups...)
+}
+
+func (m *Manager) UnregeistMetricGroup(scope, scopeID string, groups ...string) error {
+	return m.unregeistMetricGroup(scope, scopeID, groups...)
+}
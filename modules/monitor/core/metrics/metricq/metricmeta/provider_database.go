@@ -27,7 +27,8 @@ import (
 
 // tables name
 const (
-	TableMetricMeta = "sp_metric_meta"
+	TableMetricMeta  = "sp_metric_meta"
+	TableMetricGroup = "sp_metric_group"
 )
 
 type MetricMeta struct {
@@ -45,6 +46,30 @@ type MetricMeta struct {
 
 func (MetricMeta) TableName() string { return TableMetricMeta }
 
+type MetricGroup struct {
+	ID         int       `gorm:"column:id"`
+	Scope      string    `gorm:"column:scope"`
+	ScopeID    string    `gorm:"column:scope_id"`
+	GroupID    string    `gorm:"column:group_id"`
+	Parent     string    `gorm:"column:parent"`
+	Name       string    `gorm:"column:name"`
+	Order      int32     `gorm:"column:order"`
+	Mappings   string    `gorm:"column:mappings"`
+	CreateTime time.Time `gorm:"column:create_time"`
+	UpdateTime time.Time `gorm:"column:update_time"`
+}
+
+func (MetricGroup) TableName() string { return TableMetricGroup }
+
+// GroupDefine is the registration form of a metric group.
+type GroupDefine struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Parent   string            `json:"parent"`
+	Order    int32             `json:"order"`
+	Mappings []*GroupMetricMap `json:"mappings"`
+}
+
 type DatabaseGroupProvider struct {
 	db  *gorm.DB
 	log logs.Logger
@@ -58,6 +83,24 @@ func NewDatabaseGroupProvider(db *gorm.DB, log logs.Logger) (*DatabaseGroupProvi
 }
 
 func (p *DatabaseGroupProvider) MappingsByID(id, scope, scopeID string, names []string, ms map[string]*metrics.MetricMeta) (gmm []*GroupMetricMap, err error) {
+	if id != "log_metrics" {
+		var list []*MetricGroup
+		err := p.db.Table(TableMetricGroup).
+			Where("`scope`=? AND `scope_id`=? AND `group_id`=?", scope, scopeID, id).
+			Find(&list).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			mappings, err := convertGroupMappingsFromDB(item)
+			if err != nil {
+				p.log.Warn(err)
+				continue
+			}
+			gmm = append(gmm, filterGroupMappings(mappings, names)...)
+		}
+		return gmm, nil
+	}
 	for _, name := range names {
 		if mm, ok := ms[name]; ok {
 			if mm.Labels == nil || mm.Labels["_group"] != "log_metrics" {
@@ -71,6 +114,22 @@ func (p *DatabaseGroupProvider) MappingsByID(id, scope, scopeID string, names []
 	return gmm, nil
 }
 
+func filterGroupMappings(mappings []*GroupMetricMap, names []string) []*GroupMetricMap {
+	if len(names) <= 0 {
+		return mappings
+	}
+	var list []*GroupMetricMap
+	for _, m := range mappings {
+		for _, name := range names {
+			if m.Name == name {
+				list = append(list, m)
+				break
+			}
+		}
+	}
+	return list
+}
+
 func (p *DatabaseGroupProvider) Groups(langCodes i18n.LanguageCodes, t i18n.Translator, scope, scopeID string, ms map[string]*metrics.MetricMeta) (groups []*Group, err error) {
 	group := &Group{
 		ID:   "log_metrics",
@@ -86,7 +145,69 @@ func (p *DatabaseGroupProvider) Groups(langCodes i18n.LanguageCodes, t i18n.Tran
 		})
 	}
 	groups = append(groups, group)
-	return groups, nil
+
+	registered, err := p.registeredGroups(langCodes, t, scope, scopeID, ms)
+	if err != nil {
+		return nil, err
+	}
+	return appendGroups(groups, registered), nil
+}
+
+func (p *DatabaseGroupProvider) registeredGroups(langCodes i18n.LanguageCodes, t i18n.Translator, scope, scopeID string, ms map[string]*metrics.MetricMeta) ([]*Group, error) {
+	var list []*MetricGroup
+	err := p.db.Table(TableMetricGroup).
+		Where("`scope`=? AND `scope_id`=?", scope, scopeID).
+		Order("`order`, `id`").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	var (
+		roots    []*Group
+		groups   = make(map[string]*Group, len(list))
+		mappings = make(map[string][]*GroupMetricMap, len(list))
+	)
+	for _, item := range list {
+		groups[item.GroupID] = &Group{
+			ID:    item.GroupID,
+			Name:  t.Text(langCodes, item.Name),
+			Order: item.Order,
+		}
+		gmm, err := convertGroupMappingsFromDB(item)
+		if err != nil {
+			p.log.Warn(err)
+			continue
+		}
+		mappings[item.GroupID] = gmm
+	}
+	for _, item := range list {
+		g := groups[item.GroupID]
+		if parent, ok := groups[item.Parent]; ok && item.Parent != item.GroupID {
+			parent.Children = append(parent.Children, g)
+			continue
+		}
+		roots = append(roots, g)
+	}
+	return appendMetricToGroup(roots, "@", ms, mappings, false), nil
+}
+
+func convertGroupMappingsFromDB(item *MetricGroup) ([]*GroupMetricMap, error) {
+	if len(item.Mappings) <= 0 {
+		return nil, nil
+	}
+	var mappings []*GroupMetricMap
+	err := json.Unmarshal(reflectx.StringToBytes(item.Mappings), &mappings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mappings in %s=%s, group=%s: %s", item.Scope, item.ScopeID, item.GroupID, err)
+	}
+	for _, m := range mappings {
+		for _, f := range m.Filters {
+			if len(f.Op) <= 0 {
+				f.Op = "eq"
+			}
+		}
+	}
+	return mappings, nil
 }
 
 type DatabaseMetaProvider struct {
@@ -220,3 +341,47 @@ func (m *Manager) unregeistMetricMeta(scope, scopeID, group string, metrics ...s
 		Where("`scope`=? AND `scope_id`=? AND `group`=? AND `metric` IN (?)", scope, scopeID, group, metrics).
 		Delete(nil).Error
 }
+
+var metricGroupRegisterInsertUpdate = "INSERT INTO `" + TableMetricGroup + "`" +
+	"(`scope`,`scope_id`,`group_id`,`parent`,`name`,`order`,`mappings`,`create_time`,`update_time`) " +
+	"VALUES(?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `update_time`=VALUES(`update_time`),`parent`=VALUES(`parent`),`name`=VALUES(`name`),`order`=VALUES(`order`),`mappings`=VALUES(`mappings`)"
+
+func (m *Manager) regeistMetricGroup(scope, scopeID string, groups ...*GroupDefine) error {
+	db := m.db.Begin()
+	now := time.Now()
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		if len(g.ID) <= 0 {
+			db.Rollback()
+			return fmt.Errorf("group id must not be empty")
+		}
+		mappings, err := json.Marshal(g.Mappings)
+		if err != nil {
+			db.Rollback()
+			return fmt.Errorf("invalid mappings: %s", err)
+		}
+		err = db.Exec(metricGroupRegisterInsertUpdate,
+			scope,
+			scopeID,
+			g.ID,
+			g.Parent,
+			g.Name,
+			g.Order,
+			string(mappings),
+			now, now,
+		).Error
+		if err != nil {
+			db.Rollback()
+			return err
+		}
+	}
+	return db.Commit().Error
+}
+
+func (m *Manager) unregeistMetricGroup(scope, scopeID string, groups ...string) error {
+	return m.db.Table(TableMetricGroup).
+		Where("`scope`=? AND `scope_id`=? AND `group_id` IN (?)", scope, scopeID, groups).
+		Delete(nil).Error
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package metricmeta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterGroupMappings(t *testing.T) {
+	mappings := []*GroupMetricMap{{Name: "cpu"}, {Name: "mem"}, {Name: "disk"}}
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{
+			name: "no names",
+			want: []string{"cpu", "mem", "disk"},
+		},
+		{
+			name:  "filter",
+			names: []string{"disk", "cpu"},
+			want:  []string{"cpu", "disk"},
+		},
+		{
+			name:  "not found",
+			names: []string{"net"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range filterGroupMappings(mappings, tt.names) {
+				got = append(got, m.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertGroupMappingsFromDB(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings string
+		want     []*GroupMetricMap
+		wantErr  bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "default filter op",
+			mappings: `[{"name":"cpu","filters":[{"tag":"host","value":"a"},{"tag":"cluster","op":"neq","value":"b"}],"fields":["usage"]}]`,
+			want: []*GroupMetricMap{{
+				Name:    "cpu",
+				Filters: []*Filter{{Tag: "host", Op: "eq", Value: "a"}, {Tag: "cluster", Op: "neq", Value: "b"}},
+				Fields:  []string{"usage"},
+			}},
+		},
+		{
+			name:     "invalid json",
+			mappings: `{"name":"cpu"}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertGroupMappingsFromDB(&MetricGroup{Scope: "org", ScopeID: "erda", GroupID: "host", Mappings: tt.mappings})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsValidFieldType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want bool
+	}{
+		{typ: NumberType, want: true},
+		{typ: StringArrayType, want: true},
+		{typ: ""},
+		{typ: "float"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsValidFieldType(tt.typ), tt.typ)
+	}
+}
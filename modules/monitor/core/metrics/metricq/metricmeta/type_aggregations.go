@@ -59,6 +59,15 @@ const (
 	BoolArrayType   = "bool_array"
 )
 
+// IsValidFieldType .
+func IsValidFieldType(typ string) bool {
+	switch typ {
+	case NumberType, BoolType, StringType, NumberArrayType, StringArrayType, BoolArrayType:
+		return true
+	}
+	return false
+}
+
 // AggName .
 func (m *Manager) AggName(langCodes i18n.LanguageCodes, text string) string {
 	t := m.i18n.Translator("_type_aggregations")
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package metricq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/monitor/core/metrics"
+	"github.com/erda-project/erda/modules/monitor/core/metrics/metricq/metricmeta"
+)
+
+func TestCheckMetricMetas(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    []*metrics.MetricMeta
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "nil meta",
+			list: []*metrics.MetricMeta{nil},
+		},
+		{
+			name: "valid",
+			list: []*metrics.MetricMeta{{
+				Name:   metrics.NameDefine{Key: "cpu"},
+				Fields: map[string]*metrics.FieldDefine{"usage": {Type: metricmeta.NumberType}, "host": {}},
+			}},
+		},
+		{
+			name:    "empty name",
+			list:    []*metrics.MetricMeta{{Fields: map[string]*metrics.FieldDefine{"usage": {Type: metricmeta.NumberType}}}},
+			wantErr: true,
+		},
+		{
+			name: "nil field",
+			list: []*metrics.MetricMeta{{
+				Name:   metrics.NameDefine{Key: "cpu"},
+				Fields: map[string]*metrics.FieldDefine{"usage": nil},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid field type",
+			list: []*metrics.MetricMeta{{
+				Name:   metrics.NameDefine{Key: "cpu"},
+				Fields: map[string]*metrics.FieldDefine{"usage": {Type: "float"}},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMetricMetas(tt.list)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestCheckGroupDefines(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []*metricmeta.GroupDefine
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "nil group",
+			groups: []*metricmeta.GroupDefine{nil},
+		},
+		{
+			name:   "valid",
+			groups: []*metricmeta.GroupDefine{{ID: "host"}, {ID: "host_cpu", Parent: "host"}},
+		},
+		{
+			name:    "empty id",
+			groups:  []*metricmeta.GroupDefine{{ID: "host"}, {Name: "cpu"}},
+			wantErr: true,
+		},
+		{
+			name:    "id with separator",
+			groups:  []*metricmeta.GroupDefine{{ID: "host@cpu"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkGroupDefines(tt.groups)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
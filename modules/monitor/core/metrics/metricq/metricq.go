@@ -52,6 +52,8 @@ type Queryer interface {
 	GetSingleAggregationMeta(langCodes i18n.LanguageCodes, mode, name string) (*metricmeta.Aggregation, error)
 	RegeistMetricMeta(scope, scopeID, group string, metrics ...*metrics.MetricMeta) error
 	UnregeistMetricMeta(scope, scopeID, group string, metrics ...string) error
+	RegeistMetricGroup(scope, scopeID string, groups ...*metricmeta.GroupDefine) error
+	UnregeistMetricGroup(scope, scopeID string, groups ...string) error
 	MetricGroups(langCodes i18n.LanguageCodes, scope, scopeID, mode string) ([]*metricmeta.Group, error)
 	MetricGroup(langCodes i18n.LanguageCodes, scope, scopeID, group, mode, format string, appendTags bool) (*metricmeta.GroupDetail, error)
 
@@ -118,6 +120,16 @@ func (q *metricq) UnregeistMetricMeta(scope, scopeID, group string, metrics ...s
 	return q.meta.UnregeistMetricMeta(scope, scopeID, group, metrics...)
 }
 
+// RegeistMetricGroup .
+func (q *metricq) RegeistMetricGroup(scope, scopeID string, groups ...*metricmeta.GroupDefine) error {
+	return q.meta.RegeistMetricGroup(scope, scopeID, groups...)
+}
+
+// UnregeistMetricGroup .
+func (q *metricq) UnregeistMetricGroup(scope, scopeID string, groups ...string) error {
+	return q.meta.UnregeistMetricGroup(scope, scopeID, groups...)
+}
+
 // MetricGroups .
 func (q *metricq) MetricGroups(langCodes i18n.LanguageCodes, scope, scopeID, mode string) ([]*metricmeta.Group, error) {
 	return q.meta.MetricGroups(langCodes, scope, scopeID, mode)
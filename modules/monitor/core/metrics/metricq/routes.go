@@ -37,10 +37,10 @@ func (p *provider) initRoutes(routes httpserver.Router) error {
 	// metric meta
 	routes.GET("/api/metric/names", p.listMetricNames)
 	routes.GET("/api/metric/meta", p.listMetricMeta)
-	// routes.POST("/api/metric/meta", nil) // Register metric metadata，TODO .
+	routes.POST("/api/metric/meta", p.registerMetricMeta)
 	routes.GET("/api/metric/groups", p.listMetricGroups)
 	routes.GET("/api/metric/groups/:id", p.getMetricGroup)
-	// routes.POST("/api/metric/groups", nil) // Register for indicator grouping information，TODO .
+	routes.POST("/api/metric/groups", p.registerMetricGroups)
 	routes.GET("/api/metadata/groups", p.listMetricGroups)   // Reuse the previous interface path.
 	routes.GET("/api/metadata/groups/:id", p.getMetricGroup) // Reuse the previous interface path.
 	return nil
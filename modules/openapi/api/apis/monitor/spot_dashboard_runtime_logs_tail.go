@@ -0,0 +1,29 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package monitor
+
+import "github.com/erda-project/erda/modules/openapi/api/apis"
+
+var SPOT_RUNTIME_LOGS_TAIL = apis.ApiSpec{
+	Path:        "/api/runtime/logs/actions/tail",
+	BackendPath: "/api/runtime/logs/actions/tail",
+	Host:        "monitor.marathon.l4lb.thisdcos.directory:7096",
+	Scheme:      "ws",
+	Method:      "GET",
+	CheckLogin:  true,
+	CheckToken:  true,
+	// ws 代理在 openapi 完成登录与 token 鉴权、注入 User-ID 等 header 后透传 TCP 连接，
+	// WebSocket 升级请求与普通 GET 请求 (SSE) 均可使用，见 proxy/ws/ws_test.go
+	Doc: "summary: 实时跟踪Runtime日志（WebSocket/SSE）",
+}
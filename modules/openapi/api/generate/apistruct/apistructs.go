@@ -48,6 +48,8 @@ type requestParam struct {
 
 	// 只有 Typ = object 时存在 Schema
 	Schema *object `json:"schema,omitempty"`
+
+	Enum []string `json:"enum,omitempty"`
 }
 
 // responseParam 用于生成 response 参数
@@ -67,13 +69,17 @@ type object struct {
 	// 只有 Typ = array 时存在 Items
 	Items *object `json:"items,omitempty"`
 
+	// 可选值，来自字段的 enum tag，比如 `enum:"RUNNING,SUCCESS"`
+	Enum []string `json:"enum,omitempty"`
+
 	// 当前 object 所属的 node
 	node structparser.Node `json:"-"`
 }
 
 type walkInfo = object
 
-func structToParam(ctx context, tp interface{}) ([]requestParam, *responseParam) {
+// parseObject 解析 tp 的结构
+func parseObject(tp interface{}) walkInfo {
 	walkfunc := func(curr structparser.Node, children []structparser.Node) {
 		if s, ok := specialTypes[curr.TypeName()]; ok {
 			extra := curr.Extra()
@@ -175,6 +181,12 @@ func structToParam(ctx context, tp interface{}) ([]requestParam, *responseParam)
 		default:
 			panic(fmt.Sprintf("unreachable: node: %v", curr))
 		}
+		if enum := enumValues(curr); len(enum) > 0 {
+			extra := curr.Extra()
+			info := (*extra).(walkInfo)
+			info.Enum = enum
+			*extra = info
+		}
 	}
 	node := structparser.Parse(tp)
 	node = node.Compress()
@@ -184,7 +196,11 @@ func structToParam(ctx context, tp interface{}) ([]requestParam, *responseParam)
 	if !ok {
 		panic("unreachable")
 	}
+	return walkinfo
+}
 
+func structToParam(ctx context, tp interface{}) ([]requestParam, *responseParam) {
+	walkinfo := parseObject(tp)
 	if ctx.request {
 		requestParams := []requestParam{}
 		if walkinfo.Typ != "object" {
@@ -203,6 +219,7 @@ func structToParam(ctx context, tp interface{}) ([]requestParam, *responseParam)
 					false: nil}[properties.Typ == "array"],
 				Schema: &properties,
 				Format: properties.Format,
+				Enum:   properties.Enum,
 			})
 
 		}
@@ -224,6 +241,7 @@ func clearRequestFields(reqparams []requestParam) []requestParam {
 			Items:    map[bool]*object{true: reqparams[i].Items, false: nil}[reqparams[i].Typ == "array"],
 			Format:   reqparams[i].Format,
 			Schema:   map[bool]*object{true: reqparams[i].Schema, false: nil}[reqparams[i].Typ == "object"],
+			Enum:     reqparams[i].Enum,
 		})
 	}
 	return newReqParams
@@ -307,6 +325,18 @@ func ignore(n structparser.Node) bool {
 	return name(n) == ""
 }
 
+func enumValues(n structparser.Node) []string {
+	enum, ok := n.Tag().Lookup("enum")
+	if !ok || enum == "" {
+		return nil
+	}
+	var values []string
+	for _, v := range strutil.Split(enum, ",", true) {
+		values = append(values, strutil.Trim(v))
+	}
+	return values
+}
+
 func desc(n structparser.Node) string {
 	if n.Comment() != "" {
 		return n.Comment()
@@ -0,0 +1,214 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistruct
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/structparser"
+)
+
+// ErrorSchemaName 是 OpenAPI 3 文档中统一错误应答的 schema 名
+const ErrorSchemaName = "ErrorResponse"
+
+// NewOAS3 创建一个空的 OpenAPI 3 文档，包含统一的错误应答 schema
+func NewOAS3(title, version string) *openapi3.Swagger {
+	v3 := &openapi3.Swagger{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: title, Version: version},
+		Paths:   openapi3.Paths{},
+		Components: openapi3.Components{
+			Schemas: openapi3.Schemas{},
+		},
+	}
+	v3.Components.Schemas[ErrorSchemaName] = openapi3.NewSchemaRef("", toSchema(parseObject(apistructs.Header{})))
+	return v3
+}
+
+/*
+ToOAS3 将一个 API 添加到 OpenAPI 3 文档中
+path: URL path, 比如 /api/projects/<projectID>
+method: http method
+summary: 综合性描述
+req: 请求类型结构体
+resp: 应答类型结构体
+*/
+func ToOAS3(v3 *openapi3.Swagger, path, method, summary, group string, req, resp interface{}) {
+	method = strings.ToUpper(method)
+	oasPath, pathVars := OAS3Path(path)
+
+	operation := openapi3.NewOperation()
+	operation.Summary = strings.TrimSpace(strings.TrimPrefix(summary, "summary:"))
+	if group != "" {
+		operation.Tags = []string{group}
+	}
+
+	reqObject := parseObject(req)
+	var (
+		inPath   = map[string]bool{}
+		declared = map[string]bool{}
+		params   []*openapi3.Parameter
+		body     = openapi3.NewObjectSchema()
+	)
+	for _, v := range pathVars {
+		inPath[v] = true
+	}
+	for _, property := range reqObject.Properties {
+		if property.node == nil {
+			continue
+		}
+		in := requesttype(property.node)
+		if in == "body" && !methodHasBody(method) {
+			in = "query"
+		}
+		switch in {
+		case "path":
+			// 与 path 中的变量名不一致的参数无法填充，忽略
+			if !inPath[property.Name] || declared[property.Name] {
+				continue
+			}
+			declared[property.Name] = true
+			params = append(params, openapi3.NewPathParameter(property.Name).
+				WithDescription(property.Desc).WithSchema(toSchema(property)))
+		case "query":
+			params = append(params, openapi3.NewQueryParameter(queryName(property)).
+				WithDescription(property.Desc).WithSchema(toSchema(property)))
+		default:
+			body.Properties[property.Name] = openapi3.NewSchemaRef("", toSchema(property))
+		}
+	}
+	// 请求结构体中没有声明的 path 变量，也需要作为参数出现在文档中
+	for _, v := range pathVars {
+		if !declared[v] {
+			declared[v] = true
+			params = append(params, openapi3.NewPathParameter(v).WithSchema(openapi3.NewStringSchema()))
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].In != params[j].In {
+			return params[i].In < params[j].In
+		}
+		return params[i].Name < params[j].Name
+	})
+	for _, p := range params {
+		operation.AddParameter(p)
+	}
+	if len(body.Properties) > 0 {
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(body),
+		}
+	}
+
+	operation.Responses = openapi3.NewResponses()
+	okResp := openapi3.NewResponse().WithDescription("OK")
+	respSchema := toSchema(parseObject(resp))
+	if name := reflect.TypeOf(resp).Name(); name != "" {
+		v3.Components.Schemas[name] = openapi3.NewSchemaRef("", respSchema)
+		okResp.WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+name, respSchema))
+	} else {
+		okResp.WithJSONSchema(respSchema)
+	}
+	operation.Responses["200"] = &openapi3.ResponseRef{Value: okResp}
+	operation.Responses["default"] = &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription("Error").
+			WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+ErrorSchemaName,
+				v3.Components.Schemas[ErrorSchemaName].Value)),
+	}
+
+	pathItem, ok := v3.Paths[oasPath]
+	if !ok {
+		pathItem = &openapi3.PathItem{}
+		v3.Paths[oasPath] = pathItem
+	}
+	pathItem.SetOperation(method, operation)
+}
+
+// OAS3Path 将 /api/projects/<projectID>/<*> 转换为 /api/projects/{projectID}/{wildcard}，并返回其中的变量名
+func OAS3Path(path string) (string, []string) {
+	var vars []string
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "<") || !strings.HasSuffix(part, ">") {
+			continue
+		}
+		v := part[1 : len(part)-1]
+		if v == "*" {
+			v = "wildcard"
+		}
+		vars = append(vars, v)
+		parts[i] = "{" + v + "}"
+	}
+	return strings.Join(parts, "/"), vars
+}
+
+func methodHasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+		return false
+	}
+	return true
+}
+
+// queryName GET 请求的参数通常用 schema tag 解析
+func queryName(o object) string {
+	if _, ok := o.node.Tag().Lookup("query"); ok {
+		return o.Name
+	}
+	if name := strings.Split(o.node.Tag().Get("schema"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return o.Name
+}
+
+func toSchema(o object) *openapi3.Schema {
+	s := &openapi3.Schema{
+		Type:        o.Typ,
+		Format:      o.Format,
+		Description: o.Desc,
+	}
+	for _, e := range o.Enum {
+		if o.Typ == "integer" {
+			if i, err := strconv.ParseInt(e, 10, 64); err == nil {
+				s.Enum = append(s.Enum, i)
+				continue
+			}
+		}
+		s.Enum = append(s.Enum, e)
+	}
+	switch o.Typ {
+	case "array":
+		if o.Items != nil {
+			s.Items = openapi3.NewSchemaRef("", toSchema(*o.Items))
+		}
+	case "object":
+		if _, isMap := o.node.(*structparser.MapNode); isMap {
+			if v, ok := o.Properties["additionalProperties"]; ok {
+				s.AdditionalProperties = openapi3.NewSchemaRef("", toSchema(v))
+			}
+			break
+		}
+		s.Properties = openapi3.Schemas{}
+		for name, property := range o.Properties {
+			s.Properties[name] = openapi3.NewSchemaRef("", toSchema(property))
+		}
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package apistruct
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+type oas3TestListRequest struct {
+	OrgID    uint64 `schema:"orgId"`
+	Status   string `json:"status" enum:"RUNNING,SUCCESS"`
+	PageNo   int    `json:"pageNo"`
+	Unrelate string `path:"notInPath"`
+}
+
+type oas3TestUpdateRequest struct {
+	ProjectID uint64            `path:"projectID" json:"-"`
+	Name      string            `json:"name"`
+	Labels    map[string]string `json:"labels"`
+}
+
+type oas3TestResponse struct {
+	apistructs.Header
+	Data []string `json:"data"`
+}
+
+func TestToOAS3(t *testing.T) {
+	v3 := NewOAS3("test", "1.0")
+	ToOAS3(v3, "/api/projects", "GET", "summary: list projects", "projects", oas3TestListRequest{}, oas3TestResponse{})
+	ToOAS3(v3, "/api/projects/<projectID>", "PUT", "update project", "", oas3TestUpdateRequest{}, oas3TestResponse{})
+	ToOAS3(v3, "/api/files/<*>", "GET", "", "", struct{}{}, struct{}{})
+	assert.NoError(t, oas3.ValidateOAS3(gocontext.Background(), *v3))
+
+	list := v3.Paths["/api/projects"].Get
+	assert.Equal(t, "list projects", list.Summary)
+	assert.Nil(t, list.RequestBody)
+	status := list.Parameters.GetByInAndName("query", "status")
+	if assert.NotNil(t, status) {
+		assert.Equal(t, []interface{}{"RUNNING", "SUCCESS"}, status.Schema.Value.Enum)
+	}
+	assert.NotNil(t, list.Parameters.GetByInAndName("query", "orgId"))
+	assert.Nil(t, list.Parameters.GetByInAndName("path", "notInPath"))
+	assert.Equal(t, "#/components/schemas/oas3TestResponse", list.Responses["200"].Value.Content.Get("application/json").Schema.Ref)
+	assert.Equal(t, "#/components/schemas/"+ErrorSchemaName, list.Responses.Default().Value.Content.Get("application/json").Schema.Ref)
+
+	update := v3.Paths["/api/projects/{projectID}"].Put
+	assert.NotNil(t, update.Parameters.GetByInAndName("path", "projectID"))
+	body := update.RequestBody.Value.Content.Get("application/json").Schema.Value
+	assert.Contains(t, body.Properties, "name")
+	assert.NotNil(t, body.Properties["labels"].Value.AdditionalProperties)
+
+	files := v3.Paths["/api/files/{wildcard}"].Get
+	assert.NotNil(t, files.Parameters.GetByInAndName("path", "wildcard"))
+
+	errSchema := v3.Components.Schemas[ErrorSchemaName].Value
+	assert.Contains(t, errSchema.Properties, "success")
+	assert.Contains(t, errSchema.Properties, "err")
+
+	_, err := json.Marshal(v3)
+	assert.NoError(t, err)
+}
+
+func TestOAS3Path(t *testing.T) {
+	path, vars := OAS3Path("/api/orgs/<orgID>/members/<*>")
+	assert.Equal(t, "/api/orgs/{orgID}/members/{wildcard}", path)
+	assert.Equal(t, []string{"orgID", "wildcard"}, vars)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundlecheck 检查 bundle 中手写的 API 调用与 openapi 中的 API 定义是否一致
+package bundlecheck
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Call 是 bundle 中的一次 API 调用
+type Call struct {
+	Func   string
+	Pos    string
+	Method string
+	Path   string
+}
+
+// Spec 是 openapi 中定义的 API
+type Spec struct {
+	Method string
+	Path   string
+}
+
+var httpMethods = map[string]string{
+	"Get":    "GET",
+	"Post":   "POST",
+	"Put":    "PUT",
+	"Delete": "DELETE",
+	"Patch":  "PATCH",
+	"Head":   "HEAD",
+}
+
+// ParseBundle 解析 dir 下的 go 文件，找出形如 hc.Get(host).Path("/api/xxx") 的调用，
+// path 只能是字符串常量或 fmt.Sprintf 的结果，其他情况无法静态确定，忽略。
+func ParseBundle(dir string) ([]Call, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	var calls []Call
+	var filenames []string
+	files := map[string]*ast.File{}
+	for _, pkg := range pkgs {
+		for filename, f := range pkg.Files {
+			filenames = append(filenames, filename)
+			files[filename] = f
+		}
+	}
+	// 按文件名排序，保证输出稳定
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		for _, decl := range files[filename].Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := parsePathCall(n)
+				if ok {
+					call.Func = fn.Name.Name
+					pos := fset.Position(n.Pos())
+					call.Pos = filepath.Base(pos.Filename) + ":" + strconv.Itoa(pos.Line)
+					calls = append(calls, call)
+				}
+				return true
+			})
+		}
+	}
+	return calls, nil
+}
+
+func parsePathCall(n ast.Node) (Call, bool) {
+	pathCall, ok := n.(*ast.CallExpr)
+	if !ok || len(pathCall.Args) != 1 {
+		return Call{}, false
+	}
+	sel, ok := pathCall.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Path" {
+		return Call{}, false
+	}
+	methodCall, ok := sel.X.(*ast.CallExpr)
+	if !ok {
+		return Call{}, false
+	}
+	methodSel, ok := methodCall.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return Call{}, false
+	}
+	method, ok := httpMethods[methodSel.Sel.Name]
+	if !ok {
+		return Call{}, false
+	}
+	path, ok := literalPath(pathCall.Args[0])
+	if !ok {
+		return Call{}, false
+	}
+	return Call{Method: method, Path: path}, true
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+func literalPath(expr ast.Expr) (string, bool) {
+	switch v := expr.(type) {
+	case *ast.BasicLit:
+		if v.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(v.Value)
+		return s, err == nil
+	case *ast.CallExpr:
+		sel, ok := v.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Sprintf" || len(v.Args) == 0 {
+			return "", false
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "fmt" {
+			return "", false
+		}
+		format, ok := literalPath(v.Args[0])
+		if !ok {
+			return "", false
+		}
+		return formatVerb.ReplaceAllString(format, "<>"), true
+	}
+	return "", false
+}
+
+var pathVar = regexp.MustCompile(`<[^*>]*>`)
+
+// NormalizePath 去掉 query 和末尾的 /，并把所有变量统一为 <>
+func NormalizePath(path string) string {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return pathVar.ReplaceAllString(path, "<>")
+}
+
+// Check 返回 calls 中没有对应 API 定义的调用
+func Check(calls []Call, specs []Spec) []Call {
+	exact := map[string]bool{}
+	var wildcards []Spec
+	for _, spec := range specs {
+		path := NormalizePath(spec.Path)
+		if strings.HasSuffix(path, "/<*>") {
+			wildcards = append(wildcards, Spec{Method: spec.Method, Path: strings.TrimSuffix(path, "<*>")})
+			continue
+		}
+		exact[strings.ToUpper(spec.Method)+" "+path] = true
+	}
+	var drifts []Call
+	for _, call := range calls {
+		path := NormalizePath(call.Path)
+		if exact[call.Method+" "+path] || exact[" "+path] {
+			continue
+		}
+		matched := false
+		for _, w := range wildcards {
+			if (w.Method == "" || strings.EqualFold(w.Method, call.Method)) && strings.HasPrefix(path, w.Path) && len(path) > len(w.Path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			drifts = append(drifts, call)
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package bundlecheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBundleSource = `package bundle
+
+import "fmt"
+
+func (b *Bundle) GetProject(id uint64) {
+	b.hc.Get(host).Path(fmt.Sprintf("/api/projects/%d", id)).Do()
+}
+
+func (b *Bundle) ListProjects() {
+	b.hc.Get(host, httpclient.RetryOption{}).Path("/api/projects?joined=true").Do()
+}
+
+func (b *Bundle) DeleteFile(path string) {
+	b.hc.Delete(host).Path("/api/files/" + path).Do()
+	b.hc.Delete(host).Path(fmt.Sprintf("/api/files/%s/%s", "a", path)).Do()
+}
+
+func (b *Bundle) Stale() {
+	b.hc.Post(host).Path("/api/removed").Do()
+}
+`
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundlecheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "project.go"), []byte(testBundleSource), 0644))
+
+	calls, err := ParseBundle(dir)
+	assert.NoError(t, err)
+	// 字符串拼接的 path 无法静态确定，不在结果中
+	assert.Len(t, calls, 4)
+
+	drifts := Check(calls, []Spec{
+		{Method: "GET", Path: "/api/projects/<projectID>"},
+		{Method: "GET", Path: "/api/projects/"},
+		{Method: "", Path: "/api/files/<*>"},
+	})
+	if assert.Len(t, drifts, 1) {
+		assert.Equal(t, "Stale", drifts[0].Func)
+		assert.Equal(t, "POST", drifts[0].Method)
+		assert.Equal(t, "project.go:19", drifts[0].Pos)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "/api/projects/<>/apps/<>.json", NormalizePath("api/projects/<projectID>/apps/<>.json/?a=b"))
+	assert.Equal(t, "/", NormalizePath("/"))
+	assert.Equal(t, "/api/<*>", NormalizePath("/api/<*>"))
+}
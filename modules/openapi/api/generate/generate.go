@@ -29,12 +29,15 @@ import (
 
 //go:generate go run collect/collect.go
 //go:generate go run collectEvents/collectEvents.go
-//go:generate go run generate.go validate.go generate_doc.go generate_event_doc.go collectAPIs.go collectEvents.go
+//go:generate go run generate.go validate.go generate_doc.go generate_client.go generate_event_doc.go collectAPIs.go collectEvents.go
 func main() {
 	fmt.Println("generating api.go")
 	fmt.Println("generating swagger.json")
 	fmt.Println("generating swagger_all.json")
 	fmt.Println("generating events_all.json")
+	fmt.Println("generating openapi3.json")
+	fmt.Println("generating openapi3_all.json")
+	fmt.Println("generating bundle/apiclient/zz_generated_client.go")
 	var buf strings.Builder
 	trivialBegin(&buf)
 	for idx, api := range APIs {
@@ -69,6 +72,7 @@ func main() {
 			"MarathonHost":    quote(marathon),
 			"K8SHost":         quote(k8s),
 			"Port":            port,
+			"Group":           quote(docGroup(api)),
 		})
 	}
 	trivialEnd(&buf)
@@ -80,12 +84,16 @@ func main() {
 	generateDoc(true, "../swagger.json")
 	generateDoc(false, "../swagger_all.json")
 	generateEventDoc(false, "../events_all.json")
+	generateOAS3Doc(true, "../openapi3.json")
+	generateOAS3Doc(false, "../openapi3_all.json")
+	generateClient("../../../../bundle/apiclient/zz_generated_client.go")
+	checkBundle("../../../../bundle")
 
 	fmt.Println("rm generated_desc.go")
 	os.Remove("../../../../apistructs/generated_desc.go")
 }
 
-var SpecTemplate = template.Must(template.New("spec").Parse(`	{NewPath({{.Path}}), NewPath({{.BackendPath}}), {{.Host}}, {{.Scheme}}, {{.Method}}, {{.Custom}}, {{.CustomResponse}}, {{.Audit}}, {{.NeedDesensitize}}, {{.CheckLogin}}, {{.TryCheckLogin}}, {{.CheckToken}}, {{.CheckBasicAuth}}, {{.ChunkAPI}}, {{.MarathonHost}}, {{.K8SHost}}, {{.Port}}},
+var SpecTemplate = template.Must(template.New("spec").Parse(`	{NewPath({{.Path}}), NewPath({{.BackendPath}}), {{.Host}}, {{.Scheme}}, {{.Method}}, {{.Custom}}, {{.CustomResponse}}, {{.Audit}}, {{.NeedDesensitize}}, {{.CheckLogin}}, {{.TryCheckLogin}}, {{.CheckToken}}, {{.CheckBasicAuth}}, {{.ChunkAPI}}, {{.MarathonHost}}, {{.K8SHost}}, {{.Port}}, {{.Group}}},
 `))
 
 func convertHost(api *apis.ApiSpec) (marathon, k8s, port string, err error) {
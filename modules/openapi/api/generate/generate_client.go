@@ -0,0 +1,186 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/erda-project/erda/modules/openapi/api/apis"
+	"github.com/erda-project/erda/modules/openapi/api/generate/bundlecheck"
+)
+
+type clientMethod struct {
+	Name     string
+	Method   string
+	Path     string
+	Summary  string
+	Request  string
+	Response string
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by modules/openapi/api/generate. DO NOT EDIT.
+
+package apiclient
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// Routes 是所有生成的方法对应的 API
+var Routes = []Route{
+{{- range .Methods}}
+	{Name: "{{.Name}}", Method: "{{.Method}}", Path: "{{.Path}}"},
+{{- end}}
+}
+{{range .Methods}}
+// {{.Name}} {{.Method}} {{.Path}}{{if .Summary}}
+// {{.Summary}}{{end}}
+func (c *Client) {{.Name}}(req {{.Request}}) (*{{.Response}}, error) {
+	var resp {{.Response}}
+	if err := c.Do("{{.Method}}", "{{.Path}}", &req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+{{end}}`))
+
+// generateClient 为定义了 RequestType 和 ResponseType 的 API 生成类型化的 client 方法
+func generateClient(resultfile string) {
+	imports := map[string]string{} // pkgPath -> alias
+	aliases := map[string]string{} // alias -> pkgPath
+	typeExpr := func(t reflect.Type) (string, bool) {
+		if t.Kind() != reflect.Struct || t.Name() == "" || t.PkgPath() == "" {
+			return "", false
+		}
+		alias, ok := imports[t.PkgPath()]
+		if !ok {
+			alias = path.Base(t.PkgPath())
+			for i := 2; aliases[alias] != ""; i++ {
+				alias = fmt.Sprintf("%s%d", path.Base(t.PkgPath()), i)
+			}
+			imports[t.PkgPath()] = alias
+			aliases[alias] = t.PkgPath()
+		}
+		return alias + "." + t.Name(), true
+	}
+
+	var methods []clientMethod
+	names := map[string]bool{}
+	for idx, api := range APIs {
+		if api.Method == "" || api.RequestType == nil || api.ResponseType == nil {
+			continue
+		}
+		req, ok := typeExpr(reflect.TypeOf(api.RequestType))
+		if !ok {
+			continue
+		}
+		resp, ok := typeExpr(reflect.TypeOf(api.ResponseType))
+		if !ok {
+			continue
+		}
+		name := clientMethodName(APINames[idx], names)
+		names[name] = true
+		methods = append(methods, clientMethod{
+			Name:     name,
+			Method:   strings.ToUpper(api.Method),
+			Path:     clientPath(api),
+			Summary:  docSummary(api),
+			Request:  req,
+			Response: resp,
+		})
+	}
+
+	var importLines []string
+	for pkgPath, alias := range imports {
+		if alias == path.Base(pkgPath) {
+			importLines = append(importLines, fmt.Sprintf("%q", pkgPath))
+			continue
+		}
+		importLines = append(importLines, fmt.Sprintf("%s %q", alias, pkgPath))
+	}
+	sort.Strings(importLines)
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, map[string]interface{}{
+		"Imports": importLines,
+		"Methods": methods,
+	}); err != nil {
+		panic(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(resultfile, src, 0666); err != nil {
+		panic(err)
+	}
+}
+
+// clientMethodName cmdb.CMDB_PROJECT_LIST -> CmdbProjectList，重名时加上包名前缀
+func clientMethodName(apiName string, exists map[string]bool) string {
+	pkg, name := "", apiName
+	if idx := strings.LastIndex(apiName, "."); idx >= 0 {
+		pkg, name = apiName[:idx], apiName[idx+1:]
+	}
+	camel := func(s string) string {
+		var b strings.Builder
+		for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' }) {
+			b.WriteString(strings.Title(strings.ToLower(part)))
+		}
+		return b.String()
+	}
+	result := camel(name)
+	if exists[result] {
+		result = camel(pkg) + result
+	}
+	if exists[result] {
+		panic(fmt.Sprintf("duplicate client method name %s of %s", result, apiName))
+	}
+	return result
+}
+
+// clientPath client 直接调用后端服务，优先使用 BackendPath
+func clientPath(api apis.ApiSpec) string {
+	if api.BackendPath != "" {
+		return api.BackendPath
+	}
+	return api.Path
+}
+
+// checkBundle 检查 bundle 中手写的调用是否都有对应的 API 定义，输出不一致的调用
+func checkBundle(bundleDir string) {
+	calls, err := bundlecheck.ParseBundle(bundleDir)
+	if err != nil {
+		panic(err)
+	}
+	var specs []bundlecheck.Spec
+	for _, api := range APIs {
+		specs = append(specs, bundlecheck.Spec{Method: api.Method, Path: clientPath(api)})
+	}
+	drifts := bundlecheck.Check(calls, specs)
+	for _, d := range drifts {
+		fmt.Printf("[WARN] bundle %s (%s) calls %s %s which is not defined in openapi specs\n", d.Func, d.Pos, d.Method, d.Path)
+	}
+	fmt.Printf("bundle check: %d calls, %d not defined in openapi specs\n", len(calls), len(drifts))
+}
@@ -14,11 +14,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
+	"github.com/erda-project/erda/modules/openapi/api/apis"
 	"github.com/erda-project/erda/modules/openapi/api/generate/apistruct"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
 )
 
 var docJson = make(apistruct.JSON)
@@ -36,35 +41,9 @@ func generateDoc(onlyOpenapi bool, resultfile string) {
 		if !api.IsOpenAPI && onlyOpenapi {
 			continue
 		}
-		summary := ""
-		doc := strings.Split(strings.TrimSpace(api.Doc), "\n")
-		if len(doc) != 0 {
-			summary = doc[0]
-		}
-		var req, resp interface{}
-		if api.RequestType == nil {
-			req = struct{}{}
-		} else {
-			req = api.RequestType
-		}
-		if api.ResponseType == nil {
-			resp = struct{}{}
-		} else {
-			resp = api.ResponseType
-		}
-		//  如果Group为空，则默认为Path的第二部分
-		group := api.Group
-		if group == "" {
-			pathn := strings.Split(api.Path, "/")
-			if len(pathn) >= 1 && pathn[0] == "" {
-				pathn = pathn[1:]
-			}
-			if len(pathn) >= 2 {
-				group = pathn[1]
-			}
-		}
-
-		apistruct.ToJson(api.Path, strings.ToLower(api.Method), summary, group, docJson, req, resp)
+		summary := docSummary(api)
+		req, resp := docTypes(api)
+		apistruct.ToJson(api.Path, strings.ToLower(api.Method), summary, docGroup(api), docJson, req, resp)
 
 	}
 	docf, err := os.OpenFile(resultfile, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0666)
@@ -78,3 +57,62 @@ func generateDoc(onlyOpenapi bool, resultfile string) {
 	docf.Write(docJsonTxt)
 
 }
+
+// generateOAS3Doc 生成 OpenAPI 3.0 文档
+func generateOAS3Doc(onlyOpenapi bool, resultfile string) {
+	v3 := apistruct.NewOAS3("Erda OpenAPI", "1.0")
+	for _, api := range APIs {
+		if api.Method == "" {
+			continue
+		}
+		if !api.IsOpenAPI && onlyOpenapi {
+			continue
+		}
+		req, resp := docTypes(api)
+		apistruct.ToOAS3(v3, api.Path, api.Method, docSummary(api), docGroup(api), req, resp)
+	}
+	if err := oas3.ValidateOAS3(context.Background(), *v3); err != nil {
+		fmt.Printf("[WARN] %s is not a valid OpenAPI 3.0 document: %v\n", resultfile, err)
+	}
+	data, err := oas3.MarshalJsonIndent(v3, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(resultfile, data, 0666); err != nil {
+		panic(err)
+	}
+}
+
+func docSummary(api apis.ApiSpec) string {
+	doc := strings.Split(strings.TrimSpace(api.Doc), "\n")
+	if len(doc) != 0 {
+		return doc[0]
+	}
+	return ""
+}
+
+func docTypes(api apis.ApiSpec) (req, resp interface{}) {
+	req, resp = api.RequestType, api.ResponseType
+	if req == nil {
+		req = struct{}{}
+	}
+	if resp == nil {
+		resp = struct{}{}
+	}
+	return
+}
+
+// docGroup 如果Group为空，则默认为Path的第二部分
+func docGroup(api apis.ApiSpec) string {
+	if api.Group != "" {
+		return api.Group
+	}
+	pathn := strings.Split(api.Path, "/")
+	if len(pathn) >= 1 && pathn[0] == "" {
+		pathn = pathn[1:]
+	}
+	if len(pathn) >= 2 {
+		return pathn[1]
+	}
+	return ""
+}
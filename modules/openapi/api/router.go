@@ -0,0 +1,45 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+var (
+	router     *spec.Router
+	routerOnce sync.Once
+)
+
+// InitRouter 构建 API 的路由索引，需要在修改完 API 的 host 等信息之后调用
+func InitRouter() {
+	routerOnce.Do(func() {
+		router = spec.NewRouter(API)
+	})
+}
+
+// Find 查找请求对应的 spec
+func Find(req *http.Request) *spec.Spec {
+	InitRouter()
+	return router.Find(req)
+}
+
+// FindOriginPath 根据 Origin-Path header 查找对应的 spec
+func FindOriginPath(req *http.Request) *spec.Spec {
+	InitRouter()
+	return router.FindOriginPath(req)
+}
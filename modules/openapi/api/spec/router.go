@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package spec
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Router 是 APIs 的路由索引，启动时构建一次，按路径分段组织成前缀树。
+// 匹配结果与 APIs.Find 保持一致：多个 spec 同时匹配时，取在 APIs 中位置最靠前的那个。
+type Router struct {
+	apis APIs
+	root *routeNode
+	// 无法放入前缀树的 path (比如一个分段里混合了常量和变量)，仍然用正则匹配
+	fallback []int
+}
+
+type routeNode struct {
+	static map[string]*routeNode
+	param  *routeNode
+	// 以当前节点结尾的 spec 下标，升序
+	leaves []int
+	// 当前节点后跟 <*> 的 spec 下标，升序
+	wildcards []int
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{static: map[string]*routeNode{}}
+}
+
+// NewRouter 根据 apis 构建路由索引。
+// apis 中 spec 的 Path 和 Method 在构建之后不能再修改，其余字段可以修改 (比如 K8SHost)。
+func NewRouter(apis APIs) *Router {
+	r := &Router{apis: apis, root: newRouteNode()}
+	for i := range apis {
+		if apis[i].Path == nil || !r.insert(i, apis[i].Path.path) {
+			r.fallback = append(r.fallback, i)
+		}
+	}
+	return r
+}
+
+// Find 与 APIs.Find 语义相同
+func (r *Router) Find(req *http.Request) *Spec {
+	return r.find(req.Method, req.URL.EscapedPath())
+}
+
+// FindOriginPath 与 APIs.FindOriginPath 语义相同
+func (r *Router) FindOriginPath(req *http.Request) *Spec {
+	return r.find(req.Method, req.Header.Get("Origin-Path"))
+}
+
+func (r *Router) find(method, path string) *Spec {
+	path = polishPath(path)
+	best := -1
+	r.match(r.root, method, strings.Split(path, "/")[1:], 0, &best)
+	// 正则末尾的 [/]? 允许请求路径多出一个 /
+	if strings.HasSuffix(path, "/") {
+		trimmed := path[:len(path)-1]
+		r.match(r.root, method, strings.Split(polishPath(trimmed), "/")[1:], 0, &best)
+	}
+	for _, i := range r.fallback {
+		if best >= 0 && i >= best {
+			break
+		}
+		if r.matchSpec(i, method, path) {
+			best = i
+			break
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	spec := r.apis[best]
+	return &spec
+}
+
+// match 在前缀树中查找匹配 segs[pos:] 的 spec，best 记录目前找到的最小下标
+func (r *Router) match(n *routeNode, method string, segs []string, pos int, best *int) {
+	if pos == len(segs) {
+		r.pick(n.leaves, method, best)
+		return
+	}
+	// <*> 至少匹配一个字符
+	if len(n.wildcards) > 0 && strings.Join(segs[pos:], "/") != "" {
+		r.pick(n.wildcards, method, best)
+	}
+	if child, ok := n.static[segs[pos]]; ok {
+		r.match(child, method, segs, pos+1, best)
+	}
+	if n.param != nil && segs[pos] != "" {
+		r.match(n.param, method, segs, pos+1, best)
+	}
+}
+
+// pick 从升序的 candidates 中选出第一个 method 匹配且比 best 更靠前的 spec
+func (r *Router) pick(candidates []int, method string, best *int) {
+	for _, i := range candidates {
+		if *best >= 0 && i >= *best {
+			return
+		}
+		if NewMatcher(&r.apis[i]).MatchMethod(method) {
+			*best = i
+			return
+		}
+	}
+}
+
+func (r *Router) matchSpec(i int, method, path string) bool {
+	m := NewMatcher(&r.apis[i])
+	return m.MatchMethod(method) && m.MatchPath(path)
+}
+
+// insert 把 path 插入前缀树，path 无法用分段表示时返回 false
+func (r *Router) insert(i int, path string) bool {
+	segs := strings.Split(path, "/")[1:]
+	for j, seg := range segs {
+		switch segmentKind(seg) {
+		case segWildcard:
+			if j != len(segs)-1 {
+				return false
+			}
+		case segOther:
+			return false
+		}
+	}
+	n := r.root
+	for _, seg := range segs {
+		switch segmentKind(seg) {
+		case segWildcard:
+			n.wildcards = append(n.wildcards, i)
+			return true
+		case segParam:
+			if n.param == nil {
+				n.param = newRouteNode()
+			}
+			n = n.param
+		default:
+			child, ok := n.static[seg]
+			if !ok {
+				child = newRouteNode()
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+	n.leaves = append(n.leaves, i)
+	return true
+}
+
+const (
+	segStatic = iota
+	segParam
+	segWildcard
+	segOther
+)
+
+func segmentKind(seg string) int {
+	if seg == "<*>" {
+		return segWildcard
+	}
+	if strings.HasPrefix(seg, "<") && strings.HasSuffix(seg, ">") &&
+		!strings.ContainsAny(seg[1:len(seg)-1], "<>*") {
+		return segParam
+	}
+	// path 会被原样拼进正则，含有正则元字符的分段交给正则匹配
+	if strings.ContainsAny(seg, "<>") || regexp.QuoteMeta(seg) != seg {
+		return segOther
+	}
+	return segStatic
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterFind(t *testing.T) {
+	apis := APIs{
+		{Path: NewPath("/api/projects/<projectID>"), Method: "GET"},
+		{Path: NewPath("/api/projects/actions"), Method: "GET"},
+		{Path: NewPath("/api/projects/<projectID>"), Method: "DELETE"},
+		{Path: NewPath("/api/projects/<projectID>/<*>")},
+		{Path: NewPath("/api/apps/<appID>.json"), Method: "GET"},
+		{Path: NewPath("/api/apps/<appID>"), Method: "get"},
+		{Path: NewPath("/api/files/"), Method: "GET"},
+		{Path: NewPath("/<*>"), Method: "POST"},
+	}
+	r := NewRouter(apis)
+
+	cases := []struct {
+		method string
+		path   string
+		expect int
+	}{
+		{"GET", "/api/projects/1", 0},
+		{"GET", "/api/projects/1/", 0},
+		{"GET", "/api/projects/actions", 0}, // 前面的 spec 优先
+		{"DELETE", "/api/projects/1", 2},
+		{"PUT", "/api/projects/1", -1},
+		{"PUT", "/api/projects/1/a/b", 3},
+		{"PUT", "/api/projects/1/", -1},
+		{"GET", "/api/projects//", -1},
+		{"GET", "/api/apps/1.json", 4},
+		{"GET", "/api/apps/1", 5},
+		{"GET", "/api/files/", 6},
+		{"GET", "/api/files//", 6},
+		{"GET", "/api/files", -1},
+		{"POST", "/api/projects/1", 7},
+		{"POST", "/x", 7},
+		{"POST", "/", -1},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, "http://127.0.0.1"+c.path, nil)
+		got := r.Find(req)
+		expect := apis.Find(req)
+		if c.expect < 0 {
+			assert.Nil(t, expect, c.path)
+			assert.Nil(t, got, c.method+" "+c.path)
+			continue
+		}
+		assert.Equal(t, apis[c.expect].Path.String(), expect.Path.String(), c.method+" "+c.path)
+		if assert.NotNil(t, got, c.method+" "+c.path) {
+			assert.Equal(t, expect.Path.String(), got.Path.String(), c.method+" "+c.path)
+			assert.Equal(t, expect.Method, got.Method, c.method+" "+c.path)
+		}
+	}
+}
+
+func TestRouterFindOriginPath(t *testing.T) {
+	apis := APIs{{Path: NewPath("/api/orgs/<orgID>"), Method: "GET"}}
+	r := NewRouter(apis)
+	req, _ := http.NewRequest("GET", "http://127.0.0.1/anything", nil)
+	req.Header.Set("Origin-Path", "/api/orgs/1")
+	assert.NotNil(t, r.FindOriginPath(req))
+	assert.Nil(t, r.Find(req))
+}
+
+func TestRouterSameAsAPIs(t *testing.T) {
+	apis, reqs := benchmarkAPIs()
+	r := NewRouter(apis)
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		for _, req := range reqs {
+			req.Method = method
+			expect, got := apis.Find(req), r.Find(req)
+			if expect == nil {
+				assert.Nil(t, got)
+				continue
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, expect.Path.String(), got.Path.String())
+				assert.Equal(t, expect.Method, got.Method)
+			}
+		}
+	}
+}
+
+func benchmarkAPIs() (APIs, []*http.Request) {
+	var apis APIs
+	for i := 0; i < 200; i++ {
+		prefix := fmt.Sprintf("/api/resource%d", i)
+		apis = append(apis,
+			Spec{Path: NewPath(prefix), Method: "GET"},
+			Spec{Path: NewPath(prefix), Method: "POST"},
+			Spec{Path: NewPath(prefix + "/<id>"), Method: "GET"},
+			Spec{Path: NewPath(prefix + "/<id>"), Method: "PUT"},
+			Spec{Path: NewPath(prefix + "/<id>/actions/<*>")},
+		)
+	}
+	var reqs []*http.Request
+	for _, i := range []int{0, 50, 100, 150, 199} {
+		for _, p := range []string{"", "/1", "/1/actions/a/b"} {
+			req, _ := http.NewRequest("GET", fmt.Sprintf("http://127.0.0.1/api/resource%d%s", i, p), nil)
+			reqs = append(reqs, req)
+		}
+	}
+	return apis, reqs
+}
+
+func BenchmarkAPIsFind(b *testing.B) {
+	apis, reqs := benchmarkAPIs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		apis.Find(reqs[i%len(reqs)])
+	}
+}
+
+func BenchmarkRouterFind(b *testing.B) {
+	apis, reqs := benchmarkAPIs()
+	r := NewRouter(apis)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Find(reqs[i%len(reqs)])
+	}
+}
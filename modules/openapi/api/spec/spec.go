@@ -32,7 +32,7 @@ import (
 
 type APIs []Spec
 
-// TODO: add cache
+// Find 顺序遍历查找，请求处理路径上请使用 Router
 func (o APIs) Find(req *http.Request) *Spec {
 	for _, spec := range o {
 		m := NewMatcher(&spec)
@@ -121,6 +121,8 @@ type Spec struct {
 	MarathonHost string
 	K8SHost      string
 	Port         int
+	// API 分类，用于限流等按分类的配置
+	Group string
 }
 
 func (s *Spec) Validate() error {
@@ -19,14 +19,17 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-redis/redis"
 	"github.com/pkg/errors"
 
+	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/openapi/api/spec"
 	"github.com/erda-project/erda/modules/openapi/conf"
 	"github.com/erda-project/erda/modules/openapi/monitor"
 	"github.com/erda-project/erda/modules/openapi/oauth2"
+	"github.com/erda-project/erda/modules/openapi/pat"
 	"github.com/erda-project/erda/pkg/ucauth"
 )
 
@@ -55,6 +58,10 @@ const (
 type Auth struct {
 	RedisCli     *redis.Client
 	OAuth2Server *oauth2.OAuth2Server
+
+	// 校验个人访问令牌，并查询请求所属的企业以及资源所属的企业和项目
+	patBdl         patBundle
+	resolvedScopes sync.Map
 }
 
 func NewAuth(oauth2server *oauth2.OAuth2Server) (*Auth, error) {
@@ -67,7 +74,11 @@ func NewAuth(oauth2server *oauth2.OAuth2Server) (*Auth, error) {
 	if _, err := RedisCli.Ping().Result(); err != nil {
 		return nil, err
 	}
-	return &Auth{RedisCli: RedisCli, OAuth2Server: oauth2server}, nil
+	return &Auth{
+		RedisCli:     RedisCli,
+		OAuth2Server: oauth2server,
+		patBdl:       bundle.New(bundle.WithCMDB(), bundle.WithPipeline(), bundle.WithOrchestrator()),
+	}, nil
 }
 
 func (a *Auth) Auth(spec *spec.Spec, req *http.Request) AuthResult {
@@ -168,6 +179,10 @@ func whichCheck(req *http.Request, spec *spec.Spec) (checkType, error) {
 		return LOGIN, nil
 	}
 	auth := req.Header.Get("Authorization")
+	// 个人访问令牌代表用户本人，需要登录的 API 也可以使用
+	if (spec.CheckToken || spec.CheckLogin) && pat.IsPersonalAccessToken(auth) {
+		return TOKEN, nil
+	}
 	if spec.CheckBasicAuth && strings.HasPrefix(auth, "Basic ") {
 		return BASICAUTH, nil
 	}
@@ -188,9 +203,14 @@ func (a *Auth) checkLogin(req *http.Request, user *User, spec *spec.Spec) AuthRe
 }
 
 // checkToken try:
+// 0. personal access token
 // 1. uc token
 // 2. openapi oauth2 token
 func (a *Auth) checkToken(spec *spec.Spec, req *http.Request) (TokenClient, AuthResult) {
+	// 0. personal access token
+	if pat.IsPersonalAccessToken(req.Header.Get(HeaderAuthorization)) {
+		return a.checkPersonalAccessToken(spec, req)
+	}
 	// 1. uc token
 	ucTC, err := VerifyUCClientToken(req.Header.Get(HeaderAuthorization))
 	if err == nil {
@@ -0,0 +1,321 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+	"github.com/erda-project/erda/modules/openapi/pat"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/httputil"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// PersonalAccessTokenPathPrefix 个人访问令牌管理 API 的前缀，这些 API 不能用个人访问令牌访问
+const PersonalAccessTokenPathPrefix = "/api/openapi/personal-access-tokens"
+
+// maxScopeBodySize 解析请求 body 中企业和项目的最大长度，超过时有范围限制的令牌直接拒绝
+const maxScopeBodySize = 4 << 20
+
+// 请求中可以确定企业和项目的资源类型
+const (
+	scopeKindOrg      = "org"
+	scopeKindProject  = "project"
+	scopeKindApp      = "app"
+	scopeKindPipeline = "pipeline"
+	scopeKindRuntime  = "runtime"
+)
+
+// scopeKeys 参数名（小写）对应的资源类型
+var scopeKeys = map[string]string{
+	"orgid":         scopeKindOrg,
+	"projectid":     scopeKindProject,
+	"appid":         scopeKindApp,
+	"applicationid": scopeKindApp,
+	"pipelineid":    scopeKindPipeline,
+	"runtimeid":     scopeKindRuntime,
+}
+
+// scopeIDPathPrefixes path 变量为 <id> 时，根据前一段路径确定资源类型
+var scopeIDPathPrefixes = map[string]string{
+	"orgs":         scopeKindOrg,
+	"projects":     scopeKindProject,
+	"applications": scopeKindApp,
+	"pipelines":    scopeKindPipeline,
+	"runtimes":     scopeKindRuntime,
+}
+
+// patBundle 校验个人访问令牌，并查询请求所属的企业以及资源所属的企业和项目
+type patBundle interface {
+	VerifyPersonalAccessToken(token string) (*apistructs.PersonalAccessToken, error)
+	GetOrg(idOrName interface{}) (*apistructs.OrgDTO, error)
+	GetOrgByDomain(domain string, userID string) (*apistructs.OrgDTO, error)
+	ScopeRoleAccess(userID string, req *apistructs.ScopeRoleAccessRequest) (*apistructs.ScopeRole, error)
+	GetProject(id uint64) (*apistructs.ProjectDTO, error)
+	GetApp(id uint64) (*apistructs.ApplicationDTO, error)
+	GetPipeline(pipelineID uint64) (*apistructs.PipelineDetailDTO, error)
+	GetRuntimeServices(runtimeID uint64, orgID uint64, userID string) (*bundle.GetRuntimeServicesResponseData, error)
+}
+
+// resolvedScope 资源所属的企业和项目，资源不会在项目之间移动，解析结果可以一直缓存
+type resolvedScope struct {
+	orgID     uint64
+	projectID uint64
+}
+
+// requestScope 请求中出现的资源，key 为资源类型
+type requestScope map[string][]string
+
+func (s requestScope) add(kind, id string) {
+	if kind == "" || id == "" || id == "0" {
+		return
+	}
+	s[kind] = strutil.DedupSlice(append(s[kind], id))
+}
+
+// checkPersonalAccessToken 通过 cmdb 校验个人访问令牌的有效期和范围，通过后以令牌所属用户的身份访问
+func (a *Auth) checkPersonalAccessToken(spec *spec.Spec, req *http.Request) (TokenClient, AuthResult) {
+	if strings.HasPrefix(spec.Path.String(), PersonalAccessTokenPathPrefix) {
+		return TokenClient{}, AuthResult{AuthFail, "personal access token can not be used to manage personal access tokens"}
+	}
+	if a.patBdl == nil {
+		return TokenClient{}, AuthResult{InternalAuthErr, "personal access token verifier not configured"}
+	}
+	token, err := a.patBdl.VerifyPersonalAccessToken(pat.TrimBearer(req.Header.Get(HeaderAuthorization)))
+	if err != nil {
+		if apiErr, ok := err.(*errorresp.APIError); ok && apiErr.HttpCode() == http.StatusUnauthorized {
+			return TokenClient{}, AuthResult{Unauthed, err.Error()}
+		}
+		return TokenClient{}, AuthResult{InternalAuthErr, err.Error()}
+	}
+	orgID, r := a.requestOrg(req, token)
+	if r.Code != AuthSucc {
+		return TokenClient{}, r
+	}
+	var orgIDs, projectIDs []string
+	if orgID != 0 {
+		orgIDs = append(orgIDs, strconv.FormatUint(orgID, 10))
+	}
+	if len(token.OrgIDs) > 0 || len(token.ProjectIDs) > 0 {
+		scope, err := requestScopeIDs(spec, req)
+		if err != nil {
+			return TokenClient{}, AuthResult{AuthFail, err.Error()}
+		}
+		resolvedOrgIDs, resolvedProjectIDs, err := a.resolveScope(scope, token.UserID)
+		if err != nil {
+			return TokenClient{}, AuthResult{AuthFail, err.Error()}
+		}
+		orgIDs = strutil.DedupSlice(append(orgIDs, resolvedOrgIDs...))
+		projectIDs = resolvedProjectIDs
+	}
+	if err := pat.Allow(token, req.Method, orgIDs, projectIDs); err != nil {
+		return TokenClient{}, AuthResult{AuthFail, err.Error()}
+	}
+
+	req.Header.Set(httputil.UserHeader, token.UserID)
+	if orgID != 0 {
+		req.Header.Set(httputil.OrgHeader, strconv.FormatUint(orgID, 10))
+	}
+	return TokenClient{
+		ClientID:   fmt.Sprintf("pat-%d", token.ID),
+		ClientName: token.Name,
+	}, AuthResult{AuthSucc, ""}
+}
+
+// requestOrg 和登录用户一样，根据 ORG header 或域名确定请求所属的企业，都无法确定时使用令牌唯一的企业；
+// 令牌所属用户必须是该企业的成员，返回 0 表示请求不属于任何企业
+func (a *Auth) requestOrg(req *http.Request, token *apistructs.PersonalAccessToken) (uint64, AuthResult) {
+	var orgID uint64
+	if orgHeader := req.Header.Get("ORG"); orgHeader != "" {
+		org, err := a.patBdl.GetOrg(orgHeader)
+		if err != nil {
+			return 0, AuthResult{InternalAuthErr, err.Error()}
+		}
+		orgID = org.ID
+	} else {
+		domain := strutil.Split(req.Host, ":")[0]
+		org, err := a.patBdl.GetOrgByDomain(domain, token.UserID)
+		if err != nil {
+			return 0, AuthResult{InternalAuthErr, err.Error()}
+		}
+		if org != nil {
+			orgID = org.ID
+		}
+	}
+	if orgID == 0 && len(token.OrgIDs) == 1 {
+		orgID = token.OrgIDs[0]
+	}
+	if orgID == 0 {
+		return 0, AuthResult{AuthSucc, ""}
+	}
+	role, err := a.patBdl.ScopeRoleAccess(token.UserID, &apistructs.ScopeRoleAccessRequest{
+		Scope: apistructs.Scope{
+			Type: apistructs.OrgScope,
+			ID:   strconv.FormatUint(orgID, 10),
+		},
+	})
+	if err != nil {
+		return 0, AuthResult{InternalAuthErr, err.Error()}
+	}
+	if !role.Access {
+		return 0, AuthResult{AuthFail, fmt.Sprintf("access denied: userID: %v, orgID: %v", token.UserID, orgID)}
+	}
+	return orgID, AuthResult{AuthSucc, ""}
+}
+
+// requestScopeIDs 从 path 变量、query 参数和 JSON body 中找出请求涉及的资源
+func requestScopeIDs(spec *spec.Spec, req *http.Request) (requestScope, error) {
+	scope := make(requestScope)
+	vars := spec.Path.Vars(req.URL.Path)
+	parts := strings.Split(strings.Trim(spec.Path.String(), "/"), "/")
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "<") || !strings.HasSuffix(part, ">") {
+			continue
+		}
+		name := part[1 : len(part)-1]
+		kind := scopeKeys[strings.ToLower(name)]
+		if name == "id" && i > 0 {
+			kind = scopeIDPathPrefixes[parts[i-1]]
+		}
+		scope.add(kind, vars[name])
+	}
+	for k, values := range req.URL.Query() {
+		for _, v := range values {
+			scope.add(scopeKeys[strings.ToLower(k)], v)
+		}
+	}
+	if err := collectBodyScopeIDs(req, scope); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// collectBodyScopeIDs 递归查找 JSON body 中的资源 ID，读取后恢复 body 供后端使用
+func collectBodyScopeIDs(req *http.Request, scope requestScope) error {
+	if req.Body == nil || req.Body == http.NoBody || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxScopeBodySize+1))
+	if err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+	if len(body) > maxScopeBodySize {
+		return errors.Errorf("request body is too large to check the scope of personal access token")
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	walkScopeIDs(v, "", scope)
+	return nil
+}
+
+func walkScopeIDs(v interface{}, key string, scope requestScope) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			walkScopeIDs(child, k, scope)
+		}
+	case []interface{}:
+		for _, child := range value {
+			walkScopeIDs(child, key, scope)
+		}
+	case string:
+		scope.add(scopeKeys[strings.ToLower(key)], value)
+	case float64:
+		scope.add(scopeKeys[strings.ToLower(key)], strconv.FormatFloat(value, 'f', -1, 64))
+	}
+}
+
+// resolveScope 将项目、应用、流水线和部署实例解析为所属的企业和项目，无法解析到企业时拒绝访问
+func (a *Auth) resolveScope(scope requestScope, userID string) (orgIDs, projectIDs []string, err error) {
+	orgIDs = scope[scopeKindOrg]
+	for _, kind := range []string{scopeKindProject, scopeKindApp, scopeKindPipeline, scopeKindRuntime} {
+		for _, id := range scope[kind] {
+			resolved, err := a.resolveResource(kind, id, userID)
+			if err != nil {
+				return nil, nil, errors.Errorf("failed to determine the org of %s %s, err: %v", kind, id, err)
+			}
+			orgIDs = append(orgIDs, strconv.FormatUint(resolved.orgID, 10))
+			projectIDs = append(projectIDs, strconv.FormatUint(resolved.projectID, 10))
+		}
+	}
+	return strutil.DedupSlice(orgIDs), strutil.DedupSlice(projectIDs), nil
+}
+
+func (a *Auth) resolveResource(kind, id, userID string) (resolvedScope, error) {
+	cacheKey := kind + "/" + id
+	if v, ok := a.resolvedScopes.Load(cacheKey); ok {
+		return v.(resolvedScope), nil
+	}
+	resourceID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return resolvedScope{}, errors.Errorf("invalid id")
+	}
+	if a.patBdl == nil {
+		return resolvedScope{}, errors.Errorf("scope resolver not configured")
+	}
+	var resolved resolvedScope
+	switch kind {
+	case scopeKindProject:
+		resolved = resolvedScope{projectID: resourceID}
+	case scopeKindApp:
+		app, err := a.patBdl.GetApp(resourceID)
+		if err != nil {
+			return resolvedScope{}, err
+		}
+		resolved = resolvedScope{orgID: app.OrgID, projectID: app.ProjectID}
+	case scopeKindPipeline:
+		p, err := a.patBdl.GetPipeline(resourceID)
+		if err != nil {
+			return resolvedScope{}, err
+		}
+		resolved = resolvedScope{orgID: p.OrgID, projectID: p.ProjectID}
+	case scopeKindRuntime:
+		runtime, err := a.patBdl.GetRuntimeServices(resourceID, 0, userID)
+		if err != nil {
+			return resolvedScope{}, err
+		}
+		resolved = resolvedScope{projectID: runtime.ProjectID}
+	}
+	if resolved.projectID == 0 {
+		return resolvedScope{}, errors.Errorf("not belong to any project")
+	}
+	// 项目以及没有带企业信息的资源，通过所属项目确定企业
+	if resolved.orgID == 0 {
+		project, err := a.patBdl.GetProject(resolved.projectID)
+		if err != nil {
+			return resolvedScope{}, err
+		}
+		resolved.orgID = project.OrgID
+	}
+	if resolved.orgID == 0 {
+		return resolvedScope{}, errors.Errorf("not belong to any org")
+	}
+	a.resolvedScopes.Store(cacheKey, resolved)
+	return resolved, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/httputil"
+)
+
+type fakePATBundle struct{}
+
+func (fakePATBundle) VerifyPersonalAccessToken(token string) (*apistructs.PersonalAccessToken, error) {
+	switch token {
+	case "erda_pat_expired":
+		return nil, errorresp.New().NotLogin()
+	case "erda_pat_unrestricted":
+		return &apistructs.PersonalAccessToken{ID: 1, Name: "ci", UserID: "2",
+			Permissions: []string{apistructs.PersonalAccessTokenPermissionWrite}}, nil
+	case "erda_pat_org":
+		return &apistructs.PersonalAccessToken{ID: 2, Name: "ci", UserID: "2", OrgIDs: []uint64{1},
+			Permissions: []string{apistructs.PersonalAccessTokenPermissionWrite}}, nil
+	}
+	return nil, errors.New("cmdb unavailable")
+}
+
+func (fakePATBundle) GetOrg(idOrName interface{}) (*apistructs.OrgDTO, error) {
+	switch idOrName {
+	case "erda":
+		return &apistructs.OrgDTO{ID: 1, Name: "erda"}, nil
+	case "other":
+		return &apistructs.OrgDTO{ID: 2, Name: "other"}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (fakePATBundle) GetOrgByDomain(domain string, userID string) (*apistructs.OrgDTO, error) {
+	if domain == "erda.example.com" {
+		return &apistructs.OrgDTO{ID: 1, Name: "erda"}, nil
+	}
+	return nil, nil
+}
+
+func (fakePATBundle) ScopeRoleAccess(userID string, req *apistructs.ScopeRoleAccessRequest) (*apistructs.ScopeRole, error) {
+	// 用户 2 只是企业 1 和 2 的成员
+	return &apistructs.ScopeRole{Access: req.Scope.ID == "1" || req.Scope.ID == "2"}, nil
+}
+
+func (fakePATBundle) GetProject(id uint64) (*apistructs.ProjectDTO, error) {
+	switch id {
+	case 404:
+		return nil, errors.New("not found")
+	case 11:
+		return &apistructs.ProjectDTO{ID: id, OrgID: 2}, nil
+	}
+	return &apistructs.ProjectDTO{ID: id, OrgID: 1}, nil
+}
+
+func (fakePATBundle) GetApp(id uint64) (*apistructs.ApplicationDTO, error) {
+	if id == 404 {
+		return nil, errors.New("not found")
+	}
+	return &apistructs.ApplicationDTO{ID: id, OrgID: 1, ProjectID: 10}, nil
+}
+
+func (fakePATBundle) GetPipeline(pipelineID uint64) (*apistructs.PipelineDetailDTO, error) {
+	var p apistructs.PipelineDetailDTO
+	p.OrgID = 1
+	p.ProjectID = 20
+	return &p, nil
+}
+
+func (fakePATBundle) GetRuntimeServices(runtimeID uint64, orgID uint64, userID string) (*bundle.GetRuntimeServicesResponseData, error) {
+	return &bundle.GetRuntimeServicesResponseData{ID: runtimeID, ProjectID: 30}, nil
+}
+
+func TestRequestScope(t *testing.T) {
+	a := &Auth{patBdl: fakePATBundle{}}
+	for _, c := range []struct {
+		path, url, body    string
+		orgIDs, projectIDs []string
+		wantErr            bool
+	}{
+		{"/api/projects/<projectId>/members", "/api/projects/10/members?orgId=1", "", []string{"1"}, []string{"10"}, false},
+		{"/api/projects/<id>", "/api/projects/11", "", []string{"2"}, []string{"11"}, false},
+		{"/api/projects/<id>", "/api/projects/404", "", nil, nil, true},
+		{"/api/applications/<applicationId>/actions", "/api/applications/3/actions", "", []string{"1"}, []string{"10"}, false},
+		{"/api/pipelines/<pipelineID>/actions/run", "/api/pipelines/5/actions/run", "", []string{"1"}, []string{"20"}, false},
+		{"/api/runtimes/<runtimeId>", "/api/runtimes/7", "", []string{"1"}, []string{"30"}, false},
+		{"/api/runtimes", "/api/runtimes", `{"name":"master","extra":{"projectId":10,"applicationId":3}}`, []string{"1"}, []string{"10"}, false},
+		{"/api/releases", "/api/releases", `{"releaseId":"x"}`, nil, nil, false},
+		{"/api/applications/<id>", "/api/applications/404", "", nil, nil, true},
+	} {
+		req := httptest.NewRequest(http.MethodPost, c.url, strings.NewReader(c.body))
+		if c.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		scope, err := requestScopeIDs(&spec.Spec{Path: spec.NewPath(c.path)}, req)
+		assert.NoError(t, err)
+		orgIDs, projectIDs, err := a.resolveScope(scope, "1")
+		if c.wantErr {
+			assert.Error(t, err, c.url)
+			continue
+		}
+		assert.NoError(t, err, c.url)
+		assert.ElementsMatch(t, c.orgIDs, orgIDs, c.url)
+		assert.ElementsMatch(t, c.projectIDs, projectIDs, c.url)
+		if c.body != "" {
+			// body 被读取后仍然可以转发给后端
+			b, _ := ioutil.ReadAll(req.Body)
+			assert.Equal(t, c.body, string(b))
+		}
+	}
+}
+
+func TestCheckPersonalAccessToken(t *testing.T) {
+	a := &Auth{patBdl: fakePATBundle{}}
+	for _, c := range []struct {
+		name, token, path, url, orgHeader string
+		code                              int
+		orgID                             string
+	}{
+		{"expired", "erda_pat_expired", "/api/projects/<id>", "/api/projects/10", "", Unauthed, ""},
+		{"cmdb error", "erda_pat_unknown", "/api/projects/<id>", "/api/projects/10", "", InternalAuthErr, ""},
+		{"manage tokens", "erda_pat_unrestricted", "/api/openapi/personal-access-tokens", "/api/openapi/personal-access-tokens", "", AuthFail, ""},
+		{"unrestricted with org header", "erda_pat_unrestricted", "/api/releases", "/api/releases", "erda", AuthSucc, "1"},
+		{"unrestricted without org", "erda_pat_unrestricted", "/api/releases", "/api/releases", "", AuthSucc, ""},
+		{"org token falls back to its org", "erda_pat_org", "/api/releases", "/api/releases", "", AuthSucc, "1"},
+		{"org token with other org header", "erda_pat_org", "/api/releases", "/api/releases", "other", AuthFail, ""},
+		{"org token with project of other org", "erda_pat_org", "/api/projects/<id>", "/api/projects/11", "", AuthFail, ""},
+		{"org token with project of its org", "erda_pat_org", "/api/projects/<id>", "/api/projects/10", "", AuthSucc, "1"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, c.url, nil)
+		req.Header.Set(HeaderAuthorization, "Bearer "+c.token)
+		if c.orgHeader != "" {
+			req.Header.Set("ORG", c.orgHeader)
+		}
+		_, r := a.checkPersonalAccessToken(&spec.Spec{Path: spec.NewPath(c.path)}, req)
+		assert.Equal(t, c.code, r.Code, c.name)
+		if c.code == AuthSucc {
+			assert.Equal(t, "2", req.Header.Get(httputil.UserHeader), c.name)
+			assert.Equal(t, c.orgID, req.Header.Get(httputil.OrgHeader), c.name)
+		}
+	}
+}
@@ -52,6 +52,15 @@ type Conf struct {
 	OAuth2NetdataDir string `env:"OAUTH2_NETDATA_DIR" default:"/oauth2/"`
 
 	CSRFWhiteList string `env:"CSRF_WHITE_LIST"`
+
+	// 限流配置，格式: default=10:20;pipeline=5:10，表示 Spec group=每秒令牌数:桶容量，为空时不限流
+	RateLimits string `env:"RATE_LIMITS"`
+	// 认证之前每个客户端 IP 的限流，格式: 50:100，表示每秒令牌数:桶容量，为空时不限流
+	RateLimitPerIP string `env:"RATE_LIMIT_PER_IP"`
+	// memory: 单实例限流; redis: 多实例共享限流
+	RateLimitMode string `env:"RATE_LIMIT_MODE" default:"memory"`
+	// openapi 前面可信代理（如 ingress）的层数，限流时从 X-Forwarded-For 右侧取客户端 IP，为 0 时使用 RemoteAddr
+	RateLimitTrustedProxies int `env:"RATE_LIMIT_TRUSTED_PROXIES" default:"1"`
 }
 
 var cfg Conf
@@ -205,3 +214,19 @@ func GetUCRedirectHost(referer string) string {
 
 	return ""
 }
+
+func RateLimits() string {
+	return cfg.RateLimits
+}
+
+func RateLimitPerIP() string {
+	return cfg.RateLimitPerIP
+}
+
+func RateLimitMode() string {
+	return cfg.RateLimitMode
+}
+
+func RateLimitTrustedProxies() int {
+	return cfg.RateLimitTrustedProxies
+}
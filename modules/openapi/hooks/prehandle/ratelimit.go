@@ -0,0 +1,338 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package prehandle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/pkg/httputil"
+)
+
+const (
+	RateLimitModeMemory = "memory"
+	RateLimitModeRedis  = "redis"
+
+	// DefaultRateLimitGroup 没有单独配置的 group 使用 default 的配置
+	DefaultRateLimitGroup = "default"
+
+	rateLimitRedisKeyPrefix = "openapi:ratelimit:"
+)
+
+// RateLimitRule 令牌桶配置，每秒补充 Rate 个令牌，桶容量为 Burst
+type RateLimitRule struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitResult 一次取令牌的结果
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset 令牌桶恢复满的时间
+	Reset time.Duration
+	// RetryAfter 被拒绝时，下一个令牌可用的时间
+	RetryAfter time.Duration
+}
+
+// RateLimiter 从 key 对应的令牌桶中取一个令牌
+type RateLimiter interface {
+	Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+}
+
+var (
+	rateLimiter RateLimiter
+	rateLimits  map[string]RateLimitRule
+	// ipRateLimit 认证之前按客户端 IP 的限流，为 nil 时不限流
+	ipRateLimit *RateLimitRule
+	// trustedProxies openapi 前面可信代理的层数，用于从 X-Forwarded-For 中取客户端 IP
+	trustedProxies int
+)
+
+// InitRateLimit 初始化限流配置，rules 与 ipRule 为空时不限流
+// rules 格式: default=10:20;pipeline=5:10，表示 group=每秒令牌数:桶容量
+// ipRule 格式: 50:100，认证之前每个客户端 IP 的每秒令牌数:桶容量
+// proxies 为可信代理层数，为 0 时忽略 X-Forwarded-For，直接使用 RemoteAddr
+func InitRateLimit(rules, ipRule, mode string, proxies int, redisCli *redis.Client) error {
+	if proxies < 0 {
+		return errors.Errorf("invalid trusted proxies of rate limit: %d", proxies)
+	}
+	parsed, err := ParseRateLimitRules(rules)
+	if err != nil {
+		return err
+	}
+	var parsedIPRule *RateLimitRule
+	if ipRule = strings.TrimSpace(ipRule); ipRule != "" {
+		rule, err := ParseRateLimitRule(ipRule)
+		if err != nil {
+			return errors.Errorf("invalid ip rate limit rule: %s", ipRule)
+		}
+		parsedIPRule = &rule
+	}
+	switch mode {
+	case "", RateLimitModeMemory:
+		rateLimiter = NewMemoryRateLimiter()
+	case RateLimitModeRedis:
+		if redisCli == nil {
+			return errors.New("redis rate limit mode requires redis client")
+		}
+		rateLimiter = NewRedisRateLimiter(redisCli)
+	default:
+		return errors.Errorf("invalid rate limit mode: %s", mode)
+	}
+	rateLimits = parsed
+	ipRateLimit = parsedIPRule
+	trustedProxies = proxies
+	return nil
+}
+
+// RateLimitByIP 按客户端 IP 限流，在认证之前调用，避免未认证的请求压垮认证依赖的服务
+func RateLimitByIP(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	if ipRateLimit == nil || rateLimiter == nil {
+		return nil
+	}
+	return takeRateLimit(rw, "client-ip:"+clientIP(req, trustedProxies), *ipRateLimit)
+}
+
+// RateLimit 按用户、token 或客户端 IP 限流，需要在认证之后调用，group 为 Spec 的分组
+func RateLimit(ctx context.Context, rw http.ResponseWriter, req *http.Request, group string) error {
+	rule, ok := rateLimits[group]
+	if !ok {
+		rule, ok = rateLimits[DefaultRateLimitGroup]
+	}
+	if !ok || rateLimiter == nil {
+		return nil
+	}
+	return takeRateLimit(rw, RateLimitKey(req)+":"+group, rule)
+}
+
+// takeRateLimit 取令牌并设置 RateLimit-* header，被拒绝时返回 429
+func takeRateLimit(rw http.ResponseWriter, key string, rule RateLimitRule) error {
+	r, err := rateLimiter.Take(key, rule, time.Now())
+	if err != nil {
+		// 限流存储不可用时放行，不影响正常请求
+		logrus.Errorf("failed to take rate limit token, key: %s, err: %v", key, err)
+		return nil
+	}
+	rw.Header().Set("RateLimit-Limit", strconv.Itoa(rule.Burst))
+	rw.Header().Set("RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	rw.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(r.Reset)))
+	if r.Allowed {
+		return nil
+	}
+	rw.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(r.RetryAfter)))
+	err = fmt.Errorf("rate limit exceeded, retry after %ds", ceilSeconds(r.RetryAfter))
+	http.Error(rw, err.Error(), http.StatusTooManyRequests)
+	return err
+}
+
+// RateLimitKey 优先使用 token (Client-ID)，其次是用户，最后是客户端 IP
+func RateLimitKey(req *http.Request) string {
+	if clientID := req.Header.Get(httputil.ClientIDHeader); clientID != "" {
+		return "token:" + clientID
+	}
+	if userID := req.Header.Get(httputil.UserHeader); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + clientIP(req, trustedProxies)
+}
+
+// clientIP X-Forwarded-For 最左边的地址可以被客户端伪造，只信任最后 proxies 层代理追加的地址，
+// 即从右往左第 proxies 个地址；地址不足或不合法时使用 RemoteAddr
+func clientIP(req *http.Request, proxies int) string {
+	if forwarded := req.Header.Values("X-Forwarded-For"); proxies > 0 && len(forwarded) > 0 {
+		addrs := strings.Split(strings.Join(forwarded, ","), ",")
+		if len(addrs) >= proxies {
+			if ip := strings.TrimSpace(addrs[len(addrs)-proxies]); net.ParseIP(ip) != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// ParseRateLimitRules 解析 default=10:20;pipeline=5:10
+func ParseRateLimitRules(s string) (map[string]RateLimitRule, error) {
+	rules := map[string]RateLimitRule{}
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid rate limit rule: %s", item)
+		}
+		rule, err := ParseRateLimitRule(kv[1])
+		if err != nil {
+			return nil, errors.Errorf("%v of rate limit rule: %s", err, item)
+		}
+		rules[strings.TrimSpace(kv[0])] = rule
+	}
+	return rules, nil
+}
+
+// ParseRateLimitRule 解析 10:20，表示每秒令牌数:桶容量，省略桶容量时为每秒令牌数向上取整
+func ParseRateLimitRule(s string) (RateLimitRule, error) {
+	limit := strings.SplitN(s, ":", 2)
+	rate, err := strconv.ParseFloat(strings.TrimSpace(limit[0]), 64)
+	if err != nil || rate <= 0 {
+		return RateLimitRule{}, errors.New("invalid rate")
+	}
+	burst := int(math.Ceil(rate))
+	if len(limit) == 2 {
+		if burst, err = strconv.Atoi(strings.TrimSpace(limit[1])); err != nil || burst <= 0 {
+			return RateLimitRule{}, errors.New("invalid burst")
+		}
+	}
+	return RateLimitRule{Rate: rate, Burst: burst}, nil
+}
+
+// takeToken 根据上次的令牌数和时间补充令牌，并尝试取出一个
+func takeToken(rule RateLimitRule, tokens float64, elapsed time.Duration) (float64, RateLimitResult) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(rule.Burst), tokens+elapsed.Seconds()*rule.Rate)
+	}
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, makeRateLimitResult(rule, tokens, allowed)
+}
+
+func makeRateLimitResult(rule RateLimitRule, tokens float64, allowed bool) RateLimitResult {
+	r := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(rule.Burst) - tokens) / rule.Rate * float64(time.Second)),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) / rule.Rate * float64(time.Second))
+	}
+	return r
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type bucket struct {
+	rule   RateLimitRule
+	tokens float64
+	last   time.Time
+}
+
+type memoryRateLimiter struct {
+	sync.Mutex
+	buckets   map[string]*bucket
+	lastClean time.Time
+}
+
+// NewMemoryRateLimiter 单实例内存限流
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{buckets: map[string]*bucket{}}
+}
+
+func (l *memoryRateLimiter) Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.clean(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{rule: rule, tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = b
+	}
+	var r RateLimitResult
+	b.tokens, r = takeToken(rule, b.tokens, now.Sub(b.last))
+	b.rule, b.last = rule, now
+	return r, nil
+}
+
+// clean 定期清理已经恢复满的令牌桶，避免 key 无限增长
+func (l *memoryRateLimiter) clean(now time.Time) {
+	if now.Sub(l.lastClean) < time.Minute {
+		return
+	}
+	l.lastClean = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rule.Rate >= float64(b.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// 令牌桶状态保存在 hash 中，过期时间为恢复满所需的时间
+var rateLimitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+type redisRateLimiter struct {
+	cli *redis.Client
+}
+
+// NewRedisRateLimiter 多实例共享的 redis 限流
+func NewRedisRateLimiter(cli *redis.Client) RateLimiter {
+	return &redisRateLimiter{cli: cli}
+}
+
+func (l *redisRateLimiter) Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	v, err := rateLimitScript.Run(l.cli, []string{rateLimitRedisKeyPrefix + key},
+		rule.Rate, rule.Burst, now.UnixNano()/int64(time.Millisecond)).Result()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	values, ok := v.([]interface{})
+	if !ok || len(values) != 2 {
+		return RateLimitResult{}, errors.Errorf("unexpected rate limit script result: %v", v)
+	}
+	allowed, _ := values[0].(int64)
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return makeRateLimitResult(rule, tokens, allowed == 1), nil
+}
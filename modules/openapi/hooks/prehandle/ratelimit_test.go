@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package prehandle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	rules, err := ParseRateLimitRules("default=10:20; pipeline=0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, RateLimitRule{Rate: 10, Burst: 20}, rules["default"])
+	assert.Equal(t, RateLimitRule{Rate: 0.5, Burst: 1}, rules["pipeline"])
+
+	rules, err = ParseRateLimitRules("")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, invalid := range []string{"default", "default=x", "default=0", "default=1:0"} {
+		_, err := ParseRateLimitRules(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	l := NewMemoryRateLimiter()
+	rule := RateLimitRule{Rate: 1, Burst: 2}
+	now := time.Now()
+
+	r, _ := l.Take("a", rule, now)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+	r, _ = l.Take("a", rule, now)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Equal(t, 2*time.Second, r.Reset)
+
+	r, _ = l.Take("a", rule, now.Add(500*time.Millisecond))
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 500*time.Millisecond, r.RetryAfter)
+
+	// 其他 key 不受影响
+	r, _ = l.Take("b", rule, now)
+	assert.True(t, r.Allowed)
+
+	r, _ = l.Take("a", rule, now.Add(time.Second))
+	assert.True(t, r.Allowed)
+}
+
+func TestRateLimit(t *testing.T) {
+	assert.NoError(t, InitRateLimit("default=1:1;pipeline=100:100", "", RateLimitModeMemory, 1, nil))
+	defer func() { rateLimiter, rateLimits = nil, nil }()
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest("GET", "/api/projects", nil)
+		req.Header.Set("User-ID", "1")
+		return req
+	}
+	rw := httptest.NewRecorder()
+	assert.NoError(t, RateLimit(context.Background(), rw, newReq(), "cmdb"))
+	assert.Equal(t, "1", rw.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rw.Header().Get("RateLimit-Remaining"))
+
+	rw = httptest.NewRecorder()
+	assert.Error(t, RateLimit(context.Background(), rw, newReq(), "cmdb"))
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "1", rw.Header().Get("Retry-After"))
+
+	// 不同 group 的令牌桶相互独立
+	rw = httptest.NewRecorder()
+	assert.NoError(t, RateLimit(context.Background(), rw, newReq(), "pipeline"))
+	assert.Equal(t, "99", rw.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	assert.Error(t, InitRateLimit("", "x", RateLimitModeMemory, 1, nil))
+	assert.NoError(t, InitRateLimit("", "1:2", RateLimitModeMemory, 1, nil))
+	defer func() { rateLimiter, rateLimits, ipRateLimit = nil, nil, nil }()
+
+	newReq := func(ip string) *http.Request {
+		req := httptest.NewRequest("GET", "/api/projects", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", ip)
+		return req
+	}
+	for i := 0; i < 2; i++ {
+		rw := httptest.NewRecorder()
+		assert.NoError(t, RateLimitByIP(context.Background(), rw, newReq("1.1.1.1")))
+	}
+	rw := httptest.NewRecorder()
+	assert.Error(t, RateLimitByIP(context.Background(), rw, newReq("1.1.1.1")))
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+
+	// 不同客户端 IP 的令牌桶相互独立
+	assert.NoError(t, RateLimitByIP(context.Background(), httptest.NewRecorder(), newReq("2.2.2.2")))
+	// 未配置分组限流时认证之后不限流
+	assert.NoError(t, RateLimit(context.Background(), httptest.NewRecorder(), newReq("1.1.1.1"), "cmdb"))
+}
+
+func TestRateLimitKey(t *testing.T) {
+	trustedProxies = 1
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "ip:10.0.0.1", RateLimitKey(req))
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 10.0.0.2")
+	assert.Equal(t, "ip:10.0.0.2", RateLimitKey(req))
+	req.Header.Set("User-ID", "2")
+	assert.Equal(t, "user:2", RateLimitKey(req))
+	req.Header.Set("Client-ID", "pat-1")
+	assert.Equal(t, "token:pat-1", RateLimitKey(req))
+}
+
+func TestClientIP(t *testing.T) {
+	cases := []struct {
+		forwarded []string
+		proxies   int
+		ip        string
+	}{
+		{nil, 1, "10.0.0.1"},
+		{[]string{"1.1.1.1"}, 0, "10.0.0.1"}, // no trusted proxy
+		{[]string{"1.1.1.1"}, 1, "1.1.1.1"},
+		{[]string{"6.6.6.6, 1.1.1.1"}, 1, "1.1.1.1"}, // spoofed by client
+		{[]string{"6.6.6.6, 1.1.1.1, 10.0.0.2"}, 2, "1.1.1.1"},
+		{[]string{"6.6.6.6", "1.1.1.1, 10.0.0.2"}, 2, "1.1.1.1"}, // multiple headers
+		{[]string{"1.1.1.1"}, 2, "10.0.0.1"},                     // less than trusted proxies
+		{[]string{"unknown"}, 1, "10.0.0.1"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for _, v := range c.forwarded {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		assert.Equal(t, c.ip, clientIP(req, c.proxies), "forwarded: %v, proxies: %d", c.forwarded, c.proxies)
+	}
+}
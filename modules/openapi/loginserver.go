@@ -48,6 +48,9 @@ func NewLoginServer() (*LoginServer, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := prehandle.InitRateLimit(conf.RateLimits(), conf.RateLimitPerIP(), conf.RateLimitMode(), conf.RateLimitTrustedProxies(), auth.RedisCli); err != nil {
+		return nil, err
+	}
 	bdl := bundle.New(
 		bundle.WithCMDB(),
 		bundle.WithPipeline(),
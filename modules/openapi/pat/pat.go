@@ -0,0 +1,31 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pat 个人访问令牌 (personal access token) 的识别和范围校验，令牌由 cmdb 保存和校验
+package pat
+
+import (
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// IsPersonalAccessToken 判断 Authorization header 中的 token 是否为个人访问令牌
+func IsPersonalAccessToken(auth string) bool {
+	return strings.HasPrefix(TrimBearer(auth), apistructs.PersonalAccessTokenPrefix)
+}
+
+// TrimBearer 去掉 Authorization header 中的 Bearer 前缀，返回令牌明文
+func TrimBearer(auth string) string {
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestIsPersonalAccessToken(t *testing.T) {
+	token := apistructs.PersonalAccessTokenPrefix + "0123456789abcdef"
+	assert.True(t, IsPersonalAccessToken(token))
+	assert.True(t, IsPersonalAccessToken("Bearer "+token))
+	assert.False(t, IsPersonalAccessToken("Bearer xxx"))
+	assert.Equal(t, token, TrimBearer("Bearer "+token))
+}
+
+func TestAllow(t *testing.T) {
+	token := &apistructs.PersonalAccessToken{
+		Name:        "ci",
+		OrgIDs:      []uint64{1},
+		Permissions: []string{apistructs.PersonalAccessTokenPermissionRead},
+	}
+	assert.NoError(t, Allow(token, "GET", []string{"1"}, []string{"100"}))
+	assert.Error(t, Allow(token, "POST", []string{"1"}, nil))
+	assert.Error(t, Allow(token, "GET", []string{"2"}, nil))
+	assert.Error(t, Allow(token, "GET", []string{"x"}, nil))
+
+	token.Permissions = append(token.Permissions, apistructs.PersonalAccessTokenPermissionWrite)
+	token.ProjectIDs = []uint64{100}
+	assert.NoError(t, Allow(token, "DELETE", nil, []string{"100"}))
+	assert.Error(t, Allow(token, "DELETE", nil, []string{"101"}))
+	// 无法确定项目时拒绝
+	assert.Error(t, Allow(token, "GET", []string{"1"}, nil))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pat
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// Allow 校验令牌是否允许访问 method 指定的请求，orgIDs/projectIDs 是请求中出现的企业和项目
+func Allow(t *apistructs.PersonalAccessToken, method string, orgIDs, projectIDs []string) error {
+	if !allowMethod(t.Permissions, method) {
+		return errors.Errorf("personal access token %q has no permission to %s", t.Name, method)
+	}
+	for _, orgID := range orgIDs {
+		if !containsID(t.OrgIDs, orgID) {
+			return errors.Errorf("personal access token %q is not permitted to access org %s", t.Name, orgID)
+		}
+	}
+	// 限定了项目的令牌只能访问能确定所属项目的请求
+	if len(t.ProjectIDs) > 0 && len(projectIDs) == 0 {
+		return errors.Errorf("personal access token %q is limited to projects, but the project of the request can not be determined", t.Name)
+	}
+	for _, projectID := range projectIDs {
+		if !containsID(t.ProjectIDs, projectID) {
+			return errors.Errorf("personal access token %q is not permitted to access project %s", t.Name, projectID)
+		}
+	}
+	return nil
+}
+
+func allowMethod(permissions []string, method string) bool {
+	for _, p := range permissions {
+		switch p {
+		case apistructs.PersonalAccessTokenPermissionWrite:
+			return true
+		case apistructs.PersonalAccessTokenPermissionRead:
+			if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsID ids 为空表示不限制
+func containsID(ids []uint64, id string) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	v, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range ids {
+		if allowed == v {
+			return true
+		}
+	}
+	return false
+}
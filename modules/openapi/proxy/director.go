@@ -32,7 +32,7 @@ func NewDirector() func(*http.Request) {
 	return func(r *http.Request) {
 		path := r.URL.EscapedPath()
 		path = strutil.Concat("/", strutil.TrimPrefixes(path, "/"))
-		spec := api.API.Find(r)
+		spec := api.Find(r)
 
 		if spec == nil {
 			// not found
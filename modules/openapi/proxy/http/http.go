@@ -49,7 +49,7 @@ func (r *ReverseProxyWithCustom) ServeHTTP(rw http.ResponseWriter, req *http.Req
 			logrus.Errorf("[alert] openapi http proxy recover from panic: %v", err)
 		}
 	}()
-	spec := api.API.Find(req)
+	spec := api.Find(req)
 	if spec != nil && spec.Custom != nil {
 		spec.Custom(rw, req)
 		return
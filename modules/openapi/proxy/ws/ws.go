@@ -89,7 +89,7 @@ func (r *ReverseProxyWithCustom) ServeHTTP(rw http.ResponseWriter, req *http.Req
 			logrus.Errorf("[alert] openapi ws proxy recover from panic: %v", err)
 		}
 	}()
-	spec := api.API.Find(req)
+	spec := api.Find(req)
 	if spec != nil && spec.Custom != nil {
 		spec.Custom(rw, req)
 		return
@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ws
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTailBackend 模拟 monitor 的日志 tail 接口，返回经 openapi 鉴权后注入的 User-ID
+func newTailBackend(release <-chan struct{}) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("User-ID")
+		if websocket.IsWebSocketUpgrade(r) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.WriteMessage(websocket.TextMessage, []byte(userID))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: " + userID + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("data: done\n\n"))
+	}))
+}
+
+func newTailProxy(backend *httptest.Server) *httptest.Server {
+	u, _ := url.Parse(backend.URL)
+	return httptest.NewServer(NewReverseProxy(func(r *http.Request) {
+		r.Host = u.Host
+		r.URL.Host = u.Host
+		r.URL.Scheme = "http"
+		// openapi 在转发前完成鉴权并注入用户信息
+		r.Header.Set("User-ID", "2")
+	}))
+}
+
+func TestReverseProxySSE(t *testing.T) {
+	release := make(chan struct{})
+	backend := newTailBackend(release)
+	defer backend.Close()
+	proxy := newTailProxy(backend)
+	defer proxy.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL+"/api/runtime/logs/actions/tail?runtimeId=1", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// 后端未结束响应时事件已透传到客户端
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "data: 2", strings.TrimSpace(line))
+
+	close(release)
+	reader.ReadString('\n')
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "data: done", strings.TrimSpace(line))
+}
+
+func TestReverseProxyWebSocket(t *testing.T) {
+	backend := newTailBackend(nil)
+	defer backend.Close()
+	proxy := newTailProxy(backend)
+	defer proxy.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(proxy.URL, "http")+"/api/runtime/logs/actions/tail?runtimeId=1", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, msg, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(msg))
+}
@@ -34,6 +34,7 @@ import (
 	apispec "github.com/erda-project/erda/modules/openapi/api/spec"
 	"github.com/erda-project/erda/modules/openapi/auth"
 	"github.com/erda-project/erda/modules/openapi/hooks/posthandle"
+	"github.com/erda-project/erda/modules/openapi/hooks/prehandle"
 	"github.com/erda-project/erda/modules/openapi/monitor"
 	"github.com/erda-project/erda/modules/openapi/proxy"
 	phttp "github.com/erda-project/erda/modules/openapi/proxy/http"
@@ -59,19 +60,28 @@ func NewReverseProxyWithAuth(auth *auth.Auth, bundle *bundle.Bundle) (http.Handl
 func (r *ReverseProxyWithAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logrus.Infof("handle request: %v", req.URL)
 
-	spec := api.API.Find(req)
+	spec := api.Find(req)
 	if spec == nil {
 		errStr := fmt.Sprintf("not found path: %v", req.URL)
 		logrus.Error(errStr)
 		http.Error(rw, errStr, 404)
 		return
 	}
+	// 认证之前按客户端 IP 限流，认证之后再按用户限流
+	if err := prehandle.RateLimitByIP(context.Background(), rw, req); err != nil {
+		logrus.Warnf("rate limit by ip: %v, path: %v", err, req.URL)
+		return
+	}
 	if authr := r.auth.Auth(spec, req); authr.Code != auth.AuthSucc {
 		errStr := fmt.Sprintf("auth failed: %v", authr.Detail)
 		logrus.Error(errStr)
 		http.Error(rw, errStr, authr.Code)
 		return
 	}
+	if err := prehandle.RateLimit(context.Background(), rw, req, spec.Group); err != nil {
+		logrus.Warnf("rate limit: %v, path: %v", err, req.URL)
+		return
+	}
 	switch spec.Scheme {
 	case apispec.HTTP:
 		_, err := validatehttp.ValidateRequest(req)
@@ -119,7 +129,7 @@ func (r *ReverseProxyWithAuth) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 }
 
 func modifyResponse(res *http.Response) error {
-	spec := api.API.FindOriginPath(res.Request)
+	spec := api.FindOriginPath(res.Request)
 	if spec == nil {
 		// unreachable
 		logrus.Errorf("failed to modifyResponse: not found spec (unreachable):%v", res.Request.URL)
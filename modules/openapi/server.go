@@ -53,6 +53,7 @@ func NewServer() (*http.Server, error) {
 			}
 		}
 	}
+	api.InitRouter()
 
 	srv := &http.Server{
 		Addr:              conf.ListenAddr(),
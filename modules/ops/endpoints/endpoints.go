@@ -106,6 +106,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/clusters/{clusterName}/registry/layers", Method: http.MethodDelete, Handler: e.RegistryRemoveLayers},
 		{Path: "/api/clusters", Method: http.MethodPut, Handler: auth(i18nPrinter(e.ClusterUpdate))},
 		{Path: "/api/clusters/{clusterName}/registry/manifests/actions/remove", Method: http.MethodPost, Handler: e.RegistryRemoveManifests},
+		{Path: "/api/clusters/{clusterName}/registry/manifests/actions/size", Method: http.MethodPost, Handler: e.RegistryManifestsSize},
 		{Path: "/api/script/info", Method: http.MethodGet, Handler: e.GetScriptInfo},
 		{Path: "/api/script/{Name}", Method: http.MethodGet, WriterHandler: e.ServeScript},
 
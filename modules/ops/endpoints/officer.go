@@ -103,6 +103,45 @@ func (e *Endpoints) RegistryRemoveManifests(ctx context.Context, r *http.Request
 	return mkResponseData(v.Data)
 }
 
+// RegistryManifestsSize 查询镜像大小, 用于 release 回收预演
+func (e *Endpoints) RegistryManifestsSize(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.RegistryManifestsSizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return mkResponseErr("400", err.Error())
+	}
+	s := vars["clusterName"]
+	clusterinfo, err := e.bdl.QueryClusterInfo(s)
+	if err != nil {
+		errstr := fmt.Sprintf("RegistryManifestsSize queryclusterinfo err: %v, cluster: %v", err, s)
+		logrus.Errorf(errstr)
+		return mkResponseErr("400", errstr)
+	}
+
+	u := discover.Soldier()
+	if clusterinfo.MustGet(apistructs.DICE_IS_EDGE) == "true" {
+		u = clusterinfo.MustGetPublicURL("soldier")
+	}
+	req.RegistryURL = clusterinfo.MustGet(apistructs.REGISTRY_ADDR)
+	var v apistructs.RegistryManifestsSizeResponse
+	res, err := httpclient.New().Post(u).Path("/registry/manifests/size").JSONBody(req).Do().JSON(&v)
+	if err != nil {
+		errstr := fmt.Sprintf("RegistryManifestsSize call soldier failed: %v", err)
+		logrus.Errorf(errstr)
+		return mkResponseErr("502", errstr)
+	}
+	if res.StatusCode() != http.StatusOK {
+		errstr := fmt.Sprintf("call soldier failed: statuscode: %d", res.StatusCode())
+		logrus.Errorf(errstr)
+		return mkResponseErr("502", errstr)
+	}
+	if !v.Success {
+		errstr := fmt.Sprintf("call soldier failed: %v", v.Error.Msg)
+		logrus.Errorf(errstr)
+		return mkResponseErr("502", errstr)
+	}
+	return mkResponseData(v.Data)
+}
+
 func (e *Endpoints) RegistryRemoveLayers(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	s := vars["clusterName"]
 	clusterinfo, err := e.bdl.QueryClusterInfo(s)
@@ -39,6 +39,8 @@ type Conf struct {
 	InitContainerImage   string `env:"INIT_CONTAINER_IMAGE" default:"registry.cn-hangzhou.aliyuncs.com/dice-third-party/curl:stable"`
 	TokenClientID        string `env:"TOKEN_CLIENT_ID" default:"orchestrator"`
 	TokenClientSecret    string `env:"TOKEN_CLIENT_SECRET" default:"devops/orchestrator"`
+	WorkspaceQuotaRatio  string `env:"PROJECT_WORKSPACE_QUOTA_RATIO" default:""`
+	WorkspaceQuotaSurge  int    `env:"PROJECT_WORKSPACE_QUOTA_SURGE_PERCENT" default:"25"`
 }
 
 var cfg Conf
@@ -142,3 +144,14 @@ func TokenClientID() string {
 func TokenClientSecret() string {
 	return cfg.TokenClientSecret
 }
+
+// WorkspaceQuotaRatio 返回项目配额在各环境 namespace 间的分配比例, 格式: DEV:1,TEST:1,STAGING:1,PROD:1
+// 默认为空, 不在各环境 namespace 上设置配额
+func WorkspaceQuotaRatio() string {
+	return cfg.WorkspaceQuotaRatio
+}
+
+// WorkspaceQuotaSurge 返回各环境 namespace 配额额外预留的百分比, 用于滚动更新时新旧实例同时存在
+func WorkspaceQuotaSurge() int {
+	return cfg.WorkspaceQuotaSurge
+}
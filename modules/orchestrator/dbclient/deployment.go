@@ -234,6 +234,19 @@ func (db *DBClient) FindSuccessfulDeployments(runtimeId uint64, limit int) ([]De
 	return deployments, nil
 }
 
+// ExistSuccessfulDeploymentOfRelease release 是否已成功部署至应用的 workspace 环境
+func (db *DBClient) ExistSuccessfulDeploymentOfRelease(releaseID string, applicationID uint64, workspace string) (bool, error) {
+	var count int
+	if err := db.Table("ps_v2_deployments").
+		Joins("JOIN ps_v2_project_runtimes ON ps_v2_project_runtimes.id = ps_v2_deployments.runtime_id").
+		Where("ps_v2_deployments.release_id = ? AND ps_v2_deployments.status = ?", releaseID, apistructs.DeploymentStatusOK).
+		Where("ps_v2_project_runtimes.application_id = ? AND ps_v2_project_runtimes.workspace = ?", applicationID, workspace).
+		Count(&count).Error; err != nil {
+		return false, errors.Wrapf(err, "failed to count successful deployments of release %s", releaseID)
+	}
+	return count > 0, nil
+}
+
 // if not found, will return (nil, nil)
 func (db *DBClient) FindLastDeployment(runtimeId uint64) (*Deployment, error) {
 	var deployment Deployment
@@ -168,7 +168,13 @@ func (fsm *DeployFSMContext) continueWaiting() error {
 	}
 	if len(fsm.Deployment.ReleaseId) > 0 {
 		fsm.d.Log("increasing release reference...")
-		if err := fsm.bdl.IncreaseReference(fsm.Deployment.ReleaseId); err != nil {
+		if err := fsm.bdl.UpdateReferenceWithDeployment(apistructs.ReleaseReferenceUpdateRequest{
+			ReleaseID:    fsm.Deployment.ReleaseId,
+			Increase:     true,
+			RuntimeID:    fsm.Runtime.ID,
+			DeploymentID: fsm.Deployment.ID,
+			Workspace:    fsm.Runtime.Workspace,
+		}); err != nil {
 			return fsm.failDeploy(err)
 		}
 	}
@@ -801,6 +807,9 @@ func (fsm *DeployFSMContext) deployService() error {
 
 	// do deploy
 	if fsm.Runtime.Deployed {
+		if fsm.Runtime.Workspace == string(apistructs.ProdWorkspace) {
+			fsm.logServiceGroupPlan(apistructs.ServiceGroupPlanRequest(group))
+		}
 		if err := fsm.bdl.UpdateServiceGroup(apistructs.ServiceGroupUpdateV2Request(group)); err != nil {
 			return err
 		}
@@ -950,6 +959,16 @@ func (fsm *DeployFSMContext) generateDeployServiceRequest(group *apistructs.Serv
 
 	// generate project namespace into serviceGroup
 	group.ProjectNamespace = fsm.GetProjectNamespace(runtime.Workspace)
+	// 项目配额只能对应到项目级别的 namespace 上
+	if group.ProjectNamespace != "" {
+		projectInfo, err := fsm.bdl.GetProject(app.ProjectID)
+		if err != nil {
+			return nil, nil, errors.Errorf("Failed to get project info, err: %v", err)
+		}
+		// 配置了环境比例时, 项目配额按比例拆分到各环境的 namespace 上
+		group.NamespaceQuota = splitNamespaceQuota(projectInfo.CpuQuota, projectInfo.MemQuota, runtime.Workspace,
+			parseWorkspaceQuotaRatio(conf.WorkspaceQuotaRatio()), conf.WorkspaceQuotaSurge())
+	}
 
 	groupLabels := make(map[string]string)
 	utils.AppendEnv(groupLabels, obj.Meta)
@@ -975,6 +994,46 @@ func (fsm *DeployFSMContext) generateDeployServiceRequest(group *apistructs.Serv
 	return usedAddonInsMap, usedAddonTenantMap, nil
 }
 
+// parseWorkspaceQuotaRatio 解析环境配额比例, 格式: DEV:1,TEST:1,STAGING:1,PROD:1, 非法项忽略
+func parseWorkspaceQuotaRatio(s string) map[string]float64 {
+	ratio := make(map[string]float64)
+	for _, item := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || v < 0 {
+			logrus.Warnf("invalid workspace quota ratio: %s", item)
+			continue
+		}
+		ratio[strings.ToUpper(strings.TrimSpace(kv[0]))] = v
+	}
+	return ratio
+}
+
+// splitNamespaceQuota 按环境比例计算 workspace 对应 namespace 的配额, cpu 单位 core, mem 单位 GB
+// 比例未配置或总和为 0 时返回 nil, 不设置配额; 某环境比例为 0 时该环境不限制配额;
+// surge 为额外预留的百分比, 避免滚动更新时新实例因超出配额无法创建
+func splitNamespaceQuota(cpu, mem float64, workspace string, ratio map[string]float64, surge int) *apistructs.NamespaceQuota {
+	var total float64
+	for _, w := range apistructs.DiceWorkspaceSlice {
+		total += ratio[w.String()]
+	}
+	if total <= 0 {
+		return nil
+	}
+	if surge < 0 {
+		surge = 0
+	}
+	share := ratio[strings.ToUpper(workspace)] / total * float64(100+surge) / 100
+	return &apistructs.NamespaceQuota{
+		CPU: cpu * share,
+		// GB转MB
+		Mem: mem * 1024 * share,
+	}
+}
+
 func (fsm *DeployFSMContext) checkCancelOk() (bool, error) {
 	if fsm.Deployment.Extra.CancelStartAt != nil {
 		startCheckPoint := fsm.Deployment.Extra.CancelStartAt.Add(30 * time.Second)
@@ -1028,6 +1087,15 @@ func (fsm *DeployFSMContext) checkServiceReady() (bool, error) {
 		return false, nil
 	}
 	d.Log(fmt.Sprintf("checking status: %s, servicegroup: %v", serviceGroup.Status, runtime.ScheduleName))
+	// 超出项目配额时 pod 无法创建，直接失败
+	for _, svc := range serviceGroup.Services {
+		if svc.Reason != apistructs.StatusReasonQuotaExceeded {
+			continue
+		}
+		s := fmt.Sprintf("Service %s failed to create pods, the resource quota of project is exceeded: %s", svc.Name, svc.LastMessage)
+		fsm.ExportLogInfoDetail(apistructs.ErrorLevel, fmt.Sprintf("%d", fsm.Runtime.ID), "资源配额不足无法部署", s)
+		return false, errors.New(s)
+	}
 	// 如果状态是ready或者healthy，说明服务已经发起来了
 	runtimeStatus := apistructs.RuntimeStatusUnHealthy
 	if serviceGroup.Status == apistructs.StatusReady || serviceGroup.Status == apistructs.StatusHealthy {
@@ -1183,6 +1251,31 @@ func (fsm *DeployFSMContext) logAddonVars(addonvars map[string]string) {
 	fsm.d.Log("Available addon vars: " + ss)
 }
 
+// logServiceGroupPlan 生产环境更新前展示将要变更的 k8s 对象及会重启的服务，失败不影响部署
+func (fsm *DeployFSMContext) logServiceGroupPlan(req apistructs.ServiceGroupPlanRequest) {
+	plan, err := fsm.bdl.PlanServiceGroupUpdate(req)
+	if err != nil {
+		fsm.d.Log(fmt.Sprintf("failed to plan service group update, %s", err.Error()))
+		return
+	}
+	if len(plan.Items) == 0 {
+		fsm.d.Log("Deploy plan: no changes")
+		return
+	}
+	for _, item := range plan.Items {
+		// 只展示变更字段，env 中可能有敏感信息
+		paths := make([]string, 0, len(item.Diffs))
+		for _, diff := range item.Diffs {
+			paths = append(paths, diff.Path)
+		}
+		fsm.d.Log(fmt.Sprintf("Deploy plan: %s %s %s/%s, restart: %t, changed: %s",
+			item.Action, item.Kind, item.Namespace, item.Name, item.Restart, strings.Join(paths, ", ")))
+	}
+	if len(plan.Restarts) > 0 {
+		fsm.d.Log(fmt.Sprintf("Deploy plan: services to be restarted: %s", strings.Join(plan.Restarts, ", ")))
+	}
+}
+
 func (fsm *DeployFSMContext) evalTemplate(projectAddons []dbclient.AddonInstanceRouting,
 	projectAddonTenants []dbclient.AddonInstanceTenant, envs map[string]string) (map[string]string, map[string]dbclient.AddonInstanceRouting, map[string]dbclient.AddonInstanceTenant, error) {
 	addonnameMap, addonIDMap, addonTenantNameMap, addonTenantIDMap := fsm.addon.BuildAddonAndTenantMap(
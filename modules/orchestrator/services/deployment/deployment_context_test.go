@@ -474,3 +474,31 @@ func genFakeFSM(specPath ...string) *DeployFSMContext {
 	}
 	return &fsm
 }
+
+func TestSplitNamespaceQuota(t *testing.T) {
+	ratio := parseWorkspaceQuotaRatio("DEV:1, TEST:1,staging:2,PROD:4,invalid,UAT:x")
+	assert.Equal(t, map[string]float64{"DEV": 1, "TEST": 1, "STAGING": 2, "PROD": 4}, ratio)
+
+	quota := splitNamespaceQuota(16, 32, "PROD", ratio, 0)
+	assert.Equal(t, &apistructs.NamespaceQuota{CPU: 8, Mem: 16 * 1024}, quota)
+	quota = splitNamespaceQuota(16, 32, "DEV", ratio, 0)
+	assert.Equal(t, &apistructs.NamespaceQuota{CPU: 2, Mem: 4 * 1024}, quota)
+
+	// 不预留时各环境配额之和不超过项目配额
+	var cpu, mem float64
+	for _, w := range apistructs.DiceWorkspaceSlice {
+		q := splitNamespaceQuota(16, 32, w.String(), ratio, 0)
+		cpu += q.CPU
+		mem += q.Mem
+	}
+	assert.Equal(t, float64(16), cpu)
+	assert.Equal(t, float64(32*1024), mem)
+
+	// 预留滚动更新的余量
+	quota = splitNamespaceQuota(16, 32, "PROD", ratio, 25)
+	assert.Equal(t, &apistructs.NamespaceQuota{CPU: 10, Mem: 20 * 1024}, quota)
+
+	// 未配置比例时不设置配额
+	assert.Nil(t, splitNamespaceQuota(8, 16, "TEST", parseWorkspaceQuotaRatio(""), 25))
+	assert.Nil(t, splitNamespaceQuota(8, 16, "TEST", parseWorkspaceQuotaRatio("DEV:0"), 25))
+}
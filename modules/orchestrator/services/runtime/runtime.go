@@ -101,6 +101,11 @@ func (r *Runtime) CreateByReleaseIDPipeline(orgid uint64, operator user.ID, rele
 		return apistructs.RuntimeReleaseCreatePipelineResponse{}, err
 	}
 	workspaces := strutil.Split(releaseReq.Workspace, ",", true)
+	for _, workspace := range workspaces {
+		if err := r.checkReleasePromotion(app.OrgID, app.ID, releaseReq.ReleaseID, workspace); err != nil {
+			return apistructs.RuntimeReleaseCreatePipelineResponse{}, err
+		}
+	}
 	yml := apistructs.PipelineYml{
 		Version: "1.1",
 		Stages: [][]*apistructs.PipelineYmlAction{
@@ -226,7 +231,6 @@ func (r *Runtime) CreateByReleaseID(operator user.ID, releaseReq *apistructs.Run
 	if !validArtifactWorkspace {
 		return nil, errors.Errorf("release does not correspond to the workspace")
 	}
-
 	projectInfo, err := r.bdl.GetProject(releaseReq.ProjectID)
 	if err != nil {
 		return nil, err
@@ -273,6 +277,40 @@ func (r *Runtime) CreateByReleaseID(operator user.ID, releaseReq *apistructs.Run
 	return r.Create(operator, &req)
 }
 
+// checkReleasePromotion 企业开启晋级卡点后，部署至 PROD 环境的 release 须已晋级至 STAGING
+// 已在该应用 PROD 环境成功部署过的 release 不再校验；回滚和重新部署不校验，避免故障时无法恢复
+// doDeployRuntime 在创建部署单前校验，创建部署流水线时提前校验以尽早报错
+func (r *Runtime) checkReleasePromotion(orgID, appID uint64, releaseID, workspace string) error {
+	if apistructs.DiceWorkspace(strutil.ToUpper(workspace)) != apistructs.ProdWorkspace {
+		return nil
+	}
+	org, err := r.bdl.GetOrg(orgID)
+	if err != nil {
+		return err
+	}
+	if !org.EnableReleasePromotionGate {
+		return nil
+	}
+	deployed, err := r.db.ExistSuccessfulDeploymentOfRelease(releaseID, appID, string(apistructs.ProdWorkspace))
+	if err != nil {
+		return err
+	}
+	if deployed {
+		return nil
+	}
+	promotions, err := r.bdl.ListReleasePromotions(releaseID)
+	if err != nil {
+		return err
+	}
+	for _, v := range promotions {
+		if v.Workspace == string(apistructs.StagingWorkspace) && v.Status == apistructs.ReleasePromotionStatusPromoted {
+			return nil
+		}
+	}
+	return errors.Errorf("release %s has not been promoted to %s, can't be deployed to %s",
+		releaseID, apistructs.StagingWorkspace, apistructs.ProdWorkspace)
+}
+
 // Create 创建应用实例
 func (r *Runtime) Create(operator user.ID, req *apistructs.RuntimeCreateRequest) (
 	*apistructs.DeploymentCreateResponseDTO, error) {
@@ -462,6 +500,9 @@ func (r *Runtime) RedeployPipeline(operator user.ID, orgID uint64, runtimeID uin
 	if err != nil {
 		return nil, err
 	}
+	if deployment == nil {
+		return nil, errors.Errorf("last deployment not found")
+	}
 	releaseResp, err := r.bdl.GetRelease(deployment.ReleaseId)
 	if err != nil {
 		return nil, err
@@ -577,6 +618,13 @@ func (r *Runtime) Redeploy(operator user.ID, orgID uint64, runtimeID uint64) (*a
 
 // TODO: the response should be apistructs.RuntimeDTO
 func (r *Runtime) doDeployRuntime(ctx *DeployContext) (*apistructs.DeploymentCreateResponseDTO, error) {
+	// 停止服务和重新部署不涉及新的 release，不校验晋级
+	if !ctx.Scale0 && ctx.DeployType != "REDEPLOY" {
+		if err := r.checkReleasePromotion(ctx.Runtime.OrgID, ctx.Runtime.ApplicationID, ctx.ReleaseID, ctx.Runtime.Workspace); err != nil {
+			return nil, apierrors.ErrDeployRuntime.InvalidState(err.Error())
+		}
+	}
+
 	// fetch & parse diceYml
 	dice, err := r.bdl.GetDiceYAML(ctx.ReleaseID, ctx.Runtime.Workspace)
 	if err != nil {
@@ -1834,7 +1882,12 @@ func (r *Runtime) markOutdated(deployment *dbclient.Deployment) {
 		return
 	}
 	if len(deployment.ReleaseId) > 0 {
-		if err := r.bdl.DecreaseReference(deployment.ReleaseId); err != nil {
+		if err := r.bdl.UpdateReferenceWithDeployment(apistructs.ReleaseReferenceUpdateRequest{
+			ReleaseID:    deployment.ReleaseId,
+			Increase:     false,
+			RuntimeID:    deployment.RuntimeId,
+			DeploymentID: deployment.ID,
+		}); err != nil {
 			logrus.Errorf("[alert] failed to decrease reference of release: %s, (%v)",
 				deployment.ReleaseId, err)
 		}
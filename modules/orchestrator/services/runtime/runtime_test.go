@@ -15,11 +15,19 @@
 package runtime
 
 import (
+	"reflect"
 	"testing"
 
+	"bou.ke/monkey"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/orchestrator/dbclient"
+	"github.com/erda-project/erda/modules/orchestrator/spec"
+	"github.com/erda-project/erda/pkg/dbengine"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
 )
 
 func TestModifyStatusIfNotForDisplay(t *testing.T) {
@@ -37,3 +45,88 @@ func TestModifyStatusIfNotForDisplay(t *testing.T) {
 		assert.Equal(t, "Stopped", s.Status)
 	}
 }
+
+func TestCreateCheckReleasePromotion(t *testing.T) {
+	bdl := bundle.New()
+	db := &dbclient.DBClient{}
+	r := New(WithBundle(bdl), WithDBClient(db))
+
+	defer monkey.UnpatchAll()
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetApp", func(_ *bundle.Bundle, id uint64) (*apistructs.ApplicationDTO, error) {
+		return &apistructs.ApplicationDTO{ID: id, ProjectID: 2, OrgID: 1}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetBranchRules", func(_ *bundle.Bundle, scopeType apistructs.ScopeType, scopeID uint64) ([]*apistructs.BranchRule, error) {
+		return nil, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "CheckPermission", func(_ *bundle.Bundle, req *apistructs.PermissionCheckRequest) (*apistructs.PermissionCheckResponseData, error) {
+		return &apistructs.PermissionCheckResponseData{Access: true}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetCluster", func(_ *bundle.Bundle, idOrName string) (*apistructs.ClusterInfo, error) {
+		return &apistructs.ClusterInfo{ID: 1, Name: idOrName}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "FindRuntimeOrCreate", func(_ *dbclient.DBClient, uniqueID spec.RuntimeUniqueId, operator string, source apistructs.RuntimeSource,
+		clusterName string, clusterID uint64, gitRepoAbbrev string, projectID, orgID uint64) (*dbclient.Runtime, bool, error) {
+		return &dbclient.Runtime{BaseModel: dbengine.BaseModel{ID: 4}, Name: uniqueID.Name, Workspace: uniqueID.Workspace, ApplicationID: uniqueID.ApplicationId}, false, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "FindLastDeployment", func(_ *dbclient.DBClient, runtimeID uint64) (*dbclient.Deployment, error) {
+		return nil, nil
+	})
+	gateEnabled := true
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetOrg", func(_ *bundle.Bundle, idOrName interface{}) (*apistructs.OrgDTO, error) {
+		return &apistructs.OrgDTO{ID: 1, EnableReleasePromotionGate: gateEnabled}, nil
+	})
+	deployed := false
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ExistSuccessfulDeploymentOfRelease", func(_ *dbclient.DBClient, releaseID string, applicationID uint64, workspace string) (bool, error) {
+		return deployed, nil
+	})
+	var promotions []apistructs.ReleasePromotion
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "ListReleasePromotions", func(_ *bundle.Bundle, releaseID string) ([]apistructs.ReleasePromotion, error) {
+		return promotions, nil
+	})
+	// 通过校验后进入部署流程，在获取 dice.yml 时结束
+	errDeploying := errors.New("deploying")
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetDiceYAML", func(_ *bundle.Bundle, releaseID string, workspace ...string) (*diceyml.DiceYaml, error) {
+		return nil, errDeploying
+	})
+
+	newReq := func(workspace string) *apistructs.RuntimeCreateRequest {
+		return &apistructs.RuntimeCreateRequest{
+			Name:        "master",
+			ReleaseID:   "release-1",
+			Operator:    "1",
+			ClusterName: "terminus-prod",
+			Source:      apistructs.RELEASE,
+			Extra: apistructs.RuntimeCreateRequestExtra{
+				OrgID:         1,
+				ProjectID:     2,
+				ApplicationID: 3,
+				Workspace:     workspace,
+			},
+		}
+	}
+
+	_, err := r.Create("1", newReq("PROD"))
+	assert.Error(t, err)
+	assert.NotEqual(t, errDeploying, err)
+	assert.Contains(t, err.Error(), "has not been promoted")
+
+	// 非 PROD 环境不需要晋级
+	_, err = r.Create("1", newReq("TEST"))
+	assert.Equal(t, errDeploying, err)
+
+	// 已在 PROD 成功部署过的 release 不需要晋级
+	deployed = true
+	_, err = r.Create("1", newReq("PROD"))
+	assert.Equal(t, errDeploying, err)
+	deployed = false
+
+	// 企业未开启晋级卡点
+	gateEnabled = false
+	_, err = r.Create("1", newReq("PROD"))
+	assert.Equal(t, errDeploying, err)
+	gateEnabled = true
+
+	promotions = []apistructs.ReleasePromotion{{Workspace: "STAGING", Status: apistructs.ReleasePromotionStatusPromoted}}
+	_, err = r.Create("1", newReq("PROD"))
+	assert.Equal(t, errDeploying, err)
+}
@@ -49,6 +49,7 @@ func (sdk SDK) Clone() SDK {
 
 const (
 	CtxKeyTasks = iota
+	CtxKeyAnnotations
 )
 
 func (ctx *TuneContext) PutKV(k, v interface{}) {
@@ -62,3 +63,28 @@ func (ctx *TuneContext) TryGet(k interface{}) (interface{}, bool) {
 	}
 	return v, true
 }
+
+// PutAnnotations 合并调音点产出的标签，由调用方决定如何落库
+func (ctx *TuneContext) PutAnnotations(annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	merged := make(map[string]string)
+	for k, v := range ctx.Annotations() {
+		merged[k] = v
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	ctx.PutKV(CtxKeyAnnotations, merged)
+}
+
+// Annotations 返回调音点产出的标签
+func (ctx *TuneContext) Annotations() map[string]string {
+	v, ok := ctx.TryGet(CtxKeyAnnotations)
+	if !ok {
+		return nil
+	}
+	annotations, _ := v.(map[string]string)
+	return annotations
+}
@@ -19,6 +19,7 @@ import "github.com/sirupsen/logrus"
 type TuneChain []TunePoint
 
 // Handle 根据上下文调用 TuneChain
+// 普通错误仅记录日志，不影响后续调音点；VetoError 会中断调用链并返回
 func (chain TuneChain) Handle(ctx *TuneContext) error {
 	if len(chain) == 0 {
 		return nil
@@ -26,6 +27,10 @@ func (chain TuneChain) Handle(ctx *TuneContext) error {
 	for _, point := range chain {
 		logrus.Debugf("begin handle tune point, type: %s, trigger: %s, name: %s", point.Type(), ctx.SDK.TuneTrigger, point.Name())
 		if err := point.Handle(ctx); err != nil {
+			if IsVetoError(err) {
+				logrus.Warnf("end handle tune point, type: %s, trigger: %s, name: %s, vetoed, err: %v", point.Type(), ctx.SDK.TuneTrigger, point.Name(), err)
+				return err
+			}
 			logrus.Errorf("end handle tune point, type: %s, trigger: %s, name: %s, failed, err: %v", point.Type(), ctx.SDK.TuneTrigger, point.Name(), err)
 		} else {
 			logrus.Debugf("end handle tune point, type: %s, trigger: %s, name: %s, success", point.Type(), ctx.SDK.TuneTrigger, point.Name())
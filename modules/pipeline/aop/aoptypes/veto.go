@@ -0,0 +1,38 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package aoptypes
+
+import "fmt"
+
+// VetoError 表示调音点否决了当前流水线或任务的继续执行
+// 调用链遇到 VetoError 时立即中断，并将其返回给调用方
+type VetoError struct {
+	Point  string
+	Reason string
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("vetoed by tune point %s, reason: %s", e.Point, e.Reason)
+}
+
+// NewVetoError 构造否决错误
+func NewVetoError(point, reason string) *VetoError {
+	return &VetoError{Point: point, Reason: reason}
+}
+
+// IsVetoError 判断 err 是否为否决错误
+func IsVetoError(err error) bool {
+	_, ok := err.(*VetoError)
+	return ok
+}
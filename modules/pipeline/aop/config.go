@@ -0,0 +1,162 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package aop
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/modules/pipeline/aop/plugins/http_hook"
+	"github.com/erda-project/erda/modules/pipeline/aop/plugins/pipeline"
+	"github.com/erda-project/erda/modules/pipeline/aop/plugins/task"
+)
+
+const tunePointTypeHTTP = "http"
+
+// TuneChainsConfig 调用链配置，按 类型、触发时机 编排调音点
+// 配置中出现的 类型+触发时机 覆盖默认调用链，未出现的保持默认
+type TuneChainsConfig map[aoptypes.TuneType]map[aoptypes.TuneTrigger][]TunePointConfig
+
+// TunePointConfig 调音点配置
+// 内置插件只需填写 plugin；外部调音点 type 为 http，并填写 http 配置
+type TunePointConfig struct {
+	Type   string            `json:"type"`
+	Plugin string            `json:"plugin"`
+	HTTP   *http_hook.Config `json:"http"`
+}
+
+// defaultTuneGroup 返回代码中编排的默认调用链
+func defaultTuneGroup() aoptypes.TuneGroup {
+	return aoptypes.TuneGroup{
+		// pipeline level
+		aoptypes.TuneTypePipeline: copyTuneChains(pipeline.TuneTriggerChains),
+		// task level
+		aoptypes.TuneTypeTask: copyTuneChains(task.TuneTriggerChains),
+	}
+}
+
+func copyTuneChains(chains map[aoptypes.TuneTrigger]aoptypes.TuneChain) map[aoptypes.TuneTrigger]aoptypes.TuneChain {
+	result := make(map[aoptypes.TuneTrigger]aoptypes.TuneChain, len(chains))
+	for trigger, chain := range chains {
+		result[trigger] = chain
+	}
+	return result
+}
+
+// loadTuneGroup 加载配置文件并与默认调用链合并，文件为空时使用默认调用链
+func loadTuneGroup(file string) (aoptypes.TuneGroup, error) {
+	group := defaultTuneGroup()
+	if file == "" {
+		return group, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aop tune chains config file %s, err: %v", file, err)
+	}
+	var cfg TuneChainsConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse aop tune chains config file %s, err: %v", file, err)
+	}
+	if err := cfg.applyTo(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (cfg TuneChainsConfig) applyTo(group aoptypes.TuneGroup) error {
+	for typ, triggers := range cfg {
+		builtins, err := builtinTunePoints(typ)
+		if err != nil {
+			return err
+		}
+		for trigger, points := range triggers {
+			if _, ok := group[typ][trigger]; !ok && !isValidTrigger(typ, trigger) {
+				return fmt.Errorf("invalid tune trigger, type: %s, trigger: %s", typ, trigger)
+			}
+			chain := make(aoptypes.TuneChain, 0, len(points))
+			for i, pc := range points {
+				point, err := pc.build(typ, builtins)
+				if err != nil {
+					return fmt.Errorf("invalid tune point, type: %s, trigger: %s, index: %d, err: %v", typ, trigger, i, err)
+				}
+				chain = append(chain, point)
+			}
+			group[typ][trigger] = chain
+		}
+	}
+	return nil
+}
+
+func isValidTrigger(typ aoptypes.TuneType, trigger aoptypes.TuneTrigger) bool {
+	for _, t := range validTriggers[typ] {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+var validTriggers = map[aoptypes.TuneType][]aoptypes.TuneTrigger{
+	aoptypes.TuneTypePipeline: {
+		aoptypes.TuneTriggerPipelineBeforeExec,
+		aoptypes.TuneTriggerPipelineInQueuePrecheckBeforePop,
+		aoptypes.TuneTriggerPipelineAfterExec,
+	},
+	aoptypes.TuneTypeTask: {
+		aoptypes.TuneTriggerTaskBeforeExec,
+		aoptypes.TuneTriggerTaskAfterExec,
+		aoptypes.TuneTriggerTaskBeforePrepare,
+		aoptypes.TuneTriggerTaskAfterPrepare,
+		aoptypes.TuneTriggerTaskBeforeCreate,
+		aoptypes.TuneTriggerTaskAfterCreate,
+		aoptypes.TuneTriggerTaskBeforeStart,
+		aoptypes.TuneTriggerTaskAfterStart,
+		aoptypes.TuneTriggerTaskBeforeQueue,
+		aoptypes.TuneTriggerTaskAfterQueue,
+		aoptypes.TuneTriggerTaskBeforeWait,
+		aoptypes.TuneTriggerTaskAfterWait,
+	},
+}
+
+func builtinTunePoints(typ aoptypes.TuneType) (map[string]func() aoptypes.TunePoint, error) {
+	switch typ {
+	case aoptypes.TuneTypePipeline:
+		return pipeline.TunePoints, nil
+	case aoptypes.TuneTypeTask:
+		return task.TunePoints, nil
+	default:
+		return nil, fmt.Errorf("invalid tune type: %s", typ)
+	}
+}
+
+func (pc TunePointConfig) build(typ aoptypes.TuneType, builtins map[string]func() aoptypes.TunePoint) (aoptypes.TunePoint, error) {
+	switch pc.Type {
+	case "":
+		newFunc, ok := builtins[pc.Plugin]
+		if !ok {
+			return nil, fmt.Errorf("builtin plugin %q not found", pc.Plugin)
+		}
+		return newFunc(), nil
+	case tunePointTypeHTTP:
+		if pc.HTTP == nil {
+			return nil, fmt.Errorf("http config is empty")
+		}
+		return http_hook.New(typ, *pc.HTTP)
+	default:
+		return nil, fmt.Errorf("invalid tune point type: %s", pc.Type)
+	}
+}
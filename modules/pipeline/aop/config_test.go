@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package aop
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/modules/pipeline/aop/plugins/pipeline"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "aop")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	file := filepath.Join(dir, "tunechains.yaml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestLoadTuneGroup(t *testing.T) {
+	group, err := loadTuneGroup("")
+	assert.NoError(t, err)
+	assert.Equal(t, len(pipeline.TuneTriggerChains), len(group[aoptypes.TuneTypePipeline]))
+
+	group, err = loadTuneGroup(writeConfigFile(t, `
+task:
+  task_before_exec:
+    - plugin: echo
+    - type: http
+      http:
+        name: allow-list
+        url: http://127.0.0.1:8080/tune
+        timeout: 1s
+`))
+	assert.NoError(t, err)
+	chain := group.GetTuneChainByTypeAndTrigger(aoptypes.TuneTypeTask, aoptypes.TuneTriggerTaskBeforeExec)
+	assert.Len(t, chain, 2)
+	assert.Equal(t, "allow-list", chain[1].Name())
+	assert.Equal(t, aoptypes.TuneTypeTask, chain[1].Type())
+	// not configured trigger keeps default
+	assert.Equal(t, len(pipeline.TuneTriggerChains[aoptypes.TuneTriggerPipelineAfterExec]),
+		len(group.GetTuneChainByTypeAndTrigger(aoptypes.TuneTypePipeline, aoptypes.TuneTriggerPipelineAfterExec)))
+	// default chains are not modified
+	assert.Len(t, defaultTuneGroup().GetTuneChainByTypeAndTrigger(aoptypes.TuneTypeTask, aoptypes.TuneTriggerTaskBeforeExec), 1)
+}
+
+func TestLoadTuneGroupInvalid(t *testing.T) {
+	for _, content := range []string{
+		"task:\n  task_before_exec:\n    - plugin: not_exist\n",
+		"task:\n  pipeline_before_exec:\n    - plugin: echo\n",
+		"unknown:\n  task_before_exec:\n    - plugin: echo\n",
+		"task:\n  task_before_exec:\n    - type: http\n",
+		"task:\n  task_before_exec:\n    - type: http\n      http:\n        url: http://127.0.0.1\n        timeout: abc\n",
+		"task:\n  task_before_exec:\n    - type: grpc\n",
+	} {
+		_, err := loadTuneGroup(writeConfigFile(t, content))
+		assert.Error(t, err, content)
+	}
+}
+
+type vetoPoint struct{ aoptypes.TaskBaseTunePoint }
+
+func (p vetoPoint) Name() string { return "veto" }
+func (p vetoPoint) Handle(ctx *aoptypes.TuneContext) error {
+	return aoptypes.NewVetoError(p.Name(), "denied")
+}
+
+type countPoint struct {
+	aoptypes.TaskBaseTunePoint
+	count *int
+}
+
+func (p countPoint) Name() string                           { return "count" }
+func (p countPoint) Handle(ctx *aoptypes.TuneContext) error { *p.count++; return nil }
+
+func TestTuneChainVeto(t *testing.T) {
+	var count int
+	chain := aoptypes.TuneChain{countPoint{count: &count}, vetoPoint{}, countPoint{count: &count}}
+	err := chain.Handle(NewContextForTask(spec.PipelineTask{ID: 1}, spec.Pipeline{}, aoptypes.TuneTriggerTaskBeforeExec))
+	assert.True(t, aoptypes.IsVetoError(err))
+	assert.Equal(t, 1, count)
+}
@@ -18,8 +18,7 @@ import (
 
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
-	"github.com/erda-project/erda/modules/pipeline/aop/plugins/pipeline"
-	"github.com/erda-project/erda/modules/pipeline/aop/plugins/task"
+	"github.com/erda-project/erda/modules/pipeline/conf"
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
 	"github.com/erda-project/erda/modules/pipeline/services/reportsvc"
 )
@@ -31,19 +30,20 @@ var once sync.Once
 var initialized bool
 var globalSDK aoptypes.SDK
 
-func Initialize(bdl *bundle.Bundle, dbClient *dbclient.Client, report *reportsvc.ReportSvc) {
+// Initialize 初始化 AOP，若配置了调用链配置文件，则以配置覆盖默认调用链
+func Initialize(bdl *bundle.Bundle, dbClient *dbclient.Client, report *reportsvc.ReportSvc) (err error) {
 	once.Do(func() {
+		var group aoptypes.TuneGroup
+		group, err = loadTuneGroup(conf.AOPTuneChainsConfigFile())
+		if err != nil {
+			return
+		}
+		tuneGroup = group
 		initialized = true
 
 		globalSDK.Bundle = bdl
 		globalSDK.DBClient = dbClient
 		globalSDK.Report = report
-
-		tuneGroup = aoptypes.TuneGroup{
-			// pipeline level
-			aoptypes.TuneTypePipeline: pipeline.TuneTriggerChains,
-			// task level
-			aoptypes.TuneTypeTask: task.TuneTriggerChains,
-		}
 	})
+	return err
 }
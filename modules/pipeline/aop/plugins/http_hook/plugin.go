@@ -0,0 +1,223 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package http_hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/httputil"
+)
+
+const (
+	// FailurePolicyIgnore 外部服务不可用时忽略，继续执行
+	FailurePolicyIgnore = "ignore"
+	// FailurePolicyFail 外部服务不可用时否决
+	FailurePolicyFail = "fail"
+
+	defaultTimeout = 5 * time.Second
+)
+
+// Config http 调音点配置
+type Config struct {
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Timeout       string            `json:"timeout"`
+	Headers       map[string]string `json:"headers"`
+	FailurePolicy string            `json:"failurePolicy"`
+}
+
+// Request 发送给外部服务的请求体
+type Request struct {
+	Type     aoptypes.TuneType    `json:"type"`
+	Trigger  aoptypes.TuneTrigger `json:"trigger"`
+	Pipeline PipelineInfo         `json:"pipeline"`
+	Task     *TaskInfo            `json:"task,omitempty"`
+}
+
+type PipelineInfo struct {
+	ID           uint64            `json:"id"`
+	Source       string            `json:"source"`
+	YmlName      string            `json:"ymlName"`
+	ClusterName  string            `json:"clusterName"`
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	NormalLabels map[string]string `json:"normalLabels,omitempty"`
+}
+
+type TaskInfo struct {
+	ID          uint64            `json:"id"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status"`
+	Image       string            `json:"image,omitempty"`
+	ClusterName string            `json:"clusterName,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Result 外部服务返回的处理结果
+type Result struct {
+	// Veto 为 true 时否决当前流水线或任务
+	Veto   bool   `json:"veto"`
+	Reason string `json:"reason"`
+	// Labels 合并到流水线或任务标签中
+	Labels map[string]string `json:"labels"`
+	// RetryIntervalSecond 仅在排队预检查时生效，大于 0 时等待后重试而非直接失败
+	RetryIntervalSecond uint64 `json:"retryIntervalSecond"`
+}
+
+type Response struct {
+	apistructs.Header
+	Data Result `json:"data"`
+}
+
+type Plugin struct {
+	typ     aoptypes.TuneType
+	cfg     Config
+	timeout time.Duration
+}
+
+// New 根据配置构造 http 调音点
+func New(typ aoptypes.TuneType, cfg Config) (*Plugin, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("http tune point url is empty")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid http tune point url %q, err: %v", cfg.URL, err)
+	}
+	if cfg.Name == "" {
+		cfg.Name = "http"
+	}
+	switch cfg.FailurePolicy {
+	case "":
+		cfg.FailurePolicy = FailurePolicyIgnore
+	case FailurePolicyIgnore, FailurePolicyFail:
+	default:
+		return nil, fmt.Errorf("invalid http tune point failurePolicy %q", cfg.FailurePolicy)
+	}
+	timeout := defaultTimeout
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http tune point timeout %q, err: %v", cfg.Timeout, err)
+		}
+		timeout = d
+	}
+	return &Plugin{typ: typ, cfg: cfg, timeout: timeout}, nil
+}
+
+func (p *Plugin) Type() aoptypes.TuneType { return p.typ }
+func (p *Plugin) Name() string            { return p.cfg.Name }
+func (p *Plugin) Handle(ctx *aoptypes.TuneContext) error {
+	result, err := p.invoke(makeRequest(ctx))
+	if err != nil {
+		if p.cfg.FailurePolicy != FailurePolicyFail {
+			return err
+		}
+		result = &Result{Veto: true, Reason: err.Error()}
+	}
+
+	ctx.PutAnnotations(result.Labels)
+
+	if ctx.SDK.TuneTrigger == aoptypes.TuneTriggerPipelineInQueuePrecheckBeforePop {
+		putPrecheckResult(ctx, result)
+	}
+	if result.Veto {
+		return aoptypes.NewVetoError(p.Name(), result.Reason)
+	}
+	return nil
+}
+
+func (p *Plugin) invoke(req Request) (*Result, error) {
+	u, err := url.Parse(p.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	hc := httpclient.New(httpclient.WithTimeout(time.Second, p.timeout))
+	r := hc.Post(u.Scheme+"://"+u.Host).
+		Path(u.Path).
+		Params(u.Query()).
+		Header(httputil.InternalHeader, "pipeline_aop")
+	for k, v := range p.cfg.Headers {
+		r = r.Header(k, v)
+	}
+	var buffer bytes.Buffer
+	resp, err := r.JSONBody(&req).Do().Body(&buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke http tune point %s, err: %v", p.Name(), err)
+	}
+	if !resp.IsOK() {
+		return nil, fmt.Errorf("failed to invoke http tune point %s, httpcode: %d, body: %s", p.Name(), resp.StatusCode(), buffer.String())
+	}
+	var response Response
+	if err := json.NewDecoder(&buffer).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response of http tune point %s, err: %v", p.Name(), err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("http tune point %s response not success, err: %s", p.Name(), response.Error.Msg)
+	}
+	return &response.Data, nil
+}
+
+func makeRequest(ctx *aoptypes.TuneContext) Request {
+	p := ctx.SDK.Pipeline
+	req := Request{
+		Type:    ctx.SDK.TuneType,
+		Trigger: ctx.SDK.TuneTrigger,
+		Pipeline: PipelineInfo{
+			ID:           p.ID,
+			Source:       p.PipelineSource.String(),
+			YmlName:      p.PipelineYmlName,
+			ClusterName:  p.ClusterName,
+			Status:       p.Status.String(),
+			Labels:       p.Labels,
+			NormalLabels: p.NormalLabels,
+		},
+	}
+	if ctx.SDK.TuneType == aoptypes.TuneTypeTask {
+		t := ctx.SDK.Task
+		req.Task = &TaskInfo{
+			ID:          t.ID,
+			Name:        t.Name,
+			Type:        t.Type,
+			Status:      t.Status.String(),
+			Image:       t.Extra.Image,
+			ClusterName: t.Extra.ClusterName,
+			Labels:      t.Extra.Labels,
+		}
+	}
+	return req
+}
+
+// putPrecheckResult 排队预检查时将结果写入上下文，供队列判断出队、重试或失败
+func putPrecheckResult(ctx *aoptypes.TuneContext, result *Result) {
+	if !result.Veto {
+		// 不覆盖之前调音点的预检查结果
+		if _, ok := ctx.TryGet(apistructs.PipelinePreCheckResultContextKey); !ok {
+			ctx.PutKV(apistructs.PipelinePreCheckResultContextKey, apistructs.PipelineQueueValidateResult{Success: true})
+		}
+		return
+	}
+	validResult := apistructs.PipelineQueueValidateResult{Success: false, Reason: result.Reason}
+	if result.RetryIntervalSecond > 0 {
+		validResult.RetryOption = &apistructs.QueueValidateRetryOption{IntervalSecond: result.RetryIntervalSecond}
+	}
+	ctx.PutKV(apistructs.PipelinePreCheckResultContextKey, validResult)
+}
@@ -44,3 +44,14 @@ var TuneTriggerChains = map[aoptypes.TuneTrigger]aoptypes.TuneChain{
 		scene_after.New(),
 	},
 }
+
+// TunePoints 保存流水线所有可通过配置编排的内置插件，key 为插件目录名
+var TunePoints = map[string]func() aoptypes.TunePoint{
+	"echo":                func() aoptypes.TunePoint { return echo.New() },
+	"basic":               func() aoptypes.TunePoint { return basic.New() },
+	"project":             func() aoptypes.TunePoint { return project.New() },
+	"apitest_report":      func() aoptypes.TunePoint { return apitest_report.New() },
+	"precheck_before_pop": func() aoptypes.TunePoint { return precheck_before_pop.New() },
+	"scene_before":        func() aoptypes.TunePoint { return scene_before.New() },
+	"scene_after":         func() aoptypes.TunePoint { return scene_after.New() },
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/pkg/qaparser"
 )
 
 const taskType = "unit-test"
@@ -58,12 +59,23 @@ func (p *Plugin) Handle(ctx *aoptypes.TuneContext) error {
 			var suites []apistructs.TestSuite
 			err = json.Unmarshal([]byte(v.Value), &suites)
 			meta["suites"] = suites
+		case "coverage":
+			var coverage apistructs.TestCoverage
+			err = json.Unmarshal([]byte(v.Value), &coverage)
+			meta["coverage"] = coverage
 		}
 		if err != nil {
 			return fmt.Errorf("unmarshal unit-test report error: %v", err)
 		}
 	}
 
+	// go test -json 等格式的报告可能只上报 suites，此时根据 suites 汇总 totals
+	if _, ok := meta["totals"]; !ok {
+		if suites, ok := meta["suites"].([]apistructs.TestSuite); ok {
+			meta["totals"] = aggregateTotals(suites)
+		}
+	}
+
 	meta["taskId"] = ctx.SDK.Task.ID
 
 	_, err := ctx.SDK.Report.Create(apistructs.PipelineReportCreateRequest{
@@ -77,3 +89,19 @@ func (p *Plugin) Handle(ctx *aoptypes.TuneContext) error {
 
 	return nil
 }
+
+func aggregateTotals(suites []apistructs.TestSuite) apistructs.TestTotals {
+	totals := apistructs.TestTotals{Statuses: make(map[apistructs.TestStatus]int)}
+	for i := range suites {
+		if suites[i].Totals == nil {
+			s := qaparser.Suite{TestSuite: &suites[i]}
+			s.Aggregate()
+		}
+		totals.Tests += suites[i].Totals.Tests
+		totals.Duration += suites[i].Totals.Duration
+		for status, n := range suites[i].Totals.Statuses {
+			totals.Statuses[status] += n
+		}
+	}
+	return totals
+}
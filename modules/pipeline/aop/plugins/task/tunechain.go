@@ -75,3 +75,11 @@ var TuneTriggerChains = map[aoptypes.TuneTrigger]aoptypes.TuneChain{
 		autotest_cookie_keep_after.New(),
 	},
 }
+
+// TunePoints 保存任务所有可通过配置编排的内置插件，key 为插件目录名
+var TunePoints = map[string]func() aoptypes.TunePoint{
+	"echo":                        func() aoptypes.TunePoint { return echo.New() },
+	"unit_test_report":            func() aoptypes.TunePoint { return unit_test_report.New() },
+	"autotest_cookie_keep_before": func() aoptypes.TunePoint { return autotest_cookie_keep_before.New() },
+	"autotest_cookie_keep_after":  func() aoptypes.TunePoint { return autotest_cookie_keep_after.New() },
+}
@@ -16,6 +16,7 @@ package conf
 
 import (
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/erda-project/erda/pkg/envconf"
@@ -59,6 +60,21 @@ type Conf struct {
 	// oss/nfs storage
 	PipelineStorageURL string `env:"PIPELINE_STORAGE_URL" required:"true"`
 
+	// s3 compatible object storage for context and caches, used when cluster has no shared nfs
+	OSSEndpoint        string        `env:"PIPELINE_OSS_ENDPOINT"`
+	OSSAccessKeyID     string        `env:"PIPELINE_OSS_ACCESS_KEY_ID"`
+	OSSAccessKeySecret string        `env:"PIPELINE_OSS_ACCESS_KEY_SECRET"`
+	OSSBucket          string        `env:"PIPELINE_OSS_BUCKET"`
+	OSSRegion          string        `env:"PIPELINE_OSS_REGION"`
+	OSSClusters        string        `env:"PIPELINE_OSS_CLUSTERS"` // cluster1,cluster2; * means all clusters
+	OSSContextExpireIn time.Duration `env:"PIPELINE_OSS_CONTEXT_EXPIRE_IN" default:"72h"`
+	OSSCacheExpireIn   time.Duration `env:"PIPELINE_OSS_CACHE_EXPIRE_IN" default:"168h"`
+	OSSPresignExpireIn time.Duration `env:"PIPELINE_OSS_PRESIGN_EXPIRE_IN" default:"1h"`
+	OSSCleanJobCron    string        `env:"PIPELINE_OSS_CLEAN_JOB_CRON" default:"0 0 1 * * ?"`
+
+	// pipeline artifacts
+	ArtifactCleanJobCron string `env:"PIPELINE_ARTIFACT_CLEAN_JOB_CRON" default:"0 0 2 * * ?"`
+
 	// action type mapping
 	ActionTypeMappingStr string `env:"ACTION_TYPE_MAPPING"` // git:git-checkout,dicehub:release
 	ActionTypeMapping    map[string]string
@@ -83,6 +99,9 @@ type Conf struct {
 
 	// queue handle loop interval
 	QueueLoopHandleIntervalSec uint64 `env:"QUEUE_LOOP_HANDLE_INTERVAL_SEC" default:"10"`
+
+	// aop tune chains config file, yaml or json
+	AOPTuneChainsConfigFile string `env:"AOP_TUNE_CHAINS_CONFIG_FILE"`
 }
 
 var cfg Conf
@@ -267,3 +286,72 @@ func GitInnerUserPassword() string {
 func QueueLoopHandleIntervalSec() uint64 {
 	return cfg.QueueLoopHandleIntervalSec
 }
+
+// OSSEndpoint 返回存储 context 和 caches 的对象存储地址.
+func OSSEndpoint() string {
+	return cfg.OSSEndpoint
+}
+
+// OSSAccessKeyID 返回对象存储 access key id.
+func OSSAccessKeyID() string {
+	return cfg.OSSAccessKeyID
+}
+
+// OSSAccessKeySecret 返回对象存储 access key secret.
+func OSSAccessKeySecret() string {
+	return cfg.OSSAccessKeySecret
+}
+
+// OSSBucket 返回对象存储 bucket.
+func OSSBucket() string {
+	return cfg.OSSBucket
+}
+
+// OSSRegion 返回对象存储 region.
+func OSSRegion() string {
+	return cfg.OSSRegion
+}
+
+// OSSEnabledForCluster 返回集群是否使用对象存储保存 context 和 caches.
+func OSSEnabledForCluster(clusterName string) bool {
+	if cfg.OSSEndpoint == "" || cfg.OSSBucket == "" {
+		return false
+	}
+	for _, c := range strings.Split(cfg.OSSClusters, ",") {
+		c = strings.TrimSpace(c)
+		if c == "*" || (c != "" && c == clusterName) {
+			return true
+		}
+	}
+	return false
+}
+
+// OSSContextExpireIn 返回对象存储中 context 的过期时间.
+func OSSContextExpireIn() time.Duration {
+	return cfg.OSSContextExpireIn
+}
+
+// OSSCacheExpireIn 返回对象存储中 caches 的过期时间，缓存命中时会续期.
+func OSSCacheExpireIn() time.Duration {
+	return cfg.OSSCacheExpireIn
+}
+
+// OSSPresignExpireIn 返回 agent 按需获取的对象存储临时地址的有效期，需覆盖单个对象或分片的上传时间.
+func OSSPresignExpireIn() time.Duration {
+	return cfg.OSSPresignExpireIn
+}
+
+// OSSCleanJobCron 返回对象存储清理任务的 cron 表达式.
+func OSSCleanJobCron() string {
+	return cfg.OSSCleanJobCron
+}
+
+// ArtifactCleanJobCron 返回过期制品清理任务的 cron 表达式.
+func ArtifactCleanJobCron() string {
+	return cfg.ArtifactCleanJobCron
+}
+
+// AOPTuneChainsConfigFile return aop tune chains config file path.
+func AOPTuneChainsConfigFile() string {
+	return cfg.AOPTuneChainsConfigFile
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func (client *Client) CreatePipelineArtifact(artifact *spec.PipelineArtifact, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.InsertOne(artifact)
+	return err
+}
+
+func (client *Client) GetPipelineArtifact(id uint64, ops ...SessionOption) (spec.PipelineArtifact, bool, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	var artifact spec.PipelineArtifact
+	exist, err := session.ID(id).Get(&artifact)
+	return artifact, exist, err
+}
+
+// ListPipelineArtifacts 查询流水线制品，taskID 为 0 时查询流水线下所有制品
+func (client *Client) ListPipelineArtifacts(pipelineID, taskID uint64, ops ...SessionOption) ([]spec.PipelineArtifact, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	sql := session.Where("pipeline_id = ?", pipelineID)
+	if taskID > 0 {
+		sql = sql.And("task_id = ?", taskID)
+	}
+	var artifacts []spec.PipelineArtifact
+	if err := sql.Asc("id").Find(&artifacts); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// ListExpiredPipelineArtifacts 查询 before 之前过期的制品
+func (client *Client) ListExpiredPipelineArtifacts(before time.Time, limit int, ops ...SessionOption) ([]spec.PipelineArtifact, error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	var artifacts []spec.PipelineArtifact
+	if err := session.Where("expired_at is not null and expired_at <= ?", before).Asc("id").Limit(limit).Find(&artifacts); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+func (client *Client) DeletePipelineArtifact(id uint64, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.ID(id).Delete(&spec.PipelineArtifact{})
+	return err
+}
+
+func (client *Client) DeletePipelineArtifactsByPipelineID(pipelineID uint64, ops ...SessionOption) error {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	_, err := session.Where("pipeline_id = ?", pipelineID).Delete(&spec.PipelineArtifact{})
+	return err
+}
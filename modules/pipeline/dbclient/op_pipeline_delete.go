@@ -56,6 +56,11 @@ func (client *Client) DeletePipelineRelated(pipelineID uint64, ops ...SessionOpt
 		return err
 	}
 
+	// related pipeline artifacts, 文件由文件服务按过期时间清理
+	if err := client.DeletePipelineArtifactsByPipelineID(pipelineID, ops...); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -63,6 +63,27 @@ func (client *Client) CreatePipelineLabels(p *spec.Pipeline, ops ...SessionOptio
 	return err
 }
 
+// CreatePipelineLabelIfNotExist 为 pipeline 实例创建单个 label，key 已存在时忽略
+func (client *Client) CreatePipelineLabelIfNotExist(p *spec.Pipeline, key, value string, ops ...SessionOption) (err error) {
+	session := client.NewSession(ops...)
+	defer session.Close()
+
+	defer func() { err = errors.Wrap(err, "failed to create pipeline label") }()
+	exist, err := session.Exist(&spec.PipelineLabel{Type: apistructs.PipelineLabelTypeInstance, TargetID: p.ID, Key: key})
+	if err != nil || exist {
+		return err
+	}
+	_, err = session.InsertOne(&spec.PipelineLabel{
+		Type:            apistructs.PipelineLabelTypeInstance,
+		PipelineSource:  p.PipelineSource,
+		PipelineYmlName: p.PipelineYmlName,
+		TargetID:        p.ID,
+		Key:             key,
+		Value:           value,
+	})
+	return err
+}
+
 // ListLabelsByPipelineID 根据 pipelineID 获取 labels
 func (client *Client) ListLabelsByPipelineID(pipelineID uint64, ops ...SessionOption) ([]spec.PipelineLabel, error) {
 	session := client.NewSession(ops...)
@@ -14,6 +14,7 @@
 package dbclient
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -36,6 +37,7 @@ const (
 	queueLabelKeyConcurrency      string = "__queue_concurrency"
 	queueLabelKeyMaxCPU           string = "__queue_max_cpu"
 	queueLabelKeyMaxMemoryMB      string = "__queue_max_memory_MB"
+	queueLabelKeyFairShare        string = "__queue_fair_share"
 )
 
 // CreatePipelineQueue
@@ -96,6 +98,13 @@ func (client *Client) createPipelineQueueFields(req apistructs.PipelineQueueCrea
 		maxCPULabel,
 		maxMemoryMBLabel,
 	}
+	if req.ScheduleStrategy == apistructs.ScheduleStrategyInsidePipelineQueueOfFairShare && req.FairShare != nil {
+		fairShareBytes, err := json.Marshal(req.FairShare)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal queue fair share, queueID: %d, err: %v", queueID, err)
+		}
+		queueMetaLabels = append(queueMetaLabels, genMetaLabelFunc(queueID, req.PipelineSource, queueLabelKeyFairShare, string(fairShareBytes)))
+	}
 	for k, v := range req.Labels {
 		queueMetaLabels = append(queueMetaLabels, genMetaLabelFunc(queueID, req.PipelineSource, k, v))
 	}
@@ -170,6 +179,12 @@ func constructQueueByLabels(labels []spec.PipelineLabel) (*apistructs.PipelineQu
 				return nil, fmt.Errorf("failed to construct queue for maxMemoryMB, queueID: %d, value: %s, err: %v", q.ID, label.Value, err)
 			}
 			q.MaxMemoryMB = maxMemoryMB
+		case queueLabelKeyFairShare:
+			var fairShare apistructs.PipelineQueueFairShare
+			if err := json.Unmarshal([]byte(label.Value), &fairShare); err != nil {
+				return nil, fmt.Errorf("failed to construct queue for fairShare, queueID: %d, value: %s, err: %v", q.ID, label.Value, err)
+			}
+			q.FairShare = &fairShare
 
 		default:
 			// other labels
@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler"
 	"github.com/erda-project/erda/modules/pipeline/services/actionagentsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/appsvc"
+	"github.com/erda-project/erda/modules/pipeline/services/artifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildartifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildcachesvc"
 	"github.com/erda-project/erda/modules/pipeline/services/cmsvc"
@@ -51,6 +52,7 @@ type Endpoints struct {
 	extMarketSvc     *extmarketsvc.ExtMarketSvc
 	snippetSvc       *snippetsvc.SnippetSvc
 	reportSvc        *reportsvc.ReportSvc
+	artifactSvc      *artifactsvc.ArtifactSvc
 	queueManage      *queuemanage.QueueManage
 
 	dbClient           *dbclient.Client
@@ -150,6 +152,12 @@ func WithReportSvc(svc *reportsvc.ReportSvc) Option {
 	}
 }
 
+func WithArtifactSvc(svc *artifactsvc.ArtifactSvc) Option {
+	return func(e *Endpoints) {
+		e.artifactSvc = svc
+	}
+}
+
 func WithQueueManage(qm *queuemanage.QueueManage) Option {
 	return func(e *Endpoints) {
 		e.queueManage = qm
@@ -191,6 +199,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// tasks
 		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}", Method: http.MethodGet, Handler: e.pipelineTaskDetail},
 		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/get-bootstrap-info", Method: http.MethodGet, Handler: e.taskBootstrapInfo},
+		{Path: "/api/pipelines/{pipelineID}/tasks/{taskID}/actions/oss", Method: http.MethodPost, Handler: e.taskOSSObject},
 
 		// cms
 		{Path: "/api/pipelines/cms/ns", Method: http.MethodPost, Handler: e.createCmsNs},
@@ -241,5 +250,9 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// reports
 		{Path: "/api/pipeline-reportsets/{pipelineID}", Method: http.MethodGet, Handler: e.queryPipelineReportSet},
 		{Path: "/api/pipeline-reportsets", Method: http.MethodGet, Handler: e.pagingPipelineReportSets},
+
+		// artifacts
+		{Path: "/api/pipelines/{pipelineID}/artifacts", Method: http.MethodGet, Handler: e.listPipelineArtifacts},
+		{Path: "/api/pipelines/{pipelineID}/artifacts/{artifactID}/actions/download", Method: http.MethodGet, WriterHandler: e.downloadPipelineArtifact},
 	}
 }
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/pkg/httpserver"
+	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+func (e *Endpoints) listPipelineArtifacts(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+
+	var req apistructs.PipelineArtifactListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListPipelineArtifacts.InvalidParameter(err).ToResp(), nil
+	}
+
+	pipelineIDStr := vars[pathPipelineID]
+	pipelineID, err := strconv.ParseUint(pipelineIDStr, 10, 64)
+	if err != nil {
+		return apierrors.ErrListPipelineArtifacts.InvalidParameter(
+			strutil.Concat(pathPipelineID, ": ", pipelineIDStr)).ToResp(), nil
+	}
+	req.PipelineID = pipelineID
+
+	p, err := e.pipelineSvc.Get(pipelineID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	// 校验用户在应用对应分支下是否有 GET 权限
+	if err := e.checkBranchPermission(r, p.Labels[apistructs.LabelAppID], p.Labels[apistructs.LabelBranch], apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	artifacts, err := e.artifactSvc.List(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(artifacts)
+}
+
+func (e *Endpoints) downloadPipelineArtifact(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+
+	pipelineIDStr := vars[pathPipelineID]
+	pipelineID, err := strconv.ParseUint(pipelineIDStr, 10, 64)
+	if err != nil {
+		return apierrors.ErrDownloadPipelineArtifact.InvalidParameter(
+			strutil.Concat(pathPipelineID, ": ", pipelineIDStr))
+	}
+
+	artifactIDStr := vars[pathArtifactID]
+	artifactID, err := strconv.ParseUint(artifactIDStr, 10, 64)
+	if err != nil {
+		return apierrors.ErrDownloadPipelineArtifact.InvalidParameter(
+			strutil.Concat(pathArtifactID, ": ", artifactIDStr))
+	}
+
+	p, err := e.pipelineSvc.Get(pipelineID)
+	if err != nil {
+		return err
+	}
+
+	// 校验用户在应用对应分支下是否有 GET 权限
+	if err := e.checkBranchPermission(r, p.Labels[apistructs.LabelAppID], p.Labels[apistructs.LabelBranch], apistructs.GetAction); err != nil {
+		return err
+	}
+
+	artifact, body, err := e.artifactSvc.Download(pipelineID, artifactID)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", artifact.FileName))
+	if artifact.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(artifact.Size, 10))
+	}
+	// header 已写入，传输失败只能记录日志
+	if _, err := io.Copy(w, body); err != nil {
+		logrus.Errorf("failed to write pipeline artifact, pipelineID: %d, artifactID: %d, err: %v", pipelineID, artifactID, err)
+	}
+	return nil
+}
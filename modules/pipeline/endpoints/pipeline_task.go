@@ -18,12 +18,17 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/actionagent"
+	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
 	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/httpserver"
 	"github.com/erda-project/erda/pkg/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/storage"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -82,7 +87,7 @@ func (e *Endpoints) taskBootstrapInfo(ctx context.Context, r *http.Request, vars
 			strutil.Concat(pathTaskID, ": ", taskIDStr)).ToResp(), nil
 	}
 
-	p, err := e.pipelineSvc.Detail(pipelineID)
+	p, err := e.pipelineSvc.Get(pipelineID)
 	if err != nil {
 		return errorresp.ErrResp(err)
 	}
@@ -108,6 +113,11 @@ func (e *Endpoints) taskBootstrapInfo(ctx context.Context, r *http.Request, vars
 		Commands:    task.Extra.Action.Commands,
 		Context:     task.Context,
 		PrivateEnvs: task.Extra.PrivateEnvs,
+		// action-agent 据此在日志中脱敏
+		SecretValues: getTaskSecretValues(p, task),
+	}
+	for _, artifact := range task.Extra.Action.Artifacts {
+		bootstrapInfo.Artifacts = append(bootstrapInfo.Artifacts, artifact.ToApiArtifact())
 	}
 	b, err := json.Marshal(&bootstrapInfo)
 	if err != nil {
@@ -119,3 +129,70 @@ func (e *Endpoints) taskBootstrapInfo(ctx context.Context, r *http.Request, vars
 
 	return httpserver.OkResp(bootstrapInfoData)
 }
+
+// taskOSSObject action-agent 读写对象存储中的 context 和 caches，按需生成临时地址
+func (e *Endpoints) taskOSSObject(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	pipelineIDStr := vars[pathPipelineID]
+	pipelineID, err := strconv.ParseUint(pipelineIDStr, 10, 64)
+	if err != nil {
+		return apierrors.ErrHandleTaskOSSObject.InvalidParameter(
+			strutil.Concat(pathPipelineID, ": ", pipelineIDStr)).ToResp(), nil
+	}
+
+	taskIDStr := vars[pathTaskID]
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		return apierrors.ErrHandleTaskOSSObject.InvalidParameter(
+			strutil.Concat(pathTaskID, ": ", taskIDStr)).ToResp(), nil
+	}
+
+	var req apistructs.PipelineTaskOSSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrHandleTaskOSSObject.InvalidParameter(err).ToResp(), nil
+	}
+
+	if conf.OSSEndpoint() == "" || conf.OSSBucket() == "" {
+		return apierrors.ErrHandleTaskOSSObject.InvalidState("object storage is not configured").ToResp(), nil
+	}
+
+	task, err := e.pipelineSvc.TaskDetail(taskID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if task.PipelineID != pipelineID {
+		return apierrors.ErrHandleTaskOSSObject.InvalidParameter("task not belong to pipeline").ToResp(), nil
+	}
+
+	// 只有 action-agent 会使用 token 方式调用该接口，openapi checkToken 已校验 token 只能访问本 task，这里校验对象属于本 task
+	if err := pvolumes.CheckTaskOSSObjectAccess(task, &req); err != nil {
+		return apierrors.ErrHandleTaskOSSObject.AccessDenied().ToResp(), nil
+	}
+	presigner := storage.NewS3(conf.OSSEndpoint(), conf.OSSAccessKeyID(), conf.OSSAccessKeySecret(), conf.OSSBucket(),
+		storage.WithS3Region(conf.OSSRegion()))
+	data, err := pvolumes.HandleTaskOSSObject(task, presigner, conf.OSSPresignExpireIn(), &req)
+	if err != nil {
+		return apierrors.ErrHandleTaskOSSObject.InternalError(err).ToResp(), nil
+	}
+	return httpserver.OkResp(data)
+}
+
+// getTaskSecretValues 返回 task 日志中需要脱敏的 secret 值:
+// 私有配置（无论是否加密存储，文件类型的值为文件 uuid 除外）、平台密码类配置以及注入的访问凭证
+func getTaskSecretValues(p *spec.Pipeline, task *spec.PipelineTask) []string {
+	var values []string
+	for key, v := range p.Snapshot.Secrets {
+		if _, ok := p.Snapshot.CmsDiceFiles[key]; ok {
+			continue
+		}
+		values = append(values, v)
+	}
+	for key, v := range p.Snapshot.PlatformSecrets {
+		if strings.HasSuffix(key, ".password") {
+			values = append(values, v)
+		}
+	}
+	if v, ok := task.Extra.PrivateEnvs[apistructs.EnvOpenapiToken]; ok {
+		values = append(values, v)
+	}
+	return strutil.DedupSlice(values, true)
+}
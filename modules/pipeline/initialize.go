@@ -35,6 +35,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler"
 	"github.com/erda-project/erda/modules/pipeline/services/actionagentsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/appsvc"
+	"github.com/erda-project/erda/modules/pipeline/services/artifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildartifactsvc"
 	"github.com/erda-project/erda/modules/pipeline/services/buildcachesvc"
 	"github.com/erda-project/erda/modules/pipeline/services/cmsvc"
@@ -121,6 +122,7 @@ func do() (*httpserver.Server, error) {
 	extMarketSvc := extmarketsvc.New(bdl)
 	pipelineCronSvc := pipelinecronsvc.New(dbClient, crondSvc)
 	reportSvc := reportsvc.New(reportsvc.WithDBClient(dbClient))
+	artifactSvc := artifactsvc.New(artifactsvc.WithDBClient(dbClient), artifactsvc.WithBundle(bdl))
 	queueManage := queuemanage.New(queuemanage.WithDBClient(dbClient))
 
 	// pipeline engine
@@ -132,6 +134,7 @@ func do() (*httpserver.Server, error) {
 
 	pipelineFun := &reconciler.PipelineSvcFunc{
 		CronNotExecuteCompensate: pipelineSvc.CronNotExecuteCompensateById,
+		CancelPipeline:           pipelineSvc.Cancel,
 	}
 
 	snippetSvc := snippetsvc.New(dbClient, bdl)
@@ -165,6 +168,7 @@ func do() (*httpserver.Server, error) {
 		endpoints.WithPipelineSvc(pipelineSvc),
 		endpoints.WithSnippetSvc(snippetSvc),
 		endpoints.WithReportSvc(reportSvc),
+		endpoints.WithArtifactSvc(artifactSvc),
 		endpoints.WithQueueManage(queueManage),
 		endpoints.WithReconciler(r),
 	)
@@ -183,7 +187,9 @@ func do() (*httpserver.Server, error) {
 	go pipeline_network_hook_client.RegisterLifecycleHookClient(dbClient)
 
 	// aop
-	aop.Initialize(bdl, dbClient, reportSvc)
+	if err := aop.Initialize(bdl, dbClient, reportSvc); err != nil {
+		return nil, err
+	}
 
 	// engine start after all dependencies done
 	engine.Start()
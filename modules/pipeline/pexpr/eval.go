@@ -26,10 +26,34 @@ import (
 // exprStr: 表达式
 // placeholderParams: 占位符参数
 func Eval(exprStr string, placeholderParams map[string]string) (interface{}, error) {
+	exprStr, err := Render(exprStr, placeholderParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算表达式
+	expr, err := govaluate.NewEvaluableExpression(exprStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %s, err: %v", exprStr, err)
+	}
+	strParams := make(map[string]interface{}, len(placeholderParams))
+	for k, v := range placeholderParams {
+		strParams[k] = v
+	}
+	result, err := expr.Evaluate(strParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expr, expr: %s, err: %v", exprStr, err)
+	}
+
+	return result, nil
+}
+
+// Render 递归渲染占位符，不计算表达式，用于拼接字符串，例如并发组名
+func Render(exprStr string, placeholderParams map[string]string) (string, error) {
 	// 校验表达式
 	invalidPhs := FindInvalidPlaceholders(exprStr)
 	if len(invalidPhs) > 0 {
-		return nil, fmt.Errorf("invalid expression, found invalid placeholders: %s (must match: %s)", strings.Join(invalidPhs, ", "), PhRe.String())
+		return "", fmt.Errorf("invalid expression, found invalid placeholders: %s (must match: %s)", strings.Join(invalidPhs, ", "), PhRe.String())
 	}
 
 	// 递归渲染表达式中的占位符
@@ -48,7 +72,7 @@ func Eval(exprStr string, placeholderParams map[string]string) (interface{}, err
 			return exprStr
 		})
 		if len(notFoundPlaceholders) > 0 {
-			return nil, fmt.Errorf("invalid expression, not found placeholders: %s", strings.Join(notFoundPlaceholders, ", "))
+			return "", fmt.Errorf("invalid expression, not found placeholders: %s", strings.Join(notFoundPlaceholders, ", "))
 		}
 		// 没有需要替换的占位符，则退出渲染
 		if !PhRe.MatchString(exprStr) {
@@ -56,19 +80,5 @@ func Eval(exprStr string, placeholderParams map[string]string) (interface{}, err
 		}
 	}
 
-	// 计算表达式
-	expr, err := govaluate.NewEvaluableExpression(exprStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid expression: %s, err: %v", exprStr, err)
-	}
-	strParams := make(map[string]interface{}, len(placeholderParams))
-	for k, v := range placeholderParams {
-		strParams[k] = v
-	}
-	result, err := expr.Evaluate(strParams)
-	if err != nil {
-		return nil, fmt.Errorf("failed to evaluate expr, expr: %s, err: %v", exprStr, err)
-	}
-
-	return result, nil
+	return exprStr, nil
 }
@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pexpr_params
+
+import (
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+// GenerateParamsFromPipeline 生成 pipeline 级别用于计算表达式的参数，不依赖 task
+// 包括：
+// - 占位符参数
+//   - configs.key
+//   - params.key
+//
+// - 流水线信息
+//   - pipeline.id
+//   - pipeline.source
+//   - pipeline.yml_name
+//   - pipeline.branch
+//   - pipeline.env
+//   - pipeline.project_id
+//   - pipeline.app_id
+func GenerateParamsFromPipeline(p *spec.Pipeline) map[string]string {
+	params := make(map[string]string)
+
+	// configs
+	for k, v := range generateConfigs(p) {
+		params[k] = v
+	}
+
+	// run params
+	for _, rp := range p.Snapshot.RunPipelineParams {
+		value := rp.TrueValue
+		if value == nil {
+			value = rp.Value
+		}
+		params[fmt.Sprintf("params.%s", rp.Name)] = fmt.Sprintf("%v", value)
+	}
+
+	// pipeline info
+	params["pipeline.id"] = fmt.Sprintf("%d", p.ID)
+	params["pipeline.source"] = p.PipelineSource.String()
+	params["pipeline.yml_name"] = p.PipelineYmlName
+	params["pipeline.branch"] = p.GetLabel(apistructs.LabelBranch)
+	params["pipeline.env"] = p.Extra.DiceWorkspace.String()
+	params["pipeline.project_id"] = p.GetLabel(apistructs.LabelProjectID)
+	params["pipeline.app_id"] = p.GetLabel(apistructs.LabelAppID)
+
+	return params
+}
@@ -41,18 +41,8 @@ func ParseDiceYmlJobBinds(diceYmlJob *diceyml.Job) ([]apistructs.Bind, error) {
 
 // GenerateTaskCommonBinds 生成 task 通用 binds
 func GenerateTaskCommonBinds(mountPoint string) []apistructs.Bind {
-
-	const (
-		dockerSock = "/var/run/docker.sock"
-	)
-
 	var binds []apistructs.Bind
-	dockerSockBind := apistructs.Bind{
-		HostPath:      dockerSock,
-		ContainerPath: dockerSock,
-		ReadOnly:      true,
-	}
-	binds = append(binds, dockerSockBind)
+	binds = append(binds, generateDockerSockBind())
 
 	storageURL := conf.StorageURL()
 	URL, _ := url.Parse(storageURL)
@@ -68,3 +58,14 @@ func GenerateTaskCommonBinds(mountPoint string) []apistructs.Bind {
 	}
 	return binds
 }
+
+func generateDockerSockBind() apistructs.Bind {
+	const (
+		dockerSock = "/var/run/docker.sock"
+	)
+	return apistructs.Bind{
+		HostPath:      dockerSock,
+		ContainerPath: dockerSock,
+		ReadOnly:      true,
+	}
+}
@@ -27,3 +27,8 @@ func GenerateTaskDiceFileVolume(fileName, fileUUID, fileContainerPath string) ap
 	}
 	return vo
 }
+
+// GenerateTaskArtifactVolume 生成制品卷，由 agent 下载制品文件并解压到 artifactContainerPath
+func GenerateTaskArtifactVolume(artifactName, fileUUID, artifactContainerPath string) apistructs.MetadataField {
+	return GenerateTaskDiceFileVolume(artifactName, fileUUID, artifactContainerPath)
+}
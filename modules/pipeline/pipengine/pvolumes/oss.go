@@ -0,0 +1,218 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pvolumes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+	"github.com/erda-project/erda/pkg/storage"
+)
+
+const (
+	OSSContextPrefix = "pipeline/contexts"
+	OSSCachePrefix   = "pipeline/caches"
+
+	// TaskCacheDigestSuffix 缓存目录指纹文件后缀，和缓存 tar 存放在一起，用于跳过未变化缓存的上传
+	TaskCacheDigestSuffix = ".sha256"
+
+	// MaxOSSPartNumber S3 协议允许的最大分片序号
+	MaxOSSPartNumber = 10000
+)
+
+// OSSPresigner 生成单个对象的临时访问地址，分片上传的创建、合并与取消由持有凭证的 pipeline 完成
+type OSSPresigner interface {
+	PresignedURL(path string, expires time.Duration, filename string) (string, error)
+	PresignedPutURL(path string, expires time.Duration) (string, error)
+	CreateMultipartUpload(path string) (string, error)
+	PresignedUploadPartURL(path, uploadID string, partNumber int, expires time.Duration) (string, error)
+	CompleteMultipartUpload(path, uploadID string, parts []storage.CompletedPart) error
+	AbortMultipartUpload(path, uploadID string) error
+}
+
+// GenerateTaskOSSVolume 生成对象存储类型的 context，store 时上传 namespace 目录，restore 时解压到 containerContext 下
+func GenerateTaskOSSVolume(task spec.PipelineTask, namespace string) apistructs.MetadataField {
+	return apistructs.MetadataField{
+		Name:  namespace,
+		Value: spec.StoreTypeOSSProto + MakeOSSContextObjectKey(task.PipelineID, namespace),
+		Type:  string(spec.StoreTypeOSS),
+		Labels: map[string]string{
+			VoLabelKeyContainerPath: MakeTaskContainerWorkdir(namespace),
+			VoLabelKeyStageOrder:    fmt.Sprintf("%d", task.Extra.StageOrder),
+		},
+	}
+}
+
+// MakeOSSContextObjectKey 生成 context 在对象存储中的 key，同一条流水线的 context 在同一前缀下，便于清理
+func MakeOSSContextObjectKey(pipelineID uint64, namespace string) string {
+	return path.Join(OSSContextPrefix, fmt.Sprintf("%d", pipelineID), namespace+TaskCacheCompressionSuffix)
+}
+
+// HandleTaskCacheOSSVolumes 和 HandleTaskCacheVolumes 逻辑一致，缓存保存在对象存储中
+// 缓存 key 为 projectID、appID、缓存 key 和路径的内容寻址，相同声明的缓存在流水线之间共享
+func HandleTaskCacheOSSVolumes(p *spec.Pipeline, task *spec.PipelineTask) {
+	caches := task.Extra.Action.Caches
+	if len(caches) == 0 {
+		return
+	}
+
+	projectID := p.GetLabel(apistructs.LabelProjectID)
+	appID := p.GetLabel(apistructs.LabelAppID)
+
+	var volumes []apistructs.MetadataField
+	for _, cache := range caches {
+		pathHash := sha256Hex(cache.Path)
+		key := MakeOSSCacheObjectKey(projectID, appID, cache.Key, cache.Path)
+
+		labels := make(map[string]string)
+		labels[TaskCacheHashName] = pathHash
+		labels[TaskCachePath] = cache.Path
+		volumes = append(volumes, apistructs.MetadataField{
+			Name:   TaskCacheMame + "_" + pathHash,
+			Type:   string(spec.StoreTypeDiceCacheOSS),
+			Value:  spec.StoreTypeOSSProto + key,
+			Labels: labels,
+		})
+	}
+
+	task.Context.InStorages = append(task.Context.InStorages, volumes...)
+	task.Context.OutStorages = append(task.Context.OutStorages, volumes...)
+}
+
+// MakeOSSCacheObjectKey 生成缓存在对象存储中的 key
+// 占位符的处理和 nfs 一致，最终 key 为替换后结果的 sha256
+func MakeOSSCacheObjectKey(projectID, appID, cacheKey, cachePath string) string {
+	pathHash := sha256Hex(cachePath)
+	key := strings.ReplaceAll(cacheKey, " ", "")
+	if key == "" {
+		key = path.Join(TaskCachePathBasePath, TaskCachePathEndPath)
+	}
+	key = strings.ReplaceAll(key, TaskCachePathBasePath, path.Join(projectID, appID))
+	key = strings.ReplaceAll(key, TaskCachePathEndPath, pathHash)
+	return path.Join(OSSCachePrefix, sha256Hex(key)+TaskCacheCompressionSuffix)
+}
+
+// GenerateTaskOSSBinds 对象存储模式下集群没有共享存储，binds 只包含 docker.sock 和 action 声明的宿主机目录
+// action 声明的目录位于共享存储挂载点下时无法挂载，直接报错
+func GenerateTaskOSSBinds(diceYmlJob *diceyml.Job, mountPoint string) ([]apistructs.Bind, error) {
+	jobBinds, err := ParseDiceYmlJobBinds(diceYmlJob)
+	if err != nil {
+		return nil, err
+	}
+	binds := []apistructs.Bind{generateDockerSockBind()}
+	mountPoint = strings.TrimSuffix(mountPoint, "/")
+	for _, bind := range jobBinds {
+		if mountPoint != "" && (bind.HostPath == mountPoint || strings.HasPrefix(bind.HostPath, mountPoint+"/")) {
+			return nil, fmt.Errorf("bind %s is under storage mount point %s, which is not available when context and caches are stored in object storage",
+				bind.HostPath, mountPoint)
+		}
+		binds = append(binds, bind)
+	}
+	return binds, nil
+}
+
+// HandleTaskOSSObject 处理 agent 读写 context 和 caches 的请求，context 和 caches 只保存对象 key，临时地址按需生成
+// InStorages 中的对象只能读，OutStorages 中的对象只能写，缓存指纹文件可读写
+func HandleTaskOSSObject(task *spec.PipelineTask, presigner OSSPresigner, expires time.Duration,
+	req *apistructs.PipelineTaskOSSRequest) (*apistructs.PipelineTaskOSSResponseData, error) {
+	if err := CheckTaskOSSObjectAccess(task, req); err != nil {
+		return nil, err
+	}
+
+	var (
+		data apistructs.PipelineTaskOSSResponseData
+		err  error
+	)
+	switch req.Action {
+	case apistructs.PipelineTaskOSSActionGet:
+		data.URL, err = presigner.PresignedURL(req.Key, expires, "")
+	case apistructs.PipelineTaskOSSActionPut:
+		data.URL, err = presigner.PresignedPutURL(req.Key, expires)
+	case apistructs.PipelineTaskOSSActionCreateMultipart:
+		data.UploadID, err = presigner.CreateMultipartUpload(req.Key)
+	case apistructs.PipelineTaskOSSActionUploadPart:
+		if req.UploadID == "" || req.PartNumber < 1 || req.PartNumber > MaxOSSPartNumber {
+			return nil, fmt.Errorf("invalid upload part, uploadID: %q, partNumber: %d", req.UploadID, req.PartNumber)
+		}
+		data.URL, err = presigner.PresignedUploadPartURL(req.Key, req.UploadID, req.PartNumber, expires)
+	case apistructs.PipelineTaskOSSActionCompleteMultipart:
+		if req.UploadID == "" || len(req.Parts) == 0 {
+			return nil, fmt.Errorf("invalid multipart upload, uploadID: %q, parts: %d", req.UploadID, len(req.Parts))
+		}
+		parts := make([]storage.CompletedPart, 0, len(req.Parts))
+		for _, part := range req.Parts {
+			parts = append(parts, storage.CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+		}
+		err = presigner.CompleteMultipartUpload(req.Key, req.UploadID, parts)
+	case apistructs.PipelineTaskOSSActionAbortMultipart:
+		if req.UploadID == "" {
+			return nil, fmt.Errorf("missing uploadID")
+		}
+		err = presigner.AbortMultipartUpload(req.Key, req.UploadID)
+	default:
+		return nil, fmt.Errorf("invalid action: %s", req.Action)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s, err: %v", req.Action, req.Key, err)
+	}
+	return &data, nil
+}
+
+// CheckTaskOSSObjectAccess 校验 task 是否可以对该对象执行请求的操作
+func CheckTaskOSSObjectAccess(task *spec.PipelineTask, req *apistructs.PipelineTaskOSSRequest) error {
+	readable, writable := taskOSSObjectKeys(task)
+	write := req.Action != apistructs.PipelineTaskOSSActionGet
+	if _, ok := readable[req.Key]; !write && !ok {
+		return fmt.Errorf("object %s is not readable by task %d", req.Key, task.ID)
+	}
+	if _, ok := writable[req.Key]; write && !ok {
+		return fmt.Errorf("object %s is not writable by task %d", req.Key, task.ID)
+	}
+	return nil
+}
+
+// taskOSSObjectKeys 返回 task 可读和可写的对象 key
+func taskOSSObjectKeys(task *spec.PipelineTask) (readable, writable map[string]struct{}) {
+	readable, writable = make(map[string]struct{}), make(map[string]struct{})
+	collect := func(vos []apistructs.MetadataField, keys map[string]struct{}) {
+		for _, vo := range vos {
+			if vo.Type != string(spec.StoreTypeOSS) && vo.Type != string(spec.StoreTypeDiceCacheOSS) {
+				continue
+			}
+			key := strings.TrimPrefix(vo.Value, spec.StoreTypeOSSProto)
+			keys[key] = struct{}{}
+			// 缓存命中或未变化时都需要刷新指纹文件
+			if vo.Type == string(spec.StoreTypeDiceCacheOSS) {
+				readable[key+TaskCacheDigestSuffix] = struct{}{}
+				writable[key+TaskCacheDigestSuffix] = struct{}{}
+			}
+		}
+	}
+	collect(task.Context.InStorages, readable)
+	collect(task.Context.OutStorages, writable)
+	return
+}
+
+func sha256Hex(s string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(s))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
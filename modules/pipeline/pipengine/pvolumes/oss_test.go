@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pvolumes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+	"github.com/erda-project/erda/pkg/storage"
+)
+
+type fakePresigner struct {
+	completed []storage.CompletedPart
+	aborted   bool
+}
+
+func (*fakePresigner) PresignedURL(path string, expires time.Duration, filename string) (string, error) {
+	return fmt.Sprintf("GET %s", path), nil
+}
+
+func (*fakePresigner) PresignedPutURL(path string, expires time.Duration) (string, error) {
+	return fmt.Sprintf("PUT %s", path), nil
+}
+
+func (*fakePresigner) CreateMultipartUpload(path string) (string, error) {
+	return "upload-id", nil
+}
+
+func (*fakePresigner) PresignedUploadPartURL(path, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	return fmt.Sprintf("PUT %s %s %d", path, uploadID, partNumber), nil
+}
+
+func (p *fakePresigner) CompleteMultipartUpload(path, uploadID string, parts []storage.CompletedPart) error {
+	p.completed = parts
+	return nil
+}
+
+func (p *fakePresigner) AbortMultipartUpload(path, uploadID string) error {
+	p.aborted = true
+	return nil
+}
+
+func TestHandleTaskOSSObject(t *testing.T) {
+	cache := apistructs.MetadataField{
+		Name:   "action_cache_x",
+		Type:   string(spec.StoreTypeDiceCacheOSS),
+		Value:  spec.StoreTypeOSSProto + "pipeline/caches/x.tar",
+		Labels: map[string]string{TaskCachePath: "/root/.m2"},
+	}
+	task := &spec.PipelineTask{ID: 1}
+	task.Context.InStorages = []apistructs.MetadataField{
+		{Name: "repo", Type: string(spec.StoreTypeOSS), Value: spec.StoreTypeOSSProto + "pipeline/contexts/1/repo.tar"},
+		cache,
+		{Name: "nfs", Type: string(spec.StoreTypeDiceVolumeNFS), Value: "/netdata/x"},
+	}
+	task.Context.OutStorages = []apistructs.MetadataField{
+		{Name: "build", Type: string(spec.StoreTypeOSS), Value: spec.StoreTypeOSSProto + "pipeline/contexts/1/build.tar"},
+		cache,
+	}
+	presigner := &fakePresigner{}
+	handle := func(action, key string) (*apistructs.PipelineTaskOSSResponseData, error) {
+		return HandleTaskOSSObject(task, presigner, time.Hour, &apistructs.PipelineTaskOSSRequest{Action: action, Key: key})
+	}
+
+	data, err := handle(apistructs.PipelineTaskOSSActionGet, "pipeline/contexts/1/repo.tar")
+	assert.NoError(t, err)
+	assert.Equal(t, "GET pipeline/contexts/1/repo.tar", data.URL)
+	data, err = handle(apistructs.PipelineTaskOSSActionPut, "pipeline/caches/x.tar.sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT pipeline/caches/x.tar.sha256", data.URL)
+	_, err = handle(apistructs.PipelineTaskOSSActionGet, "pipeline/caches/x.tar.sha256")
+	assert.NoError(t, err)
+
+	// 前置 task 的 context 只读，自己的 context 只写，其他对象不可访问
+	_, err = handle(apistructs.PipelineTaskOSSActionPut, "pipeline/contexts/1/repo.tar")
+	assert.Error(t, err)
+	_, err = handle(apistructs.PipelineTaskOSSActionGet, "pipeline/contexts/1/build.tar")
+	assert.Error(t, err)
+	_, err = handle(apistructs.PipelineTaskOSSActionGet, "pipeline/contexts/2/repo.tar")
+	assert.Error(t, err)
+	_, err = handle(apistructs.PipelineTaskOSSActionGet, "/netdata/x")
+	assert.Error(t, err)
+
+	// 分片上传
+	data, err = handle(apistructs.PipelineTaskOSSActionCreateMultipart, "pipeline/contexts/1/build.tar")
+	assert.NoError(t, err)
+	assert.Equal(t, "upload-id", data.UploadID)
+	data, err = HandleTaskOSSObject(task, presigner, time.Hour, &apistructs.PipelineTaskOSSRequest{
+		Action: apistructs.PipelineTaskOSSActionUploadPart, Key: "pipeline/contexts/1/build.tar", UploadID: "upload-id", PartNumber: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT pipeline/contexts/1/build.tar upload-id 2", data.URL)
+	_, err = HandleTaskOSSObject(task, presigner, time.Hour, &apistructs.PipelineTaskOSSRequest{
+		Action: apistructs.PipelineTaskOSSActionUploadPart, Key: "pipeline/contexts/1/build.tar", UploadID: "upload-id", PartNumber: MaxOSSPartNumber + 1})
+	assert.Error(t, err)
+	_, err = HandleTaskOSSObject(task, presigner, time.Hour, &apistructs.PipelineTaskOSSRequest{
+		Action: apistructs.PipelineTaskOSSActionCompleteMultipart, Key: "pipeline/contexts/1/build.tar", UploadID: "upload-id",
+		Parts: []apistructs.PipelineTaskOSSPart{{PartNumber: 1, ETag: "a"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []storage.CompletedPart{{PartNumber: 1, ETag: "a"}}, presigner.completed)
+	_, err = HandleTaskOSSObject(task, presigner, time.Hour, &apistructs.PipelineTaskOSSRequest{
+		Action: apistructs.PipelineTaskOSSActionAbortMultipart, Key: "pipeline/contexts/1/build.tar", UploadID: "upload-id"})
+	assert.NoError(t, err)
+	assert.True(t, presigner.aborted)
+
+	_, err = handle("delete", "pipeline/contexts/1/build.tar")
+	assert.Error(t, err)
+}
+
+func TestGenerateTaskOSSBinds(t *testing.T) {
+	binds, err := GenerateTaskOSSBinds(&diceyml.Job{Binds: []string{"/var/lib/docker:/var/lib/docker:r"}}, "/netdata/")
+	assert.NoError(t, err)
+	assert.Len(t, binds, 2)
+	assert.Equal(t, "/var/lib/docker", binds[1].HostPath)
+	assert.True(t, binds[1].ReadOnly)
+
+	_, err = GenerateTaskOSSBinds(&diceyml.Job{Binds: []string{"/netdata/devops/ci/cache:/root/.m2:rw"}}, "/netdata")
+	assert.Error(t, err)
+
+	binds, err = GenerateTaskOSSBinds(&diceyml.Job{}, "")
+	assert.NoError(t, err)
+	assert.Len(t, binds, 1)
+}
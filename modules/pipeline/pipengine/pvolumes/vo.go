@@ -34,6 +34,7 @@ var (
 
 	ContainerVolumeMountRootDir = "/.pipeline/context"                  // task volume 的挂载目录的父目录
 	ContainerDiceFilesDir       = "/.pipeline/container/cms/dice_files" // cms dice files 类型在运行时的挂载目录
+	ContainerArtifactsDir       = "/.pipeline/container/artifacts"      // 引用的制品在运行时的解压目录
 )
 
 // MakeTaskContainerWorkdir 生成 task 在容器内的 workdir 目录
@@ -55,3 +56,8 @@ func MakeTaskContainerVolumeMountDir(taskName string) string {
 func MakeTaskContainerDiceFilesPath(fileName string) string {
 	return filepath.Join(ContainerDiceFilesDir, fileName)
 }
+
+// MakeTaskContainerArtifactPath 生成引用的制品在容器内的解压目录
+func MakeTaskContainerArtifactPath(taskName, artifactName string) string {
+	return filepath.Join(ContainerArtifactsDir, taskName, artifactName)
+}
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/queue/throttler"
@@ -64,6 +65,7 @@ type Reconciler struct {
 // 该结构体为了解决假如 Reconciler 引入 pipelinesvc 导致循环依赖问题，所以将 svc 方法挂载进来
 type PipelineSvcFunc struct {
 	CronNotExecuteCompensate func(id uint64) error
+	CancelPipeline           func(req *apistructs.PipelineCancelRequest) error
 }
 
 // New generate a new reconciler.
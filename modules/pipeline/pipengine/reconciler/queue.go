@@ -20,7 +20,11 @@ import (
 // loadQueueManger
 func (r *Reconciler) loadQueueManger() error {
 	// init queue manager
-	r.QueueManager = manager.New(manager.WithDBClient(r.dbClient))
+	var ops = []manager.Option{manager.WithDBClient(r.dbClient)}
+	if r.pipelineSvcFunc != nil {
+		ops = append(ops, manager.WithCancelPipelineFunc(r.pipelineSvcFunc.CancelPipeline))
+	}
+	r.QueueManager = manager.New(ops...)
 
 	return nil
 }
@@ -0,0 +1,295 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/events"
+	"github.com/erda-project/erda/modules/pipeline/pexpr"
+	"github.com/erda-project/erda/modules/pipeline/pexpr/pexpr_params"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/loop"
+)
+
+const (
+	EventComponentConcurrencyGroup  = "ConcurrencyGroup"
+	concurrencyCancelInternalClient = "pipeline-concurrency-group"
+	concurrencyCancelMaxTimes       = 5
+)
+
+// concurrencyGroup contains pipelines with the same rendered concurrency group.
+// Only one member is active (passed the group gate) at the same time,
+// the newest member supersedes others.
+type concurrencyGroup struct {
+	key     string
+	members map[uint64]*concurrencyMember // key: pipelineID
+}
+
+type concurrencyMember struct {
+	pipelineID       uint64
+	cancelInProgress bool
+	active           bool
+	// waitCh closed when member becomes active or leaves the group
+	waitCh chan struct{}
+}
+
+// makeConcurrencyGroupKey render group expression of pipeline, return empty if pipeline doesn't declare concurrency.
+func makeConcurrencyGroupKey(p *spec.Pipeline) (string, error) {
+	if p.IsSnippet || p.Extra.ConcurrencyInfo == nil || p.Extra.ConcurrencyInfo.GroupExpr == "" {
+		return "", nil
+	}
+	group, err := pexpr.Render(p.Extra.ConcurrencyInfo.GroupExpr, pexpr_params.GenerateParamsFromPipeline(p))
+	if err != nil {
+		return "", fmt.Errorf("failed to render concurrency group: %s, err: %v", p.Extra.ConcurrencyInfo.GroupExpr, err)
+	}
+	return fmt.Sprintf("%s/%s/%s", p.PipelineSource, p.GetLabel(apistructs.LabelProjectID), group), nil
+}
+
+// enterConcurrencyGroup put pipeline into its concurrency group and cancel superseded pipelines.
+// return: waitCh which will be closed when pipeline can go on, nil means no need wait.
+func (mgr *defaultManager) enterConcurrencyGroup(p *spec.Pipeline) <-chan struct{} {
+	if mgr.cancelPipelineFunc == nil {
+		return nil
+	}
+	groupKey, err := makeConcurrencyGroupKey(p)
+	if err != nil {
+		rlog.PWarnf(p.ID, "ignore concurrency group, err: %v", err)
+		return nil
+	}
+	if groupKey == "" {
+		return nil
+	}
+
+	// persist group, so members can be restored after restart or leader changed
+	if err := mgr.dbClient.CreatePipelineLabelIfNotExist(p, apistructs.LabelConcurrencyGroup, groupKey); err != nil {
+		rlog.PWarnf(p.ID, "failed to persist concurrency group, err: %v", err)
+	}
+	var restored []*concurrencyMember
+	mgr.cLock.Lock()
+	_, groupExist := mgr.concurrencyGroups[groupKey]
+	mgr.cLock.Unlock()
+	if !groupExist {
+		restored = mgr.restoreConcurrencyGroupMembers(p, groupKey)
+	}
+
+	mgr.cLock.Lock()
+	defer mgr.cLock.Unlock()
+
+	// already entered
+	if key, ok := mgr.concurrencyGroupKeyByPipelineID[p.ID]; ok {
+		member := mgr.concurrencyGroups[key].members[p.ID]
+		if member.active {
+			return nil
+		}
+		return member.waitCh
+	}
+
+	g, ok := mgr.concurrencyGroups[groupKey]
+	if !ok {
+		g = &concurrencyGroup{key: groupKey, members: make(map[uint64]*concurrencyMember)}
+		mgr.concurrencyGroups[groupKey] = g
+	}
+	for _, member := range restored {
+		if _, entered := mgr.concurrencyGroupKeyByPipelineID[member.pipelineID]; entered {
+			continue
+		}
+		g.members[member.pipelineID] = member
+		mgr.concurrencyGroupKeyByPipelineID[member.pipelineID] = groupKey
+	}
+	member := &concurrencyMember{
+		pipelineID:       p.ID,
+		cancelInProgress: p.Extra.ConcurrencyInfo.CancelInProgress,
+		// pipeline already after queue (e.g. leader changed), cannot be blocked
+		active: p.Status.AfterPipelineQueue(),
+		waitCh: make(chan struct{}),
+	}
+	g.members[p.ID] = member
+	mgr.concurrencyGroupKeyByPipelineID[p.ID] = groupKey
+
+	// cancel superseded pipelines asynchronously, waiting ones leave the group after cancelled,
+	// active ones leave at teardown after they have stopped
+	newest := g.newestMember()
+	for _, supersededID := range g.supersededPipelineIDs(newest) {
+		go mgr.cancelSupersededPipeline(supersededID, newest.pipelineID, groupKey)
+	}
+
+	g.activate()
+	if member.active {
+		return nil
+	}
+	return member.waitCh
+}
+
+// restoreConcurrencyGroupMembers query unfinished pipelines of the persisted group,
+// pipelines already after queue are active, others wait for their own entering.
+func (mgr *defaultManager) restoreConcurrencyGroupMembers(p *spec.Pipeline, groupKey string) []*concurrencyMember {
+	pipelineIDs, err := mgr.dbClient.SelectTargetIDsByLabels(apistructs.TargetIDSelectByLabelRequest{
+		Type:            apistructs.PipelineLabelTypeInstance,
+		PipelineSources: []apistructs.PipelineSource{p.PipelineSource},
+		MustMatchLabels: map[string][]string{apistructs.LabelConcurrencyGroup: {groupKey}},
+	})
+	if err != nil {
+		rlog.PWarnf(p.ID, "failed to restore concurrency group members, err: %v", err)
+		return nil
+	}
+	var otherIDs []uint64
+	for _, id := range pipelineIDs {
+		if id != p.ID {
+			otherIDs = append(otherIDs, id)
+		}
+	}
+	if len(otherIDs) == 0 {
+		return nil
+	}
+	pipelines, err := mgr.dbClient.ListPipelinesByIDs(otherIDs)
+	if err != nil {
+		rlog.PWarnf(p.ID, "failed to restore concurrency group members, err: %v", err)
+		return nil
+	}
+	var members []*concurrencyMember
+	for _, other := range pipelines {
+		if other.Status.IsEndStatus() || other.Extra.ConcurrencyInfo == nil {
+			continue
+		}
+		members = append(members, &concurrencyMember{
+			pipelineID:       other.ID,
+			cancelInProgress: other.Extra.ConcurrencyInfo.CancelInProgress,
+			active:           other.Status.AfterPipelineQueue(),
+			waitCh:           make(chan struct{}),
+		})
+	}
+	return members
+}
+
+// LeaveConcurrencyGroup remove pipeline from its concurrency group and activate the next one.
+func (mgr *defaultManager) LeaveConcurrencyGroup(pipelineID uint64) {
+	mgr.leaveConcurrencyGroup(pipelineID, false)
+}
+
+// leaveConcurrencyGroupIfWaiting remove pipeline from its concurrency group only if it hasn't been activated,
+// active pipeline keeps the group until it stopped.
+func (mgr *defaultManager) leaveConcurrencyGroupIfWaiting(pipelineID uint64) {
+	mgr.leaveConcurrencyGroup(pipelineID, true)
+}
+
+func (mgr *defaultManager) leaveConcurrencyGroup(pipelineID uint64, onlyWaiting bool) {
+	mgr.cLock.Lock()
+	defer mgr.cLock.Unlock()
+
+	groupKey, ok := mgr.concurrencyGroupKeyByPipelineID[pipelineID]
+	if !ok {
+		return
+	}
+	if onlyWaiting && mgr.concurrencyGroups[groupKey].members[pipelineID].active {
+		return
+	}
+	delete(mgr.concurrencyGroupKeyByPipelineID, pipelineID)
+	g := mgr.concurrencyGroups[groupKey]
+	member := g.members[pipelineID]
+	delete(g.members, pipelineID)
+	if !member.active {
+		close(member.waitCh)
+	}
+	if len(g.members) == 0 {
+		delete(mgr.concurrencyGroups, groupKey)
+		return
+	}
+	g.activate()
+}
+
+func (g *concurrencyGroup) newestMember() *concurrencyMember {
+	var newest *concurrencyMember
+	for _, member := range g.members {
+		if newest == nil || member.pipelineID > newest.pipelineID {
+			newest = member
+		}
+	}
+	return newest
+}
+
+// supersededPipelineIDs return pipelines superseded by the newest member:
+// waiting ones are always superseded, active ones only when newest cancel in progress.
+func (g *concurrencyGroup) supersededPipelineIDs(newest *concurrencyMember) []uint64 {
+	var ids []uint64
+	for _, member := range g.members {
+		if member.pipelineID == newest.pipelineID {
+			continue
+		}
+		if !member.active || newest.cancelInProgress {
+			ids = append(ids, member.pipelineID)
+		}
+	}
+	return ids
+}
+
+// activate let the newest waiting member go on if no member is active.
+func (g *concurrencyGroup) activate() {
+	var newestWaiting *concurrencyMember
+	for _, member := range g.members {
+		if member.active {
+			return
+		}
+		if newestWaiting == nil || member.pipelineID > newestWaiting.pipelineID {
+			newestWaiting = member
+		}
+	}
+	if newestWaiting == nil {
+		return
+	}
+	newestWaiting.active = true
+	close(newestWaiting.waitCh)
+}
+
+// cancelSupersededPipeline cancel pipeline through the existing cancel path, retry if failed.
+// Pipeline stays in the group if it cannot be cancelled, so it still blocks newer ones.
+func (mgr *defaultManager) cancelSupersededPipeline(pipelineID, byPipelineID uint64, groupKey string) {
+	msg := fmt.Sprintf("superseded by pipeline %d in concurrency group %s, cancel it", byPipelineID, groupKey)
+	rlog.PInfof(pipelineID, "%s", msg)
+	now := time.Now()
+	events.EmitPipelineStreamEvent(pipelineID, []*apistructs.PipelineEvent{{
+		Reason:         "Superseded",
+		Message:        msg,
+		Source:         apistructs.PipelineEventSource{Component: EventComponentConcurrencyGroup},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           events.EventLevelNormal,
+	}})
+	var cancelErr error
+	_ = loop.New(loop.WithMaxTimes(concurrencyCancelMaxTimes), loop.WithDeclineLimit(time.Second*10), loop.WithDeclineRatio(2)).
+		Do(func() (abort bool, err error) {
+			cancelErr = mgr.cancelPipelineFunc(&apistructs.PipelineCancelRequest{
+				PipelineID:   pipelineID,
+				IdentityInfo: apistructs.IdentityInfo{InternalClient: concurrencyCancelInternalClient},
+			})
+			if cancelErr != nil {
+				rlog.PWarnf(pipelineID, "failed to cancel superseded pipeline, err: %v", cancelErr)
+				return false, cancelErr
+			}
+			return true, nil
+		})
+	if cancelErr != nil {
+		logrus.Errorf("[alert] queue manager: pipelineID: %d, failed to cancel superseded pipeline, keep it in concurrency group %s, err: %v",
+			pipelineID, groupKey, cancelErr)
+		return
+	}
+	// waiting pipeline never runs, pop out directly; active one pops out at teardown
+	mgr.PopOutPipelineFromQueue(pipelineID)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package manager
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyGroup(t *testing.T) {
+	g := &concurrencyGroup{key: "dice/1/feature", members: make(map[uint64]*concurrencyMember)}
+	add := func(id uint64, cancelInProgress bool) *concurrencyMember {
+		m := &concurrencyMember{pipelineID: id, cancelInProgress: cancelInProgress, waitCh: make(chan struct{})}
+		g.members[id] = m
+		return m
+	}
+	isClosed := func(ch chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// first pipeline becomes active directly
+	m1 := add(1, false)
+	g.activate()
+	assert.True(t, m1.active)
+	assert.True(t, isClosed(m1.waitCh))
+
+	// waiting pipelines are superseded by the newest one, active one keeps running
+	m2 := add(2, false)
+	m3 := add(3, false)
+	g.activate()
+	assert.False(t, m2.active)
+	assert.False(t, m3.active)
+	assert.Equal(t, []uint64{2}, g.supersededPipelineIDs(g.newestMember()))
+
+	// cancel in progress supersedes active one too
+	m4 := add(4, true)
+	ids := g.supersededPipelineIDs(g.newestMember())
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	assert.Equal(t, []uint64{1, 2, 3}, ids)
+
+	// newest waiting member activated after active one left
+	delete(g.members, 1)
+	g.activate()
+	assert.True(t, m4.active)
+	assert.False(t, m3.active)
+}
+
+func TestLeaveConcurrencyGroupIfWaiting(t *testing.T) {
+	mgr := New().(*defaultManager)
+	g := &concurrencyGroup{key: "dice/1/feature", members: make(map[uint64]*concurrencyMember)}
+	mgr.concurrencyGroups[g.key] = g
+	for _, id := range []uint64{1, 2} {
+		g.members[id] = &concurrencyMember{pipelineID: id, waitCh: make(chan struct{})}
+		mgr.concurrencyGroupKeyByPipelineID[id] = g.key
+	}
+	g.activate()
+	assert.True(t, g.members[2].active)
+
+	// active pipeline keeps the group until teardown
+	mgr.leaveConcurrencyGroupIfWaiting(2)
+	assert.Contains(t, g.members, uint64(2))
+
+	// waiting pipeline leaves directly
+	waitCh := g.members[1].waitCh
+	mgr.leaveConcurrencyGroupIfWaiting(1)
+	assert.NotContains(t, g.members, uint64(1))
+	_, open := <-waitCh
+	assert.False(t, open)
+
+	mgr.LeaveConcurrencyGroup(2)
+	assert.Empty(t, mgr.concurrencyGroups)
+}
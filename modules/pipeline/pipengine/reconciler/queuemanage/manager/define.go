@@ -16,6 +16,7 @@ package manager
 import (
 	"sync"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/queuemanage/types"
 )
@@ -29,7 +30,13 @@ type defaultManager struct {
 	//pipelineCaches map[uint64]*spec.Pipeline
 	//pCacheLock     sync.RWMutex
 
-	dbClient *dbclient.Client
+	// concurrency groups
+	concurrencyGroups               map[string]*concurrencyGroup // key: rendered group key
+	concurrencyGroupKeyByPipelineID map[uint64]string
+	cLock                           sync.Mutex
+
+	dbClient           *dbclient.Client
+	cancelPipelineFunc func(req *apistructs.PipelineCancelRequest) error
 }
 
 // New return a new queue manager.
@@ -38,6 +45,8 @@ func New(ops ...Option) types.QueueManager {
 
 	mgr.queueByID = make(map[string]types.Queue)
 	mgr.queueStopChanByID = make(map[string]chan struct{})
+	mgr.concurrencyGroups = make(map[string]*concurrencyGroup)
+	mgr.concurrencyGroupKeyByPipelineID = make(map[uint64]string)
 
 	//mgr.pipelineCaches = make(map[uint64]*spec.Pipeline)
 
@@ -56,3 +65,10 @@ func WithDBClient(dbClient *dbclient.Client) Option {
 		mgr.dbClient = dbClient
 	}
 }
+
+// WithCancelPipelineFunc set cancel func used to cancel superseded pipelines of concurrency group.
+func WithCancelPipelineFunc(f func(req *apistructs.PipelineCancelRequest) error) Option {
+	return func(mgr *defaultManager) {
+		mgr.cancelPipelineFunc = f
+	}
+}
@@ -47,6 +47,33 @@ func (mgr *defaultManager) PutPipelineIntoQueue(pipelineID uint64) (<-chan struc
 		return popCh, false, nil
 	}
 
+	// concurrency group: wait until superseded or older in-progress pipelines leave the group
+	if waitCh := mgr.enterConcurrencyGroup(p); waitCh != nil {
+		mgr.markPipelineWaitingForConcurrencyGroup(p)
+		go func() {
+			<-waitCh
+			// pipeline may be cancelled while waiting
+			if latestP := mgr.ensureQueryPipelineDetail(pipelineID); latestP != nil {
+				p = latestP
+			}
+			if p.Status.IsEndStatus() {
+				popCh <- struct{}{}
+				close(popCh)
+				return
+			}
+			mgr.putPipelineIntoBindQueue(p, popCh)
+		}()
+		return popCh, false, nil
+	}
+
+	mgr.putPipelineIntoBindQueue(p, popCh)
+
+	// return channel when pipeline pop from queue
+	return popCh, false, nil
+}
+
+// putPipelineIntoBindQueue add pipeline into bind queue, send signal to popCh directly if no queue bound.
+func (mgr *defaultManager) putPipelineIntoBindQueue(p *spec.Pipeline, popCh chan struct{}) {
 	// query pipeline queue detail
 	pq := mgr.ensureQueryPipelineQueueDetail(p)
 	if pq == nil {
@@ -55,7 +82,7 @@ func (mgr *defaultManager) PutPipelineIntoQueue(pipelineID uint64) (<-chan struc
 			popCh <- struct{}{}
 			close(popCh)
 		}()
-		return popCh, false, nil
+		return
 	}
 
 	// add queue to manager
@@ -63,9 +90,24 @@ func (mgr *defaultManager) PutPipelineIntoQueue(pipelineID uint64) (<-chan struc
 
 	// add pipeline to queue
 	q.AddPipelineIntoQueue(p, popCh)
+}
 
-	// return channel when pipeline pop from queue
-	return popCh, false, nil
+// markPipelineWaitingForConcurrencyGroup update pipeline status to Queue, so it can be cancelled while waiting.
+func (mgr *defaultManager) markPipelineWaitingForConcurrencyGroup(p *spec.Pipeline) {
+	rlog.PInfof(p.ID, "waiting for other pipelines in the same concurrency group")
+	if p.Status == apistructs.PipelineStatusQueue {
+		return
+	}
+	_ = loop.New(loop.WithDeclineLimit(time.Second*10), loop.WithDeclineRatio(2)).Do(func() (abort bool, err error) {
+		if err := mgr.dbClient.UpdatePipelineBaseStatus(p.ID, apistructs.PipelineStatusQueue); err != nil {
+			err = fmt.Errorf("failed to update pipeline status to Queue, err: %v", err)
+			rlog.PErrorf(p.ID, err.Error())
+			return false, err
+		}
+		return true, nil
+	})
+	p.Status = apistructs.PipelineStatusQueue
+	events.EmitPipelineInstanceEvent(p, p.GetRunUserID())
 }
 
 // ensureQueryPipelineDetail handle err properly.
@@ -18,6 +18,9 @@ import (
 )
 
 func (mgr *defaultManager) PopOutPipelineFromQueue(pipelineID uint64) {
+	// running pipeline leaves its concurrency group at teardown, after it has stopped
+	mgr.leaveConcurrencyGroupIfWaiting(pipelineID)
+
 	p := mgr.ensureQueryPipelineDetail(pipelineID)
 	if p == nil {
 		return
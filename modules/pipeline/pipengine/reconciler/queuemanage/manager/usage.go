@@ -30,3 +30,14 @@ func (mgr *defaultManager) QueryQueueUsage(pq *apistructs.PipelineQueue) *pb.Que
 	usage := q.Usage()
 	return &usage
 }
+
+func (mgr *defaultManager) QueryQueueGroupShares(pq *apistructs.PipelineQueue) []*apistructs.PipelineQueueGroupShare {
+	mgr.qLock.RLock()
+	defer mgr.qLock.RUnlock()
+	q, ok := mgr.queueByID[queue.New(pq).ID()]
+	if !ok {
+		return nil
+	}
+
+	return q.GroupShares()
+}
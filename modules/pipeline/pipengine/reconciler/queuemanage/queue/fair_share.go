@@ -0,0 +1,219 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package queue
+
+import (
+	"sort"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/queue/priorityqueue"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+const (
+	// fairShareDefaultGroup is the group of pipelines which cannot get group value.
+	fairShareDefaultGroup  = "default"
+	fairShareDefaultWeight = int64(1)
+)
+
+func (q *defaultQueue) isFairShare() bool {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	return q.pq.ScheduleStrategy == apistructs.ScheduleStrategyInsidePipelineQueueOfFairShare
+}
+
+func (q *defaultQueue) fairShareConfig() apistructs.PipelineQueueFairShare {
+	if q.pq.FairShare == nil {
+		return apistructs.PipelineQueueFairShare{GroupBy: apistructs.PipelineQueueFairShareGroupByProject}
+	}
+	return *q.pq.FairShare
+}
+
+// getFairShareGroup return group of pipeline according to fair share config.
+func getFairShareGroup(fs apistructs.PipelineQueueFairShare, p *spec.Pipeline) string {
+	if p == nil {
+		return fairShareDefaultGroup
+	}
+	var group string
+	switch fs.GroupBy {
+	case apistructs.PipelineQueueFairShareGroupByUser:
+		group = p.GetRunUserID()
+	case apistructs.PipelineQueueFairShareGroupByLabel:
+		group = p.GetLabel(fs.LabelKey)
+	default:
+		group = p.GetLabel(apistructs.LabelProjectID)
+	}
+	if group == "" {
+		return fairShareDefaultGroup
+	}
+	return group
+}
+
+// getFairShareWeight return weight of group, default is 1.
+func getFairShareWeight(fs apistructs.PipelineQueueFairShare, group string) int64 {
+	if weight, ok := fs.Weights[group]; ok && weight > 0 {
+		return weight
+	}
+	return fairShareDefaultWeight
+}
+
+// fairShareOrderedPendingItems return snapshot of pending items ordered by fair share.
+func (q *defaultQueue) fairShareOrderedPendingItems() []priorityqueue.Item {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	fs := q.fairShareConfig()
+	groupOfItem := func(item priorityqueue.Item) string {
+		return getFairShareGroup(fs, q.pipelineCaches[parsePipelineIDFromQueueItem(item)])
+	}
+
+	processingCountByGroup := make(map[string]int64)
+	q.eq.ProcessingQueue().Range(func(item priorityqueue.Item) (stopRange bool) {
+		processingCountByGroup[groupOfItem(item)]++
+		return false
+	})
+	var pendingItems []priorityqueue.Item
+	q.eq.PendingQueue().Range(func(item priorityqueue.Item) (stopRange bool) {
+		pendingItems = append(pendingItems, item)
+		return false
+	})
+
+	return orderItemsByFairShare(pendingItems, groupOfItem,
+		func(group string) int64 { return getFairShareWeight(fs, group) },
+		processingCountByGroup)
+}
+
+// orderItemsByFairShare order items:
+//  1. higher priority first, same as priority queue;
+//  2. between groups of same priority, pick group which has the lowest (processing + picked) / weight;
+//  3. still same, pick the earliest created one.
+func orderItemsByFairShare(items []priorityqueue.Item, groupOf func(priorityqueue.Item) string,
+	weightOf func(string) int64, processingCountByGroup map[string]int64) []priorityqueue.Item {
+
+	sorted := make([]priorityqueue.Item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority() == sorted[j].Priority() {
+			return sorted[i].CreationTime().Before(sorted[j].CreationTime())
+		}
+		return sorted[i].Priority() > sorted[j].Priority()
+	})
+
+	// split into groups, keep order inside group
+	itemsByGroup := make(map[string][]priorityqueue.Item)
+	var groups []string
+	for _, item := range sorted {
+		group := groupOf(item)
+		if _, ok := itemsByGroup[group]; !ok {
+			groups = append(groups, group)
+		}
+		itemsByGroup[group] = append(itemsByGroup[group], item)
+	}
+
+	usedByGroup := make(map[string]int64, len(groups))
+	for _, group := range groups {
+		usedByGroup[group] = processingCountByGroup[group]
+	}
+
+	result := make([]priorityqueue.Item, 0, len(sorted))
+	for len(result) < len(sorted) {
+		var pickedGroup string
+		var found bool
+		for _, group := range groups {
+			if len(itemsByGroup[group]) == 0 {
+				continue
+			}
+			if !found {
+				pickedGroup, found = group, true
+				continue
+			}
+			head, pickedHead := itemsByGroup[group][0], itemsByGroup[pickedGroup][0]
+			if head.Priority() != pickedHead.Priority() {
+				if head.Priority() > pickedHead.Priority() {
+					pickedGroup = group
+				}
+				continue
+			}
+			// compare used/weight by cross multiply
+			left := usedByGroup[group] * weightOf(pickedGroup)
+			right := usedByGroup[pickedGroup] * weightOf(group)
+			if left < right || (left == right && head.CreationTime().Before(pickedHead.CreationTime())) {
+				pickedGroup = group
+			}
+		}
+		result = append(result, itemsByGroup[pickedGroup][0])
+		itemsByGroup[pickedGroup] = itemsByGroup[pickedGroup][1:]
+		usedByGroup[pickedGroup]++
+	}
+
+	return result
+}
+
+// GroupShares return usage of each group, only for FAIR_SHARE queue.
+func (q *defaultQueue) GroupShares() []*apistructs.PipelineQueueGroupShare {
+	if !q.isFairShare() {
+		return nil
+	}
+
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	fs := q.fairShareConfig()
+	shareByGroup := make(map[string]*apistructs.PipelineQueueGroupShare)
+	getShare := func(item priorityqueue.Item) (*apistructs.PipelineQueueGroupShare, *spec.Pipeline) {
+		p := q.pipelineCaches[parsePipelineIDFromQueueItem(item)]
+		group := getFairShareGroup(fs, p)
+		share, ok := shareByGroup[group]
+		if !ok {
+			share = &apistructs.PipelineQueueGroupShare{Group: group, Weight: getFairShareWeight(fs, group)}
+			shareByGroup[group] = share
+		}
+		return share, p
+	}
+
+	var totalProcessing int64
+	q.eq.ProcessingQueue().Range(func(item priorityqueue.Item) (stopRange bool) {
+		share, p := getShare(item)
+		share.ProcessingCount++
+		totalProcessing++
+		if p != nil {
+			resources := p.GetPipelineAppliedResources()
+			share.InUseCPU += resources.Requests.CPU
+			share.InUseMemoryMB += resources.Requests.MemoryMB
+		}
+		return false
+	})
+	q.eq.PendingQueue().Range(func(item priorityqueue.Item) (stopRange bool) {
+		share, _ := getShare(item)
+		share.PendingCount++
+		return false
+	})
+
+	var totalWeight int64
+	shares := make([]*apistructs.PipelineQueueGroupShare, 0, len(shareByGroup))
+	for _, share := range shareByGroup {
+		totalWeight += share.Weight
+		shares = append(shares, share)
+	}
+	for _, share := range shares {
+		if totalProcessing > 0 {
+			share.Share = float64(share.ProcessingCount) / float64(totalProcessing)
+		}
+		share.ExpectedShare = float64(share.Weight) / float64(totalWeight)
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].Group < shares[j].Group })
+
+	return shares
+}
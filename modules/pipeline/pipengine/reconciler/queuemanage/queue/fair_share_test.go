@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package queue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/queue/priorityqueue"
+)
+
+func TestOrderItemsByFairShare(t *testing.T) {
+	now := time.Now()
+	// key format: group-index
+	items := []priorityqueue.Item{
+		priorityqueue.NewItem("a-1", 10, now),
+		priorityqueue.NewItem("a-2", 10, now.Add(time.Second)),
+		priorityqueue.NewItem("a-3", 10, now.Add(2*time.Second)),
+		priorityqueue.NewItem("b-1", 10, now.Add(3*time.Second)),
+		priorityqueue.NewItem("b-2", 10, now.Add(4*time.Second)),
+		priorityqueue.NewItem("c-1", 20, now.Add(5*time.Second)),
+	}
+	groupOf := func(item priorityqueue.Item) string { return strings.SplitN(item.Key(), "-", 2)[0] }
+	keysOf := func(items []priorityqueue.Item) []string {
+		var keys []string
+		for _, item := range items {
+			keys = append(keys, item.Key())
+		}
+		return keys
+	}
+
+	// same weight, round-robin between groups, priority first
+	weightOf := func(string) int64 { return 1 }
+	ordered := orderItemsByFairShare(items, groupOf, weightOf, nil)
+	assert.Equal(t, []string{"c-1", "a-1", "b-1", "a-2", "b-2", "a-3"}, keysOf(ordered))
+
+	// group a already has processing pipelines
+	ordered = orderItemsByFairShare(items, groupOf, weightOf, map[string]int64{"a": 2})
+	assert.Equal(t, []string{"c-1", "b-1", "b-2", "a-1", "a-2", "a-3"}, keysOf(ordered))
+
+	// group a has double weight
+	weightOf = func(group string) int64 {
+		if group == "a" {
+			return 2
+		}
+		return 1
+	}
+	ordered = orderItemsByFairShare(items, groupOf, weightOf, nil)
+	assert.Equal(t, []string{"c-1", "a-1", "b-1", "a-2", "a-3", "b-2"}, keysOf(ordered))
+}
+
+func TestGetFairShareGroupAndWeight(t *testing.T) {
+	fs := apistructs.PipelineQueueFairShare{
+		GroupBy: apistructs.PipelineQueueFairShareGroupByProject,
+		Weights: map[string]int64{"1": 3},
+	}
+	assert.Equal(t, fairShareDefaultGroup, getFairShareGroup(fs, nil))
+	assert.Equal(t, int64(3), getFairShareWeight(fs, "1"))
+	assert.Equal(t, fairShareDefaultWeight, getFairShareWeight(fs, "2"))
+}
@@ -56,94 +56,110 @@ func (q *defaultQueue) RangePendingQueue() {
 			q.unsetNeedReRangePendingQueueFlag()
 		}
 	}()
-	q.eq.PendingQueue().Range(func(item priorityqueue.Item) (stopRange bool) {
-		// fast reRange
-		defer func() {
-			if q.needReRangePendingQueue() {
-				// stop current range
-				stopRange = true
+	// fair share: range snapshot ordered by group shares
+	if q.isFairShare() {
+		for _, item := range q.fairShareOrderedPendingItems() {
+			// already removed from pending queue
+			if q.eq.PendingQueue().Get(item.Key()) == nil {
+				continue
+			}
+			if q.handlePendingItem(item) {
+				return
 			}
-		}()
-
-		pipelineID := parsePipelineIDFromQueueItem(item)
-		if pipelineID == 0 {
-			rlog.PErrorf(pipelineID, "queueManager: invalid queue item key: %s, failed to parse to pipelineID, remove this item", pipelineID)
-			return q.doStopAndRemove(item, false)
 		}
+		return
+	}
+	q.eq.PendingQueue().Range(q.handlePendingItem)
+}
 
-		// get pipeline
-		q.lock.RLock()
-		p := q.pipelineCaches[pipelineID]
-		q.lock.RUnlock()
-		if p == nil {
-			// pipeline not exist, remove this invalid item, continue handle next pipeline inside the queue
-			rlog.PWarnf(pipelineID, "queueManager: failed to handle pipeline inside queue, pipeline not exist, pop from pending queue")
-			return q.doStopAndRemove(item, false)
+// handlePendingItem validate and try pop the pending item, return whether stop range.
+func (q *defaultQueue) handlePendingItem(item priorityqueue.Item) (stopRange bool) {
+	// fast reRange
+	defer func() {
+		if q.needReRangePendingQueue() {
+			// stop current range
+			stopRange = true
 		}
+	}()
 
-		// queue validate
-		validateResult := q.validatePipeline(p)
-		if !validateResult.Success {
-			q.emitEvent(p, PendingQueueValidate, validateResult.Reason, events.EventLevelWarning)
-			// stopRange if queue is strict mode
-			return q.IsStrictMode()
-		}
+	pipelineID := parsePipelineIDFromQueueItem(item)
+	if pipelineID == 0 {
+		rlog.PErrorf(pipelineID, "queueManager: invalid queue item key: %s, failed to parse to pipelineID, remove this item", pipelineID)
+		return q.doStopAndRemove(item, false)
+	}
 
-		// precheck before run
-		customKVsOfAOP := map[interface{}]interface{}{}
-		ctx := aop.NewContextForPipeline(*p, aoptypes.TuneTriggerPipelineInQueuePrecheckBeforePop, customKVsOfAOP)
-		_ = aop.Handle(ctx)
-		checkResultI, ok := ctx.TryGet(apistructs.PipelinePreCheckResultContextKey)
-		if !ok {
-			// no result, log and wait for another retry
-			stopRange = false
-			q.emitEvent(p, PendingQueueValidate,
-				"queue precheck missing result, waiting for retry",
-				events.EventLevelNormal)
-			return
-		}
-		checkResult, ok := checkResultI.(apistructs.PipelineQueueValidateResult)
-		if !ok {
-			// invalid result, log and wait for another retry
-			q.emitEvent(p, PendingQueueValidate,
-				fmt.Sprintf("queue precheck result type is not expected, detail: %#v", checkResult),
-				events.EventLevelNormal)
-			stopRange = false
-			return
-		}
-		// check result
-		if checkResult.IsFailed() {
-			// not retry if retryOption is nil
-			if checkResult.RetryOption == nil {
-				q.emitEvent(p, FailedQueue,
-					fmt.Sprintf("validate failed(no retry option), stop and remove from queue, reason: %s", checkResult.Reason),
-					events.EventLevelWarning)
-				// mark pipeline as failed
-				q.emitEvent(p, FailedQueue,
-					"mark pipeline as failed",
-					events.EventLevelNormal)
-				q.ensureMarkPipelineFailed(p)
-				return q.doStopAndRemove(item)
-			}
-			// need retry, sleep specific time
-			q.emitEvent(p, PendingQueueValidate,
-				fmt.Sprintf("validate failed(need retry), waiting for retry(%dsec)", checkResult.RetryOption.IntervalSecond),
+	// get pipeline
+	q.lock.RLock()
+	p := q.pipelineCaches[pipelineID]
+	q.lock.RUnlock()
+	if p == nil {
+		// pipeline not exist, remove this invalid item, continue handle next pipeline inside the queue
+		rlog.PWarnf(pipelineID, "queueManager: failed to handle pipeline inside queue, pipeline not exist, pop from pending queue")
+		return q.doStopAndRemove(item, false)
+	}
+
+	// queue validate
+	validateResult := q.validatePipeline(p)
+	if !validateResult.Success {
+		q.emitEvent(p, PendingQueueValidate, validateResult.Reason, events.EventLevelWarning)
+		// stopRange if queue is strict mode
+		return q.IsStrictMode()
+	}
+
+	// precheck before run
+	customKVsOfAOP := map[interface{}]interface{}{}
+	ctx := aop.NewContextForPipeline(*p, aoptypes.TuneTriggerPipelineInQueuePrecheckBeforePop, customKVsOfAOP)
+	_ = aop.Handle(ctx)
+	checkResultI, ok := ctx.TryGet(apistructs.PipelinePreCheckResultContextKey)
+	if !ok {
+		// no result, log and wait for another retry
+		stopRange = false
+		q.emitEvent(p, PendingQueueValidate,
+			"queue precheck missing result, waiting for retry",
+			events.EventLevelNormal)
+		return
+	}
+	checkResult, ok := checkResultI.(apistructs.PipelineQueueValidateResult)
+	if !ok {
+		// invalid result, log and wait for another retry
+		q.emitEvent(p, PendingQueueValidate,
+			fmt.Sprintf("queue precheck result type is not expected, detail: %#v", checkResult),
+			events.EventLevelNormal)
+		stopRange = false
+		return
+	}
+	// check result
+	if checkResult.IsFailed() {
+		// not retry if retryOption is nil
+		if checkResult.RetryOption == nil {
+			q.emitEvent(p, FailedQueue,
+				fmt.Sprintf("validate failed(no retry option), stop and remove from queue, reason: %s", checkResult.Reason),
+				events.EventLevelWarning)
+			// mark pipeline as failed
+			q.emitEvent(p, FailedQueue,
+				"mark pipeline as failed",
 				events.EventLevelNormal)
-			// judge whether need reRange before sleep
-			if q.needReRangePendingQueue() {
-				return true
-			}
-			time.Sleep(time.Second * time.Duration(checkResult.RetryOption.IntervalSecond))
-			// according to queue mode, check next pipeline or skip
-			return q.IsStrictMode()
+			q.ensureMarkPipelineFailed(p)
+			return q.doStopAndRemove(item)
 		}
-		// do pop
-		q.emitEvent(p, SuccessQueue,
-			"validate success, try pop now",
+		// need retry, sleep specific time
+		q.emitEvent(p, PendingQueueValidate,
+			fmt.Sprintf("validate failed(need retry), waiting for retry(%dsec)", checkResult.RetryOption.IntervalSecond),
 			events.EventLevelNormal)
-		stopRange = q.doPop(item)
-		return
-	})
+		// judge whether need reRange before sleep
+		if q.needReRangePendingQueue() {
+			return true
+		}
+		time.Sleep(time.Second * time.Duration(checkResult.RetryOption.IntervalSecond))
+		// according to queue mode, check next pipeline or skip
+		return q.IsStrictMode()
+	}
+	// do pop
+	q.emitEvent(p, SuccessQueue,
+		"validate success, try pop now",
+		events.EventLevelNormal)
+	stopRange = q.doPop(item)
+	return
 }
 
 func (q *defaultQueue) doPop(item priorityqueue.Item) (stopRange bool) {
@@ -16,6 +16,9 @@ package queue
 import "github.com/erda-project/erda/apistructs"
 
 func (q *defaultQueue) Update(pq *apistructs.PipelineQueue) {
+	q.lock.Lock()
 	q.pq = pq
+	q.lock.Unlock()
+
 	q.eq.SetProcessingWindow(pq.Concurrency)
 }
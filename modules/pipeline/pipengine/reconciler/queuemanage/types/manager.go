@@ -22,6 +22,8 @@ import (
 type QueueManager interface {
 	IdempotentAddQueue(pq *apistructs.PipelineQueue) Queue
 	QueryQueueUsage(pq *apistructs.PipelineQueue) *pb.QueueUsage
+	QueryQueueGroupShares(pq *apistructs.PipelineQueue) []*apistructs.PipelineQueueGroupShare
 	PutPipelineIntoQueue(pipelineID uint64) (popCh <-chan struct{}, needRetryIfErr bool, err error)
 	PopOutPipelineFromQueue(pipelineID uint64)
+	LeaveConcurrencyGroup(pipelineID uint64)
 }
@@ -25,6 +25,7 @@ type Queue interface {
 	ID() string
 	IsStrictMode() bool
 	Usage() pb.QueueUsage
+	GroupShares() []*apistructs.PipelineQueueGroupShare
 	Update(pq *apistructs.PipelineQueue)
 	RangePendingQueue()
 	AddPipelineIntoQueue(p *spec.Pipeline, doneCh chan struct{})
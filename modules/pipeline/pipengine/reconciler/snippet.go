@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/commonutil/statusutil"
 	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -250,8 +251,37 @@ func (r *Reconciler) copyParentPipelineRunInfo(snippetPipeline *spec.Pipeline) e
 
 		snippetPipeline.Snapshot.RunPipelineParams[i].TrueValue = reffedValue
 	}
+	if err := checkSnippetPipelineRunParams(snippetPipeline); err != nil {
+		return err
+	}
 	if err := r.dbClient.UpdatePipelineExtraSnapshot(snippetPipeline.ID, snippetPipeline.Snapshot); err != nil {
 		return err
 	}
 	return nil
 }
+
+// checkSnippetPipelineRunParams 占位符替换后，按嵌套流水线声明的参数类型校验 runParams
+func checkSnippetPipelineRunParams(snippetPipeline *spec.Pipeline) error {
+	pipelineYml, err := pipelineyml.New([]byte(snippetPipeline.PipelineYml))
+	if err != nil {
+		return err
+	}
+	definedParams := make(map[string]*pipelineyml.PipelineParam)
+	for _, param := range pipelineYml.Spec().Params {
+		definedParams[param.Name] = param
+	}
+	for _, runParam := range snippetPipeline.Snapshot.RunPipelineParams {
+		param, ok := definedParams[runParam.Name]
+		if !ok {
+			continue
+		}
+		value := runParam.TrueValue
+		if value == nil {
+			value = runParam.PipelineRunParam.Value
+		}
+		if err := pipelineyml.CheckTypedParamValue(param, value); err != nil {
+			return fmt.Errorf("invalid snippet pipeline params, err: %v", err)
+		}
+	}
+	return nil
+}
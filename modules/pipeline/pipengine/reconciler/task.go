@@ -35,9 +35,9 @@ func reconcileTask(tr *taskrun.TaskRun) error {
 	// }()
 	// do aop
 	rlog.TDebugf(tr.P.ID, tr.Task.ID, "start do task aop")
-	if err := aop.Handle(aop.NewContextForTask(*tr.Task, *tr.P, aoptypes.TuneTriggerTaskBeforeExec)); err != nil {
-		rlog.TErrorf(tr.P.ID, tr.Task.ID, "failed to handle aop, type: %s, err: %v", aoptypes.TuneTriggerTaskBeforeExec, err)
-	}
+	// task vetoed by aop will be failed, and handled as end status below
+	aopCtx := aop.NewContextForTask(*tr.Task, *tr.P, aoptypes.TuneTriggerTaskBeforeExec)
+	tr.HandleTuneResult(aopCtx, aop.Handle(aopCtx))
 	rlog.TDebugf(tr.P.ID, tr.Task.ID, "end do task aop")
 
 	// 系统异常时重试3次
@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package taskrun
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/aop/aoptypes"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/rlog"
+)
+
+// HandleTuneResult 处理任务 AOP 结果：合并调音点产出的标签；被否决时将任务置为失败。
+// 返回 true 表示任务已被否决。
+func (tr *TaskRun) HandleTuneResult(ctx *aoptypes.TuneContext, err error) (vetoed bool) {
+	if annotations := ctx.Annotations(); len(annotations) > 0 {
+		if tr.Task.Extra.Labels == nil {
+			tr.Task.Extra.Labels = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			tr.Task.Extra.Labels[k] = v
+		}
+		if err := tr.DBClient.UpdatePipelineTaskExtra(tr.Task.ID, tr.Task.Extra); err != nil {
+			rlog.TErrorf(tr.P.ID, tr.Task.ID, "failed to update task labels from aop, trigger: %s, err: %v", ctx.SDK.TuneTrigger, err)
+		}
+	}
+
+	if err == nil {
+		return false
+	}
+	if !aoptypes.IsVetoError(err) {
+		rlog.TErrorf(tr.P.ID, tr.Task.ID, "failed to handle aop, trigger: %s, err: %v", ctx.SDK.TuneTrigger, err)
+		return false
+	}
+
+	rlog.TWarnf(tr.P.ID, tr.Task.ID, "task vetoed by aop, trigger: %s, err: %v", ctx.SDK.TuneTrigger, err)
+	tr.Task.Status = apistructs.PipelineStatusFailed
+	tr.Task.Result.Errors = append(tr.Task.Result.Errors, apistructs.ErrorResponse{Msg: err.Error()})
+	tr.Update()
+	return true
+}
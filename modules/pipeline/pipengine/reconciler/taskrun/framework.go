@@ -77,7 +77,12 @@ func (tr *TaskRun) Do(itr TaskOp) error {
 
 		// aop: before processing
 		if itr.TuneTriggers().BeforeProcessing != "" {
-			_ = aop.Handle(aop.NewContextForTask(*tr.Task, *tr.P, itr.TuneTriggers().BeforeProcessing))
+			aopCtx := aop.NewContextForTask(*tr.Task, *tr.P, itr.TuneTriggers().BeforeProcessing)
+			if vetoed := tr.HandleTuneResult(aopCtx, aop.Handle(aopCtx)); vetoed {
+				// task already marked as failed, skip processing
+				handleProcessingResult(nil, nil)
+				return
+			}
 		}
 
 		// processing op
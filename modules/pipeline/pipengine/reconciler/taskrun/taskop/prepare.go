@@ -34,6 +34,8 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/queue/throttler"
 	"github.com/erda-project/erda/modules/pipeline/pipengine/reconciler/taskrun"
+	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/dice"
+	"github.com/erda-project/erda/modules/pipeline/precheck/prechecktype"
 	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
 	"github.com/erda-project/erda/modules/pipeline/services/extmarketsvc"
 	"github.com/erda-project/erda/modules/pipeline/spec"
@@ -140,6 +142,12 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 	for actionAlias, kvs := range dbOutputs {
 		outputs[pipelineyml.ActionAlias(actionAlias)] = kvs
 	}
+	// ARTIFACT
+	dbArtifacts, err := pre.DBClient.ListPipelineArtifacts(p.ID, 0)
+	if err != nil {
+		return true, apierrors.ErrListPipelineArtifacts.InternalError(err)
+	}
+	artifacts, artifactVolumes := makeAvailableArtifacts(dbArtifacts, time.Now())
 
 	allSecrets := make(map[string]string)
 	for k, v := range p.Snapshot.Secrets {
@@ -163,6 +171,7 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 		pipelineyml.WithAliasesToCheckRefOp(p.Labels, pipelineyml.ActionAlias(task.Name)),
 		pipelineyml.WithRefs(refs),
 		pipelineyml.WithRefOpOutputs(outputs),
+		pipelineyml.WithRefOpArtifacts(artifacts),
 		pipelineyml.WithActionTypeMapping(conf.ActionTypeMapping()),
 		//pipelineyml.WithRenderSnippet(p.Labels, p.Snippets),
 		pipelineyml.WithFlatParams(true),
@@ -201,6 +210,14 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 		return false, apierrors.ErrParsePipelineYml.InternalError(err)
 	}
 	task.Extra.Action = *action
+	// 部署 action 的 release_id 可能引用上游输出, 渲染后再检查漏洞卡点
+	if action.Type == dice.ActionType {
+		if abort, messages := dice.Default().Check(pre.Ctx, *action, prechecktype.ItemsForCheck{Labels: p.MergeLabels()}); abort {
+			return false, apierrors.ErrRunPipeline.InvalidState(strings.Join(messages, "; "))
+		}
+	}
+	// 只下载被引用的制品
+	task.Context.Artifacts = pickReferencedArtifacts(action, artifactVolumes)
 	// --- uuid ---
 	task.Extra.UUID = fmt.Sprintf("pipeline-task-%d", task.ID)
 
@@ -355,12 +372,17 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 	task.Extra.PublicEnvs["PIPELINE_LIMITED_MEM"] = fmt.Sprintf("%g", task.Extra.RuntimeResource.Memory)
 	task.Extra.PublicEnvs["PIPELINE_LIMITED_DISK"] = fmt.Sprintf("%g", task.Extra.RuntimeResource.Disk)
 
+	// runner 标签选择器，由 action labels 中 runner. 前缀的标签生成，action 创建 runner task 时使用
+	if runnerLabels := apistructs.RunnerLabelsFromActionLabels(task.Extra.Action.Labels); len(runnerLabels) > 0 {
+		task.Extra.PublicEnvs[apistructs.EnvPipelineRunnerLabels] = apistructs.FormatRunnerLabels(runnerLabels)
+	}
+
 	// 条件表达式存在
 	if jump := condition(task); jump {
 		return false, nil
 	}
 
-	if p.Extra.StorageConfig.EnableNFSVolume() &&
+	if (p.Extra.StorageConfig.EnableNFSVolume() || p.Extra.StorageConfig.EnableOSSVolume()) &&
 		!p.Extra.StorageConfig.EnableShareVolume() &&
 		task.ExecutorKind == spec.PipelineTaskExecutorKindScheduler {
 		// --- cmd ---
@@ -487,6 +509,29 @@ func (pre *prepare) makeTaskRun() (needRetry bool, err error) {
 			task.Context.OutStorages = append(task.Context.OutStorages, pvolumes.GenerateTaskVolume(*task, namespace, nil))
 		}
 	}
+	if p.Extra.StorageConfig.EnableOSSVolume() &&
+		!p.Extra.StorageConfig.EnableShareVolume() &&
+		task.ExecutorKind == spec.PipelineTaskExecutorKindScheduler {
+		for _, namespace := range task.Extra.Action.Namespaces {
+			task.Context.OutStorages = append(task.Context.OutStorages, pvolumes.GenerateTaskOSSVolume(*task, namespace))
+		}
+		pvolumes.HandleTaskCacheOSSVolumes(p, task)
+		// context 和 caches 只保存对象 key，agent 读写时通过该 api 获取临时地址，对象存储凭证不下发
+		task.Extra.OpenapiOAuth2TokenPayload.AccessibleAPIs = append(task.Extra.OpenapiOAuth2TokenPayload.AccessibleAPIs,
+			apistructs.AccessibleAPI{
+				Path:   "/api/pipelines/<pipelineID>/tasks/<taskID>/actions/oss",
+				Method: http.MethodPost,
+				Schema: "http",
+			},
+		)
+		// --- binds ---
+		// context 和 caches 通过对象存储传递，不需要挂载 volume
+		binds, err := pvolumes.GenerateTaskOSSBinds(diceYmlJob, mountPoint)
+		if err != nil {
+			return false, apierrors.ErrRunPipeline.InvalidParameter(err)
+		}
+		task.Extra.Binds = binds
+	}
 
 	// loop
 	// 若 retriedTimes != nil，说明已经是在循环了，不能重新赋值
@@ -626,6 +671,46 @@ func getActionAgentTypeVersion() string {
 	return "agent@1.0"
 }
 
+// makeAvailableArtifacts 返回未过期的制品在容器内的路径，以及对应的制品卷
+func makeAvailableArtifacts(dbArtifacts []spec.PipelineArtifact, now time.Time) (pipelineyml.Artifacts, apistructs.Metadata) {
+	artifacts := pipelineyml.Artifacts{}
+	var vos apistructs.Metadata
+	for _, a := range dbArtifacts {
+		if a.IsExpired(now) {
+			continue
+		}
+		alias := pipelineyml.ActionAlias(a.TaskName)
+		if artifacts[alias] == nil {
+			artifacts[alias] = make(map[string]string)
+		}
+		containerPath := pvolumes.MakeTaskContainerArtifactPath(a.TaskName, a.Name)
+		artifacts[alias][a.Name] = containerPath
+		vos = append(vos, pvolumes.GenerateTaskArtifactVolume(a.Name, a.FileUUID, containerPath))
+	}
+	return artifacts, vos
+}
+
+// pickReferencedArtifacts 根据渲染后的 params 和 commands 中出现的容器路径，挑选 action 引用的制品
+func pickReferencedArtifacts(action *pipelineyml.Action, vos apistructs.Metadata) apistructs.Metadata {
+	if len(vos) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(struct {
+		Params   map[string]interface{}
+		Commands []string
+	}{action.Params, action.Commands})
+	if err != nil {
+		return nil
+	}
+	var picked apistructs.Metadata
+	for _, vo := range vos {
+		if strings.Contains(string(b), vo.Value) {
+			picked = append(picked, vo)
+		}
+	}
+	return picked
+}
+
 func contextVolumes(context spec.PipelineTaskContext) []apistructs.MetadataField {
 	vos := make([]apistructs.MetadataField, 0)
 	for _, vo := range append(context.InStorages, context.OutStorages...) {
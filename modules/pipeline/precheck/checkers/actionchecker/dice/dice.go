@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/pipeline/precheck/prechecktype"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	ActionType = "dice"
+
+	paramReleaseID = "release_id"
+	// paramVulnerabilityGate 漏洞卡点等级, 为空时使用 dicehub 默认等级, none 表示不检查
+	paramVulnerabilityGate = "vulnerability_gate"
+	vulnerabilityGateNone  = "none"
+)
+
+type dice struct {
+	bdl *bundle.Bundle
+}
+
+var (
+	defaultDice *dice
+	defaultOnce sync.Once
+)
+
+func New() *dice {
+	return &dice{bdl: bundle.New(bundle.WithDiceHub())}
+}
+
+// Default 返回共享的 dice checker, 避免每次检查都新建 bundle
+func Default() *dice {
+	defaultOnce.Do(func() {
+		defaultDice = New()
+	})
+	return defaultDice
+}
+
+func (d *dice) ActionType() pipelineyml.ActionType {
+	return ActionType
+}
+
+// Check 部署指定 release 时检查 release 镜像漏洞扫描结果
+func (d *dice) Check(ctx context.Context, data interface{}, itemsForCheck prechecktype.ItemsForCheck) (abort bool, messages []string) {
+	// data type: pipelineyml.Action
+	actualAction, ok := data.(pipelineyml.Action)
+	if !ok || actualAction.Params == nil {
+		return
+	}
+
+	// 只检查直接指定的 release_id, 引用上游输出的在运行前无法确定, 由任务 prepare 时渲染后再次检查
+	releaseID, _ := actualAction.Params[paramReleaseID].(string)
+	if releaseID == "" || strings.Contains(releaseID, "${") {
+		return
+	}
+	severity, _ := actualAction.Params[paramVulnerabilityGate].(string)
+	if strings.EqualFold(severity, vulnerabilityGateNone) {
+		return
+	}
+
+	workspace := itemsForCheck.Labels[apistructs.LabelDiceWorkspace]
+
+	result, err := d.bdl.CheckReleaseScanGate(releaseID, severity, workspace)
+	if err != nil {
+		// 卡点是否开启由 dicehub 决定, 无法获取结果时放行并给出提示
+		messages = append(messages, fmt.Sprintf("failed to check vulnerability gate of release %s, err: %v", releaseID, err))
+		return
+	}
+	if !result.Passed {
+		abort = true
+		messages = append(messages, fmt.Sprintf("release %s is blocked by vulnerability gate, %s", releaseID, result.Message))
+		return
+	}
+	if result.Message != "" {
+		messages = append(messages, fmt.Sprintf("release %s: %s", releaseID, result.Message))
+	}
+	return
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/api_register"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/buildpack"
+	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/dice"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/actionchecker/release"
 	"github.com/erda-project/erda/modules/pipeline/precheck/checkers/diceymlchecker"
 	"github.com/erda-project/erda/modules/pipeline/precheck/prechecktype"
@@ -179,6 +180,7 @@ func initialize() {
 			buildpack.New(),
 			release.New(),
 			api_register.New(),
+			dice.Default(),
 		}
 		actionPreCheckerMap = make(map[pipelineyml.ActionType]prechecktype.ActionPreChecker)
 		for _, checker := range actionPreCheckers {
@@ -52,6 +52,7 @@ var (
 	ErrListPipelineTasks     = err("ErrListPipelineTasks", "获取 pipeline 任务列表失败")
 	ErrGetPipelineTaskDetail = err("ErrGetPipelineTaskDetail", "获取 pipeline 任务详情失败")
 	ErrGetTaskBootstrapInfo  = err("ErrGetPipelineTaskBootstrapInfo", "获取任务启动信息失败")
+	ErrHandleTaskOSSObject   = err("ErrHandlePipelineTaskOSSObject", "读写任务对象存储失败")
 	ErrGetPipelineOutputs    = err("ErrGetPipelineOutputs", "获取流水线输出失败")
 	ErrPreCheckPipeline      = err("ErrPreCheckPipeline", "流水线前置校验失败")
 	ErrGetOpenapiOAuth2Token = err("ErrGetOpenapiOAuth2Token", "申请 openapi oauth2 token 失败")
@@ -102,6 +103,9 @@ var (
 	ErrCreatePipelineReport   = err("ErrCreatePipelineReport", "创建流水线报告失败")
 	ErrQueryPipelineReportSet = err("ErrQueryPipelineReportSet", "查询流水线报告集失败")
 	ErrPagingPipelineReports  = err("ErrPagingPipelineReports", "分页查询流水线报告集失败")
+
+	ErrListPipelineArtifacts    = err("ErrListPipelineArtifacts", "查询流水线制品失败")
+	ErrDownloadPipelineArtifact = err("ErrDownloadPipelineArtifact", "下载流水线制品失败")
 )
 
 func err(template, defaultValue string) *errorresp.APIError {
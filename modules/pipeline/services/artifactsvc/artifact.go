@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package artifactsvc
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+// List 查询流水线制品，已过期的制品不返回
+func (svc *ArtifactSvc) List(req apistructs.PipelineArtifactListRequest) ([]apistructs.PipelineArtifact, error) {
+	dbArtifacts, err := svc.dbClient.ListPipelineArtifacts(req.PipelineID, req.TaskID)
+	if err != nil {
+		return nil, apierrors.ErrListPipelineArtifacts.InternalError(err)
+	}
+	now := time.Now()
+	artifacts := make([]apistructs.PipelineArtifact, 0, len(dbArtifacts))
+	for _, a := range dbArtifacts {
+		if a.IsExpired(now) {
+			continue
+		}
+		artifacts = append(artifacts, a.Convert2DTO())
+	}
+	return artifacts, nil
+}
+
+// Download 返回制品文件内容，调用方负责关闭
+func (svc *ArtifactSvc) Download(pipelineID, artifactID uint64) (*spec.PipelineArtifact, io.ReadCloser, error) {
+	artifact, exist, err := svc.dbClient.GetPipelineArtifact(artifactID)
+	if err != nil {
+		return nil, nil, apierrors.ErrDownloadPipelineArtifact.InternalError(err)
+	}
+	if !exist || artifact.PipelineID != pipelineID {
+		return nil, nil, apierrors.ErrDownloadPipelineArtifact.NotFound()
+	}
+	if artifact.IsExpired(time.Now()) {
+		return nil, nil, apierrors.ErrDownloadPipelineArtifact.InvalidState(fmt.Sprintf("artifact %s already expired", artifact.Name))
+	}
+	r, err := svc.bdl.DownloadDiceFile(artifact.FileUUID)
+	if err != nil {
+		return nil, nil, apierrors.ErrDownloadPipelineArtifact.InternalError(err)
+	}
+	return &artifact, r, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package artifactsvc
+
+import (
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+)
+
+type ArtifactSvc struct {
+	dbClient *dbclient.Client
+	bdl      *bundle.Bundle
+}
+
+func New(ops ...Option) *ArtifactSvc {
+	var svc ArtifactSvc
+
+	for _, op := range ops {
+		op(&svc)
+	}
+
+	return &svc
+}
+
+type Option func(*ArtifactSvc)
+
+func WithDBClient(dbClient *dbclient.Client) Option {
+	return func(svc *ArtifactSvc) {
+		svc.dbClient = dbClient
+	}
+}
+
+func WithBundle(bdl *bundle.Bundle) Option {
+	return func(svc *ArtifactSvc) {
+		svc.bdl = bdl
+	}
+}
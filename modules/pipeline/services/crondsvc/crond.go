@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/cron"
 	"github.com/erda-project/erda/pkg/jsonstore/storetypes"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 )
 
 const etcdCrondUpdateWatchKey = "/devops/pipeline/crond/update"
@@ -78,14 +79,16 @@ func (s *CrondSvc) ReloadCrond(pipelineCronFunc func(uint64)) ([]string, error)
 
 	for i := range pcs {
 		pc := pcs[i]
-		//todo 校验pc.CronExpr是否合法
 		if pc.Enable != nil && *pc.Enable && pc.CronExpr != "" {
-			if err = s.crond.AddFunc(pc.CronExpr, func() { pipelineCronFunc(pc.ID) }, makePipelineCronName(pc)); err != nil {
+			// 支持多个带时区的表达式及禁止触发窗口
+			schedule, err := pipelineyml.NewCronSchedule(pc.CronExpr, pipelineyml.ConvertAPICronBlackouts(pc.Extra.CronBlackouts))
+			if err != nil {
 				l := fmt.Sprintf("failed to load pipeline cron item: %s, err: %v", makePipelineCronName(pc), err)
 				logs = append(logs, l)
 				logrus.Errorln("[alert]", l)
 				continue
 			}
+			s.crond.Schedule(schedule, cron.FuncJob(func() { pipelineCronFunc(pc.ID) }), makePipelineCronName(pc))
 			logs = append(logs, fmt.Sprintf("loaded pipeline cron item: %s", makePipelineCronName(pc)))
 		}
 	}
@@ -100,6 +103,28 @@ func (s *CrondSvc) ReloadCrond(pipelineCronFunc func(uint64)) ([]string, error)
 		logs = append(logs, fmt.Sprintf("loaded build cache clean cron task: %s", buildCacheCleanJobName))
 	}
 
+	// clean oss context and caches cron task
+	if conf.OSSEndpoint() != "" {
+		ossCleanJobName := makeCleanOSSStorageJobName(conf.OSSCleanJobCron())
+		if err = s.crond.AddFunc(conf.OSSCleanJobCron(), s.CleanOSSStorage, ossCleanJobName); err != nil {
+			l := fmt.Sprintf("failed to load oss storage clean cron task: %s, err: %v", ossCleanJobName, err)
+			logs = append(logs, l)
+			logrus.Errorln("[alert]", l)
+		} else {
+			logs = append(logs, fmt.Sprintf("loaded oss storage clean cron task: %s", ossCleanJobName))
+		}
+	}
+
+	// clean expired pipeline artifacts cron task
+	artifactCleanJobName := makeCleanPipelineArtifactsJobName(conf.ArtifactCleanJobCron())
+	if err = s.crond.AddFunc(conf.ArtifactCleanJobCron(), s.CleanExpiredPipelineArtifacts, artifactCleanJobName); err != nil {
+		l := fmt.Sprintf("failed to load pipeline artifact clean cron task: %s, err: %v", artifactCleanJobName, err)
+		logs = append(logs, l)
+		logrus.Errorln("[alert]", l)
+	} else {
+		logs = append(logs, fmt.Sprintf("loaded pipeline artifact clean cron task: %s", artifactCleanJobName))
+	}
+
 	logs = append(logs, "reload crond DONE")
 	logs = append(logs, s.CrondSnapshot()...)
 
@@ -123,3 +148,11 @@ func makePipelineCronName(cron spec.PipelineCron) string {
 func makeCleanBuildCacheJobName(cronExpr string) string {
 	return fmt.Sprintf("clean-build-cache-image-[%s]", cronExpr)
 }
+
+func makeCleanOSSStorageJobName(cronExpr string) string {
+	return fmt.Sprintf("clean-oss-storage-[%s]", cronExpr)
+}
+
+func makeCleanPipelineArtifactsJobName(cronExpr string) string {
+	return fmt.Sprintf("clean-pipeline-artifacts-[%s]", cronExpr)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package crondsvc
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/pipengine/pvolumes"
+	"github.com/erda-project/erda/pkg/storage"
+)
+
+// CleanOSSStorage 清理对象存储中过期的 context 和 caches
+func (s *CrondSvc) CleanOSSStorage() {
+	if conf.OSSEndpoint() == "" || conf.OSSBucket() == "" {
+		return
+	}
+	oss := storage.NewS3(conf.OSSEndpoint(), conf.OSSAccessKeyID(), conf.OSSAccessKeySecret(), conf.OSSBucket(),
+		storage.WithS3Region(conf.OSSRegion()))
+	now := time.Now()
+
+	// context 按流水线分组，整条流水线的 context 都过期后才删除，避免删除运行中流水线的 context
+	contexts, err := oss.List(pvolumes.OSSContextPrefix + "/")
+	if err != nil {
+		logrus.Errorf("[alert] failed to list oss contexts, err: %v", err)
+	} else {
+		deleteExpiredObjects(oss, contexts, func(objectPath string) string {
+			return path.Dir(objectPath)
+		}, now.Add(-conf.OSSContextExpireIn()))
+	}
+
+	// cache 的 tar 和指纹文件为一组，指纹文件在缓存命中时会刷新
+	caches, err := oss.List(pvolumes.OSSCachePrefix + "/")
+	if err != nil {
+		logrus.Errorf("[alert] failed to list oss caches, err: %v", err)
+	} else {
+		deleteExpiredObjects(oss, caches, func(objectPath string) string {
+			return strings.TrimSuffix(objectPath, pvolumes.TaskCacheDigestSuffix)
+		}, now.Add(-conf.OSSCacheExpireIn()))
+	}
+}
+
+// deleteExpiredObjects 按 groupBy 分组，组内最近修改时间早于 expireBefore 时删除整组
+func deleteExpiredObjects(oss storage.Storager, objects []storage.ObjectInfo, groupBy func(string) string, expireBefore time.Time) {
+	groups := make(map[string][]storage.ObjectInfo)
+	lastModified := make(map[string]time.Time)
+	for _, o := range objects {
+		g := groupBy(o.Path)
+		groups[g] = append(groups[g], o)
+		if o.LastModified.After(lastModified[g]) {
+			lastModified[g] = o.LastModified
+		}
+	}
+
+	for g, objs := range groups {
+		if !lastModified[g].Before(expireBefore) {
+			continue
+		}
+		for _, o := range objs {
+			if err := oss.Delete(o.Path); err != nil {
+				logrus.Errorf("failed to delete expired oss object: %s, err: %v", o.Path, err)
+				continue
+			}
+		}
+		logrus.Infof("deleted expired oss objects: %s, count: %d", g, len(objs))
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package crondsvc
+
+import (
+	"io"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/storage"
+)
+
+type fakeStorager struct {
+	deleted []string
+}
+
+func (f *fakeStorager) Type() storage.Type                  { return storage.TypeS3 }
+func (f *fakeStorager) Read(path string) (io.Reader, error) { return nil, nil }
+func (f *fakeStorager) Write(path string, r io.Reader) error {
+	return nil
+}
+func (f *fakeStorager) Delete(path string) error {
+	f.deleted = append(f.deleted, path)
+	return nil
+}
+
+func TestDeleteExpiredObjects(t *testing.T) {
+	now := time.Now()
+	objects := []storage.ObjectInfo{
+		{Path: "pipeline/contexts/1/repo.tar", LastModified: now.Add(-100 * time.Hour)},
+		{Path: "pipeline/contexts/1/build.tar", LastModified: now.Add(-90 * time.Hour)},
+		// pipeline 2 still has a fresh context
+		{Path: "pipeline/contexts/2/repo.tar", LastModified: now.Add(-100 * time.Hour)},
+		{Path: "pipeline/contexts/2/build.tar", LastModified: now.Add(-time.Hour)},
+	}
+	f := &fakeStorager{}
+	deleteExpiredObjects(f, objects, path.Dir, now.Add(-72*time.Hour))
+	assert.ElementsMatch(t, []string{"pipeline/contexts/1/repo.tar", "pipeline/contexts/1/build.tar"}, f.deleted)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package crondsvc
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const cleanPipelineArtifactsBatchSize = 100
+
+// CleanExpiredPipelineArtifacts 清理过期的流水线制品，先删除文件再删除记录
+// 文件删除失败时仍删除记录，文件服务会按过期时间兜底清理
+func (s *CrondSvc) CleanExpiredPipelineArtifacts() {
+	now := time.Now()
+	var deleted int
+	for {
+		artifacts, err := s.dbClient.ListExpiredPipelineArtifacts(now, cleanPipelineArtifactsBatchSize)
+		if err != nil {
+			logrus.Errorf("[alert] failed to list expired pipeline artifacts, err: %v", err)
+			return
+		}
+		for _, a := range artifacts {
+			if err := s.bdl.DeleteDiceFile(a.FileUUID); err != nil {
+				logrus.Warnf("failed to delete expired pipeline artifact file, artifactID: %d, uuid: %s, err: %v", a.ID, a.FileUUID, err)
+			}
+			if err := s.dbClient.DeletePipelineArtifact(a.ID); err != nil {
+				logrus.Errorf("[alert] failed to delete expired pipeline artifact, artifactID: %d, err: %v", a.ID, err)
+				return
+			}
+			deleted++
+		}
+		if len(artifacts) < cleanPipelineArtifactsBatchSize {
+			break
+		}
+	}
+	if deleted > 0 {
+		logrus.Infof("deleted expired pipeline artifacts, count: %d", deleted)
+	}
+}
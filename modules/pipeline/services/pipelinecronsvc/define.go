@@ -103,6 +103,7 @@ func (s *PipelineCronSvc) Create(req apistructs.PipelineCronCreateRequest) (*spe
 			Envs:          req.PipelineCreateRequest.Envs,
 			CronStartFrom: req.PipelineCreateRequest.CronStartFrom,
 			Version:       "v2",
+			CronBlackouts: pipelineyml.ConvertCronBlackouts(pipelineYml.Spec().CronBlackouts),
 		},
 	}
 	err = s.dbClient.InsertOrUpdatePipelineCron(&cron)
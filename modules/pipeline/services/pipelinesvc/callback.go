@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/events"
@@ -69,6 +70,10 @@ func (s *PipelineSvc) DealPipelineCallbackOfAction(data []byte) (err error) {
 	if err = s.doCallbackOfJarResource(&p, &task, cb); err != nil {
 		return err
 	}
+	// 3. artifacts
+	if err = s.doCallbackOfArtifacts(&p, &task, cb); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -130,6 +135,49 @@ func (s *PipelineSvc) doCallbackOfJarResource(p *spec.Pipeline, task *spec.Pipel
 	return nil
 }
 
+// doCallbackOfArtifacts 记录 action 上传的制品，同名制品重复上报时覆盖，被覆盖制品的文件一并删除
+func (s *PipelineSvc) doCallbackOfArtifacts(p *spec.Pipeline, task *spec.PipelineTask, cb apistructs.ActionCallback) error {
+	if len(cb.Artifacts) == 0 {
+		return nil
+	}
+	existArtifacts, err := s.dbClient.ListPipelineArtifacts(p.ID, task.ID)
+	if err != nil {
+		return err
+	}
+	existMap := make(map[string]spec.PipelineArtifact, len(existArtifacts))
+	for _, a := range existArtifacts {
+		existMap[a.Name] = a
+	}
+	for _, a := range cb.Artifacts {
+		exist, replaced := existMap[a.Name]
+		if replaced {
+			if err := s.dbClient.DeletePipelineArtifact(exist.ID); err != nil {
+				return err
+			}
+		}
+		artifact := spec.PipelineArtifact{
+			PipelineID: p.ID,
+			TaskID:     task.ID,
+			TaskName:   task.Name,
+			Name:       a.Name,
+			FileUUID:   a.FileUUID,
+			FileName:   a.FileName,
+			Size:       a.Size,
+			ExpiredAt:  a.ExpiredAt,
+		}
+		if err := s.dbClient.CreatePipelineArtifact(&artifact); err != nil {
+			return err
+		}
+		// 文件删除失败时文件服务会按过期时间兜底清理
+		if replaced && exist.FileUUID != a.FileUUID {
+			if err := s.bdl.DeleteDiceFile(exist.FileUUID); err != nil {
+				logrus.Warnf("failed to delete replaced pipeline artifact file, artifactID: %d, uuid: %s, err: %v", exist.ID, exist.FileUUID, err)
+			}
+		}
+	}
+	return nil
+}
+
 // findFlinkSparkTasks 寻找 depend 为指定值的 task
 func (s *PipelineSvc) findFlinkSparkTasks(p *spec.Pipeline, depend string) ([]spec.PipelineTask, error) {
 	tasks, err := s.dbClient.ListPipelineTasksByPipelineID(p.ID)
@@ -0,0 +1,30 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelinesvc
+
+import (
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+// makeConcurrencyInfo 根据 pipeline.yml concurrency 生成并发组信息，组名在进入队列前渲染
+func makeConcurrencyInfo(concurrency *pipelineyml.ConcurrencyConfig) *spec.ConcurrencyInfo {
+	if concurrency == nil || concurrency.Group == "" {
+		return nil
+	}
+	return &spec.ConcurrencyInfo{
+		GroupExpr:        concurrency.Group,
+		CancelInProgress: concurrency.CancelInProgress,
+	}
+}
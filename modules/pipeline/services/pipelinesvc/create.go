@@ -165,10 +165,13 @@ func (s *PipelineSvc) makePipelineFromRequest(req *apistructs.PipelineCreateRequ
 		return nil, apierrors.ErrParsePipelineYml.InternalError(err)
 	}
 	p.Extra.CronExpr = pipelineYml.Spec().Cron
-	if err := s.UpdatePipelineCron(p, nil, nil, pipelineYml.Spec().CronCompensator); err != nil {
+	if err := s.UpdatePipelineCron(p, nil, nil, pipelineYml.Spec().CronCompensator, pipelineYml.Spec().CronBlackouts); err != nil {
 		return nil, apierrors.ErrCreatePipeline.InternalError(err)
 	}
 
+	// --- concurrency ---
+	p.Extra.ConcurrencyInfo = makeConcurrencyInfo(pipelineYml.Spec().Concurrency)
+
 	version, err := pipelineyml.GetVersion([]byte(p.PipelineYml))
 	if err != nil {
 		return nil, apierrors.ErrParsePipelineYml.InvalidParameter("version")
@@ -333,10 +336,17 @@ func (s *PipelineSvc) createPipelineGraph(p *spec.Pipeline, passedDataOpt ...pas
 	sourceSnippetConfigMap := make(map[string]map[string]apistructs.SnippetConfig) // key: source, value: type
 	for _, snippetTask := range snippetTasks {
 		yamlSnippetConfig := snippetTask.Extra.Action.SnippetConfig
+		// 带上引用方信息，便于模板记录依赖方
+		labels := make(map[string]string, len(yamlSnippetConfig.Labels)+2)
+		for k, v := range yamlSnippetConfig.Labels {
+			labels[k] = v
+		}
+		labels[apistructs.LabelSnippetReferrerSource] = p.PipelineSource.String()
+		labels[apistructs.LabelSnippetReferrerName] = p.PipelineYmlName
 		snippetConfig := apistructs.SnippetConfig{
 			Source: yamlSnippetConfig.Source,
 			Name:   yamlSnippetConfig.Name,
-			Labels: yamlSnippetConfig.Labels,
+			Labels: labels,
 		}
 		if _, ok := sourceSnippetConfigMap[snippetConfig.Source]; !ok {
 			sourceSnippetConfigMap[snippetConfig.Source] = make(map[string]apistructs.SnippetConfig)
@@ -187,6 +187,15 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 		p.Extra.StorageConfig.EnableNFS = false
 		p.Extra.StorageConfig.EnableLocal = false
 	}
+	// 没有共享 nfs 的集群使用对象存储保存 context 和 caches
+	if !p.Extra.StorageConfig.EnableLocal &&
+		((storageConfig != nil && storageConfig.Context == "oss") || conf.OSSEnabledForCluster(p.ClusterName)) {
+		if conf.OSSEndpoint() == "" || conf.OSSBucket() == "" {
+			return nil, apierrors.ErrCreatePipeline.InvalidParameter("storage context oss is not configured")
+		}
+		p.Extra.StorageConfig.EnableNFS = false
+		p.Extra.StorageConfig.EnableOSS = true
+	}
 
 	// auto run
 	p.Extra.IsAutoRun = req.AutoRun
@@ -262,6 +271,9 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 		p.Extra.CronExpr = pc.CronExpr
 	}
 
+	// concurrency
+	p.Extra.ConcurrencyInfo = makeConcurrencyInfo(pipelineYml.Spec().Concurrency)
+
 	// triggerMode
 	if v, ok := labels[apistructs.LabelPipelineTriggerMode]; ok {
 		if !apistructs.PipelineTriggerMode(v).Valid() {
@@ -276,7 +288,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 	// gc
 	p.Extra.GC = req.GC
 
-	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator); err != nil {
+	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator, pipelineYml.Spec().CronBlackouts); err != nil {
 		return nil, apierrors.ErrCreatePipeline.InternalError(err)
 	}
 
@@ -311,14 +323,14 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 
 // 非定时触发的，如果有定时配置，需要插入或更新 pipeline_crons enable 配置
 // 不管是定时还是非定时，只要定时配置是空的，就将pipeline_crons disable
-func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator) error {
+func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator, cronBlackouts []*pipelineyml.CronBlackout) error {
 
 	var cron *spec.PipelineCron
 
 	//是定时类型的流水线，切定时的表达式不为空，更新cron的配置
 	if p.TriggerMode != apistructs.PipelineTriggerModeCron && p.Extra.CronExpr != "" {
 
-		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator)
+		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator, cronBlackouts)
 
 		if err := s.dbClient.InsertOrUpdatePipelineCron(cron); err != nil {
 			return apierrors.ErrUpdatePipelineCron.InternalError(err)
@@ -332,7 +344,7 @@ func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.T
 	//cron表达式为空，就需要关闭定时
 	if p.Extra.CronExpr == "" {
 
-		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator)
+		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator, cronBlackouts)
 		if err := s.dbClient.DisablePipelineCron(cron); err != nil {
 			return apierrors.ErrUpdatePipelineCron.InternalError(err)
 		}
@@ -346,7 +358,7 @@ func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.T
 	return nil
 }
 
-func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator) *spec.PipelineCron {
+func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator, cronBlackouts []*pipelineyml.CronBlackout) *spec.PipelineCron {
 	appID, _ := strconv.ParseUint(p.Labels[apistructs.LabelAppID], 10, 64)
 	var compensator *apistructs.CronCompensator
 	if cronCompensator != nil {
@@ -373,6 +385,7 @@ func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configMan
 			Version:                "v2",
 			Compensator:            compensator,
 			LastCompensateAt:       nil,
+			CronBlackouts:          pipelineyml.ConvertCronBlackouts(cronBlackouts),
 		},
 	}
 
@@ -169,6 +169,7 @@ func (s *PipelineSvc) cronInterruptCompensate(pc spec.PipelineCron) error {
 	needTriggerTimes, err := pipelineyml.ListNextCronTime(pc.CronExpr,
 		pipelineyml.WithCronStartEndTime(&compensateFromTime, &now),
 		pipelineyml.WithListNextScheduleCount(100),
+		pipelineyml.WithCronBlackouts(pipelineyml.ConvertAPICronBlackouts(pc.Extra.CronBlackouts)),
 	)
 	if err != nil {
 		return errors.Errorf("[alert] failed to list next crontimes, cronID: %d, err: %v", pc.ID, err)
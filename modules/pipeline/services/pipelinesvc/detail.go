@@ -174,6 +174,7 @@ func getPipelineParams(pipelineYml string, runParams []apistructs.PipelineRunPar
 				Default:  param.Default,
 				Required: param.Required,
 				Type:     param.Type,
+				Options:  param.Options,
 			},
 			Value: runParamsMap[param.Name],
 		})
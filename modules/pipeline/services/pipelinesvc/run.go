@@ -111,7 +111,25 @@ func (s *PipelineSvc) RunPipeline(req *apistructs.PipelineRunRequest) (*spec.Pip
 	}
 
 	// aop
-	_ = aop.Handle(aop.NewContextForPipeline(p, aoptypes.TuneTriggerPipelineBeforeExec))
+	aopCtx := aop.NewContextForPipeline(p, aoptypes.TuneTriggerPipelineBeforeExec)
+	aopErr := aop.Handle(aopCtx)
+	if annotations := aopCtx.Annotations(); len(annotations) > 0 {
+		if p.NormalLabels == nil {
+			p.NormalLabels = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			p.NormalLabels[k] = v
+		}
+		if err := s.dbClient.UpdatePipelineExtraByPipelineID(p.ID, &p.PipelineExtra); err != nil {
+			return nil, apierrors.ErrRunPipeline.InternalError(err)
+		}
+	}
+	if aoptypes.IsVetoError(aopErr) {
+		if err := s.dbClient.UpdatePipelineBaseStatus(p.ID, apistructs.PipelineStatusFailed); err != nil {
+			return nil, apierrors.ErrUpdatePipeline.InternalError(err)
+		}
+		return nil, apierrors.ErrRunPipeline.InvalidState(aopErr.Error())
+	}
 
 	// send to pipengine reconciler
 	s.engine.Send(p.ID)
@@ -152,6 +170,9 @@ func getRealRunParams(runParams []apistructs.PipelineRunParam, yml string) (resu
 		}
 
 		if runValue.Value != nil {
+			if err := pipelineyml.CheckTypedParamValue(param, runValue.Value); err != nil {
+				return nil, apierrors.ErrRunPipeline.InvalidParameter(err)
+			}
 			realParamsMap[param.Name] = runValue.Value
 		}
 
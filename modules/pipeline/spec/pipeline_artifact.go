@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package spec
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// PipelineArtifact represents `pipeline_artifacts` table.
+type PipelineArtifact struct {
+	ID         uint64 `xorm:"pk autoincr"`
+	PipelineID uint64
+	TaskID     uint64
+	TaskName   string
+	Name       string
+	FileUUID   string `xorm:"file_uuid"`
+	FileName   string
+	Size       int64
+	ExpiredAt  *time.Time
+	CreatedAt  time.Time `xorm:"created"`
+	UpdatedAt  time.Time `xorm:"updated"`
+}
+
+func (*PipelineArtifact) TableName() string {
+	return "pipeline_artifacts"
+}
+
+// IsExpired 制品是否已过期
+func (a *PipelineArtifact) IsExpired(now time.Time) bool {
+	return a.ExpiredAt != nil && !now.Before(*a.ExpiredAt)
+}
+
+func (a *PipelineArtifact) Convert2DTO() apistructs.PipelineArtifact {
+	return apistructs.PipelineArtifact{
+		ID:          a.ID,
+		PipelineID:  a.PipelineID,
+		TaskID:      a.TaskID,
+		TaskName:    a.TaskName,
+		Name:        a.Name,
+		FileUUID:    a.FileUUID,
+		FileName:    a.FileName,
+		Size:        a.Size,
+		DownloadURL: MakePipelineArtifactDownloadPath(a.PipelineID, a.ID),
+		ExpiredAt:   a.ExpiredAt,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// MakePipelineArtifactDownloadPath 生成制品的下载地址
+func MakePipelineArtifactDownloadPath(pipelineID, artifactID uint64) string {
+	return fmt.Sprintf("/api/pipelines/%d/artifacts/%d/actions/download", pipelineID, artifactID)
+}
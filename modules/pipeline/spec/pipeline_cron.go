@@ -64,6 +64,9 @@ type PipelineCronExtra struct {
 	Compensator *apistructs.CronCompensator `json:"compensator,omitempty"`
 	//每次中断补偿执行的时间，下次中断补偿从这个时间开始查询
 	LastCompensateAt *time.Time `json:"lastCompensateAt,omitempty"`
+
+	// CronBlackouts 禁止触发窗口，窗口内的定时触发会被跳过
+	CronBlackouts []apistructs.CronBlackout `json:"cronBlackouts,omitempty"`
 }
 
 func (PipelineCron) TableName() string {
@@ -112,6 +112,8 @@ type PipelineExtraInfo struct {
 	SnippetChain []uint64 `json:"snippetChain,omitempty"`
 
 	QueueInfo *QueueInfo `json:"queueInfo,omitempty"`
+
+	ConcurrencyInfo *ConcurrencyInfo `json:"concurrencyInfo,omitempty"`
 }
 
 type QueueInfo struct {
@@ -119,6 +121,13 @@ type QueueInfo struct {
 	CustomPriority int64  `json:"customPriority"`
 }
 
+// ConcurrencyInfo 并发组信息，来自 pipeline.yml concurrency
+type ConcurrencyInfo struct {
+	// GroupExpr 并发组表达式，进入队列前渲染
+	GroupExpr        string `json:"groupExpr"`
+	CancelInProgress bool   `json:"cancelInProgress"`
+}
+
 type Snapshot struct {
 	PipelineYml     string            `json:"pipeline_yml,omitempty"` // 对占位符进行渲染
 	Secrets         map[string]string `json:"secrets,omitempty"`
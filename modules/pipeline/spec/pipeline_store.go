@@ -22,8 +22,10 @@ var (
 	StoreTypeDiceVolumeLocal StoreType = "dice-local-volume"
 	StoreTypeDiceVolumeFake  StoreType = "dice-fake-volume"
 	StoreTypeDiceCacheNFS    StoreType = "dice-cache-nfs-volume"
+	StoreTypeDiceCacheOSS    StoreType = "dice-cache-oss"
 )
 
 const (
 	StoreTypeNFSProto = "file://"
+	StoreTypeOSSProto = "oss://"
 )
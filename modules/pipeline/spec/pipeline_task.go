@@ -113,6 +113,9 @@ type PipelineTaskContext struct {
 	OutStorages apistructs.Metadata `json:"outStorages,omitempty"`
 
 	CmsDiceFiles apistructs.Metadata `json:"cmsDiceFiles,omitempty"`
+
+	// Artifacts 引用的前置 action 制品，由 agent 下载并解压
+	Artifacts apistructs.Metadata `json:"artifacts,omitempty"`
 }
 
 func (c *PipelineTaskContext) Dedup() {
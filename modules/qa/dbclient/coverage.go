@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dbclient
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/cimysql"
+)
+
+// TestCoverageDO 存储应用每次提交的代码覆盖率，对应数据库表 qa_test_coverages
+type TestCoverageDO struct {
+	ID        uint64    `xorm:"pk autoincr 'id'" json:"id"`
+	CreatedAt time.Time `xorm:"created" json:"createdAt"`
+	UpdatedAt time.Time `xorm:"updated" json:"updatedAt"`
+
+	ApplicationID   int64                             `xorm:"app_id" json:"applicationId"`
+	ProjectID       int64                             `xorm:"project_id" json:"projectId"`
+	CommitID        string                            `xorm:"commit_id" json:"commitId"`
+	Branch          string                            `xorm:"branch" json:"branch"`
+	RecordID        uint64                            `xorm:"record_id" json:"recordId"`
+	Format          string                            `xorm:"format" json:"format"`
+	LinesCovered    int64                             `xorm:"lines_covered" json:"linesCovered"`
+	LinesValid      int64                             `xorm:"lines_valid" json:"linesValid"`
+	LineRate        float64                           `xorm:"line_rate" json:"lineRate"`
+	BranchesCovered int64                             `xorm:"branches_covered" json:"branchesCovered"`
+	BranchesValid   int64                             `xorm:"branches_valid" json:"branchesValid"`
+	BranchRate      float64                           `xorm:"branch_rate" json:"branchRate"`
+	Packages        []*apistructs.TestCoveragePackage `xorm:"longtext 'packages'" json:"packages,omitempty"`
+}
+
+// TableName TestCoverageDO 对应的数据库表 qa_test_coverages
+func (TestCoverageDO) TableName() string {
+	return "qa_test_coverages"
+}
+
+// SaveTestCoverage 同一应用同一提交只保留一份覆盖率，重复上报时覆盖
+func SaveTestCoverage(c *TestCoverageDO) error {
+	var exist TestCoverageDO
+	success, err := cimysql.Engine.Where("app_id = ? AND commit_id = ?", c.ApplicationID, c.CommitID).Get(&exist)
+	if err != nil {
+		return errors.Wrap(err, "find test coverage")
+	}
+	if !success {
+		if _, err := cimysql.Engine.InsertOne(c); err != nil {
+			return errors.Wrap(err, "insert test coverage")
+		}
+		return nil
+	}
+
+	c.ID = exist.ID
+	if _, err := cimysql.Engine.Id(c.ID).AllCols().Omit("created_at").Update(c); err != nil {
+		return errors.Wrap(err, "update test coverage")
+	}
+	return nil
+}
+
+// FindTestCoverage 查询应用某次提交的覆盖率，commitID 为空时返回最近一次
+func FindTestCoverage(appID int64, commitID string) (*TestCoverageDO, error) {
+	var c TestCoverageDO
+	session := cimysql.Engine.Where("app_id = ?", appID)
+	if commitID != "" {
+		session = session.And("commit_id = ?", commitID)
+	}
+	success, err := session.Desc("id").Get(&c)
+	if err != nil {
+		return nil, errors.Wrapf(err, "find test coverage, appID: %d, commitID: %s", appID, commitID)
+	}
+	if !success {
+		return nil, errors.Errorf("test coverage not found, appID: %d, commitID: %s", appID, commitID)
+	}
+	return &c, nil
+}
@@ -95,12 +95,32 @@ func (e *Endpoints) GetTestRecord(ctx context.Context, r *http.Request, vars map
 	return httpserver.OkResp(record)
 }
 
+func (e *Endpoints) GetTestCoverage(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.TestCoverageGetRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrGetTestCoverage.InvalidParameter(err).ToResp(), nil
+	}
+
+	coverage, err := dbclient.FindTestCoverage(req.ApplicationID, req.CommitID)
+	if err != nil {
+		return apierrors.ErrGetTestCoverage.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(coverage)
+}
+
 func storeTestResults(testResults *apistructs.TestCallBackRequest) (string, error) {
 	tpRecord := convertTestRecords(testResults)
 	if _, err := dbclient.InsertTPRecord(tpRecord); err != nil {
 		return "", err
 	}
 
+	if testResults.Coverage != nil {
+		if err := dbclient.SaveTestCoverage(convertTestCoverage(testResults, tpRecord.ID)); err != nil {
+			return "", err
+		}
+	}
+
 	return strconv.FormatUint(tpRecord.ID, 10), nil
 }
 
@@ -125,3 +145,21 @@ func convertTestRecords(results *apistructs.TestCallBackRequest) *dbclient.TPRec
 		UUID:            results.Results.UUID,
 	}
 }
+
+func convertTestCoverage(results *apistructs.TestCallBackRequest, recordID uint64) *dbclient.TestCoverageDO {
+	return &dbclient.TestCoverageDO{
+		ApplicationID:   results.Results.ApplicationID,
+		ProjectID:       results.Results.ProjectID,
+		CommitID:        results.Results.CommitID,
+		Branch:          results.Results.Branch,
+		RecordID:        recordID,
+		Format:          results.Coverage.Format,
+		LinesCovered:    results.Coverage.LinesCovered,
+		LinesValid:      results.Coverage.LinesValid,
+		LineRate:        results.Coverage.LineRate,
+		BranchesCovered: results.Coverage.BranchesCovered,
+		BranchesValid:   results.Coverage.BranchesValid,
+		BranchRate:      results.Coverage.BranchRate,
+		Packages:        results.Coverage.Packages,
+	}
+}
@@ -60,6 +60,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/qa/actions/test-list", Method: http.MethodGet, Handler: e.GetRecords},
 		{Path: "/api/qa/test/{id}", Method: http.MethodGet, Handler: e.GetTestRecord},
 		{Path: "/api/qa/actions/test-callback", Method: http.MethodPost, Handler: e.TestCallback},
+		{Path: "/api/qa/actions/test-coverage", Method: http.MethodGet, Handler: e.GetTestCoverage},
 		{Path: "/api/qa/actions/get-sonar-credential", Method: http.MethodGet, Handler: e.GetSonarCredential},
 
 		// pmp api test
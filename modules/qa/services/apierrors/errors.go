@@ -31,6 +31,7 @@ var (
 
 	ErrPagingTestRecords = err("ErrPagingTestRecords", "测试记录分页查询失败")
 	ErrGetTestRecord     = err("ErrGetTestRecord", "查询测试记录详情失败")
+	ErrGetTestCoverage   = err("ErrGetTestCoverage", "查询代码覆盖率失败")
 
 	ErrCreateAPITestEnv = err("ErrCreateAPITestEnv", "创建接口测试环境失败")
 	ErrUpdateAPITestEnv = err("ErrUpdateAPITestEnv", "更新接口测试环境失败")
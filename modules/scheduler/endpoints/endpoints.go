@@ -407,6 +407,33 @@ func (h *HTTPEndpoints) ServiceGroupPrecheck(ctx context.Context, r *http.Reques
 
 }
 
+func (h *HTTPEndpoints) ServiceGroupPlan(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	req := apistructs.ServiceGroupPlanRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errstr := fmt.Sprintf("decode plan servicegroup request fail: %v", err)
+		return mkResponse(apistructs.ServiceGroupPlanResponse{
+			Header: apistructs.Header{
+				Success: false,
+				Error:   apistructs.ErrorResponse{Msg: errstr},
+			}})
+	}
+	res, err := h.serviceGroupImpl.Plan(req)
+	if err != nil {
+		errstr := fmt.Sprintf("plan servicegroup fail: %v", err)
+		return mkResponse(apistructs.ServiceGroupPlanResponse{
+			Header: apistructs.Header{
+				Success: false,
+				Error:   apistructs.ErrorResponse{Msg: errstr},
+			},
+		})
+	}
+	return mkResponse(apistructs.ServiceGroupPlanResponse{
+		Header: apistructs.Header{Success: true},
+		Data:   res,
+	})
+}
+
 func (h *HTTPEndpoints) ServiceGroupKillPod(ctx context.Context, r *http.Request, vars map[string]string) (
 	httpserver.Responser, error) {
 	req := apistructs.ServiceGroupKillPodRequest{}
@@ -103,6 +103,12 @@ type TerminalExecutor interface {
 	Terminal(namespace, podname, containername string, conn *websocket.Conn)
 }
 
+// PlanExecutor computes the changes of servicegroup update without applying them
+// Only k8s executor implement this interface
+type PlanExecutor interface {
+	Plan(ctx context.Context, spec interface{}) (apistructs.ServiceGroupPlanData, error)
+}
+
 type ExecutorWholeConfigs struct {
 	// Common cluster configuration
 	BasicConfig map[string]string
@@ -176,6 +176,9 @@ func (k *Kubernetes) newDaemonSet(service *apistructs.Service, sg *apistructs.Se
 	daemonset.Labels[LabelServiceGroupID] = service.Env[KeyServiceGroupID]
 	daemonset.Labels["app"] = service.Name
 	daemonset.Spec.Template.Labels["app"] = service.Name
+	if err := setSubsetPodLabels(service, daemonset.Spec.Template.Labels); err != nil {
+		return nil, err
+	}
 
 	if daemonset.Spec.Template.Annotations == nil {
 		daemonset.Spec.Template.Annotations = make(map[string]string)
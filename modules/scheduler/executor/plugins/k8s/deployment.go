@@ -24,6 +24,7 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
@@ -73,6 +74,11 @@ func (k *Kubernetes) getDeploymentStatus(service *apistructs.Service) (apistruct
 	for _, c := range status.Conditions {
 		if c.Type == k8sapi.DeploymentReplicaFailure && c.Status == "True" {
 			statusDesc.Status = apistructs.StatusFailing
+			// e.g. pods are forbidden by exceeded ResourceQuota
+			statusDesc.LastMessage = c.Message
+			if isQuotaExceeded(c.Message) {
+				statusDesc.Reason = apistructs.StatusReasonQuotaExceeded
+			}
 			return statusDesc, nil
 		}
 		if c.Type == k8sapi.DeploymentAvailable && c.Status == "False" {
@@ -474,7 +480,9 @@ func (k *Kubernetes) newDeployment(service *apistructs.Service, sg *apistructs.S
 	deployment.Labels["app"] = service.Name
 	deployment.Spec.Template.Labels["app"] = service.Name
 
-	setDeploymentLabels(service, deployment)
+	if err := setDeploymentLabels(service, deployment); err != nil {
+		return nil, err
+	}
 
 	if deployment.Spec.Template.Annotations == nil {
 		deployment.Spec.Template.Annotations = make(map[string]string)
@@ -647,7 +655,7 @@ func (k *Kubernetes) AddPodMountVolume(service *apistructs.Service, podSpec *api
 		if !strutil.HasPrefixes(hostPath, "/") {
 			pvcName := strings.Replace(hostPath, "_", "-", -1)
 			sc := "dice-local-volume"
-			if err := k.pvc.CreateIfNotExists(&apiv1.PersistentVolumeClaim{
+			if err := k.createPVCIfNotExists(&apiv1.PersistentVolumeClaim{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      fmt.Sprintf("%s-%s", service.Name, pvcName),
 					Namespace: service.Namespace,
@@ -748,6 +756,13 @@ func (k *Kubernetes) AddPodMountVolume(service *apistructs.Service, podSpec *api
 
 	return nil
 }
+func (k *Kubernetes) createPVCIfNotExists(pvc *apiv1.PersistentVolumeClaim) error {
+	if k.dryRun {
+		return nil
+	}
+	return k.pvc.CreateIfNotExists(pvc)
+}
+
 func (k *Kubernetes) AddSpotEmptyDir(podSpec *apiv1.PodSpec) {
 	podSpec.Volumes = append(podSpec.Volumes, apiv1.Volume{
 		Name:         "spot-emptydir",
@@ -767,12 +782,38 @@ func getDeployName(service *apistructs.Service) string {
 	return service.Name
 }
 
-func setDeploymentLabels(service *apistructs.Service, deployment *appsv1.Deployment) {
+func setDeploymentLabels(service *apistructs.Service, deployment *appsv1.Deployment) error {
+	if err := setSubsetPodLabels(service, deployment.Spec.Template.Labels); err != nil {
+		return err
+	}
 	if v, ok := service.Env[ProjectNamespace]; ok && v == "true" {
 		deployment.Spec.Selector.MatchLabels[LabelServiceGroupID] = service.Env[KeyServiceGroupID]
 		deployment.Spec.Template.Labels[LabelServiceGroupID] = service.Env[KeyServiceGroupID]
 		deployment.Labels[LabelServiceGroupID] = service.Env[KeyServiceGroupID]
 	}
+	return nil
+}
+
+// setSubsetPodLabels stamp deployments.labels of dice.yml on pods for istio subsets to select pods,
+// only when mesh is enabled and subsets are declared. They are not added to the selector which is immutable
+func setSubsetPodLabels(service *apistructs.Service, podLabels map[string]string) error {
+	if service.MeshEnable == nil || !*service.MeshEnable ||
+		service.TrafficManagement == nil || len(service.TrafficManagement.Subsets) == 0 {
+		return nil
+	}
+	for k, v := range service.DeploymentLabels {
+		if k == "app" || k == LabelServiceGroupID {
+			continue
+		}
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return errors.Errorf("invalid deployment label key %q of service %s: %s", k, service.Name, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return errors.Errorf("invalid deployment label value %q of service %s: %s", v, service.Name, strings.Join(errs, "; "))
+		}
+		podLabels[k] = v
+	}
+	return nil
 }
 
 func ConvertToHostAlias(hosts []string) []apiv1.HostAlias {
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/istioctl/assembler"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func newTestDeployment(service *apistructs.Service) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}},
+		Spec: appsv1.DeploymentSpec{
+			Template: apiv1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": service.Name}},
+		},
+	}
+	deployment.Labels["app"] = service.Name
+	deployment.Spec.Template.Labels["app"] = service.Name
+	return deployment
+}
+
+func TestSetDeploymentLabelsMatchSubsets(t *testing.T) {
+	meshEnable := true
+	tm := &diceyml.TrafficManagement{
+		Subsets: []diceyml.TrafficSubset{
+			{Name: "stable", Labels: map[string]string{"version": "v1"}, Weight: 100},
+			{Name: "canary", Labels: map[string]string{"version": "v2"}},
+		},
+		Routes: []diceyml.TrafficRoute{
+			{Headers: map[string]string{"x-canary": "true"}, Subset: "canary"},
+		},
+	}
+	newPodLabels := func(version string) labels.Set {
+		service := &apistructs.Service{
+			Name:              "web",
+			DeploymentLabels:  map[string]string{"version": version, "app": "other"},
+			MeshEnable:        &meshEnable,
+			TrafficManagement: tm,
+		}
+		deployment := newTestDeployment(service)
+		assert.NoError(t, setDeploymentLabels(service, deployment))
+		assert.Equal(t, map[string]string{"app": service.Name}, deployment.Spec.Selector.MatchLabels)
+		return deployment.Spec.Template.Labels
+	}
+	v1Pod, v2Pod := newPodLabels("v1"), newPodLabels("v2")
+	assert.Equal(t, "web", v1Pod["app"])
+
+	subsets := assembler.NewSubsets(&apistructs.Service{TrafficManagement: tm})
+	assert.Len(t, subsets, 2)
+	stable := labels.SelectorFromSet(subsets[0].Labels)
+	canary := labels.SelectorFromSet(subsets[1].Labels)
+	assert.True(t, stable.Matches(v1Pod))
+	assert.False(t, stable.Matches(v2Pod))
+	assert.True(t, canary.Matches(v2Pod))
+	assert.False(t, canary.Matches(v1Pod))
+}
+
+func TestSetDeploymentLabelsWithoutSubsets(t *testing.T) {
+	meshEnable := true
+	service := &apistructs.Service{
+		Name:             "web",
+		DeploymentLabels: map[string]string{"version": "v1"},
+	}
+	// not stamped when mesh is disabled
+	deployment := newTestDeployment(service)
+	assert.NoError(t, setDeploymentLabels(service, deployment))
+	assert.Equal(t, map[string]string{"app": "web"}, deployment.Spec.Template.Labels)
+
+	// not stamped when no subset is declared
+	service.MeshEnable = &meshEnable
+	service.TrafficManagement = &diceyml.TrafficManagement{Timeout: "3s"}
+	deployment = newTestDeployment(service)
+	assert.NoError(t, setDeploymentLabels(service, deployment))
+	assert.Equal(t, map[string]string{"app": "web"}, deployment.Spec.Template.Labels)
+}
+
+func TestSetDeploymentLabelsInvalid(t *testing.T) {
+	meshEnable := true
+	service := &apistructs.Service{
+		Name:       "web",
+		MeshEnable: &meshEnable,
+		TrafficManagement: &diceyml.TrafficManagement{
+			Subsets: []diceyml.TrafficSubset{{Name: "stable", Labels: map[string]string{"version": "v1"}, Weight: 100}},
+		},
+	}
+	service.DeploymentLabels = map[string]string{"version": "v1/beta"}
+	assert.Error(t, setDeploymentLabels(service, newTestDeployment(service)))
+	service.DeploymentLabels = map[string]string{"bad key": "v1"}
+	assert.Error(t, setDeploymentLabels(service, newTestDeployment(service)))
+}
@@ -46,12 +46,14 @@ import (
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/instanceinfosync"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sservice"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/limitrange"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/namespace"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/nodelabel"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/persistentvolume"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/persistentvolumeclaim"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/pod"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/resourceinfo"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/resourcequota"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/secret"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/serviceaccount"
 	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/statefulset"
@@ -172,6 +174,8 @@ type Kubernetes struct {
 	sts          *statefulset.StatefulSet
 	pod          *pod.Pod
 	secret       *secret.Secret
+	quota        *resourcequota.ResourceQuota
+	limitRange   *limitrange.LimitRange
 	sa           *serviceaccount.ServiceAccount
 	nodeLabel    *nodelabel.NodeLabel
 	ClusterInfo  *clusterinfo.ClusterInfo
@@ -202,6 +206,9 @@ type Kubernetes struct {
 	dbclient *instanceinfo.Client
 
 	istioEngine istioctl.IstioEngine
+
+	// dryRun is used by Plan, the dependent secrets and pvcs would not be created when generating objects
+	dryRun bool
 }
 
 func (k *Kubernetes) GetK8SAddr() string {
@@ -336,6 +343,8 @@ func New(name executortypes.Name, clusterName string, options map[string]string)
 	sts := statefulset.New(statefulset.WithCompleteParams(addr, client))
 	k8spod := pod.New(pod.WithCompleteParams(addr, client))
 	k8ssecret := secret.New(secret.WithCompleteParams(addr, client))
+	rq := resourcequota.New(resourcequota.WithCompleteParams(addr, client))
+	lr := limitrange.New(limitrange.WithCompleteParams(addr, client))
 	sa := serviceaccount.New(serviceaccount.WithCompleteParams(addr, client))
 	nodeLabel := nodelabel.New(addr, client)
 	event := event.New(event.WithCompleteParams(addr, client))
@@ -385,6 +394,8 @@ func New(name executortypes.Name, clusterName string, options map[string]string)
 		sts:                      sts,
 		pod:                      k8spod,
 		secret:                   k8ssecret,
+		quota:                    rq,
+		limitRange:               lr,
 		sa:                       sa,
 		nodeLabel:                nodeLabel,
 		ClusterInfo:              clusterInfo,
@@ -630,6 +641,11 @@ func (k *Kubernetes) ResourceInfo(brief bool) (apistructs.ClusterResourceInfoDat
 	r.TestMEMOverCommit = k.testMemSubscribeRatio
 	r.StagingMEMOverCommit = k.stagingMemSubscribeRatio
 
+	if !brief {
+		if r.Namespaces, err = k.namespaceResourceInfo(); err != nil {
+			return r, err
+		}
+	}
 	return r, nil
 }
 
@@ -980,6 +996,11 @@ func (k *Kubernetes) getStatelessStatus(ctx context.Context, sg *apistructs.Serv
 				sg.Services[i].LastMessage = podstatuses[0].Message
 				sg.Services[i].Reason = string(podstatuses[0].Reason)
 			}
+			// pods are never created when quota exceeded, so there is no pod status
+			if status.Reason == apistructs.StatusReasonQuotaExceeded {
+				sg.Services[i].LastMessage = status.LastMessage
+				sg.Services[i].Reason = status.Reason
+			}
 			continue
 		}
 
@@ -0,0 +1,149 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package limitrange manipulates the k8s api of limitrange object
+package limitrange
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// LimitRange is the object to encapsulate the k8s limitrange api
+type LimitRange struct {
+	addr   string
+	client *httpclient.HTTPClient
+}
+
+// Option configures a LimitRange
+type Option func(*LimitRange)
+
+// New news a LimitRange
+func New(options ...Option) *LimitRange {
+	lr := &LimitRange{}
+
+	for _, op := range options {
+		op(lr)
+	}
+
+	return lr
+}
+
+// WithCompleteParams provides an Option
+func WithCompleteParams(addr string, client *httpclient.HTTPClient) Option {
+	return func(lr *LimitRange) {
+		lr.addr = addr
+		lr.client = client
+	}
+}
+
+// Get gets a k8s limitrange
+func (lr *LimitRange) Get(namespace, name string) (*apiv1.LimitRange, error) {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", namespace, "/limitranges/", name)
+
+	resp, err := lr.client.Get(lr.addr).
+		Path(path).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return nil, errors.Errorf("failed to get limitrange, name: %s, (%v)", name, err)
+	}
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return nil, k8serror.ErrNotFound
+		}
+		return nil, errors.Errorf("failed to get limitrange, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	limitRange := &apiv1.LimitRange{}
+	if err := json.NewDecoder(&b).Decode(limitRange); err != nil {
+		return nil, err
+	}
+	return limitRange, nil
+}
+
+// Create creates a k8s limitrange
+func (lr *LimitRange) Create(limitRange *apiv1.LimitRange) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", limitRange.Namespace, "/limitranges")
+
+	resp, err := lr.client.Post(lr.addr).
+		Path(path).
+		JSONBody(limitRange).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to create limitrange, name: %s, (%v)", limitRange.Name, err)
+	}
+	if !resp.IsOK() {
+		return errors.Errorf("failed to create limitrange, name: %s, statuscode: %v, body: %v",
+			limitRange.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Put updates a k8s limitrange
+func (lr *LimitRange) Put(limitRange *apiv1.LimitRange) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", limitRange.Namespace, "/limitranges/", limitRange.Name)
+
+	resp, err := lr.client.Put(lr.addr).
+		Path(path).
+		JSONBody(limitRange).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to put limitrange, name: %s, (%v)", limitRange.Name, err)
+	}
+	if !resp.IsOK() {
+		return errors.Errorf("failed to put limitrange, name: %s, statuscode: %v, body: %v",
+			limitRange.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Delete deletes a k8s limitrange
+func (lr *LimitRange) Delete(namespace, name string) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", namespace, "/limitranges/", name)
+
+	resp, err := lr.client.Delete(lr.addr).
+		Path(path).
+		JSONBody(k8sapi.DeleteOptions).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to delete limitrange, name: %s, (%v)", name, err)
+	}
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return k8serror.ErrNotFound
+		}
+		return errors.Errorf("failed to delete limitrange, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
@@ -44,7 +44,7 @@ func (k *Kubernetes) CreateNamespace(ns string, sg *apistructs.ServiceGroup) err
 
 	if !notfound {
 		if sg.ProjectNamespace != "" {
-			return nil
+			return k.reconcileNamespaceQuota(ns, sg)
 		}
 		return errors.Errorf("failed to create namespace, ns: %s, (namespace already exists)", ns)
 	}
@@ -62,7 +62,7 @@ func (k *Kubernetes) CreateNamespace(ns string, sg *apistructs.ServiceGroup) err
 	if err = k.NewRuntimeImageSecret(ns, sg); err != nil {
 		logrus.Errorf("failed to create imagePullSecret, namespace: %s, (%v)", ns, err)
 	}
-	return nil
+	return k.reconcileNamespaceQuota(ns, sg)
 }
 
 // UpdateNamespace
@@ -81,7 +81,10 @@ func (k *Kubernetes) UpdateNamespace(ns string, sg *apistructs.ServiceGroup) err
 		labels["istio-injection"] = "enabled"
 	}
 
-	return k.namespace.Update(ns, labels)
+	if err = k.namespace.Update(ns, labels); err != nil {
+		return err
+	}
+	return k.reconcileNamespaceQuota(ns, sg)
 }
 
 // NotfoundNamespace not found namespace
@@ -0,0 +1,475 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+)
+
+const (
+	planKindDeployment = "Deployment"
+	planKindDaemonSet  = "DaemonSet"
+	planKindService    = "Service"
+
+	podTemplatePath = "spec.template"
+)
+
+// Plan implements computing the k8s objects which Update would create, update or delete, nothing is applied to the cluster
+func (k *Kubernetes) Plan(ctx context.Context, specObj interface{}) (apistructs.ServiceGroupPlanData, error) {
+	sg, err := ValidateRuntime(specObj, "Plan")
+	if err != nil {
+		return apistructs.ServiceGroupPlanData{}, err
+	}
+	if _, ok := sg.Labels["USE_OPERATOR"]; ok {
+		return apistructs.ServiceGroupPlanData{}, errors.Errorf("Not supported for planning addon operator")
+	}
+	// Stateful apps don’t support updates yet
+	if IsGroupStateful(sg) {
+		return apistructs.ServiceGroupPlanData{}, errors.Errorf("Not supported for planning stateful applications")
+	}
+
+	labelSelector := make(map[string]string)
+	var ns = MakeNamespace(sg)
+	if sg.ProjectNamespace != "" {
+		ns = sg.ProjectNamespace
+		k.setProjectNamespaceEnvs(sg)
+		labelSelector[LabelServiceGroupID] = sg.ID
+	}
+
+	// Generating desired objects must not create the secrets and pvcs they depend on
+	planner := *k
+	planner.dryRun = true
+	return planner.planServiceGroup(ns, labelSelector, sg)
+}
+
+func (k *Kubernetes) planServiceGroup(ns string, labelSelector map[string]string, sg *apistructs.ServiceGroup) (
+	apistructs.ServiceGroupPlanData, error) {
+	var plan apistructs.ServiceGroupPlanData
+
+	// namespace does not exist, Update is equivalent to creating
+	nsNotFound, err := k.NotfoundNamespace(ns)
+	if err != nil {
+		return plan, err
+	}
+	liveWorkloads := make(map[string]string)
+	if !nsNotFound {
+		deployList, err := k.deploy.List(ns, labelSelector)
+		if err != nil {
+			return plan, err
+		}
+		for _, item := range deployList.Items {
+			liveWorkloads[item.Name] = planKindDeployment
+		}
+		dsList, err := k.ds.List(ns, labelSelector)
+		if err != nil {
+			return plan, err
+		}
+		for _, item := range dsList.Items {
+			liveWorkloads[item.Name] = planKindDaemonSet
+		}
+	}
+
+	desired := make(map[string]struct{})
+	for _, svc := range sg.Services {
+		svc.Namespace = ns
+		desired[getDeployName(&svc)] = struct{}{}
+		items, err := k.planService(&svc, sg, nsNotFound)
+		if err != nil {
+			return plan, err
+		}
+		plan.Items = append(plan.Items, items...)
+	}
+
+	// The workloads that are not in the desired services would be deleted, together with the k8s services of the same name
+	var toBeDeleted []string
+	for name := range liveWorkloads {
+		if _, ok := desired[name]; !ok {
+			toBeDeleted = append(toBeDeleted, name)
+		}
+	}
+	sort.Strings(toBeDeleted)
+	for _, name := range toBeDeleted {
+		plan.Items = append(plan.Items, apistructs.ServiceGroupPlanItem{
+			Kind:      liveWorkloads[name],
+			Namespace: ns,
+			Name:      name,
+			Service:   name,
+			Action:    apistructs.ServiceGroupPlanDelete,
+		})
+		if _, err := k.GetService(ns, name); err == nil {
+			plan.Items = append(plan.Items, apistructs.ServiceGroupPlanItem{
+				Kind:      planKindService,
+				Namespace: ns,
+				Name:      name,
+				Service:   name,
+				Action:    apistructs.ServiceGroupPlanDelete,
+			})
+		} else if err != k8serror.ErrNotFound {
+			return plan, err
+		}
+	}
+
+	for _, item := range plan.Items {
+		if item.Restart {
+			plan.Restarts = append(plan.Restarts, item.Service)
+		}
+	}
+	return plan, nil
+}
+
+func (k *Kubernetes) planService(svc *apistructs.Service, sg *apistructs.ServiceGroup, nsNotFound bool) (
+	[]apistructs.ServiceGroupPlanItem, error) {
+	var items []apistructs.ServiceGroupPlanItem
+
+	// k8s service is only created for services with exposed ports
+	var liveService *apiv1.Service
+	if !nsNotFound {
+		s, err := k.GetService(svc.Namespace, svc.Name)
+		if err != nil && err != k8serror.ErrNotFound {
+			return nil, err
+		}
+		liveService = s
+	}
+	if item, changed := planK8sService(svc, newService(svc), liveService); changed {
+		items = append(items, item)
+	}
+
+	item := apistructs.ServiceGroupPlanItem{
+		Namespace: svc.Namespace,
+		Name:      getDeployName(svc),
+		Service:   svc.Name,
+	}
+	switch svc.WorkLoad {
+	case ServicePerNode:
+		item.Kind = planKindDaemonSet
+		desiredDaemonSet, err := k.newDaemonSet(svc, sg)
+		if err != nil {
+			return nil, err
+		}
+		var liveDaemonSet *appsv1.DaemonSet
+		if !nsNotFound {
+			if liveDaemonSet, err = k.getDaemonSet(svc.Namespace, item.Name); err != nil && err != k8serror.ErrNotFound {
+				return nil, err
+			}
+		}
+		if liveDaemonSet == nil {
+			item.Action = apistructs.ServiceGroupPlanCreate
+		} else {
+			item.Diffs = diffPodTemplate(podTemplatePath, &liveDaemonSet.Spec.Template, &desiredDaemonSet.Spec.Template)
+		}
+	default:
+		item.Kind = planKindDeployment
+		desiredDeployment, err := k.newDeployment(svc, sg)
+		if err != nil {
+			return nil, err
+		}
+		var liveDeployment *appsv1.Deployment
+		if !nsNotFound {
+			if liveDeployment, err = k.getDeployment(svc.Namespace, item.Name); err != nil && err != k8serror.ErrNotFound {
+				return nil, err
+			}
+		}
+		if liveDeployment == nil {
+			item.Action = apistructs.ServiceGroupPlanCreate
+		} else {
+			item.Diffs = diffDeployment(liveDeployment, desiredDeployment)
+		}
+	}
+	if item.Action == "" {
+		if len(item.Diffs) == 0 {
+			return items, nil
+		}
+		item.Action = apistructs.ServiceGroupPlanUpdate
+		item.Restart = needRestart(item.Diffs)
+	}
+	return append(items, item), nil
+}
+
+func planK8sService(svc *apistructs.Service, desired, live *apiv1.Service) (apistructs.ServiceGroupPlanItem, bool) {
+	item := apistructs.ServiceGroupPlanItem{
+		Kind:      planKindService,
+		Namespace: svc.Namespace,
+		Name:      svc.Name,
+		Service:   svc.Name,
+	}
+	switch {
+	case desired == nil && live == nil:
+		return item, false
+	case desired == nil:
+		item.Action = apistructs.ServiceGroupPlanDelete
+	case live == nil:
+		item.Action = apistructs.ServiceGroupPlanCreate
+	default:
+		item.Diffs = diffK8sService(live, desired)
+		if len(item.Diffs) == 0 {
+			return item, false
+		}
+		item.Action = apistructs.ServiceGroupPlanUpdate
+	}
+	return item, true
+}
+
+// needRestart any change of pod template would trigger rolling update
+func needRestart(diffs []apistructs.ServiceGroupPlanFieldDiff) bool {
+	for _, diff := range diffs {
+		if strings.HasPrefix(diff.Path, podTemplatePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffDeployment only compares the fields set by newDeployment, as the live one is filled with defaults by apiserver
+func diffDeployment(live, desired *appsv1.Deployment) []apistructs.ServiceGroupPlanFieldDiff {
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	diffs = appendDiff(diffs, "spec.replicas", int32PtrString(live.Spec.Replicas), int32PtrString(desired.Spec.Replicas))
+	diffs = appendDiff(diffs, "spec.strategy.type",
+		defaultString(string(live.Spec.Strategy.Type), string(appsv1.RollingUpdateDeploymentStrategyType)),
+		defaultString(string(desired.Spec.Strategy.Type), string(appsv1.RollingUpdateDeploymentStrategyType)))
+	diffs = append(diffs, diffStringMap("metadata.labels", live.Labels, desired.Labels)...)
+	diffs = append(diffs, diffPodTemplate(podTemplatePath, &live.Spec.Template, &desired.Spec.Template)...)
+	return diffs
+}
+
+func diffK8sService(live, desired *apiv1.Service) []apistructs.ServiceGroupPlanFieldDiff {
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	diffs = append(diffs, diffStringMap("metadata.labels", live.Labels, desired.Labels)...)
+	diffs = append(diffs, diffStringMap("spec.selector", live.Spec.Selector, desired.Spec.Selector)...)
+	diffs = appendDiff(diffs, "spec.ports", servicePortsString(live.Spec.Ports), servicePortsString(desired.Spec.Ports))
+	return diffs
+}
+
+func diffPodTemplate(path string, live, desired *apiv1.PodTemplateSpec) []apistructs.ServiceGroupPlanFieldDiff {
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	diffs = append(diffs, diffStringMap(path+".metadata.labels", live.Labels, desired.Labels)...)
+	diffs = append(diffs, diffStringMap(path+".metadata.annotations", live.Annotations, desired.Annotations)...)
+	diffs = append(diffs, diffContainers(path+".spec.containers", live.Spec.Containers, desired.Spec.Containers)...)
+	diffs = append(diffs, diffContainers(path+".spec.initContainers", live.Spec.InitContainers, desired.Spec.InitContainers)...)
+	diffs = appendDiff(diffs, path+".spec.volumes", volumesString(live.Spec.Volumes), volumesString(desired.Spec.Volumes))
+	diffs = appendDiff(diffs, path+".spec.hostAliases", fmt.Sprintf("%v", live.Spec.HostAliases), fmt.Sprintf("%v", desired.Spec.HostAliases))
+	diffs = append(diffs, diffStringMap(path+".spec.nodeSelector", live.Spec.NodeSelector, desired.Spec.NodeSelector)...)
+	diffs = appendDiff(diffs, path+".spec.affinity", jsonString(live.Spec.Affinity), jsonString(desired.Spec.Affinity))
+	diffs = appendDiff(diffs, path+".spec.tolerations", jsonString(live.Spec.Tolerations), jsonString(desired.Spec.Tolerations))
+	diffs = appendDiff(diffs, path+".spec.imagePullSecrets", imagePullSecretsString(live.Spec.ImagePullSecrets), imagePullSecretsString(desired.Spec.ImagePullSecrets))
+	diffs = appendDiff(diffs, path+".spec.securityContext", jsonString(live.Spec.SecurityContext), jsonString(desired.Spec.SecurityContext))
+	diffs = appendDiff(diffs, path+".spec.serviceAccountName", live.Spec.ServiceAccountName, desired.Spec.ServiceAccountName)
+	return diffs
+}
+
+func diffContainers(path string, live, desired []apiv1.Container) []apistructs.ServiceGroupPlanFieldDiff {
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	liveContainers := make(map[string]apiv1.Container, len(live))
+	for _, c := range live {
+		liveContainers[c.Name] = c
+	}
+	desiredNames := make(map[string]struct{}, len(desired))
+	for _, d := range desired {
+		desiredNames[d.Name] = struct{}{}
+		p := fmt.Sprintf("%s[%s]", path, d.Name)
+		l, ok := liveContainers[d.Name]
+		if !ok {
+			diffs = appendDiff(diffs, p, "", d.Image)
+			continue
+		}
+		diffs = appendDiff(diffs, p+".image", l.Image, d.Image)
+		diffs = appendDiff(diffs, p+".command", strings.Join(l.Command, " "), strings.Join(d.Command, " "))
+		diffs = append(diffs, diffStringMap(p+".env", envMap(l.Env), envMap(d.Env))...)
+		diffs = append(diffs, diffResourceList(p+".resources.requests", l.Resources.Requests, d.Resources.Requests)...)
+		diffs = append(diffs, diffResourceList(p+".resources.limits", l.Resources.Limits, d.Resources.Limits)...)
+		diffs = appendDiff(diffs, p+".ports", containerPortsString(l.Ports), containerPortsString(d.Ports))
+		diffs = appendDiff(diffs, p+".volumeMounts", volumeMountsString(l.VolumeMounts), volumeMountsString(d.VolumeMounts))
+		diffs = appendDiff(diffs, p+".livenessProbe", probeString(l.LivenessProbe), probeString(d.LivenessProbe))
+		diffs = appendDiff(diffs, p+".readinessProbe", probeString(l.ReadinessProbe), probeString(d.ReadinessProbe))
+	}
+	for _, l := range live {
+		if _, ok := desiredNames[l.Name]; !ok {
+			diffs = appendDiff(diffs, fmt.Sprintf("%s[%s]", path, l.Name), l.Image, "")
+		}
+	}
+	return diffs
+}
+
+func diffStringMap(path string, live, desired map[string]string) []apistructs.ServiceGroupPlanFieldDiff {
+	keys := make(map[string]struct{}, len(live)+len(desired))
+	for key := range live {
+		keys[key] = struct{}{}
+	}
+	for key := range desired {
+		keys[key] = struct{}{}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	for _, key := range sortedKeys {
+		diffs = appendDiff(diffs, path+"."+key, live[key], desired[key])
+	}
+	return diffs
+}
+
+func diffResourceList(path string, live, desired apiv1.ResourceList) []apistructs.ServiceGroupPlanFieldDiff {
+	var diffs []apistructs.ServiceGroupPlanFieldDiff
+	for _, name := range []apiv1.ResourceName{apiv1.ResourceCPU, apiv1.ResourceMemory} {
+		l, lok := live[name]
+		d, dok := desired[name]
+		// quantities are normalized by apiserver, e.g. 1000m => 1
+		if lok == dok && (!lok || l.Cmp(d) == 0) {
+			continue
+		}
+		diffs = appendDiff(diffs, path+"."+string(name), quantityString(l, lok), quantityString(d, dok))
+	}
+	return diffs
+}
+
+func appendDiff(diffs []apistructs.ServiceGroupPlanFieldDiff, path, old, new string) []apistructs.ServiceGroupPlanFieldDiff {
+	if old == new {
+		return diffs
+	}
+	return append(diffs, apistructs.ServiceGroupPlanFieldDiff{Path: path, Old: old, New: new})
+}
+
+// envMap env values may contain secrets, only their digests are exposed in plan
+func envMap(envs []apiv1.EnvVar) map[string]string {
+	m := make(map[string]string, len(envs))
+	for _, env := range envs {
+		if env.ValueFrom != nil {
+			m[env.Name] = fmt.Sprintf("valueFrom(%s)", env.ValueFrom.String())
+			continue
+		}
+		m[env.Name] = envValueDigest(env.Value)
+	}
+	return m
+}
+
+func envValueDigest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+func quantityString(q resource.Quantity, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return q.String()
+}
+
+func volumesString(volumes []apiv1.Volume) string {
+	names := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		names = append(names, v.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func volumeMountsString(mounts []apiv1.VolumeMount) string {
+	strs := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		strs = append(strs, fmt.Sprintf("%s:%s:%t", m.Name, m.MountPath, m.ReadOnly))
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ",")
+}
+
+// containerPortsString protocol is defaulted to TCP by apiserver
+func containerPortsString(ports []apiv1.ContainerPort) string {
+	strs := make([]string, 0, len(ports))
+	for _, p := range ports {
+		strs = append(strs, fmt.Sprintf("%s/%d/%s", p.Name, p.ContainerPort,
+			defaultString(string(p.Protocol), string(apiv1.ProtocolTCP))))
+	}
+	return strings.Join(strs, ",")
+}
+
+func imagePullSecretsString(secrets []apiv1.LocalObjectReference) string {
+	names := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// jsonString nil and empty values are treated as the same, e.g. securityContext is defaulted to {} by apiserver
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	switch s := string(b); s {
+	case "null", "{}", "[]":
+		return ""
+	default:
+		return s
+	}
+}
+
+func servicePortsString(ports []apiv1.ServicePort) string {
+	strs := make([]string, 0, len(ports))
+	for _, p := range ports {
+		strs = append(strs, fmt.Sprintf("%s/%d/%s/%s", p.Name, p.Port, p.TargetPort.String(),
+			defaultString(string(p.Protocol), string(apiv1.ProtocolTCP))))
+	}
+	return strings.Join(strs, ",")
+}
+
+// probeString only includes the fields set by SetHealthCheck
+func probeString(probe *apiv1.Probe) string {
+	if probe == nil {
+		return ""
+	}
+	var handler string
+	switch {
+	case probe.Exec != nil:
+		handler = "exec " + strings.Join(probe.Exec.Command, " ")
+	case probe.HTTPGet != nil:
+		handler = fmt.Sprintf("http %s %s %s", defaultString(string(probe.HTTPGet.Scheme), string(apiv1.URISchemeHTTP)),
+			probe.HTTPGet.Port.String(), probe.HTTPGet.Path)
+	case probe.TCPSocket != nil:
+		handler = "tcp " + probe.TCPSocket.Port.String()
+	}
+	return fmt.Sprintf("%s delay=%d timeout=%d period=%d failure=%d", handler,
+		probe.InitialDelaySeconds, probe.TimeoutSeconds, probe.PeriodSeconds, probe.FailureThreshold)
+}
+
+func int32PtrString(i *int32) string {
+	if i == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *i)
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
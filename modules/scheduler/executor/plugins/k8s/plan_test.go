@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+func newPlanTestDeployment(replicas int32, cpu string, envs ...apiv1.EnvVar) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{
+						Name:  "web",
+						Image: "web:v1",
+						Env:   envs,
+						Resources: apiv1.ResourceRequirements{
+							Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse(cpu)},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestDiffDeployment(t *testing.T) {
+	live := newPlanTestDeployment(1, "1", apiv1.EnvVar{Name: "A", Value: "1"})
+	live.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+
+	// scaling and normalized quantities do not restart pods
+	diffs := diffDeployment(live, newPlanTestDeployment(2, "1000m", apiv1.EnvVar{Name: "A", Value: "1"}))
+	assert.Equal(t, []apistructs.ServiceGroupPlanFieldDiff{{Path: "spec.replicas", Old: "1", New: "2"}}, diffs)
+	assert.False(t, needRestart(diffs))
+
+	diffs = diffDeployment(live, newPlanTestDeployment(1, "1", apiv1.EnvVar{Name: "A", Value: "2"}))
+	assert.Equal(t, []apistructs.ServiceGroupPlanFieldDiff{
+		{Path: "spec.template.spec.containers[web].env.A", Old: envValueDigest("1"), New: envValueDigest("2")},
+	}, diffs)
+	assert.True(t, needRestart(diffs))
+
+	assert.Empty(t, diffDeployment(live, newPlanTestDeployment(1, "1", apiv1.EnvVar{Name: "A", Value: "1"})))
+}
+
+func TestDiffPodTemplateSpec(t *testing.T) {
+	desired := newPlanTestDeployment(1, "1")
+	desired.Spec.Template.Spec.Containers[0].Ports = []apiv1.ContainerPort{{ContainerPort: 8080}}
+	desired.Spec.Template.Spec.NodeSelector = map[string]string{"dice/stateless": "true"}
+	desired.Spec.Template.Spec.Tolerations = []apiv1.Toleration{{Key: "dice/platform", Operator: apiv1.TolerationOpExists}}
+	desired.Spec.Template.Spec.ImagePullSecrets = []apiv1.LocalObjectReference{{Name: "aliyun-registry"}}
+
+	// fields defaulted by apiserver are not diffs
+	live := desired.DeepCopy()
+	live.Spec.Template.Spec.Containers[0].Ports[0].Protocol = apiv1.ProtocolTCP
+	live.Spec.Template.Spec.SecurityContext = &apiv1.PodSecurityContext{}
+	assert.Empty(t, diffDeployment(live, desired))
+
+	changed := desired.DeepCopy()
+	changed.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort = 8081
+	changed.Spec.Template.Spec.NodeSelector["dice/stateless"] = "false"
+	changed.Spec.Template.Spec.Affinity = &apiv1.Affinity{NodeAffinity: &apiv1.NodeAffinity{}}
+	changed.Spec.Template.Spec.Tolerations = nil
+	changed.Spec.Template.Spec.ImagePullSecrets = append(changed.Spec.Template.Spec.ImagePullSecrets, apiv1.LocalObjectReference{Name: "harbor"})
+	runAsUser := int64(1000)
+	changed.Spec.Template.Spec.SecurityContext = &apiv1.PodSecurityContext{RunAsUser: &runAsUser}
+	changed.Spec.Template.Spec.ServiceAccountName = "web"
+
+	diffs := diffDeployment(live, changed)
+	paths := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		paths = append(paths, diff.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.template.spec.containers[web].ports",
+		"spec.template.spec.nodeSelector.dice/stateless",
+		"spec.template.spec.affinity",
+		"spec.template.spec.tolerations",
+		"spec.template.spec.imagePullSecrets",
+		"spec.template.spec.securityContext",
+		"spec.template.spec.serviceAccountName",
+	}, paths)
+	assert.Equal(t, apistructs.ServiceGroupPlanFieldDiff{
+		Path: "spec.template.spec.containers[web].ports", Old: "/8080/TCP", New: "/8081/TCP",
+	}, diffs[0])
+	assert.True(t, needRestart(diffs))
+}
+
+func TestPlanK8sService(t *testing.T) {
+	svc := &apistructs.Service{Name: "web", Namespace: "ns", Ports: []diceyml.ServicePort{{Port: 8080, Protocol: "TCP"}}}
+	desired := newService(svc)
+
+	item, changed := planK8sService(svc, desired, nil)
+	assert.True(t, changed)
+	assert.Equal(t, apistructs.ServiceGroupPlanCreate, item.Action)
+
+	live := desired.DeepCopy()
+	live.Spec.ClusterIP = "10.0.0.1"
+	live.Spec.Ports[0].Protocol = apiv1.ProtocolTCP
+	_, changed = planK8sService(svc, desired, live)
+	assert.False(t, changed)
+
+	item, changed = planK8sService(&apistructs.Service{Name: "web", Namespace: "ns"}, nil, live)
+	assert.True(t, changed)
+	assert.Equal(t, apistructs.ServiceGroupPlanDelete, item.Action)
+	assert.False(t, item.Restart)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// ProjectQuotaName name of the ResourceQuota and LimitRange reconciled from project quota
+	ProjectQuotaName = "project-quota"
+
+	// LIMITRANGE_DEFAULT_* default container limits and requests of LimitRange, e.g.
+	// EXECUTOR_K8S_K8SFORSERVICE_LIMITRANGE_DEFAULT_CPU = 1
+	// EXECUTOR_K8S_K8SFORSERVICE_DEV_LIMITRANGE_DEFAULT_MEM = 512Mi
+	LIMITRANGE_DEFAULT_CPU         = "LIMITRANGE_DEFAULT_CPU"
+	LIMITRANGE_DEFAULT_MEM         = "LIMITRANGE_DEFAULT_MEM"
+	LIMITRANGE_DEFAULT_REQUEST_CPU = "LIMITRANGE_DEFAULT_REQUEST_CPU"
+	LIMITRANGE_DEFAULT_REQUEST_MEM = "LIMITRANGE_DEFAULT_REQUEST_MEM"
+)
+
+var defaultLimitRangeValues = map[string]string{
+	LIMITRANGE_DEFAULT_CPU:         "1",
+	LIMITRANGE_DEFAULT_MEM:         "1Gi",
+	LIMITRANGE_DEFAULT_REQUEST_CPU: "100m",
+	LIMITRANGE_DEFAULT_REQUEST_MEM: "128Mi",
+}
+
+// reconcileNamespaceQuota Sync the ResourceQuota and LimitRange of namespace with sg.NamespaceQuota
+// LimitRange is always applied before ResourceQuota, as pods without limits would be rejected by a limits.* quota
+func (k *Kubernetes) reconcileNamespaceQuota(ns string, sg *apistructs.ServiceGroup) error {
+	quota := sg.NamespaceQuota
+	if quota == nil {
+		return nil
+	}
+	if quota.CPU <= 0 && quota.Mem <= 0 {
+		return k.deleteNamespaceQuota(ns)
+	}
+	if err := k.applyLimitRange(k.newLimitRange(ns, strutil.ToUpper(sg.Labels["DICE_WORKSPACE"]))); err != nil {
+		return err
+	}
+	return k.applyResourceQuota(newResourceQuota(ns, quota))
+}
+
+func (k *Kubernetes) applyResourceQuota(desired *apiv1.ResourceQuota) error {
+	live, err := k.quota.Get(desired.Namespace, desired.Name)
+	if err == k8serror.ErrNotFound {
+		return k.quota.Create(desired)
+	}
+	if err != nil {
+		return err
+	}
+	if resourceListEqual(live.Spec.Hard, desired.Spec.Hard) {
+		return nil
+	}
+	desired.ResourceVersion = live.ResourceVersion
+	return k.quota.Put(desired)
+}
+
+func (k *Kubernetes) applyLimitRange(desired *apiv1.LimitRange) error {
+	live, err := k.limitRange.Get(desired.Namespace, desired.Name)
+	if err == k8serror.ErrNotFound {
+		return k.limitRange.Create(desired)
+	}
+	if err != nil {
+		return err
+	}
+	if len(live.Spec.Limits) == 1 &&
+		resourceListEqual(live.Spec.Limits[0].Default, desired.Spec.Limits[0].Default) &&
+		resourceListEqual(live.Spec.Limits[0].DefaultRequest, desired.Spec.Limits[0].DefaultRequest) {
+		return nil
+	}
+	desired.ResourceVersion = live.ResourceVersion
+	return k.limitRange.Put(desired)
+}
+
+func (k *Kubernetes) deleteNamespaceQuota(ns string) error {
+	if err := k.quota.Delete(ns, ProjectQuotaName); err != nil && err != k8serror.ErrNotFound {
+		return err
+	}
+	if err := k.limitRange.Delete(ns, ProjectQuotaName); err != nil && err != k8serror.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func newResourceQuota(ns string, quota *apistructs.NamespaceQuota) *apiv1.ResourceQuota {
+	hard := apiv1.ResourceList{}
+	if quota.CPU > 0 {
+		hard[apiv1.ResourceLimitsCPU] = resource.MustParse(fmt.Sprintf("%.fm", quota.CPU*1000))
+	}
+	if quota.Mem > 0 {
+		hard[apiv1.ResourceLimitsMemory] = resource.MustParse(fmt.Sprintf("%.fMi", quota.Mem))
+	}
+	return &apiv1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ResourceQuota",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ProjectQuotaName,
+			Namespace: ns,
+		},
+		Spec: apiv1.ResourceQuotaSpec{Hard: hard},
+	}
+}
+
+func (k *Kubernetes) newLimitRange(ns, workspace string) *apiv1.LimitRange {
+	return &apiv1.LimitRange{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "LimitRange",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ProjectQuotaName,
+			Namespace: ns,
+		},
+		Spec: apiv1.LimitRangeSpec{
+			Limits: []apiv1.LimitRangeItem{{
+				Type: apiv1.LimitTypeContainer,
+				Default: apiv1.ResourceList{
+					apiv1.ResourceCPU:    k.limitRangeValue(workspace, LIMITRANGE_DEFAULT_CPU),
+					apiv1.ResourceMemory: k.limitRangeValue(workspace, LIMITRANGE_DEFAULT_MEM),
+				},
+				DefaultRequest: apiv1.ResourceList{
+					apiv1.ResourceCPU:    k.limitRangeValue(workspace, LIMITRANGE_DEFAULT_REQUEST_CPU),
+					apiv1.ResourceMemory: k.limitRangeValue(workspace, LIMITRANGE_DEFAULT_REQUEST_MEM),
+				},
+			}},
+		},
+	}
+}
+
+// limitRangeValue Same as subscribe ratio, the key of non-PROD workspace is prefixed with workspace
+func (k *Kubernetes) limitRangeValue(workspace, key string) resource.Quantity {
+	optionKey := key
+	if workspace != "" && workspace != "PROD" {
+		optionKey = workspace + "_" + key
+	}
+	if v, ok := k.options[optionKey]; ok && len(v) > 0 {
+		q, err := resource.ParseQuantity(v)
+		if err == nil {
+			return q
+		}
+		logrus.Errorf("invalid executor option %s: %s, (%v)", optionKey, v, err)
+	}
+	return resource.MustParse(defaultLimitRangeValues[key])
+}
+
+// namespaceResourceInfo Usage versus quota of namespaces with project quota
+func (k *Kubernetes) namespaceResourceInfo() (map[string]*apistructs.NamespaceResourceInfo, error) {
+	quotas, err := k.quota.ListAllNamespace([]string{"metadata.name=" + ProjectQuotaName})
+	if err != nil {
+		return nil, err
+	}
+	r := make(map[string]*apistructs.NamespaceResourceInfo, len(quotas.Items))
+	for _, quota := range quotas.Items {
+		info := &apistructs.NamespaceResourceInfo{}
+		if q, ok := quota.Status.Hard[apiv1.ResourceLimitsCPU]; ok {
+			info.CPUQuota = float64(q.MilliValue()) / 1000
+		}
+		if q, ok := quota.Status.Used[apiv1.ResourceLimitsCPU]; ok {
+			info.CPUUsed = float64(q.MilliValue()) / 1000
+		}
+		if q, ok := quota.Status.Hard[apiv1.ResourceLimitsMemory]; ok {
+			info.MemQuota = q.Value()
+		}
+		if q, ok := quota.Status.Used[apiv1.ResourceLimitsMemory]; ok {
+			info.MemUsed = q.Value()
+		}
+		r[quota.Namespace] = info
+	}
+	return r, nil
+}
+
+func resourceListEqual(left, right apiv1.ResourceList) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for name, l := range left {
+		r, ok := right[name]
+		if !ok || l.Cmp(r) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isQuotaExceeded e.g. pods "web-xxx" is forbidden: exceeded quota: project-quota, requested: limits.cpu=2, used: limits.cpu=9, limited: limits.cpu=10
+func isQuotaExceeded(message string) bool {
+	return strings.Contains(message, "exceeded quota")
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestNewResourceQuota(t *testing.T) {
+	quota := newResourceQuota("project-1-prod", &apistructs.NamespaceQuota{CPU: 2.5, Mem: 4096})
+	assert.Equal(t, ProjectQuotaName, quota.Name)
+	assert.True(t, resourceListEqual(apiv1.ResourceList{
+		apiv1.ResourceLimitsCPU:    resource.MustParse("2500m"),
+		apiv1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+	}, quota.Spec.Hard))
+
+	quota = newResourceQuota("project-1-prod", &apistructs.NamespaceQuota{CPU: 2})
+	_, ok := quota.Spec.Hard[apiv1.ResourceLimitsMemory]
+	assert.False(t, ok)
+}
+
+func TestNewLimitRange(t *testing.T) {
+	k := &Kubernetes{options: map[string]string{
+		"DEV_" + LIMITRANGE_DEFAULT_MEM: "512Mi",
+		LIMITRANGE_DEFAULT_CPU:          "invalid",
+	}}
+	limits := k.newLimitRange("project-1-dev", "DEV").Spec.Limits[0]
+	assert.Equal(t, apiv1.LimitTypeContainer, limits.Type)
+	assert.Equal(t, "512Mi", limits.Default.Memory().String())
+	assert.Equal(t, "1", limits.Default.Cpu().String())
+
+	// key of PROD has no workspace prefix
+	limits = k.newLimitRange("project-1-prod", "PROD").Spec.Limits[0]
+	assert.Equal(t, "1Gi", limits.Default.Memory().String())
+	assert.Equal(t, "100m", limits.DefaultRequest.Cpu().String())
+}
+
+func TestIsQuotaExceeded(t *testing.T) {
+	assert.True(t, isQuotaExceeded(`pods "web-7d9f" is forbidden: exceeded quota: project-quota, requested: limits.cpu=2, used: limits.cpu=9, limited: limits.cpu=10`))
+	assert.False(t, isQuotaExceeded("0/3 nodes are available: 3 Insufficient cpu."))
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package resourcequota manipulates the k8s api of resourcequota object
+package resourcequota
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8sapi"
+	"github.com/erda-project/erda/modules/scheduler/executor/plugins/k8s/k8serror"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// ResourceQuota is the object to encapsulate the k8s resourcequota api
+type ResourceQuota struct {
+	addr   string
+	client *httpclient.HTTPClient
+}
+
+// Option configures a ResourceQuota
+type Option func(*ResourceQuota)
+
+// New news a ResourceQuota
+func New(options ...Option) *ResourceQuota {
+	rq := &ResourceQuota{}
+
+	for _, op := range options {
+		op(rq)
+	}
+
+	return rq
+}
+
+// WithCompleteParams provides an Option
+func WithCompleteParams(addr string, client *httpclient.HTTPClient) Option {
+	return func(rq *ResourceQuota) {
+		rq.addr = addr
+		rq.client = client
+	}
+}
+
+// Get gets a k8s resourcequota
+func (rq *ResourceQuota) Get(namespace, name string) (*apiv1.ResourceQuota, error) {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", namespace, "/resourcequotas/", name)
+
+	resp, err := rq.client.Get(rq.addr).
+		Path(path).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return nil, errors.Errorf("failed to get resourcequota, name: %s, (%v)", name, err)
+	}
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return nil, k8serror.ErrNotFound
+		}
+		return nil, errors.Errorf("failed to get resourcequota, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	quota := &apiv1.ResourceQuota{}
+	if err := json.NewDecoder(&b).Decode(quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// ListAllNamespace lists resourcequotas of all namespaces, filtered by fieldSelectors
+func (rq *ResourceQuota) ListAllNamespace(fieldSelectors []string) (*apiv1.ResourceQuotaList, error) {
+	var b bytes.Buffer
+	resp, err := rq.client.Get(rq.addr).
+		Path("/api/v1/resourcequotas").
+		Param("fieldSelector", strutil.Join(fieldSelectors, ",")).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return nil, errors.Errorf("failed to list resourcequotas, (%v)", err)
+	}
+	if !resp.IsOK() {
+		return nil, errors.Errorf("failed to list resourcequotas, statuscode: %v, body: %v",
+			resp.StatusCode(), b.String())
+	}
+	quotas := &apiv1.ResourceQuotaList{}
+	if err := json.NewDecoder(&b).Decode(quotas); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// Create creates a k8s resourcequota
+func (rq *ResourceQuota) Create(quota *apiv1.ResourceQuota) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", quota.Namespace, "/resourcequotas")
+
+	resp, err := rq.client.Post(rq.addr).
+		Path(path).
+		JSONBody(quota).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to create resourcequota, name: %s, (%v)", quota.Name, err)
+	}
+	if !resp.IsOK() {
+		return errors.Errorf("failed to create resourcequota, name: %s, statuscode: %v, body: %v",
+			quota.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Put updates a k8s resourcequota
+func (rq *ResourceQuota) Put(quota *apiv1.ResourceQuota) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", quota.Namespace, "/resourcequotas/", quota.Name)
+
+	resp, err := rq.client.Put(rq.addr).
+		Path(path).
+		JSONBody(quota).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to put resourcequota, name: %s, (%v)", quota.Name, err)
+	}
+	if !resp.IsOK() {
+		return errors.Errorf("failed to put resourcequota, name: %s, statuscode: %v, body: %v",
+			quota.Name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
+
+// Delete deletes a k8s resourcequota
+func (rq *ResourceQuota) Delete(namespace, name string) error {
+	var b bytes.Buffer
+	path := strutil.Concat("/api/v1/namespaces/", namespace, "/resourcequotas/", name)
+
+	resp, err := rq.client.Delete(rq.addr).
+		Path(path).
+		JSONBody(k8sapi.DeleteOptions).
+		Do().
+		Body(&b)
+
+	if err != nil {
+		return errors.Errorf("failed to delete resourcequota, name: %s, (%v)", name, err)
+	}
+	if !resp.IsOK() {
+		if resp.IsNotfound() {
+			return k8serror.ErrNotFound
+		}
+		return errors.Errorf("failed to delete resourcequota, name: %s, statuscode: %v, body: %v",
+			name, resp.StatusCode(), b.String())
+	}
+	return nil
+}
@@ -132,8 +132,10 @@ func (k *Kubernetes) CopyErdaSecrets(originns, dstns string) ([]apiv1.Secret, er
 			StringData: secret.StringData,
 			Type:       secret.Type,
 		}
-		if err := k.secret.CreateIfNotExist(dstsecret); err != nil {
-			return nil, err
+		if !k.dryRun {
+			if err := k.secret.CreateIfNotExist(dstsecret); err != nil {
+				return nil, err
+			}
 		}
 		result = append(result, secret)
 	}
@@ -88,6 +88,9 @@ func (k *Kubernetes) createStatefulSet(info StatefulsetInfo) error {
 		},
 	}
 	set.Spec.Template.Spec.Affinity = &affinity
+	if err := setSubsetPodLabels(service, set.Spec.Template.Labels); err != nil {
+		return err
+	}
 	// Currently only one business container is set in our Pod
 	container := &apiv1.Container{
 		Name:  statefulName,
@@ -0,0 +1,44 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package servicegroup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/scheduler/task"
+)
+
+// Plan dry-run of Update, return the k8s objects which would be created, updated or deleted
+func (s ServiceGroupImpl) Plan(req apistructs.ServiceGroupPlanRequest) (apistructs.ServiceGroupPlanData, error) {
+	sg, err := convertServiceGroupUpdateV2Request(apistructs.ServiceGroupUpdateV2Request(req), s.clusterinfo)
+	if err != nil {
+		return apistructs.ServiceGroupPlanData{}, err
+	}
+
+	oldSg := apistructs.ServiceGroup{}
+	if err := s.js.Get(context.Background(), mkServiceGroupKey(sg.Type, sg.ID), &oldSg); err != nil {
+		return apistructs.ServiceGroupPlanData{}, fmt.Errorf("Cannot get servicegroup(%s/%s) from etcd, err: %v", sg.Type, sg.ID, err)
+	}
+	// same as Update, but not saved to etcd
+	diffAndPatchRuntime(&sg, &oldSg)
+
+	oldSg.Labels = appendServiceTags(oldSg.Labels, oldSg.Executor)
+	t, err := s.handleServiceGroup(context.Background(), &oldSg, task.TaskPlan)
+	if err != nil {
+		return apistructs.ServiceGroupPlanData{}, err
+	}
+	return t.Extra.(apistructs.ServiceGroupPlanData), nil
+}
@@ -53,6 +53,7 @@ type ServiceGroup interface {
 	Delete(namespace string, name, force string) error
 	Info(ctx context.Context, namespace string, name string) (apistructs.ServiceGroup, error)
 	Precheck(sg apistructs.ServiceGroupPrecheckRequest) (apistructs.ServiceGroupPrecheckData, error)
+	Plan(sg apistructs.ServiceGroupPlanRequest) (apistructs.ServiceGroupPlanData, error)
 	ConfigUpdate(sg apistructs.ServiceGroup) error
 	KillPod(ctx context.Context, namespace string, name string, podname string) error
 }
@@ -194,6 +195,7 @@ func convertServiceGroup(req apistructs.ServiceGroupCreateV2Request, clusterinfo
 	sg.ID = req.ID
 	sg.Type = req.Type
 	sg.ProjectNamespace = req.ProjectNamespace
+	sg.NamespaceQuota = req.NamespaceQuota
 	sg.Labels = req.GroupLabels
 	sg.ServiceDiscoveryMode = req.ServiceDiscoveryMode
 
@@ -270,20 +272,21 @@ func convertServiceGroup(req apistructs.ServiceGroupCreateV2Request, clusterinfo
 				Mem:  float64(service.Resources.Mem),
 				Disk: float64(service.Resources.Disk),
 			},
-			Depends:          service.DependsOn,
-			Env:              service.Envs,
-			Labels:           service.Labels,
-			Selectors:        service.Deployments.Selectors,
-			WorkLoad:         service.Deployments.Workload,
-			DeploymentLabels: service.Deployments.Labels,
-			Binds:            binds,
-			Volumes:          volumes,
-			Hosts:            service.Hosts,
-			NewHealthCheck:   convertHealthcheck(service.HealthCheck),
-			SideCars:         service.SideCars,
-			InitContainer:    service.Init,
-			MeshEnable:       service.MeshEnable,
-			TrafficSecurity:  service.TrafficSecurity,
+			Depends:           service.DependsOn,
+			Env:               service.Envs,
+			Labels:            service.Labels,
+			Selectors:         service.Deployments.Selectors,
+			WorkLoad:          service.Deployments.Workload,
+			DeploymentLabels:  service.Deployments.Labels,
+			Binds:             binds,
+			Volumes:           volumes,
+			Hosts:             service.Hosts,
+			NewHealthCheck:    convertHealthcheck(service.HealthCheck),
+			SideCars:          service.SideCars,
+			InitContainer:     service.Init,
+			MeshEnable:        service.MeshEnable,
+			TrafficSecurity:   service.TrafficSecurity,
+			TrafficManagement: service.TrafficManagement,
 		}
 		sgServices = append(sgServices, sgService)
 	}
@@ -56,6 +56,7 @@ func diffAndPatchRuntime(newsg *apistructs.ServiceGroup, oldsg *apistructs.Servi
 
 	oldsg.Labels = newsg.Labels
 	oldsg.ServiceDiscoveryKind = newsg.ServiceDiscoveryKind
+	oldsg.NamespaceQuota = newsg.NamespaceQuota
 
 	// TODO: refactor it, separate data and status into different etcd key
 	// Full update
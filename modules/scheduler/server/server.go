@@ -225,6 +225,7 @@ func (s *Server) initEndpoints() {
 		{"/api/servicegroup/actions/restart", http.MethodPost, s.httpendpoints.ServiceGroupRestart},
 		{"/api/servicegroup/actions/cancel", http.MethodPost, s.httpendpoints.ServiceGroupCancel},
 		{"/api/servicegroup/actions/precheck", http.MethodPost, s.httpendpoints.ServiceGroupPrecheck},
+		{"/api/servicegroup/actions/plan", http.MethodPost, s.httpendpoints.ServiceGroupPlan},
 		{"/api/servicegroup/actions/config", http.MethodPut, s.httpendpoints.ServiceGroupConfigUpdate},
 		{"/api/servicegroup/actions/killpod", http.MethodPost, s.httpendpoints.ServiceGroupKillPod},
 
@@ -38,6 +38,7 @@ const (
 	TaskPrecheck
 	TaskJobVolumeCreate
 	TaskKillPod
+	TaskPlan
 )
 
 var (
@@ -218,6 +219,18 @@ func (t *Task) Run(ctx context.Context) TaskResponse {
 		return TaskResponse{
 			err: err,
 		}
+	case TaskPlan:
+		planExecutor, ok := executor.(executortypes.PlanExecutor)
+		if !ok {
+			return TaskResponse{
+				err: errors.Errorf("executor %s not support plan", executor.Name()),
+			}
+		}
+		r, err := planExecutor.Plan(ctx, t.Spec)
+		return TaskResponse{
+			err:   err,
+			Extra: r,
+		}
 	default:
 		return TaskResponse{
 			err: errors.Errorf("invlaid action: %d", t.Action),
@@ -278,6 +291,8 @@ func (a *Action) String() string {
 		return "TaskJobVolumeCreate"
 	case TaskKillPod:
 		return "TaskKillPod"
+	case TaskPlan:
+		return "TaskPlan"
 	}
 	panic("unreachable")
 }
@@ -46,6 +46,7 @@ func Initialize() error {
 
 	registryRouter := router.PathPrefix("/registry").Subrouter()
 	registryRouter.Methods("POST").PathPrefix("/remove/manifests").HandlerFunc(registry.RemoveManifests)
+	registryRouter.Methods("POST").PathPrefix("/manifests/size").HandlerFunc(registry.ManifestsSize)
 	//registryRouter.Methods("POST").PathPrefix("/remove/layers").HandlerFunc(registry.RemoveLayers)
 	registryRouter.Methods("GET").PathPrefix("/readonly").HandlerFunc(registry.Readonly)
 
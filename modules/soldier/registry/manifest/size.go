@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package manifest 读取 registry 中的镜像 manifest
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/httpclient"
+)
+
+// manifestV2 镜像 manifest 中计算大小需要的字段
+type manifestV2 struct {
+	Config struct {
+		Size int64 `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+}
+
+// Size 返回镜像 config 与所有 layer 大小之和
+func Size(registryURL, image string) (int64, error) {
+	var name, tag string
+	if i := strings.IndexByte(image, '/'); i != -1 {
+		name = image[i+1:]
+		if i := strings.LastIndexByte(name, ':'); i != -1 {
+			name, tag = name[:i], name[i+1:]
+		}
+	}
+	if name == "" {
+		return 0, fmt.Errorf("image name is empty")
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	var buf bytes.Buffer
+	res, err := httpclient.New().Get(registryURL).Path(fmt.Sprintf("/v2/%s/manifests/%s", name, tag)).
+		Header("Accept", "application/vnd.docker.distribution.manifest.v2+json").
+		Do().Body(&buf)
+	if err != nil {
+		return 0, fmt.Errorf("get manifests failed: %v", err)
+	}
+	if sc := res.StatusCode(); sc != http.StatusOK {
+		return 0, fmt.Errorf("get manifests failed: status code is %s", strconv.Itoa(sc))
+	}
+	var m manifestV2
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return 0, fmt.Errorf("invalid manifests: %v", err)
+	}
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// Sizes 查询镜像大小，layer 在镜像之间共享时会重复计算，结果用于 release 回收预演的估算
+func Sizes(registryURL string, images []string) apistructs.RegistryManifestsSizeResponseData {
+	res := apistructs.RegistryManifestsSizeResponseData{Sizes: make(map[string]int64)}
+	for _, image := range images {
+		if _, ok := res.Sizes[image]; ok {
+			continue
+		}
+		size, err := Size(registryURL, image)
+		if err != nil {
+			if res.Failed == nil {
+				res.Failed = make(map[string]string)
+			}
+			logrus.Warningf("%s: %v\n", image, err)
+			res.Failed[image] = err.Error()
+			continue
+		}
+		res.Sizes[image] = size
+	}
+	return res
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package manifest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizes(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/app/web/manifests/v1":
+			assert.Equal(t, "application/vnd.docker.distribution.manifest.v2+json", r.Header.Get("Accept"))
+			w.Write([]byte(`{"config":{"size":100},"layers":[{"size":1000},{"size":2000}]}`))
+		case "/v2/app/web/manifests/latest":
+			w.Write([]byte(`{"config":{"size":1},"layers":[{"size":2}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	res := Sizes(registry.URL, []string{
+		"registry.local/app/web:v1",
+		"registry.local/app/web:v1",
+		"registry.local/app/web",
+		"registry.local/app/api:v2",
+		"web",
+	})
+	assert.Equal(t, map[string]int64{"registry.local/app/web:v1": 3100, "registry.local/app/web": 3}, res.Sizes)
+	assert.Contains(t, res.Failed["registry.local/app/api:v2"], "404")
+	assert.Equal(t, "image name is empty", res.Failed["web"])
+}
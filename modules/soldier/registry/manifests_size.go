@@ -0,0 +1,41 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pkg/colonyutil"
+	"github.com/erda-project/erda/modules/soldier/registry/manifest"
+)
+
+// ManifestsSize 查询镜像大小，用于 release 回收预演
+func ManifestsSize(w http.ResponseWriter, r *http.Request) {
+	var req apistructs.RegistryManifestsSizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		colonyutil.WriteErr(w, "400", err.Error())
+		return
+	}
+	if req.RegistryURL == "" {
+		req.RegistryURL = os.Getenv("REGISTRY_ADDR")
+	}
+	if req.RegistryURL == "" {
+		colonyutil.WriteErr(w, "400", "no registry url")
+		return
+	}
+	colonyutil.WriteData(w, manifest.Sizes(req.RegistryURL, req.Images))
+}
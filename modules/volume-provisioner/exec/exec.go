@@ -40,7 +40,11 @@ func NewCmdExecutor(config *restclient.Config, client kubernetes.Interface, name
 // OnLocal execute 'cmd' on the specified node
 func (c *CmdExecutor) OnLocal(cmd string) error {
 	cc := exec.Command("/bin/sh", "-c", cmd)
-	if _, err := cc.Output(); err != nil {
+	out, err := cc.CombinedOutput()
+	if len(out) > 0 {
+		logrus.Infof("Result output: %s", out)
+	}
+	if err != nil {
 		return err
 	}
 
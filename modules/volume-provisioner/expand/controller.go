@@ -0,0 +1,243 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package expand handles PVC expansion of volumes provisioned by volume-provisioner.
+// Volumes are directories on the host, so expansion only changes their capacity limit,
+// no filesystem resize is needed and PVC is resized online.
+package expand
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Expander resize volumes of a provisioner
+type Expander interface {
+	// Owns whether the volume should be resized by this provisioner instance
+	Owns(pv *v1.PersistentVolume) bool
+	// Expand enlarge the capacity limit of the volume
+	Expand(ctx context.Context, pv *v1.PersistentVolume, capacity resource.Quantity) error
+}
+
+type Controller struct {
+	client    kubernetes.Interface
+	expanders map[string]Expander // key: provisioner name
+
+	factory   informers.SharedInformerFactory
+	pvcLister corelisters.PersistentVolumeClaimLister
+	scLister  storagelisters.StorageClassLister
+	queue     workqueue.RateLimitingInterface
+}
+
+func NewController(client kubernetes.Interface, resync time.Duration) *Controller {
+	factory := informers.NewSharedInformerFactory(client, resync)
+	c := &Controller{
+		client:    client,
+		expanders: make(map[string]Expander),
+		factory:   factory,
+		pvcLister: factory.Core().V1().PersistentVolumeClaims().Lister(),
+		scLister:  factory.Storage().V1().StorageClasses().Lister(),
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "volume-expand"),
+	}
+	factory.Core().V1().PersistentVolumeClaims().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	return c
+}
+
+// Register resize volumes provisioned by 'provisionerName' with 'expander'
+func (c *Controller) Register(provisionerName string, expander Expander) {
+	c.expanders[provisionerName] = expander
+}
+
+// Run start workers and block until ctx is done
+func (c *Controller) Run(ctx context.Context, workers int) {
+	defer c.queue.ShutDown()
+
+	c.factory.Start(ctx.Done())
+	for typ, synced := range c.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logrus.Errorf("Failed to sync informer cache of %v", typ)
+			return
+		}
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c.processNextItem(ctx) {
+			}
+		}()
+	}
+	<-ctx.Done()
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok || pvc.Spec.VolumeName == "" {
+		return
+	}
+	// only bound pvc whose request is larger than its capacity need to be resized
+	if _, ok := expandSize(pvc.Spec.Resources.Requests, pvc.Status.Capacity); !ok {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *Controller) processNextItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(ctx, key.(string)); err != nil {
+		logrus.Errorf("Failed to resize volume of pvc %s: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pvc, err := c.pvcLister.PersistentVolumeClaims(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	requested, ok := expandSize(pvc.Spec.Resources.Requests, pvc.Status.Capacity)
+	if !ok {
+		return nil
+	}
+	expander := c.expanderOf(pvc)
+	if expander == nil {
+		return nil
+	}
+	pv, err := c.client.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if !expander.Owns(pv) {
+		return nil
+	}
+
+	if _, ok := expandSize(pvc.Spec.Resources.Requests, pv.Spec.Capacity); ok {
+		logrus.Infof("Start resizing volume %s of pvc %s to %s", pv.Name, key, requested.String())
+		if err := expander.Expand(ctx, pv, requested); err != nil {
+			return err
+		}
+		if err := c.updatePVCapacity(ctx, pv.Name, requested); err != nil {
+			return err
+		}
+	}
+	return c.updatePVCCapacity(ctx, namespace, name, requested)
+}
+
+func (c *Controller) expanderOf(pvc *v1.PersistentVolumeClaim) Expander {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil
+	}
+	sc, err := c.scLister.Get(*pvc.Spec.StorageClassName)
+	if err != nil {
+		return nil
+	}
+	return c.expanders[sc.Provisioner]
+}
+
+func (c *Controller) updatePVCapacity(ctx context.Context, pvName string, capacity resource.Quantity) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pv, err := c.client.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := expandSize(v1.ResourceList{v1.ResourceStorage: capacity}, pv.Spec.Capacity); !ok {
+			return nil
+		}
+		if pv.Spec.Capacity == nil {
+			pv.Spec.Capacity = v1.ResourceList{}
+		}
+		pv.Spec.Capacity[v1.ResourceStorage] = capacity
+		_, err = c.client.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (c *Controller) updatePVCCapacity(ctx context.Context, namespace, name string, capacity resource.Quantity) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pvc, err := c.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := expandSize(v1.ResourceList{v1.ResourceStorage: capacity}, pvc.Status.Capacity); !ok {
+			return nil
+		}
+		if pvc.Status.Capacity == nil {
+			pvc.Status.Capacity = v1.ResourceList{}
+		}
+		pvc.Status.Capacity[v1.ResourceStorage] = capacity
+		pvc.Status.Conditions = removeResizeConditions(pvc.Status.Conditions)
+		if _, err = c.client.CoreV1().PersistentVolumeClaims(namespace).UpdateStatus(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		logrus.Infof("Resized volume of pvc %s/%s to %s", namespace, name, capacity.String())
+		return nil
+	})
+}
+
+// expandSize return requested storage if it's larger than current capacity
+func expandSize(requests, capacity v1.ResourceList) (resource.Quantity, bool) {
+	requested, ok := requests[v1.ResourceStorage]
+	if !ok {
+		return resource.Quantity{}, false
+	}
+	current, ok := capacity[v1.ResourceStorage]
+	if !ok {
+		// not bound yet
+		return resource.Quantity{}, false
+	}
+	return requested, requested.Cmp(current) > 0
+}
+
+func removeResizeConditions(conditions []v1.PersistentVolumeClaimCondition) []v1.PersistentVolumeClaimCondition {
+	var result []v1.PersistentVolumeClaimCondition
+	for _, cond := range conditions {
+		if cond.Type == v1.PersistentVolumeClaimResizing || cond.Type == v1.PersistentVolumeClaimFileSystemResizePending {
+			continue
+		}
+		result = append(result, cond)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package expand
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeExpander struct {
+	expanded map[string]resource.Quantity
+}
+
+func (e *fakeExpander) Owns(pv *v1.PersistentVolume) bool { return true }
+
+func (e *fakeExpander) Expand(ctx context.Context, pv *v1.PersistentVolume, capacity resource.Quantity) error {
+	e.expanded[pv.Name] = capacity
+	return nil
+}
+
+func storage(size string) v1.ResourceList {
+	return v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)}
+}
+
+func TestSync(t *testing.T) {
+	scName := "erda-local-volume"
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: scName}, Provisioner: "erda/local-volume"}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv1"},
+		Spec:       v1.PersistentVolumeSpec{Capacity: storage("1Gi")},
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "mysql"},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &scName,
+			VolumeName:       "pv1",
+			Resources:        v1.ResourceRequirements{Requests: storage("2Gi")},
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Capacity:   storage("1Gi"),
+			Conditions: []v1.PersistentVolumeClaimCondition{{Type: v1.PersistentVolumeClaimResizing}},
+		},
+	}
+	client := fake.NewSimpleClientset(sc, pv, pvc)
+
+	c := NewController(client, time.Minute)
+	expander := &fakeExpander{expanded: map[string]resource.Quantity{}}
+	c.Register("erda/local-volume", expander)
+	assert.NoError(t, c.factory.Storage().V1().StorageClasses().Informer().GetIndexer().Add(sc))
+	assert.NoError(t, c.factory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc))
+
+	ctx := context.Background()
+	assert.NoError(t, c.sync(ctx, "default/mysql"))
+	expanded := expander.expanded["pv1"]
+	assert.Equal(t, "2Gi", expanded.String())
+
+	pv, err := client.CoreV1().PersistentVolumes().Get(ctx, "pv1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2Gi", pv.Spec.Capacity.Storage().String())
+	pvc, err = client.CoreV1().PersistentVolumeClaims("default").Get(ctx, "mysql", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2Gi", pvc.Status.Capacity.Storage().String())
+	assert.Empty(t, pvc.Status.Conditions)
+
+	// volumes of other provisioners are ignored
+	delete(expander.expanded, "pv1")
+	c.expanders = map[string]Expander{}
+	assert.NoError(t, c.sync(ctx, "default/mysql"))
+	assert.Empty(t, expander.expanded)
+}
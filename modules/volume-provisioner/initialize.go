@@ -16,6 +16,7 @@ package volumeprovisioner
 
 import (
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/version"
@@ -23,11 +24,13 @@ import (
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/sig-storage-lib-external-provisioner/v6/controller"
 
+	"github.com/erda-project/erda/modules/volume-provisioner/expand"
 	"github.com/erda-project/erda/modules/volume-provisioner/localvolume"
 	"github.com/erda-project/erda/modules/volume-provisioner/netdatavolume"
+	"github.com/erda-project/erda/modules/volume-provisioner/usage"
 )
 
-func initLocalVolumeProvisioner(config *config, csConfig *rest.Config, client kubernetes.Interface, version *version.Info) {
+func initLocalVolumeProvisioner(config *config, csConfig *rest.Config, client kubernetes.Interface, version *version.Info) expand.Expander {
 	var pc *controller.ProvisionController
 
 	logrus.Infof("Creating localvolumeProvisioner...")
@@ -49,15 +52,17 @@ func initLocalVolumeProvisioner(config *config, csConfig *rest.Config, client ku
 	}
 
 	go pc.Run(context.Background())
+	return lvp
 }
 
-func initNetDataVolumeProvisioner(config *config, csConfig *rest.Config, client kubernetes.Interface, version *version.Info) {
+func initNetDataVolumeProvisioner(config *config, csConfig *rest.Config, client kubernetes.Interface, version *version.Info) expand.Expander {
 	logrus.Infof("Creating netdatavolumeProvisioner...")
 
 	nvp := netdatavolume.NewNetDataVolumeProvisioner(csConfig, client)
 	pc := controller.NewProvisionController(client, config.NetProvisionerName, nvp, version.GitVersion)
 
 	go pc.Run(context.Background())
+	return nvp
 }
 
 func initialize(config *config) error {
@@ -78,12 +83,21 @@ func initialize(config *config) error {
 		return err
 	}
 
+	// resize volumes when pvc is expanded
+	expandController := expand.NewController(cs, 10*time.Minute)
 	if config.ModeEdge {
 		logrus.Infof("Edge mode, create localvolumeProvisioner only")
-		initLocalVolumeProvisioner(config, csConfig, cs, serverVersion)
+		expandController.Register(config.LocalProvisionerName, initLocalVolumeProvisioner(config, csConfig, cs, serverVersion))
 	} else {
-		initLocalVolumeProvisioner(config, csConfig, cs, serverVersion)
-		initNetDataVolumeProvisioner(config, csConfig, cs, serverVersion)
+		expandController.Register(config.LocalProvisionerName, initLocalVolumeProvisioner(config, csConfig, cs, serverVersion))
+		expandController.Register(config.NetProvisionerName, initNetDataVolumeProvisioner(config, csConfig, cs, serverVersion))
+	}
+	go expandController.Run(context.Background(), 1)
+
+	if config.NodeName != "" && config.CollectorAddr != "" {
+		logrus.Infof("Reporting usage of local volumes on node %s to %s", config.NodeName, config.CollectorAddr)
+		reporter := usage.NewReporter(cs, config.NodeName, config.LocalProvisionerName, config.CollectorAddr, config.UsageReportInterval)
+		go reporter.Run(context.Background())
 	}
 
 	ch := make(chan struct{})
@@ -22,6 +22,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -44,15 +45,19 @@ func (p *localVolumeProvisioner) Delete(ctx context.Context, pv *v1.PersistentVo
 		if selectNodeName != p.lvpConfig.NodeName {
 			return nil
 		}
-		return p.cmdExecutor.OnLocal(fmt.Sprintf("rm -rf %s || true",
-			strutil.JoinPath("/hostfs", pv.Spec.PersistentVolumeSource.Local.Path)))
+		return p.cmdExecutor.OnLocal(deleteCmd(pv))
 	}
 
-	return p.cmdExecutor.OnNodesPods(fmt.Sprintf("rm -rf %s || true",
-		strutil.JoinPath("/hostfs", pv.Spec.PersistentVolumeSource.Local.Path)),
+	return p.cmdExecutor.OnNodesPods(deleteCmd(pv),
 		nodeListOption, metav1.ListOptions{LabelSelector: p.lvpConfig.MatchLabel})
 }
 
+// deleteCmd clear the capacity limit before removing the volume directory
+func deleteCmd(pv *v1.PersistentVolume) string {
+	volPath := strutil.JoinPath("/hostfs", pv.Spec.PersistentVolumeSource.Local.Path)
+	return fmt.Sprintf("(%s) || true\nrm -rf %s || true", quota.ClearLimitCmd(volPath, pv.Name), volPath)
+}
+
 func genListOptionFromNodeAffinity(affinity *v1.VolumeNodeAffinity) (metav1.ListOptions, error) {
 	for _, t := range affinity.Required.NodeSelectorTerms {
 		for _, expr := range t.MatchExpressions {
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/sig-storage-lib-external-provisioner/v6/controller"
 
 	"github.com/erda-project/erda/modules/volume-provisioner/exec"
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -74,18 +75,27 @@ func (p *localVolumeProvisioner) Provision(ctx context.Context, options controll
 		return nil, controller.ProvisioningFinished, err
 	}
 
+	quotaMode, err := quota.ParseMode(options.StorageClass.Parameters)
+	if err != nil {
+		return nil, controller.ProvisioningFinished, err
+	}
+	capacity := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	// create directory and limit its capacity to the requested size
+	cmd := fmt.Sprintf("mkdir -p %s || exit 1\n%s", volPath,
+		quota.SetLimitCmd(volPath, options.PVName, capacity.Value(), quotaMode))
+
 	if p.lvpConfig.ModeEdge {
 		if p.lvpConfig.NodeName != options.SelectedNode.Name {
 			err = fmt.Errorf("cant't match create request, want: %s, request: %s", p.lvpConfig.NodeName, options.SelectedNode.Name)
 			return nil, controller.ProvisioningFinished, err
 		}
-		if err = p.cmdExecutor.OnLocal(fmt.Sprintf("mkdir -p %s", volPath)); err != nil {
+		if err = p.cmdExecutor.OnLocal(cmd); err != nil {
 			logrus.Errorf("node %s mkdir %s error: %v", p.lvpConfig.NodeName, volPath, err)
 			return nil, controller.ProvisioningFinished, err
 		}
 	} else {
 		nodeSelector := fmt.Sprintf("kubernetes.io/hostname=%s", options.SelectedNode.Name)
-		if err := p.cmdExecutor.OnNodesPods(fmt.Sprintf("mkdir -p %s", volPath),
+		if err := p.cmdExecutor.OnNodesPods(cmd,
 			metav1.ListOptions{
 				LabelSelector: nodeSelector,
 			}, metav1.ListOptions{
@@ -97,13 +107,14 @@ func (p *localVolumeProvisioner) Provision(ctx context.Context, options controll
 
 	return &v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: options.PVName,
+			Name:        options.PVName,
+			Annotations: map[string]string{quota.AnnotationQuotaMode: string(quotaMode)},
 		},
 		Spec: v1.PersistentVolumeSpec{
 			PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
 			AccessModes:                   options.PVC.Spec.AccessModes,
 			Capacity: v1.ResourceList{
-				v1.ResourceName(v1.ResourceStorage): options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)],
+				v1.ResourceName(v1.ResourceStorage): capacity,
 			},
 			PersistentVolumeSource: v1.PersistentVolumeSource{
 				Local: &v1.LocalVolumeSource{
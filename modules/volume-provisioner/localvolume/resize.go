@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package localvolume
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// Owns local volume is resized by the provisioner on its node if node name is configured
+func (p *localVolumeProvisioner) Owns(pv *v1.PersistentVolume) bool {
+	if p.lvpConfig.NodeName == "" {
+		return !p.lvpConfig.ModeEdge
+	}
+	return VolumeNode(pv) == p.lvpConfig.NodeName
+}
+
+// Expand enlarge the capacity limit of the local volume
+func (p *localVolumeProvisioner) Expand(ctx context.Context, pv *v1.PersistentVolume, capacity resource.Quantity) error {
+	if pv.Spec.Local == nil {
+		return fmt.Errorf("pv %s is not a local volume", pv.Name)
+	}
+	node := VolumeNode(pv)
+	if node == "" {
+		return fmt.Errorf("failed to get node of pv %s", pv.Name)
+	}
+	volPath := strutil.JoinPath("/hostfs", pv.Spec.Local.Path)
+	cmd := quota.ResizeCmd(volPath, pv.Name, capacity.Value(), quota.ModeOf(pv.Annotations))
+
+	if node == p.lvpConfig.NodeName {
+		return p.cmdExecutor.OnLocal(cmd)
+	}
+	return p.cmdExecutor.OnNodesPods(cmd,
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("kubernetes.io/hostname=%s", node)},
+		metav1.ListOptions{LabelSelector: p.lvpConfig.MatchLabel})
+}
+
+// VolumeNode return the node which local volume located on
+func VolumeNode(pv *v1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	opt, err := genListOptionFromNodeAffinity(pv.Spec.NodeAffinity)
+	if err != nil {
+		return ""
+	}
+	return strutil.TrimPrefixes(opt.LabelSelector, "kubernetes.io/hostname=")
+}
@@ -20,12 +20,16 @@ import (
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
 func (p *netDataVolumeProvisioner) Delete(ctx context.Context, pv *v1.PersistentVolume) error {
 	logrus.Infof("Start deleting volume: %s/%s", pv.Namespace, pv.Name)
 	volPathInContainer := strutil.JoinPath("/hostfs", pv.Spec.PersistentVolumeSource.Local.Path)
+	if err := p.cmdExecutor.OnLocal(quota.ClearLimitCmd(volPathInContainer, pv.Name)); err != nil {
+		logrus.Errorf("Failed to clear capacity limit of path: %v, err: %v", volPathInContainer, err)
+	}
 	if err := os.RemoveAll(volPathInContainer); err != nil {
 		logrus.Errorf("Failed to remove path: %v", volPathInContainer)
 	}
@@ -26,20 +26,24 @@ import (
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/sig-storage-lib-external-provisioner/v6/controller"
 
+	"github.com/erda-project/erda/modules/volume-provisioner/exec"
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
 type netDataVolumeProvisioner struct {
-	client     kubernetes.Interface
-	restClient rest.Interface
-	config     *rest.Config
+	client      kubernetes.Interface
+	restClient  rest.Interface
+	config      *rest.Config
+	cmdExecutor *exec.CmdExecutor
 }
 
 func NewNetDataVolumeProvisioner(config *rest.Config, client kubernetes.Interface) *netDataVolumeProvisioner {
 	return &netDataVolumeProvisioner{
-		client:     client,
-		restClient: client.CoreV1().RESTClient(),
-		config:     config,
+		client:      client,
+		restClient:  client.CoreV1().RESTClient(),
+		config:      config,
+		cmdExecutor: exec.NewCmdExecutor(config, client, ""),
 	}
 }
 
@@ -53,18 +57,28 @@ func (p *netDataVolumeProvisioner) Provision(ctx context.Context, options contro
 	if err != nil {
 		return nil, controller.ProvisioningFinished, err
 	}
+	quotaMode, err := quota.ParseMode(options.StorageClass.Parameters)
+	if err != nil {
+		return nil, controller.ProvisioningFinished, err
+	}
 	if err := os.MkdirAll(volPath, 0666); err != nil {
 		return nil, controller.ProvisioningFinished, fmt.Errorf("Failed to mkdir: %v, err: %v", volPath, err)
 	}
+	// nfs/glusterfs don't support project quota, it only takes effect when netdata is backed by xfs/ext4
+	capacity := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	if err := p.cmdExecutor.OnLocal(quota.SetLimitCmd(volPath, options.PVName, capacity.Value(), quotaMode)); err != nil {
+		return nil, controller.ProvisioningFinished, fmt.Errorf("Failed to limit capacity of %v, err: %v", volPath, err)
+	}
 	return &v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: options.PVName,
+			Name:        options.PVName,
+			Annotations: map[string]string{quota.AnnotationQuotaMode: string(quotaMode)},
 		},
 		Spec: v1.PersistentVolumeSpec{
 			PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
 			AccessModes:                   options.PVC.Spec.AccessModes,
 			Capacity: v1.ResourceList{
-				v1.ResourceName(v1.ResourceStorage): options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)],
+				v1.ResourceName(v1.ResourceStorage): capacity,
 			},
 			PersistentVolumeSource: v1.PersistentVolumeSource{
 				Local: &v1.LocalVolumeSource{
@@ -0,0 +1,39 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package netdatavolume
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// Owns netdata volume is shared by all nodes, so every provisioner can resize it
+func (p *netDataVolumeProvisioner) Owns(pv *v1.PersistentVolume) bool {
+	return true
+}
+
+// Expand enlarge the capacity limit of the netdata volume
+func (p *netDataVolumeProvisioner) Expand(ctx context.Context, pv *v1.PersistentVolume, capacity resource.Quantity) error {
+	if pv.Spec.Local == nil {
+		return fmt.Errorf("pv %s is not a netdata volume", pv.Name)
+	}
+	volPathInContainer := strutil.JoinPath("/hostfs", pv.Spec.Local.Path)
+	return p.cmdExecutor.OnLocal(quota.ResizeCmd(volPathInContainer, pv.Name, capacity.Value(), quota.ModeOf(pv.Annotations)))
+}
@@ -16,6 +16,7 @@ package volumeprovisioner
 
 import (
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -65,8 +66,13 @@ type config struct {
 	LocalMatchLabel string `env:"LOCAL_MATCH_LABEL" default:"app=volume-provisioner"`
 	// ModeEdge Used for edge computing,
 	ModeEdge bool `env:"EDGE_MODE" default:"false"`
-	// NodeName Used for edge computing, directory creation action on the specified edge nodeSite
+	// NodeName Used for edge computing, directory creation action on the specified edge nodeSite,
+	// local volumes on this node are resized and reported by this provisioner if specified
 	NodeName string `env:"NODE_NAME" default:""`
+	// CollectorAddr Address of monitor collector, usage of local volumes is not reported if empty
+	CollectorAddr string `env:"COLLECTOR_ADDR" default:""`
+	// UsageReportInterval Interval of reporting volume usage
+	UsageReportInterval time.Duration `env:"VOLUME_USAGE_REPORT_INTERVAL" default:"5m"`
 }
 
 // TODO: refactor
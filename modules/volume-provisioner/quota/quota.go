@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package quota limits the capacity of directory volumes with XFS/ext4 project quotas.
+// Commands are generated as shell scripts, so they can be executed either locally
+// or in the provisioner pod of the volume's node.
+package quota
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Mode defines how a volume's capacity is enforced, set by storageclass parameter 'quota'
+type Mode string
+
+const (
+	// ModeAuto apply project quota if the backing filesystem supports it, otherwise only warn
+	ModeAuto Mode = "auto"
+	// ModeRequired fail provisioning or resizing if project quota can't be applied
+	ModeRequired Mode = "required"
+	// ModeNone never apply project quota
+	ModeNone Mode = "none"
+
+	// ParamQuota storageclass parameter of quota mode
+	ParamQuota = "quota"
+	// AnnotationQuotaMode pv annotation of quota mode, used when resizing the volume
+	AnnotationQuotaMode = "erda.io/volume-quota"
+
+	// project ids below minProjectID are left for manually configured projects
+	minProjectID = 1 << 20
+	maxProjectID = 1<<31 - 1
+)
+
+// ParseMode parse quota mode from storageclass parameters, default is auto
+func ParseMode(parameters map[string]string) (Mode, error) {
+	switch v := Mode(strings.ToLower(parameters[ParamQuota])); v {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeRequired, ModeNone:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid storageclass parameter %s: %s", ParamQuota, v)
+	}
+}
+
+// ModeOf get quota mode of a provisioned pv, volumes provisioned before quota supported are treated as auto
+func ModeOf(annotations map[string]string) Mode {
+	mode, err := ParseMode(map[string]string{ParamQuota: annotations[AnnotationQuotaMode]})
+	if err != nil {
+		return ModeAuto
+	}
+	return mode
+}
+
+// ProjectID generate a stable project id from pv name, so it needn't to be stored anywhere
+func ProjectID(pvName string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(pvName))
+	return h.Sum32()%(maxProjectID-minProjectID) + minProjectID
+}
+
+// SetLimitCmd assign 'path' to the volume's project and limit its capacity,
+// used when provisioning a new volume
+func SetLimitCmd(path, pvName string, bytes int64, mode Mode) string {
+	return limitCmd(path, pvName, bytes, mode, true)
+}
+
+// ResizeCmd change the capacity limit of an existing volume
+func ResizeCmd(path, pvName string, bytes int64, mode Mode) string {
+	return limitCmd(path, pvName, bytes, mode, false)
+}
+
+// ClearLimitCmd remove the capacity limit of the volume, always succeed
+func ClearLimitCmd(path, pvName string) string {
+	return limitCmd(path, pvName, 0, ModeAuto, false)
+}
+
+func limitCmd(path, pvName string, bytes int64, mode Mode, assign bool) string {
+	if mode == ModeNone {
+		return "true"
+	}
+	id := ProjectID(pvName)
+	// both xfs_quota and setquota use 1KiB blocks
+	kib := (bytes + 1023) / 1024
+	exitCode := 0
+	if mode == ModeRequired {
+		exitCode = 1
+	}
+
+	var xfs, ext4 []string
+	if assign {
+		xfs = append(xfs, fmt.Sprintf(`xfs_quota -x -c "project -s -p $p %d" "$mp" >/dev/null || fail "failed to set xfs project %d on $p"`, id, id))
+		ext4 = append(ext4, fmt.Sprintf(`chattr +P -p %d "$p" || fail "failed to set ext4 project %d on $p"`, id, id))
+	}
+	xfs = append(xfs, fmt.Sprintf(`xfs_quota -x -c "limit -p bhard=%dk %d" "$mp" || fail "failed to limit xfs project %d"`, kib, id, id))
+	ext4 = append(ext4, fmt.Sprintf(`setquota -P %d 0 %d 0 0 "$mp" || fail "failed to limit ext4 project %d"`, id, kib, id))
+
+	return strings.Join([]string{
+		"p=" + shellQuote(path),
+		fmt.Sprintf(`fail() { echo "volume quota: $1"; exit %d; }`, exitCode),
+		`[ -d "$p" ] || fail "$p not exist"`,
+		`mp=$(df -P "$p" | awk 'NR==2{print $6}')`,
+		`fs=$(stat -f -c %T "$p")`,
+		`case "$fs" in`,
+		"xfs)", strings.Join(xfs, "\n"), ";;",
+		"ext2/ext3)", strings.Join(ext4, "\n"), ";;",
+		`*) fail "project quota is not supported on $fs" ;;`,
+		"esac",
+	}, "\n")
+}
+
+// UsageCmd print the used bytes of the volume in KiB
+func UsageCmd(path string) string {
+	return fmt.Sprintf("du -sk %s | cut -f1", shellQuote(path))
+}
+
+// ParseUsage parse the output of UsageCmd, return used bytes
+func ParseUsage(output string) (int64, error) {
+	kib, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid volume usage: %q", output)
+	}
+	return kib * 1024, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
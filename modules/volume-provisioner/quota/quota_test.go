@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package quota
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMode(t *testing.T) {
+	for param, want := range map[string]Mode{"": ModeAuto, "Required": ModeRequired, "none": ModeNone} {
+		mode, err := ParseMode(map[string]string{ParamQuota: param})
+		assert.NoError(t, err)
+		assert.Equal(t, want, mode)
+	}
+	_, err := ParseMode(map[string]string{ParamQuota: "soft"})
+	assert.Error(t, err)
+}
+
+func TestProjectID(t *testing.T) {
+	id := ProjectID("pvc-2f8a0c36-7b1e-4c1a-9d36-1b1c3f7e2a10")
+	assert.True(t, id >= minProjectID && id < maxProjectID)
+	assert.Equal(t, id, ProjectID("pvc-2f8a0c36-7b1e-4c1a-9d36-1b1c3f7e2a10"))
+}
+
+func TestLimitCmd(t *testing.T) {
+	cmd := SetLimitCmd("/hostfs/data/localvolume/pv'1", "pv1", 10<<30, ModeRequired)
+	assert.Contains(t, cmd, `p='/hostfs/data/localvolume/pv'\''1'`)
+	assert.Contains(t, cmd, "exit 1;")
+	assert.Contains(t, cmd, "project -s -p $p")
+	assert.Contains(t, cmd, "bhard=10485760k")
+	assert.Contains(t, cmd, "setquota -P")
+	assert.NotContains(t, ResizeCmd("/data/pv1", "pv1", 1024, ModeAuto), "project -s")
+	assert.Contains(t, ClearLimitCmd("/data/pv1", "pv1"), "bhard=0k")
+	assert.Equal(t, "true", SetLimitCmd("/data/pv1", "pv1", 1024, ModeNone))
+
+	// check shell syntax
+	if _, err := exec.LookPath("sh"); err == nil {
+		assert.NoError(t, exec.Command("sh", "-n", "-c", cmd).Run())
+	}
+}
+
+func TestParseUsage(t *testing.T) {
+	used, err := ParseUsage("2048\n")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2048*1024), used)
+	_, err = ParseUsage("du: cannot access")
+	assert.Error(t, err)
+}
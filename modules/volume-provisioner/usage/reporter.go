@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package usage reports usage of local volumes on current node to monitor collector.
+// NetData volumes are shared by all nodes, their usage should be reported by the storage itself.
+package usage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/erda-project/erda/modules/volume-provisioner/localvolume"
+	"github.com/erda-project/erda/modules/volume-provisioner/quota"
+	"github.com/erda-project/erda/pkg/httpclient"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	metricName = "volume_usage"
+
+	annotationProvisionedBy = "pv.kubernetes.io/provisioned-by"
+)
+
+// metric is the metric format accepted by collector
+type metric struct {
+	Name      string                 `json:"name"`
+	Timestamp int64                  `json:"timestamp"`
+	Tags      map[string]string      `json:"tags"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+type Reporter struct {
+	client        kubernetes.Interface
+	nodeName      string
+	provisioner   string
+	collectorAddr string
+	interval      time.Duration
+}
+
+func NewReporter(client kubernetes.Interface, nodeName, provisioner, collectorAddr string, interval time.Duration) *Reporter {
+	return &Reporter{
+		client:        client,
+		nodeName:      nodeName,
+		provisioner:   provisioner,
+		collectorAddr: collectorAddr,
+		interval:      interval,
+	}
+}
+
+// Run report usage periodically until ctx is done
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.report(ctx); err != nil {
+				logrus.Errorf("Failed to report volume usage: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Reporter) report(ctx context.Context) error {
+	pvs, err := r.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var metrics []metric
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Annotations[annotationProvisionedBy] != r.provisioner || pv.Spec.Local == nil ||
+			localvolume.VolumeNode(pv) != r.nodeName {
+			continue
+		}
+		used, err := volumeUsage(pv)
+		if err != nil {
+			logrus.Warnf("Failed to get usage of volume %s: %v", pv.Name, err)
+			continue
+		}
+		metrics = append(metrics, newMetric(pv, r.nodeName, used))
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	return r.push(metrics)
+}
+
+func volumeUsage(pv *v1.PersistentVolume) (int64, error) {
+	volPath := strutil.JoinPath("/hostfs", pv.Spec.Local.Path)
+	out, err := exec.Command("/bin/sh", "-c", quota.UsageCmd(volPath)).Output()
+	if err != nil {
+		return 0, err
+	}
+	return quota.ParseUsage(string(out))
+}
+
+func newMetric(pv *v1.PersistentVolume, nodeName string, used int64) metric {
+	capacity := pv.Spec.Capacity.Storage().Value()
+	m := metric{
+		Name:      metricName,
+		Timestamp: time.Now().UnixNano(),
+		Tags: map[string]string{
+			"pv_name":       pv.Name,
+			"storage_class": pv.Spec.StorageClassName,
+			"node_name":     nodeName,
+			"quota":         string(quota.ModeOf(pv.Annotations)),
+		},
+		Fields: map[string]interface{}{
+			"used":     used,
+			"capacity": capacity,
+		},
+	}
+	if ref := pv.Spec.ClaimRef; ref != nil {
+		m.Tags["pvc_namespace"] = ref.Namespace
+		m.Tags["pvc_name"] = ref.Name
+	}
+	if capacity > 0 {
+		m.Fields["used_percent"] = float64(used) * 100 / float64(capacity)
+	}
+	return m
+}
+
+func (r *Reporter) push(metrics []metric) error {
+	var respBody bytes.Buffer
+	resp, err := httpclient.New(httpclient.WithCompleteRedirect()).
+		Post(r.collectorAddr).
+		Path("/collect/metrics").
+		JSONBody(map[string][]metric{"metrics": metrics}).
+		Header("Content-Type", "application/json").
+		Do().
+		Body(&respBody)
+	if err != nil {
+		return fmt.Errorf("failed to push volume usage to collector, err: %v", err)
+	}
+	if !resp.IsOK() {
+		return fmt.Errorf("failed to push volume usage to collector, resp body: %s", respBody.String())
+	}
+	return nil
+}
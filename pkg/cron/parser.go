@@ -88,7 +88,42 @@ func NewParser(options ParseOption) Parser {
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
 // It accepts crontab specs and features configured by NewParser.
+// The spec may be prefixed with "CRON_TZ=<IANA timezone> " (or "TZ=") to
+// evaluate the schedule in the given timezone.
 func (p Parser) Parse(spec string) (Schedule, error) {
+	loc, spec, err := extractLocation(spec)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := p.parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if ss, ok := schedule.(*SpecSchedule); ok {
+		ss.Location = loc
+	}
+	return schedule, nil
+}
+
+// extractLocation splits the optional timezone prefix from spec.
+func extractLocation(spec string) (*time.Location, string, error) {
+	spec = strings.TrimSpace(spec)
+	if !strings.HasPrefix(spec, "TZ=") && !strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, spec, nil
+	}
+	eq := strings.Index(spec, "=")
+	end := strings.IndexAny(spec, " \t")
+	if end == -1 {
+		return nil, "", fmt.Errorf("Missing schedule after timezone: %s", spec)
+	}
+	loc, err := time.LoadLocation(spec[eq+1 : end])
+	if err != nil {
+		return nil, "", fmt.Errorf("Provided bad location %s: %v", spec[eq+1:end], err)
+	}
+	return loc, strings.TrimSpace(spec[end:]), nil
+}
+
+func (p Parser) parse(spec string) (Schedule, error) {
 	if len(spec) == 0 {
 		return nil, fmt.Errorf("Empty spec string")
 	}
@@ -192,6 +192,14 @@ func TestParse(t *testing.T) {
 			expr: "",
 			err:  "Empty spec string",
 		},
+		{
+			expr: "CRON_TZ=Nowhere/Bad 0 0 * * *",
+			err:  "Provided bad location",
+		},
+		{
+			expr: "CRON_TZ=UTC",
+			err:  "Missing schedule after timezone",
+		},
 	}
 
 	for _, c := range entries {
@@ -216,7 +224,7 @@ func TestStandardSpecSchedule(t *testing.T) {
 	}{
 		{
 			expr:     "5 * * * *",
-			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow)},
+			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow), nil},
 		},
 		{
 			expr:     "@every 5m",
@@ -228,6 +228,10 @@ func TestNextWithTz(t *testing.T) {
 		// Passing tests
 		{"2016-01-03T14:09:03+0530", "0 14 14 * * *", "2016-01-03T14:14:00+0530"},
 		{"2016-01-03T14:00:00+0530", "0 14 14 * * ?", "2016-01-03T14:14:00+0530"},
+
+		// Schedule timezone
+		{"2016-01-03T13:09:03+0530", "CRON_TZ=UTC 0 14 14 * * *", "2016-01-03T19:44:00+0530"},
+		{"2016-01-03T13:09:03+0530", "TZ=Asia/Tokyo 0 0 9 * * *", "2016-01-04T05:30:00+0530"},
 	}
 	for _, c := range runs {
 		sched, err := Parse(c.spec)
@@ -30,12 +30,13 @@ var Re = pexpr.PhRe
 var OldRe = regexp.MustCompile(`\${([^{}]+)}`)
 
 const (
-	Dirs    = "dirs"
-	Outputs = "outputs"
-	Random  = "random"
-	Params  = "params"
-	Globals = "globals"
-	Configs = "configs"
+	Dirs      = "dirs"
+	Outputs   = "outputs"
+	Artifacts = "artifacts"
+	Random    = "random"
+	Params    = "params"
+	Globals   = "globals"
+	Configs   = "configs"
 )
 
 const (
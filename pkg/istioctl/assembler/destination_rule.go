@@ -24,6 +24,11 @@ import (
 func NewDestinationRule(svc *apistructs.Service) *v1alpha3.DestinationRule {
 	result := &v1alpha3.DestinationRule{}
 	result.Name = svc.Name
-	result.Spec.Host = fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace)
+	result.Spec.Host = ServiceHost(svc)
 	return result
 }
+
+// ServiceHost return the k8s service FQDN
+func ServiceHost(svc *apistructs.Service) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package assembler
+
+import (
+	"github.com/gogo/protobuf/types"
+	"istio.io/api/networking/v1alpha3"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// NewSubsets subset labels select pods labeled by deployments.labels of service
+func NewSubsets(svc *apistructs.Service) []*v1alpha3.Subset {
+	if svc.TrafficManagement == nil {
+		return nil
+	}
+	var subsets []*v1alpha3.Subset
+	for _, subset := range svc.TrafficManagement.Subsets {
+		subsets = append(subsets, &v1alpha3.Subset{
+			Name:   subset.Name,
+			Labels: subset.Labels,
+		})
+	}
+	return subsets
+}
+
+// NewCircuitBreakerSettings return outlier detection and connection pool settings of circuit breaker
+func NewCircuitBreakerSettings(svc *apistructs.Service) (*v1alpha3.OutlierDetection, *v1alpha3.ConnectionPoolSettings, error) {
+	if svc.TrafficManagement == nil || svc.TrafficManagement.CircuitBreaker == nil {
+		return nil, nil, nil
+	}
+	cb := svc.TrafficManagement.CircuitBreaker
+	var outlier *v1alpha3.OutlierDetection
+	if cb.Consecutive5xxErrors > 0 {
+		interval, err := parseDuration(cb.Interval)
+		if err != nil {
+			return nil, nil, err
+		}
+		baseEjectionTime, err := parseDuration(cb.BaseEjectionTime)
+		if err != nil {
+			return nil, nil, err
+		}
+		outlier = &v1alpha3.OutlierDetection{
+			Consecutive_5XxErrors: &types.UInt32Value{Value: uint32(cb.Consecutive5xxErrors)},
+			Interval:              interval,
+			BaseEjectionTime:      baseEjectionTime,
+			MaxEjectionPercent:    int32(cb.MaxEjectionPercent),
+		}
+	}
+	var pool *v1alpha3.ConnectionPoolSettings
+	if cb.MaxConnections > 0 || cb.HTTP1MaxPendingRequests > 0 || cb.MaxRequestsPerConnection > 0 {
+		pool = &v1alpha3.ConnectionPoolSettings{}
+		if cb.MaxConnections > 0 {
+			pool.Tcp = &v1alpha3.ConnectionPoolSettings_TCPSettings{MaxConnections: int32(cb.MaxConnections)}
+		}
+		if cb.HTTP1MaxPendingRequests > 0 || cb.MaxRequestsPerConnection > 0 {
+			pool.Http = &v1alpha3.ConnectionPoolSettings_HTTPSettings{
+				Http1MaxPendingRequests:  int32(cb.HTTP1MaxPendingRequests),
+				MaxRequestsPerConnection: int32(cb.MaxRequestsPerConnection),
+			}
+		}
+	}
+	return outlier, pool, nil
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package assembler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	networkingv1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
+)
+
+const (
+	headerMatchExact  = "exact:"
+	headerMatchPrefix = "prefix:"
+	headerMatchRegex  = "regex:"
+)
+
+func NewVirtualService(svc *apistructs.Service) (*v1alpha3.VirtualService, error) {
+	result := &v1alpha3.VirtualService{}
+	result.Name = svc.Name
+	result.Spec.Hosts = []string{ServiceHost(svc)}
+	routes, err := NewHTTPRoutes(svc)
+	if err != nil {
+		return nil, err
+	}
+	result.Spec.Http = routes
+	return result, nil
+}
+
+// NewHTTPRoutes header based routes first, then the default weighted route
+func NewHTTPRoutes(svc *apistructs.Service) ([]*networkingv1alpha3.HTTPRoute, error) {
+	tm := svc.TrafficManagement
+	if tm == nil {
+		return nil, nil
+	}
+	host := ServiceHost(svc)
+	var routes []*networkingv1alpha3.HTTPRoute
+	for i, route := range tm.Routes {
+		headers := make(map[string]*networkingv1alpha3.StringMatch, len(route.Headers))
+		for k, v := range route.Headers {
+			headers[k] = newStringMatch(v)
+		}
+		routes = append(routes, &networkingv1alpha3.HTTPRoute{
+			Name:  fmt.Sprintf("route-%d", i),
+			Match: []*networkingv1alpha3.HTTPMatchRequest{{Headers: headers}},
+			Route: []*networkingv1alpha3.HTTPRouteDestination{{
+				Destination: &networkingv1alpha3.Destination{Host: host, Subset: route.Subset},
+			}},
+		})
+	}
+	defaultRoute := &networkingv1alpha3.HTTPRoute{Name: "default"}
+	for _, subset := range tm.Subsets {
+		if subset.Weight <= 0 {
+			continue
+		}
+		defaultRoute.Route = append(defaultRoute.Route, &networkingv1alpha3.HTTPRouteDestination{
+			Destination: &networkingv1alpha3.Destination{Host: host, Subset: subset.Name},
+			Weight:      int32(subset.Weight),
+		})
+	}
+	if len(defaultRoute.Route) == 0 {
+		defaultRoute.Route = []*networkingv1alpha3.HTTPRouteDestination{{
+			Destination: &networkingv1alpha3.Destination{Host: host},
+		}}
+	}
+	routes = append(routes, defaultRoute)
+
+	// common policies
+	timeout, err := parseDuration(tm.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := newHTTPRetry(tm.Retries)
+	if err != nil {
+		return nil, err
+	}
+	fault, err := newHTTPFaultInjection(tm.Fault)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes {
+		route.Timeout = timeout
+		route.Retries = retries
+		route.Fault = fault
+	}
+	return routes, nil
+}
+
+func newStringMatch(value string) *networkingv1alpha3.StringMatch {
+	switch {
+	case strings.HasPrefix(value, headerMatchPrefix):
+		return &networkingv1alpha3.StringMatch{MatchType: &networkingv1alpha3.StringMatch_Prefix{
+			Prefix: strings.TrimPrefix(value, headerMatchPrefix)}}
+	case strings.HasPrefix(value, headerMatchRegex):
+		return &networkingv1alpha3.StringMatch{MatchType: &networkingv1alpha3.StringMatch_Regex{
+			Regex: strings.TrimPrefix(value, headerMatchRegex)}}
+	default:
+		return &networkingv1alpha3.StringMatch{MatchType: &networkingv1alpha3.StringMatch_Exact{
+			Exact: strings.TrimPrefix(value, headerMatchExact)}}
+	}
+}
+
+func newHTTPRetry(retries *diceyml.TrafficRetries) (*networkingv1alpha3.HTTPRetry, error) {
+	if retries == nil {
+		return nil, nil
+	}
+	perTryTimeout, err := parseDuration(retries.PerTryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &networkingv1alpha3.HTTPRetry{
+		Attempts:      int32(retries.Attempts),
+		PerTryTimeout: perTryTimeout,
+		RetryOn:       retries.RetryOn,
+	}, nil
+}
+
+func newHTTPFaultInjection(fault *diceyml.TrafficFault) (*networkingv1alpha3.HTTPFaultInjection, error) {
+	if fault == nil {
+		return nil, nil
+	}
+	result := &networkingv1alpha3.HTTPFaultInjection{}
+	if fault.Delay != "" {
+		delay, err := parseDuration(fault.Delay)
+		if err != nil {
+			return nil, err
+		}
+		result.Delay = &networkingv1alpha3.HTTPFaultInjection_Delay{
+			HttpDelayType: &networkingv1alpha3.HTTPFaultInjection_Delay_FixedDelay{FixedDelay: delay},
+			Percentage:    &networkingv1alpha3.Percent{Value: fault.DelayPercent},
+		}
+	}
+	if fault.AbortStatus != 0 {
+		result.Abort = &networkingv1alpha3.HTTPFaultInjection_Abort{
+			ErrorType:  &networkingv1alpha3.HTTPFaultInjection_Abort_HttpStatus{HttpStatus: int32(fault.AbortStatus)},
+			Percentage: &networkingv1alpha3.Percent{Value: fault.AbortPercent},
+		}
+	}
+	return result, nil
+}
+
+// parseDuration return nil if duration is empty
+func parseDuration(d string) (*types.Duration, error) {
+	if d == "" {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %s, err: %v", d, err)
+	}
+	return types.DurationProto(duration), nil
+}
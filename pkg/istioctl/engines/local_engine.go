@@ -14,6 +14,8 @@
 package engines
 
 import (
+	"istio.io/client-go/pkg/clientset/versioned"
+
 	"github.com/erda-project/erda/pkg/clientgo"
 	"github.com/erda-project/erda/pkg/istioctl"
 	"github.com/erda-project/erda/pkg/istioctl/executors"
@@ -28,9 +30,16 @@ func NewLocalEngine(addr string) (*LocalEngine, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewLocalEngineWithClient(client.CustomClient), nil
+}
+
+// NewLocalEngineWithClient create local engine with the given istio client, e.g. a fake clientset in tests
+func NewLocalEngineWithClient(client versioned.Interface) *LocalEngine {
 	authN := &executors.AuthNExecutor{}
-	authN.SetIstioClient(client.CustomClient)
+	authN.SetIstioClient(client)
+	traffic := &executors.TrafficExecutor{}
+	traffic.SetIstioClient(client)
 	return &LocalEngine{
-		DefaultEngine: istioctl.NewDefaultEngine(authN),
-	}, nil
+		DefaultEngine: istioctl.NewDefaultEngine(authN, traffic),
+	}
 }
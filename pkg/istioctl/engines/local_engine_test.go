@@ -14,9 +14,81 @@
 package engines
 
 import (
+	"context"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"istio.io/client-go/pkg/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/istioctl"
+	"github.com/erda-project/erda/pkg/parser/diceyml"
 )
 
 func TestNewLocalEngine(t *testing.T) {
 	_, _ = NewLocalEngine("")
 }
+
+func TestLocalEngineTrafficManagement(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	engine := NewLocalEngineWithClient(client)
+	meshEnable := true
+	svc := &apistructs.Service{
+		Name:       "web",
+		Namespace:  "default",
+		MeshEnable: &meshEnable,
+		TrafficManagement: &diceyml.TrafficManagement{
+			Subsets: []diceyml.TrafficSubset{
+				{Name: "v1", Weight: 90, Labels: map[string]string{"version": "v1"}},
+				{Name: "v2", Weight: 10, Labels: map[string]string{"release": "canary"}},
+			},
+			Routes: []diceyml.TrafficRoute{
+				{Headers: map[string]string{"x-canary": "true"}, Subset: "v2"},
+			},
+			Timeout: "3s",
+			Retries: &diceyml.TrafficRetries{Attempts: 2, PerTryTimeout: "1s", RetryOn: "5xx"},
+			CircuitBreaker: &diceyml.TrafficCircuitBreaker{
+				Consecutive5xxErrors: 5,
+				Interval:             "10s",
+				BaseEjectionTime:     "30s",
+				MaxConnections:       100,
+			},
+		},
+	}
+	ctx := context.Background()
+	assert.NoError(t, engine.OnServiceOperator(istioctl.ServiceCreate, svc))
+
+	vs, err := client.NetworkingV1alpha3().VirtualServices("default").Get(ctx, "web", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web.default.svc.cluster.local"}, vs.Spec.Hosts)
+	assert.Equal(t, 2, len(vs.Spec.Http))
+	assert.Equal(t, "v2", vs.Spec.Http[0].Route[0].Destination.Subset)
+	assert.Equal(t, "true", vs.Spec.Http[0].Match[0].Headers["x-canary"].GetExact())
+	assert.Equal(t, int32(90), vs.Spec.Http[1].Route[0].Weight)
+	assert.Equal(t, int32(10), vs.Spec.Http[1].Route[1].Weight)
+	assert.Equal(t, int64(3), vs.Spec.Http[1].Timeout.Seconds)
+	assert.Equal(t, int32(2), vs.Spec.Http[1].Retries.Attempts)
+
+	dr, err := client.NetworkingV1alpha3().DestinationRules("default").Get(ctx, "web", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"version": "v1"}, dr.Spec.Subsets[0].Labels)
+	assert.Equal(t, map[string]string{"release": "canary"}, dr.Spec.Subsets[1].Labels)
+	assert.Equal(t, uint32(5), dr.Spec.TrafficPolicy.OutlierDetection.Consecutive_5XxErrors.Value)
+	assert.Equal(t, int32(100), dr.Spec.TrafficPolicy.ConnectionPool.Tcp.MaxConnections)
+
+	// 去掉流量治理配置后清理 vs 和 dr 中的相关配置
+	svc.TrafficManagement = nil
+	assert.NoError(t, engine.OnServiceOperator(istioctl.ServiceUpdate, svc))
+	_, err = client.NetworkingV1alpha3().VirtualServices("default").Get(ctx, "web", v1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+	dr, err = client.NetworkingV1alpha3().DestinationRules("default").Get(ctx, "web", v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, dr.Spec.Subsets)
+	assert.Nil(t, dr.Spec.TrafficPolicy.OutlierDetection)
+
+	assert.NoError(t, engine.OnServiceOperator(istioctl.ServiceDelete, svc))
+	_, err = client.NetworkingV1alpha3().DestinationRules("default").Get(ctx, "web", v1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package executors
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+	"istio.io/api/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/istioctl"
+	"github.com/erda-project/erda/pkg/istioctl/assembler"
+)
+
+type TrafficExecutor struct {
+	BaseExecutor
+}
+
+func (exe TrafficExecutor) GetName() string {
+	return "traffic"
+}
+
+func (exe TrafficExecutor) onServiceCreateOrUpdate(ctx context.Context, svc *apistructs.Service) (istioctl.ExecResult, error) {
+	if svc.TrafficManagement == nil {
+		return exe.cleanTrafficManagement(ctx, svc)
+	}
+	if err := exe.applyVirtualService(ctx, svc); err != nil {
+		return istioctl.ExecSkip, err
+	}
+	outlier, pool, err := assembler.NewCircuitBreakerSettings(svc)
+	if err != nil {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	drExist := true
+	dr, err := exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Get(ctx, svc.Name, v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return istioctl.ExecSkip, pkgerrors.WithStack(err)
+		}
+		drExist = false
+		dr = assembler.NewDestinationRule(svc)
+	}
+	// tls 相关配置由 authN 维护，这里只处理 subsets 和熔断
+	dr.Spec.Subsets = assembler.NewSubsets(svc)
+	if dr.Spec.TrafficPolicy == nil {
+		dr.Spec.TrafficPolicy = &v1alpha3.TrafficPolicy{}
+	}
+	dr.Spec.TrafficPolicy.OutlierDetection = outlier
+	dr.Spec.TrafficPolicy.ConnectionPool = pool
+	if !drExist {
+		_, err = exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Create(ctx, dr, v1.CreateOptions{})
+	} else {
+		_, err = exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Update(ctx, dr, v1.UpdateOptions{})
+	}
+	if err != nil {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	return istioctl.ExecSuccess, nil
+}
+
+func (exe TrafficExecutor) applyVirtualService(ctx context.Context, svc *apistructs.Service) error {
+	vs, err := assembler.NewVirtualService(svc)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+	old, err := exe.client.NetworkingV1alpha3().VirtualServices(svc.Namespace).Get(ctx, svc.Name, v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return pkgerrors.WithStack(err)
+		}
+		_, err = exe.client.NetworkingV1alpha3().VirtualServices(svc.Namespace).Create(ctx, vs, v1.CreateOptions{})
+		return pkgerrors.WithStack(err)
+	}
+	old.Spec = vs.Spec
+	_, err = exe.client.NetworkingV1alpha3().VirtualServices(svc.Namespace).Update(ctx, old, v1.UpdateOptions{})
+	return pkgerrors.WithStack(err)
+}
+
+// cleanTrafficManagement 未配置流量治理时，删除 vs 并清理 dr 中的 subsets 和熔断配置
+func (exe TrafficExecutor) cleanTrafficManagement(ctx context.Context, svc *apistructs.Service) (istioctl.ExecResult, error) {
+	err := exe.client.NetworkingV1alpha3().VirtualServices(svc.Namespace).Delete(ctx, svc.Name, v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	dr, err := exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Get(ctx, svc.Name, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return istioctl.ExecSuccess, nil
+		}
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	dr.Spec.Subsets = nil
+	if dr.Spec.TrafficPolicy != nil {
+		dr.Spec.TrafficPolicy.OutlierDetection = nil
+		dr.Spec.TrafficPolicy.ConnectionPool = nil
+	}
+	_, err = exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Update(ctx, dr, v1.UpdateOptions{})
+	if err != nil {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	return istioctl.ExecSuccess, nil
+}
+
+// OnServiceCreate
+func (exe TrafficExecutor) OnServiceCreate(ctx context.Context, svc *apistructs.Service) (istioctl.ExecResult, error) {
+	return exe.onServiceCreateOrUpdate(ctx, svc)
+}
+
+// OnServiceUpdate
+func (exe TrafficExecutor) OnServiceUpdate(ctx context.Context, svc *apistructs.Service) (istioctl.ExecResult, error) {
+	return exe.onServiceCreateOrUpdate(ctx, svc)
+}
+
+// OnServiceDelete
+func (exe TrafficExecutor) OnServiceDelete(ctx context.Context, svc *apistructs.Service) (istioctl.ExecResult, error) {
+	err := exe.client.NetworkingV1alpha3().VirtualServices(svc.Namespace).Delete(ctx, svc.Name, v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	err = exe.client.NetworkingV1alpha3().DestinationRules(svc.Namespace).Delete(ctx, svc.Name, v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return istioctl.ExecSkip, pkgerrors.WithStack(err)
+	}
+	return istioctl.ExecSuccess, nil
+}
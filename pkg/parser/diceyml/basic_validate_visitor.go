@@ -18,6 +18,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -94,6 +95,11 @@ func (o *BasicValidateVisitor) VisitService(v DiceYmlVisitor, obj *Service) {
 	default:
 		o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService}, "traffic_security")] = errors.Wrap(invalidTrafficSecurityMode, o.currentService)
 	}
+	if obj.TrafficManagement != nil {
+		if err := validateTrafficManagement(obj.TrafficManagement); err != nil {
+			o.collectErrors[yamlHeaderRegexWithUpperHeader([]string{o.currentService}, "traffic_management")] = errors.Wrapf(invalidTrafficManagement, "%s: %v", o.currentService, err)
+		}
+	}
 	for _, endpoint := range obj.Endpoints {
 		if !o.isEndpointValid(endpoint) {
 			break
@@ -179,3 +185,80 @@ func BasicValidate(obj *Object) ValidateError {
 	obj.Accept(visitor)
 	return visitor.(*BasicValidateVisitor).collectErrors
 }
+
+func validateTrafficManagement(tm *TrafficManagement) error {
+	subsets := map[string]struct{}{}
+	totalWeight, weightedSubsets := 0, 0
+	for _, subset := range tm.Subsets {
+		if subset.Name == "" {
+			return errors.New("empty subset name")
+		}
+		if _, ok := subsets[subset.Name]; ok {
+			return errors.Errorf("duplicate subset: %s", subset.Name)
+		}
+		subsets[subset.Name] = struct{}{}
+		if len(subset.Labels) == 0 {
+			return errors.Errorf("subset %s must have labels to select pods", subset.Name)
+		}
+		if subset.Weight < 0 || subset.Weight > 100 {
+			return errors.Errorf("subset %s weight must between 0 and 100", subset.Name)
+		}
+		totalWeight += subset.Weight
+		if subset.Weight > 0 {
+			weightedSubsets++
+		}
+	}
+	if len(tm.Subsets) > 0 && totalWeight != 100 {
+		return errors.Errorf("sum of subset weights must be 100, got %d", totalWeight)
+	}
+	// a service runs as a single workload, only one subset can have pods
+	if weightedSubsets > 1 {
+		return errors.New("weighted traffic split across multiple subsets is not supported, only one subset can have weight")
+	}
+	for _, route := range tm.Routes {
+		if len(route.Headers) == 0 {
+			return errors.New("empty route headers")
+		}
+		if _, ok := subsets[route.Subset]; !ok {
+			return errors.Errorf("route subset not found: %s", route.Subset)
+		}
+	}
+	durations := map[string]string{"timeout": tm.Timeout}
+	if tm.Retries != nil {
+		if tm.Retries.Attempts <= 0 {
+			return errors.New("retries attempts must > 0")
+		}
+		durations["retries.per_try_timeout"] = tm.Retries.PerTryTimeout
+	}
+	if tm.Fault != nil {
+		if tm.Fault.Delay == "" && tm.Fault.AbortStatus == 0 {
+			return errors.New("fault must contain delay or abort_status")
+		}
+		if tm.Fault.DelayPercent < 0 || tm.Fault.DelayPercent > 100 || tm.Fault.AbortPercent < 0 || tm.Fault.AbortPercent > 100 {
+			return errors.New("fault percent must between 0 and 100")
+		}
+		if tm.Fault.AbortStatus != 0 && (tm.Fault.AbortStatus < 200 || tm.Fault.AbortStatus > 599) {
+			return errors.Errorf("invalid fault abort_status: %d", tm.Fault.AbortStatus)
+		}
+		durations["fault.delay"] = tm.Fault.Delay
+	}
+	if cb := tm.CircuitBreaker; cb != nil {
+		if cb.Consecutive5xxErrors < 0 || cb.MaxConnections < 0 || cb.HTTP1MaxPendingRequests < 0 || cb.MaxRequestsPerConnection < 0 {
+			return errors.New("circuit_breaker values must >= 0")
+		}
+		if cb.MaxEjectionPercent < 0 || cb.MaxEjectionPercent > 100 {
+			return errors.New("circuit_breaker max_ejection_percent must between 0 and 100")
+		}
+		durations["circuit_breaker.interval"] = cb.Interval
+		durations["circuit_breaker.base_ejection_time"] = cb.BaseEjectionTime
+	}
+	for name, d := range durations {
+		if d == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(d); err != nil || duration <= 0 {
+			return errors.Errorf("invalid %s: %s", name, d)
+		}
+	}
+	return nil
+}
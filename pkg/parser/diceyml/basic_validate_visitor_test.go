@@ -84,3 +84,66 @@ func TestBasicValidate(t *testing.T) {
 	assert.Equal(t, 3, len(es), "%v", es)
 
 }
+
+var traffic_management_yml = `version: 2.0
+services:
+  web:
+    ports:
+    - 8080
+    resources:
+      cpu: 0.1
+      mem: 128
+    mesh_enable: true
+    traffic_management:
+      subsets:
+      - name: v1
+        labels:
+          version: v1
+        weight: 100
+      - name: v2
+        labels:
+          version: v2
+      routes:
+      - headers:
+          x-canary: "true"
+        subset: v2
+      timeout: 3s
+      retries:
+        attempts: 3
+        per_try_timeout: 1s
+      fault:
+        delay: 100ms
+        delay_percent: 5
+      circuit_breaker:
+        consecutive_5xx_errors: 5
+        interval: 10s
+        base_ejection_time: 30s
+`
+
+func TestBasicValidateTrafficManagement(t *testing.T) {
+	d, err := New([]byte(traffic_management_yml), true)
+	assert.NoError(t, err)
+	obj := d.Obj()
+	tm := obj.Services["web"].TrafficManagement
+	assert.Len(t, tm.Subsets, 2)
+	assert.Equal(t, "v2", tm.Routes[0].Subset)
+
+	tm.Subsets[1].Weight = 20
+	es := BasicValidate(obj)
+	assert.Equal(t, 1, len(es), "%v", es)
+
+	tm.Subsets[0].Weight = 80
+	assert.EqualError(t, validateTrafficManagement(tm), "weighted traffic split across multiple subsets is not supported, only one subset can have weight")
+
+	tm.Subsets[0].Weight = 100
+	tm.Subsets[1].Weight = 0
+	tm.Routes[0].Subset = "v3"
+	tm.Timeout = "3"
+	assert.Error(t, validateTrafficManagement(tm))
+
+	tm.Routes[0].Subset = "v2"
+	tm.Timeout = "3s"
+	assert.NoError(t, validateTrafficManagement(tm))
+	tm.Subsets[0].Labels = nil
+	assert.EqualError(t, validateTrafficManagement(tm), "subset v1 must have labels to select pods")
+}
@@ -135,6 +135,8 @@ type Service struct {
 	MeshEnable      *bool                    `yaml:"mesh_enable,omitempty" json:"mesh_enable,omitempty"`
 	TrafficSecurity TrafficSecurity          `yaml:"traffic_security,omitempty" json:"traffic_security,omitempty"`
 	Endpoints       []Endpoint               `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+	// TrafficManagement only works when mesh_enable is true
+	TrafficManagement *TrafficManagement `yaml:"traffic_management,omitempty" json:"traffic_management,omitempty"`
 }
 
 type ServicePort struct {
@@ -453,6 +455,57 @@ type TrafficSecurity struct {
 	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
 }
 
+// TrafficManagement is the service mesh traffic settings of service, durations use go duration format, e.g. 500ms, 3s
+type TrafficManagement struct {
+	// Subsets are versions of service selected by labels, only one subset can have weight as a service runs as a single workload
+	Subsets []TrafficSubset `yaml:"subsets,omitempty" json:"subsets,omitempty"`
+	// Routes route requests to subset by headers, checked in order before weighted split
+	Routes []TrafficRoute `yaml:"routes,omitempty" json:"routes,omitempty"`
+	// Timeout of each request
+	Timeout        string                 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retries        *TrafficRetries        `yaml:"retries,omitempty" json:"retries,omitempty"`
+	Fault          *TrafficFault          `yaml:"fault,omitempty" json:"fault,omitempty"`
+	CircuitBreaker *TrafficCircuitBreaker `yaml:"circuit_breaker,omitempty" json:"circuit_breaker,omitempty"`
+}
+
+type TrafficSubset struct {
+	Name string `yaml:"name" json:"name"`
+	// Labels select pods of subset, pods are labeled by deployments.labels of service
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Weight is the percentage of traffic, must be 100 for the only weighted subset
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+type TrafficRoute struct {
+	// Headers match request headers, value supports prefix 'exact:', 'prefix:' and 'regex:', default is exact
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	Subset  string            `yaml:"subset" json:"subset"`
+}
+
+type TrafficRetries struct {
+	Attempts      int    `yaml:"attempts" json:"attempts"`
+	PerTryTimeout string `yaml:"per_try_timeout,omitempty" json:"per_try_timeout,omitempty"`
+	RetryOn       string `yaml:"retry_on,omitempty" json:"retry_on,omitempty"`
+}
+
+type TrafficFault struct {
+	Delay        string  `yaml:"delay,omitempty" json:"delay,omitempty"`
+	DelayPercent float64 `yaml:"delay_percent,omitempty" json:"delay_percent,omitempty"`
+	AbortStatus  int     `yaml:"abort_status,omitempty" json:"abort_status,omitempty"`
+	AbortPercent float64 `yaml:"abort_percent,omitempty" json:"abort_percent,omitempty"`
+}
+
+// TrafficCircuitBreaker ejects unhealthy instances by outlier detection and limits connections
+type TrafficCircuitBreaker struct {
+	Consecutive5xxErrors     int    `yaml:"consecutive_5xx_errors,omitempty" json:"consecutive_5xx_errors,omitempty"`
+	Interval                 string `yaml:"interval,omitempty" json:"interval,omitempty"`
+	BaseEjectionTime         string `yaml:"base_ejection_time,omitempty" json:"base_ejection_time,omitempty"`
+	MaxEjectionPercent       int    `yaml:"max_ejection_percent,omitempty" json:"max_ejection_percent,omitempty"`
+	MaxConnections           int    `yaml:"max_connections,omitempty" json:"max_connections,omitempty"`
+	HTTP1MaxPendingRequests  int    `yaml:"http1_max_pending_requests,omitempty" json:"http1_max_pending_requests,omitempty"`
+	MaxRequestsPerConnection int    `yaml:"max_requests_per_connection,omitempty" json:"max_requests_per_connection,omitempty"`
+}
+
 type Endpoint struct {
 	Domain      string           `yaml:"domain,omitempty" json:"domain,omitempty"`
 	Path        string           `yaml:"path,omitempty" json:"path,omitempty"`
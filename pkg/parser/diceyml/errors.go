@@ -35,6 +35,7 @@ var (
 	invalidAddonPlan           = errortype("invalid addon plan in yaml")
 	invalidImage               = errortype("invalid image defined in yaml")
 	invalidTrafficSecurityMode = errortype("invalid traffic security mode in yaml, must be 'https'")
+	invalidTrafficManagement   = errortype("invalid traffic management in yaml")
 	emptyEndpointDomain        = errortype("empty domain in endpoints")
 	invalidEndpointDomain      = errortype("invalid domain in endpoints")
 	invalidEndpointPath        = errortype("invalid path in endpoints, must start with '/'")
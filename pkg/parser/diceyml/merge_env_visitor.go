@@ -68,6 +68,9 @@ func (o *MergeEnvVisitor) VisitService(v DiceYmlVisitor, obj *Service) {
 	overrideIfNotZero(o.envObj.Services[o.currentService].Volumes, &obj.Volumes)
 	overrideIfNotZero(o.envObj.Services[o.currentService].DependsOn, &obj.DependsOn)
 	overrideIfNotZero(o.envObj.Services[o.currentService].Expose, &obj.Expose)
+	if o.envObj.Services[o.currentService].TrafficManagement != nil {
+		override(o.envObj.Services[o.currentService].TrafficManagement, &obj.TrafficManagement)
+	}
 }
 
 func (o *MergeEnvVisitor) VisitResources(v DiceYmlVisitor, obj *Resources) {
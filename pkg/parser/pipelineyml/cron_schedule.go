@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/cron"
+)
+
+const (
+	// cronExprSeparator 多个定时表达式之间的分隔符，规范化后统一使用 `; `
+	cronExprSeparator = ";"
+
+	// maxCronBlackoutSkips 跳过禁止窗口的最大次数，避免配置异常时死循环
+	maxCronBlackoutSkips = 1000
+)
+
+var cronBlackoutTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// CronSchedule 由多个定时表达式与禁止窗口组合而成的调度，实现 cron.Schedule
+type CronSchedule struct {
+	schedules []cron.Schedule
+	blackouts []cronWindow
+}
+
+type cronWindow struct {
+	start, end time.Time
+}
+
+// NewCronSchedule 解析定时配置与禁止窗口
+func NewCronSchedule(expr string, blackouts []*CronBlackout) (*CronSchedule, error) {
+	_, schedules, err := parseCronExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := parseCronBlackouts(blackouts)
+	if err != nil {
+		return nil, err
+	}
+	return &CronSchedule{schedules: schedules, blackouts: windows}, nil
+}
+
+// Next 返回 t 之后最早的、不在禁止窗口内的触发时间；找不到返回零值
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxCronBlackoutSkips; i++ {
+		next := s.next(t)
+		if next.IsZero() {
+			return next
+		}
+		end, blocked := s.blackoutEnd(next)
+		if !blocked {
+			return next
+		}
+		// Next 返回严格大于参数的时间，回退 1s 使窗口结束时刻本身可被调度
+		t = end.Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+// next 返回所有表达式中最早的下次触发时间
+func (s *CronSchedule) next(t time.Time) time.Time {
+	var earliest time.Time
+	for _, schedule := range s.schedules {
+		next := schedule.Next(t)
+		if next.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	return earliest
+}
+
+// blackoutEnd 若 t 处于禁止窗口内，返回最晚结束的窗口结束时间
+func (s *CronSchedule) blackoutEnd(t time.Time) (time.Time, bool) {
+	var end time.Time
+	for _, w := range s.blackouts {
+		if !t.Before(w.start) && t.Before(w.end) && w.end.After(end) {
+			end = w.end
+		}
+	}
+	return end, !end.IsZero()
+}
+
+// parseCronExpr 解析以 `;` 或换行分隔的多个定时表达式，返回规范化后的表达式
+func parseCronExpr(expr string) (string, []cron.Schedule, error) {
+	var (
+		normalized []string
+		schedules  []cron.Schedule
+	)
+	for _, item := range strings.FieldsFunc(expr, func(r rune) bool {
+		return r == '\n' || r == ';'
+	}) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		schedule, normalizedItem, err := parseSingleCronExpr(item)
+		if err != nil {
+			return "", nil, err
+		}
+		normalized = append(normalized, normalizedItem)
+		schedules = append(schedules, schedule)
+	}
+	if len(schedules) == 0 {
+		return "", nil, errors.Errorf("invalid cron: %q, no schedule found", expr)
+	}
+	return strings.Join(normalized, cronExprSeparator+" "), schedules, nil
+}
+
+// parseSingleCronExpr 解析单个表达式，支持 5(标准)、6(带秒)、7(带年，年会被忽略) 个字段
+func parseSingleCronExpr(expr string) (cron.Schedule, string, error) {
+	var tzPrefix string
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		fields := strings.SplitN(expr, " ", 2)
+		tzPrefix = fields[0] + " "
+		expr = ""
+		if len(fields) > 1 {
+			expr = strings.TrimSpace(fields[1])
+		}
+	}
+
+	var (
+		schedule cron.Schedule
+		err      error
+	)
+	switch fields := strings.Fields(expr); len(fields) {
+	case 7:
+		expr = strings.Join(fields[:len(fields)-1], " ")
+		fallthrough
+	case 6:
+		schedule, err = cron.Parse(tzPrefix + expr)
+	default:
+		schedule, err = cron.ParseStandard(tzPrefix + expr)
+	}
+	if err != nil {
+		return nil, "", errors.Errorf("invalid cron: %q, err: %v", tzPrefix+expr, err)
+	}
+	return schedule, tzPrefix + expr, nil
+}
+
+func parseCronBlackouts(blackouts []*CronBlackout) ([]cronWindow, error) {
+	var windows []cronWindow
+	for i, b := range blackouts {
+		if b == nil {
+			continue
+		}
+		loc := time.Local
+		if b.Timezone != "" {
+			var err error
+			if loc, err = time.LoadLocation(b.Timezone); err != nil {
+				return nil, errors.Errorf("invalid cron blackout[%d] timezone: %s, err: %v", i, b.Timezone, err)
+			}
+		}
+		start, _, err := parseCronBlackoutTime(b.Start, loc)
+		if err != nil {
+			return nil, errors.Errorf("invalid cron blackout[%d] start: %v", i, err)
+		}
+		end, dateOnly, err := parseCronBlackoutTime(b.End, loc)
+		if err != nil {
+			return nil, errors.Errorf("invalid cron blackout[%d] end: %v", i, err)
+		}
+		// 仅日期的 end 包含当天
+		if dateOnly {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !end.After(start) {
+			return nil, errors.Errorf("invalid cron blackout[%d]: end must be after start", i)
+		}
+		windows = append(windows, cronWindow{start: start, end: end})
+	}
+	return windows, nil
+}
+
+func parseCronBlackoutTime(value string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false, fmt.Errorf("empty time")
+	}
+	for _, layout := range cronBlackoutTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("unsupported time format: %s", value)
+}
+
+// ConvertCronBlackouts 转换为 apistructs 结构，用于存储
+func ConvertCronBlackouts(blackouts []*CronBlackout) []apistructs.CronBlackout {
+	var result []apistructs.CronBlackout
+	for _, b := range blackouts {
+		if b == nil {
+			continue
+		}
+		result = append(result, apistructs.CronBlackout{Start: b.Start, End: b.End, Timezone: b.Timezone})
+	}
+	return result
+}
+
+// ConvertAPICronBlackouts 将 apistructs 结构转换回 CronBlackout
+func ConvertAPICronBlackouts(blackouts []apistructs.CronBlackout) []*CronBlackout {
+	var result []*CronBlackout
+	for _, b := range blackouts {
+		result = append(result, &CronBlackout{Start: b.Start, End: b.End, Timezone: b.Timezone})
+	}
+	return result
+}
@@ -37,8 +37,11 @@ type Spec struct {
 
 	Envs map[string]string `yaml:"envs,omitempty"`
 
-	Cron            string           `yaml:"cron,omitempty"`
+	Cron            string           `yaml:"cron,omitempty"` // 支持多个以 `;` 或换行分隔的表达式，可用 `CRON_TZ=<IANA 时区>` 前缀指定时区
 	CronCompensator *CronCompensator `yaml:"cron_compensator,omitempty"`
+	CronBlackouts   []*CronBlackout  `yaml:"cron_blackouts,omitempty"` // 定时禁止触发窗口
+
+	Concurrency *ConcurrencyConfig `yaml:"concurrency,omitempty"` // 并发组，同组新流水线会取代旧流水线
 
 	Stages []*Stage `yaml:"stages"`
 
@@ -96,11 +99,12 @@ type Stage struct {
 }
 
 type PipelineParam struct {
-	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`         // 名称
-	Required bool        `json:"required,omitempty" yaml:"required,omitempty"` // 是否必须
-	Default  interface{} `json:"default,omitempty" yaml:"default,omitempty"`   // 默认值
-	Desc     string      `json:"desc,omitempty" yaml:"desc,omitempty"`         // 描述
-	Type     string      `json:"type,omitempty" yaml:"type,omitempty"`         // 类型
+	Name     string        `json:"name,omitempty" yaml:"name,omitempty"`         // 名称
+	Required bool          `json:"required,omitempty" yaml:"required,omitempty"` // 是否必须
+	Default  interface{}   `json:"default,omitempty" yaml:"default,omitempty"`   // 默认值
+	Desc     string        `json:"desc,omitempty" yaml:"desc,omitempty"`         // 描述
+	Type     string        `json:"type,omitempty" yaml:"type,omitempty"`         // 类型
+	Options  []interface{} `json:"options,omitempty" yaml:"options,omitempty"`   // enum 类型的可选值
 }
 
 type PipelineOutput struct {
@@ -132,6 +136,8 @@ type Action struct {
 
 	Caches []ActionCache `yaml:"caches,omitempty"` // action 构建缓存
 
+	Artifacts []ActionArtifact `yaml:"artifacts,omitempty"` // action 执行结束后上传的制品
+
 	SnippetConfig *SnippetConfig `yaml:"snippet_config,omitempty"` // snippet 类型的 action 的配置
 
 	If string `yaml:"if,omitempty"` // 条件执行
@@ -190,6 +196,20 @@ type ActionCache struct {
 	Path string `yaml:"path,omitempty"` // 指定那个目录被缓存, 只能是由 / 开始的绝对路径
 }
 
+type ActionArtifact struct {
+	Name     string   `yaml:"name,omitempty"`      // 制品名，同一个 action 内唯一，后续 action 通过 ${{ artifacts.alias.name }} 引用
+	Paths    []string `yaml:"paths,omitempty"`     // 需要上传的路径，相对路径基于 action 工作目录，支持通配符
+	ExpireIn string   `yaml:"expire_in,omitempty"` // 过期时间，例如 24h，为空使用默认值
+}
+
+func (a ActionArtifact) ToApiArtifact() apistructs.ActionArtifact {
+	return apistructs.ActionArtifact{
+		Name:     a.Name,
+		Paths:    a.Paths,
+		ExpireIn: a.ExpireIn,
+	}
+}
+
 type ActionType string
 type ActionAlias string
 
@@ -232,6 +252,22 @@ type CronCompensator struct {
 	StopIfLatterExecuted bool `yaml:"stop_if_latter_executed"`
 }
 
+// ConcurrencyConfig 并发组配置
+// 同一并发组内同时只运行一条流水线，新流水线进入时取消组内仍在排队的旧流水线；
+// CancelInProgress 为 true 时同时取消组内正在运行的旧流水线，否则等待其结束后再运行。
+type ConcurrencyConfig struct {
+	Group            string `yaml:"group"`                        // 组名，支持 ${{ pipeline.branch }} 等占位符
+	CancelInProgress bool   `yaml:"cancel_in_progress,omitempty"` // 是否取消运行中的旧流水线
+}
+
+// CronBlackout 定时禁止触发窗口 [start, end)，窗口内的定时触发会被跳过
+// 时间格式: 2006-01-02 / 2006-01-02 15:04 / 2006-01-02 15:04:05 / RFC3339；仅日期的 end 包含当天
+type CronBlackout struct {
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	Timezone string `yaml:"timezone,omitempty"` // IANA 时区，默认为服务所在时区
+}
+
 // indices:
 // 0: stage index
 // 1: action name or index inside a stage
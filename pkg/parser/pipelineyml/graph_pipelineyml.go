@@ -36,6 +36,13 @@ func ConvertGraphPipelineYmlContent(data []byte) ([]byte, error) {
 			StopIfLatterExecuted: frontendYmlSpec.CronCompensator.StopIfLatterExecuted,
 		}
 	}
+	s.CronBlackouts = ConvertAPICronBlackouts(frontendYmlSpec.CronBlackouts)
+	if frontendYmlSpec.Concurrency != nil {
+		s.Concurrency = &ConcurrencyConfig{
+			Group:            frontendYmlSpec.Concurrency.Group,
+			CancelInProgress: frontendYmlSpec.Concurrency.CancelInProgress,
+		}
+	}
 	s.Stages = make([]*Stage, 0)
 	for _, stage := range frontendYmlSpec.Stages {
 		actions := make([]typedActionMap, 0)
@@ -150,13 +157,20 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 		}
 	}
 	result := &apistructs.PipelineYml{
-		Version:     pipelineYml.Spec().Version,
-		Envs:        pipelineYml.Spec().Envs,
-		Cron:        pipelineYml.Spec().Cron,
-		NeedUpgrade: pipelineYml.needUpgrade,
-		Params:      pipelineParams,
-		Outputs:     pipelineOutputs,
-		On:          on,
+		Version:       pipelineYml.Spec().Version,
+		Envs:          pipelineYml.Spec().Envs,
+		Cron:          pipelineYml.Spec().Cron,
+		CronBlackouts: ConvertCronBlackouts(pipelineYml.Spec().CronBlackouts),
+		NeedUpgrade:   pipelineYml.needUpgrade,
+		Params:        pipelineParams,
+		Outputs:       pipelineOutputs,
+		On:            on,
+	}
+	if concurrency := pipelineYml.Spec().Concurrency; concurrency != nil {
+		result.Concurrency = &apistructs.PipelineConcurrency{
+			Group:            concurrency.Group,
+			CancelInProgress: concurrency.CancelInProgress,
+		}
 	}
 
 	if result.NeedUpgrade {
@@ -198,6 +212,10 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 					resultAction.Caches = resultActionCaches
 				}
 
+				for _, v := range action.Artifacts {
+					resultAction.Artifacts = append(resultAction.Artifacts, v.ToApiArtifact())
+				}
+
 				if action.SnippetConfig != nil {
 					resultAction.SnippetConfig = action.SnippetConfig.toApiSnippetConfig()
 				}
@@ -217,6 +235,7 @@ func toApiParam(pipelineInput *PipelineParam) (params *apistructs.PipelineParam)
 		Default:  pipelineInput.Default,
 		Desc:     pipelineInput.Desc,
 		Type:     pipelineInput.Type,
+		Options:  pipelineInput.Options,
 	}
 }
 
@@ -235,6 +254,7 @@ func toPipelineYamlParam(params *apistructs.PipelineParam) (pipelineInput *Pipel
 		Default:  params.Default,
 		Desc:     params.Desc,
 		Type:     params.Type,
+		Options:  params.Options,
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 //   ${{ configs.key }}
 //   ${{ dirs.preTaskName.fileName }}
 //   ${{ outputs.preTaskName.key }}
+//   ${{ artifacts.preTaskName.name }}
 //   ${{ params.key }}
 //   ${{ (echo hello world) }}
 var PhRe = regexp.MustCompile(`\${{[ ]{1}([^{}\s]+)[ ]{1}}}`) // [ ]{1} 强调前后均有且仅有一个空格
@@ -33,6 +33,7 @@ type PipelineYml struct {
 	aliasToCheckRefOp               []ActionAlias
 	refs                            Refs
 	outputs                         Outputs
+	artifacts                       Artifacts
 	allowMissingCustomScriptOutputs bool
 
 	// snippet
@@ -131,9 +132,11 @@ func New(b []byte, ops ...Option) (_ *PipelineYml, err error) {
 
 	y.s.Accept(NewCronVisitor())
 	y.s.Accept(NewTimeoutVisitor())
+	y.s.Accept(NewConcurrencyVisitor())
+	y.s.Accept(NewArtifactVisitor())
 
 	if len(y.aliasToCheckRefOp) > 0 {
-		y.s.Accept(NewRefOpVisitor(y.aliasToCheckRefOp, y.refs, y.outputs, y.allowMissingCustomScriptOutputs, y.globalSnippetConfigLabels))
+		y.s.Accept(NewRefOpVisitor(y.aliasToCheckRefOp, y.refs, y.outputs, y.artifacts, y.allowMissingCustomScriptOutputs, y.globalSnippetConfigLabels))
 	}
 
 	// 设置flatParams, 假如是render就放入loadPipelineTemplateToAction方法中了
@@ -179,6 +182,13 @@ func WithRefOpOutputs(outputs Outputs) Option {
 	}
 }
 
+// WithRefOpArtifacts 设置可用的 ref op artifacts
+func WithRefOpArtifacts(artifacts Artifacts) Option {
+	return func(y *PipelineYml) {
+		y.artifacts = artifacts
+	}
+}
+
 func WithRunParams(runParams []apistructs.PipelineRunParamWithValue) Option {
 	return func(y *PipelineYml) {
 		var polished []apistructs.PipelineRunParam
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var artifactNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+type ArtifactVisitor struct{}
+
+func NewArtifactVisitor() *ArtifactVisitor {
+	return &ArtifactVisitor{}
+}
+
+func (v *ArtifactVisitor) Visit(s *Spec) {
+	for stageIndex, stage := range s.Stages {
+		for _, typedActionMap := range stage.Actions {
+			for _, action := range typedActionMap {
+				names := make(map[string]struct{}, len(action.Artifacts))
+				for _, artifact := range action.Artifacts {
+					if err := validateArtifact(artifact); err != nil {
+						s.appendError(err, stageIndex, action.Alias)
+						continue
+					}
+					if _, ok := names[artifact.Name]; ok {
+						s.appendError(errors.Errorf("duplicate artifact name: %s", artifact.Name), stageIndex, action.Alias)
+						continue
+					}
+					names[artifact.Name] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+func validateArtifact(artifact ActionArtifact) error {
+	if !artifactNameRe.MatchString(artifact.Name) {
+		return errors.Errorf("invalid artifact name: %q, only letters, digits, '_', '.' and '-' are allowed", artifact.Name)
+	}
+	if len(artifact.Paths) == 0 {
+		return errors.Errorf("invalid artifact: %s, missing paths", artifact.Name)
+	}
+	if artifact.ExpireIn != "" {
+		d, err := time.ParseDuration(artifact.ExpireIn)
+		if err != nil || d <= 0 {
+			return errors.Errorf("invalid artifact: %s, invalid expire_in: %s", artifact.Name, artifact.ExpireIn)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactSample(t *testing.T) {
+	b, err := ioutil.ReadFile("./samples/pipeline_artifact.yml")
+	assert.NoError(t, err)
+
+	y, err := New(b,
+		WithAliasesToCheckRefOp(nil, "deploy"),
+		WithRefOpArtifacts(Artifacts{"build": {"app": "/.pipeline/container/artifacts/build/app"}}),
+	)
+	assert.NoError(t, err)
+	build, err := GetAction(y.Spec(), "build")
+	assert.NoError(t, err)
+	assert.Equal(t, []ActionArtifact{{Name: "app", Paths: []string{"target/*.jar"}, ExpireIn: "72h"}}, build.Artifacts)
+	deploy, err := GetAction(y.Spec(), "deploy")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ls /.pipeline/container/artifacts/build/app",
+		"ls /.pipeline/container/artifacts/build/app",
+	}, deploy.Commands)
+
+	// artifact not uploaded
+	_, err = New(b, WithAliasesToCheckRefOp(nil, "deploy"))
+	assert.Error(t, err)
+
+	graph, err := ConvertToGraphPipelineYml(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", graph.Stages[0][0].Artifacts[0].Name)
+}
+
+func TestArtifactVisitor(t *testing.T) {
+	newSpec := func(artifacts ...ActionArtifact) *Spec {
+		return &Spec{Stages: []*Stage{{Actions: []typedActionMap{{"custom-script": &Action{Alias: "a", Artifacts: artifacts}}}}}}
+	}
+
+	s := newSpec(ActionArtifact{Name: "app", Paths: []string{"target"}}, ActionArtifact{Name: "doc", Paths: []string{"doc"}, ExpireIn: "24h"})
+	s.Accept(NewArtifactVisitor())
+	assert.Len(t, s.errs, 0)
+
+	s = newSpec(ActionArtifact{Name: "app", Paths: []string{"target"}}, ActionArtifact{Name: "app", Paths: []string{"doc"}})
+	s.Accept(NewArtifactVisitor())
+	assert.Len(t, s.errs, 1)
+
+	s = newSpec(ActionArtifact{Name: "a/b", Paths: []string{"target"}}, ActionArtifact{Name: "c"}, ActionArtifact{Name: "d", Paths: []string{"d"}, ExpireIn: "7d"})
+	s.Accept(NewArtifactVisitor())
+	assert.Len(t, s.errs, 3)
+}
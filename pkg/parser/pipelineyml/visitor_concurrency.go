@@ -0,0 +1,39 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+)
+
+type ConcurrencyVisitor struct{}
+
+func NewConcurrencyVisitor() *ConcurrencyVisitor {
+	return &ConcurrencyVisitor{}
+}
+
+func (v *ConcurrencyVisitor) Visit(s *Spec) {
+	if s.Concurrency == nil {
+		return
+	}
+	if s.Concurrency.Group == "" {
+		s.appendError(errors.New("invalid concurrency: missing group"))
+		return
+	}
+	if invalidPhs := pexpr.FindInvalidPlaceholders(s.Concurrency.Group); len(invalidPhs) > 0 {
+		s.appendError(errors.Errorf("invalid concurrency group: %s, found invalid placeholders: %v", s.Concurrency.Group, invalidPhs))
+	}
+}
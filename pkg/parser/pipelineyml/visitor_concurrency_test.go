@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencySample(t *testing.T) {
+	b, err := ioutil.ReadFile("./samples/pipeline_concurrency.yml")
+	assert.NoError(t, err)
+	y, err := New(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy-${{ pipeline.branch }}-${{ pipeline.env }}", y.Spec().Concurrency.Group)
+	assert.True(t, y.Spec().Concurrency.CancelInProgress)
+
+	graph, err := ConvertToGraphPipelineYml(b)
+	assert.NoError(t, err)
+	assert.Equal(t, y.Spec().Concurrency.Group, graph.Concurrency.Group)
+}
+
+func TestConcurrencyVisitor(t *testing.T) {
+	s := &Spec{Concurrency: &ConcurrencyConfig{}}
+	s.Accept(NewConcurrencyVisitor())
+	assert.Len(t, s.errs, 1)
+
+	s = &Spec{Concurrency: &ConcurrencyConfig{Group: "${{pipeline.branch}}"}}
+	s.Accept(NewConcurrencyVisitor())
+	assert.Len(t, s.errs, 1)
+
+	s = &Spec{Concurrency: &ConcurrencyConfig{Group: "${{ pipeline.branch }}"}}
+	s.Accept(NewConcurrencyVisitor())
+	assert.Len(t, s.errs, 0)
+}
@@ -14,10 +14,7 @@
 package pipelineyml
 
 import (
-	"strings"
 	"time"
-
-	"github.com/erda-project/erda/pkg/cron"
 )
 
 const (
@@ -36,6 +33,7 @@ type CronVisitor struct {
 	cronStartTime *time.Time
 	cronEndTime   *time.Time
 	count         int
+	blackouts     []*CronBlackout
 
 	// result
 	nextTimes []time.Time
@@ -70,6 +68,13 @@ func WithListNextScheduleCount(count int) CronVisitorOption {
 	}
 }
 
+// WithCronBlackouts 指定禁止触发窗口，用于 ListNextCronTime
+func WithCronBlackouts(blackouts []*CronBlackout) CronVisitorOption {
+	return func(v *CronVisitor) {
+		v.blackouts = blackouts
+	}
+}
+
 func (v *CronVisitor) Visit(s *Spec) {
 	if s.Cron == "" {
 		s.CronCompensator = nil
@@ -79,25 +84,19 @@ func (v *CronVisitor) Visit(s *Spec) {
 
 	v.isCron = true
 
-	var (
-		schedule cron.Schedule
-		err      error
-	)
-
-	switch fields := strings.Fields(s.Cron); len(fields) {
-	case 7:
-		fieldsWithoutYear := fields[:len(fields)-1]
-		s.Cron = strings.Join(fieldsWithoutYear, " ")
-		fallthrough
-	case 6:
-		schedule, err = cron.Parse(s.Cron)
-	default:
-		schedule, err = cron.ParseStandard(s.Cron)
+	normalized, schedules, err := parseCronExpr(s.Cron)
+	if err != nil {
+		s.appendError(err)
+		return
 	}
+	s.Cron = normalized
+
+	blackouts, err := parseCronBlackouts(s.CronBlackouts)
 	if err != nil {
 		s.appendError(err)
 		return
 	}
+	schedule := &CronSchedule{schedules: schedules, blackouts: blackouts}
 
 	now := time.Unix(time.Now().Unix(), 0)
 	scheduleFrom := now
@@ -113,6 +112,9 @@ func (v *CronVisitor) Visit(s *Spec) {
 			break
 		}
 		nextTime := schedule.Next(scheduleFrom)
+		if nextTime.IsZero() {
+			break
+		}
 		if v.cronEndTime != nil && (*v.cronEndTime).Before(nextTime) {
 			break
 		}
@@ -127,8 +129,8 @@ func (v *CronVisitor) Visit(s *Spec) {
 }
 
 func ListNextCronTime(cronExpr string, ops ...CronVisitorOption) ([]time.Time, error) {
-	s := Spec{Cron: cronExpr}
 	v := NewCronVisitor(ops...)
+	s := Spec{Cron: cronExpr, CronBlackouts: v.blackouts}
 	s.Accept(v)
 	return v.nextTimes, s.mergeErrors()
 }
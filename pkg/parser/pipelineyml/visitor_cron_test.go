@@ -15,6 +15,7 @@ package pipelineyml
 
 import (
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"testing"
 	"time"
@@ -102,3 +103,63 @@ func TestListNextCronTime2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, len(nextTimes) == 9)
 }
+
+func TestListNextCronTimeMultiSchedule(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	nextTimes, err := ListNextCronTime("CRON_TZ=Asia/Shanghai 0 2 * * *; CRON_TZ=America/New_York 0 2 * * *",
+		WithCronStartEndTime(&start, nil), WithListNextScheduleCount(3))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 5, 1, 6, 0, 0, 0, time.UTC),  // 02:00 New York (EDT)
+		time.Date(2021, 5, 1, 18, 0, 0, 0, time.UTC), // 02:00 Shanghai
+		time.Date(2021, 5, 2, 6, 0, 0, 0, time.UTC),
+	}, utcTimes(nextTimes))
+
+	_, err = ListNextCronTime("0 2 * * *\nCRON_TZ=Nowhere/Bad 0 2 * * *")
+	assert.Error(t, err)
+}
+
+func TestListNextCronTimeBlackout(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	blackouts := []*CronBlackout{
+		{Start: "2021-05-02", End: "2021-05-03", Timezone: "UTC"},
+	}
+	nextTimes, err := ListNextCronTime("CRON_TZ=UTC 0 2 * * *",
+		WithCronStartEndTime(&start, nil), WithListNextScheduleCount(2), WithCronBlackouts(blackouts))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 5, 1, 2, 0, 0, 0, time.UTC),
+		time.Date(2021, 5, 4, 2, 0, 0, 0, time.UTC),
+	}, utcTimes(nextTimes))
+
+	_, err = ListNextCronTime(everyMin, WithCronBlackouts([]*CronBlackout{{Start: "2021-05-03", End: "2021-05-02"}}))
+	assert.Error(t, err)
+}
+
+func TestCronVisitorNormalize(t *testing.T) {
+	s := Spec{Cron: "0 0 2 * * ? *\n  CRON_TZ=UTC 0 3 * * *  \n"}
+	s.Accept(NewCronVisitor())
+	assert.NoError(t, s.mergeErrors())
+	assert.Equal(t, "0 0 2 * * ?; CRON_TZ=UTC 0 3 * * *", s.Cron)
+}
+
+func utcTimes(times []time.Time) []time.Time {
+	result := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		result = append(result, t.UTC())
+	}
+	return result
+}
+
+func TestMultiCronSample(t *testing.T) {
+	b, err := ioutil.ReadFile("./samples/pipeline_multi_cron.yml")
+	assert.NoError(t, err)
+	y, err := New(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "CRON_TZ=Asia/Shanghai 0 2 * * *; CRON_TZ=Europe/Berlin 0 2 * * *; CRON_TZ=America/New_York 0 2 * * *", y.Spec().Cron)
+	assert.Len(t, y.Spec().CronBlackouts, 1)
+
+	graph, err := ConvertToGraphPipelineYml(b)
+	assert.NoError(t, err)
+	assert.Len(t, graph.CronBlackouts, 1)
+}
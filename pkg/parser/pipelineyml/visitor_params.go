@@ -14,6 +14,7 @@
 package pipelineyml
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -64,6 +65,10 @@ func ReplacePipelineParams(pipeline string, params map[string]interface{}) strin
 			replaceStr = strconv.FormatBool(v.(bool))
 		case string:
 			replaceStr = v.(string)
+		case []interface{}:
+			// list 类型参数渲染为 yaml flow 格式
+			b, _ := json.Marshal(v)
+			replaceStr = string(b)
 		default:
 			replaceStr = fmt.Sprintf("%v", v)
 		}
@@ -83,3 +88,19 @@ func ReplacePipelineParams(pipeline string, params map[string]interface{}) strin
 	}
 	return pipeline
 }
+
+// CheckTypedParamValue 校验声明了类型的参数值，未声明类型或 string 类型不校验
+// 值中仍包含未替换的占位符时跳过校验
+func CheckTypedParamValue(param *PipelineParam, value interface{}) error {
+	if param.Type == "" || param.Type == apistructs.PipelineParamStringType || value == nil {
+		return nil
+	}
+	if s, ok := value.(string); ok && strings.Contains(s, expression.OldLeftPlaceholder) {
+		return nil
+	}
+	typedParam := toApiParam(param)
+	if _, err := typedParam.CheckValue(value); err != nil {
+		return fmt.Errorf("param %s: %v", param.Name, err)
+	}
+	return nil
+}
@@ -115,3 +115,11 @@ func TestFloat64(t *testing.T) {
 	fmt.Println(strconv.FormatFloat(1.0, 'f', -1, 64))
 	fmt.Println(strconv.FormatFloat(11.1111, 'f', -1, 64))
 }
+
+func TestCheckTypedParamValue(t *testing.T) {
+	assert.NoError(t, CheckTypedParamValue(&PipelineParam{Name: "a"}, []interface{}{"x"}))
+	assert.NoError(t, CheckTypedParamValue(&PipelineParam{Name: "a", Type: "number"}, "${{ outputs.build.count }}"))
+	assert.NoError(t, CheckTypedParamValue(&PipelineParam{Name: "a", Type: "number"}, "3"))
+	assert.Error(t, CheckTypedParamValue(&PipelineParam{Name: "a", Type: "number"}, "three"))
+	assert.Error(t, CheckTypedParamValue(&PipelineParam{Name: "a", Type: "enum", Options: []interface{}{"dev"}}, "prod"))
+}
@@ -25,7 +25,8 @@ import (
 )
 
 const (
-	RefOpOutput = "OUTPUT"
+	RefOpOutput   = "OUTPUT"
+	RefOpArtifact = "ARTIFACT"
 )
 
 // RefOp split from ${alias:OPERATION:key}
@@ -43,6 +44,7 @@ type RefOp struct {
 
 type Refs map[string]string
 type Outputs map[ActionAlias]map[string]string
+type Artifacts map[ActionAlias]map[string]string // alias -> artifact name -> 容器内路径
 
 type HandleResult struct {
 	Errs  []error
@@ -69,13 +71,16 @@ type RefOpVisitor struct {
 	availableOutputs                Outputs
 	allowMissingCustomScriptOutputs bool
 
+	// ARTIFACT
+	availableArtifacts Artifacts
+
 	// result
 	result HandleResult
 }
 
 // commitDetail 用作 snippet 校验 outputs
 // bdl 用作 snippet 校验 outputs
-func NewRefOpVisitor(aliases []ActionAlias, availableRefs Refs, availableOutputs Outputs, allowMissingCustomScriptOutputs bool, globalSnippetConfigLabels map[string]string) *RefOpVisitor {
+func NewRefOpVisitor(aliases []ActionAlias, availableRefs Refs, availableOutputs Outputs, availableArtifacts Artifacts, allowMissingCustomScriptOutputs bool, globalSnippetConfigLabels map[string]string) *RefOpVisitor {
 	aliasMap := make(map[ActionAlias]struct{})
 	for _, alias := range aliases {
 		aliasMap[alias] = struct{}{}
@@ -88,6 +93,8 @@ func NewRefOpVisitor(aliases []ActionAlias, availableRefs Refs, availableOutputs
 		availableOutputs:                availableOutputs,
 		allowMissingCustomScriptOutputs: allowMissingCustomScriptOutputs,
 		globalSnippetConfigLabels:       globalSnippetConfigLabels,
+
+		availableArtifacts: availableArtifacts,
 	}
 }
 
@@ -154,6 +161,14 @@ func (v *RefOpVisitor) handleOneParamOrCmdV2(ori string) string {
 			refOp.Op = RefOpOutput
 			refOp.Key = ss[2]
 			return v.handleOneRefOp(refOp)
+		case expression.Artifacts:
+			// - artifacts.alias.name
+			if len(ss) < 3 {
+				return refOp.Ori
+			}
+			refOp.Op = RefOpArtifact
+			refOp.Key = ss[2]
+			return v.handleOneRefOp(refOp)
 		case expression.Random:
 			typeValue := ss[1]
 			value := apitestsv2.MockValue(typeValue)
@@ -206,6 +221,13 @@ func (v *RefOpVisitor) handleAction(action *indexedAction, handler func(ori stri
 		}
 	}
 
+	// artifacts, 将 paths 中的 ${git-checkout} 转化为实际地址
+	for i := range action.Artifacts {
+		for j := range action.Artifacts[i].Paths {
+			action.Artifacts[i].Paths[j] = handler(action.Artifacts[i].Paths[j])
+		}
+	}
+
 	// if
 	if action.If != "" {
 		condition := expression.ReplacePlaceholder(action.If)
@@ -316,8 +338,10 @@ func (v *RefOpVisitor) handleOneRefOp(refOp RefOp) (replaced string) {
 	switch refOp.Op {
 	case RefOpOutput:
 		return v.handleOneRefOpOutput(refOp)
+	case RefOpArtifact:
+		return v.handleOneRefOpArtifact(refOp)
 	default:
-		v.result.AppendError(fmt.Errorf("%q, invalid operation [%s], only support [%s] now", refOp.Ori, refOp.Op, RefOpOutput))
+		v.result.AppendError(fmt.Errorf("%q, invalid operation [%s], only support [%s, %s] now", refOp.Ori, refOp.Op, RefOpOutput, RefOpArtifact))
 		return
 	}
 }
@@ -349,6 +373,29 @@ func (v *RefOpVisitor) handleOneRefOpOutput(refOp RefOp) (replaced string) {
 	return
 }
 
+// handleOneRefOpArtifact handle ${alias:ARTIFACT:name}
+func (v *RefOpVisitor) handleOneRefOpArtifact(refOp RefOp) (replaced string) {
+	replaced = refOp.Ori
+
+	// found artifact, return container path
+	if v.availableArtifacts[ActionAlias(refOp.Ref)] != nil {
+		if p, ok := v.availableArtifacts[ActionAlias(refOp.Ref)][refOp.Key]; ok {
+			return p
+		}
+	}
+
+	// not found
+	if refOp.RefStageIndex < refOp.CurrentStageIndex {
+		v.result.AppendError(fmt.Errorf("%q, action %q doesn't have artifact %q or it has expired", refOp.Ori, refOp.Ref, refOp.Key))
+	} else if refOp.RefStageIndex == refOp.CurrentStageIndex {
+		v.result.AppendError(fmt.Errorf("%q, cannot reference parallel action %q", refOp.Ori, refOp.Ref))
+	} else {
+		v.result.AppendError(fmt.Errorf("%q, cannot reference not-executed action %q", refOp.Ori, refOp.Ref))
+	}
+
+	return
+}
+
 func (v *RefOpVisitor) getStageIndex(namespace string) (stageIndex int, isAlias bool, isNamespace bool) {
 	stageIndex, isAlias, isNamespace = -1, false, false
 	for _, action := range v.allActions {
@@ -54,10 +54,14 @@ func HandleSnippetConfigLabel(snippetConfig *SnippetConfig, globalSnippetConfigL
 		scopeID = "0"
 	}
 
+	snippetConfig.Labels[apistructs.LabelDiceSnippetScopeID] = scopeID
+
+	// chooseVersion 可以是具体版本，也可以是 semver 约束，如 ^1.2、~1.2.3，由 dicehub 解析
 	version := snippetConfig.Labels[apistructs.LabelChooseSnippetVersion]
 	if version == "" {
 		version = "latest"
 	}
+	snippetConfig.Labels[apistructs.LabelChooseSnippetVersion] = version
 
 	return *snippetConfig
 }
@@ -67,8 +71,10 @@ func GetParamDefaultValue(paramType string) interface{} {
 	switch paramType {
 	case apistructs.PipelineParamStringType, apistructs.PipelineParamIntType:
 		return ""
-	case apistructs.PipelineParamBoolType:
+	case apistructs.PipelineParamBoolType, apistructs.PipelineParamBoolAliasType:
 		return "false"
+	case apistructs.PipelineParamListType:
+		return []interface{}{}
 	}
 	return ""
 }
@@ -114,7 +120,7 @@ func DoRenderTemplateHandler(params map[string]interface{}, templateAction *apis
 
 		template := replaceOutput(pipelineYmlStr, aliasMap)
 
-		outputs, err := getTemplateOutputs(templateAction, alias)
+		outputs, err := getTemplateOutputs(templateAction, alias, params)
 		if err != nil {
 			return "", nil, err
 		}
@@ -147,30 +153,54 @@ func setDefaultValue(specYaml *apistructs.PipelineTemplateSpec, params map[strin
 	paramsList := specYaml.Params
 	for _, v := range paramsList {
 		runValue, ok := params[v.Name]
+		// 显式传空值的必填参数报错，未传值时与之前一致使用类型零值
 		if runValue == nil && v.Default == nil && v.Required && ok {
 			return fmt.Errorf("params %s is required", v.Name)
 		}
 
 		if runValue == nil && v.Default != nil {
-			params[v.Name] = v.Default
+			runValue = v.Default
 		}
 
-		if runValue == nil && v.Default == nil {
+		// 未传值且无默认值，使用类型零值，不做类型校验
+		if runValue == nil {
 			params[v.Name] = GetParamDefaultValue(v.Type)
+			continue
 		}
 
-		if params[v.Name] == nil {
-			return fmt.Errorf("params %s is required", v.Name)
+		value, err := v.CheckValue(runValue)
+		if err != nil {
+			return fmt.Errorf("params %s: %v", v.Name, err)
 		}
+		params[v.Name] = value
 	}
 	return nil
 }
 
-func getTemplateOutputs(action *apistructs.PipelineTemplateSpec, alias string) ([]apistructs.SnippetFormatOutputs, error) {
+func getTemplateOutputs(action *apistructs.PipelineTemplateSpec, alias string, params map[string]interface{}) ([]apistructs.SnippetFormatOutputs, error) {
 
 	outputs := action.Outputs
 	var result []apistructs.SnippetFormatOutputs
 	for _, v := range outputs {
+		// 计算输出直接替换为渲染后的值
+		if v.Computed != "" {
+			value, err := doFormatAndReplaceValue(v.Computed, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute snippet output %s, err: %v", v.Name, err)
+			}
+			result = append(result,
+				apistructs.SnippetFormatOutputs{
+					PreOutputName:   fmt.Sprintf("%s%s:%s:%s%s", expression.OldLeftPlaceholder, alias, RefOpOutput, v.Name, expression.OldRightPlaceholder),
+					AfterOutputName: value,
+				},
+				apistructs.SnippetFormatOutputs{
+					PreOutputName:   fmt.Sprintf("%s %s.%s.%s %s", expression.LeftPlaceholder, expression.Outputs, alias, v.Name, expression.RightPlaceholder),
+					AfterOutputName: value,
+				},
+			)
+			continue
+		}
+
 		if v.Ref == "" {
 			return nil, errors.New(fmt.Sprintf(" error to format snippet output, output %s ref is empty", v.Name))
 		}
@@ -211,7 +241,13 @@ func getTemplateOutputs(action *apistructs.PipelineTemplateSpec, alias string) (
 
 func checkParams(specYaml *apistructs.PipelineTemplateSpec, params map[string]interface{}) error {
 	paramsList := specYaml.Params
+	listParams := make(map[string]struct{})
 	for _, v := range paramsList {
+		// 未声明类型的参数不限制类型
+		if v.Type == "" || v.Type == apistructs.PipelineParamListType {
+			listParams[v.Name] = struct{}{}
+		}
+
 		if v.Required == false {
 			continue
 		}
@@ -230,6 +266,11 @@ func checkParams(specYaml *apistructs.PipelineTemplateSpec, params map[string]in
 		switch typ.Kind() {
 		case reflect.String, reflect.Int, reflect.Float32, reflect.Float64, reflect.Bool:
 			continue
+		case reflect.Slice, reflect.Map:
+			if _, ok := listParams[name]; ok {
+				continue
+			}
+			return errors.New(fmt.Sprintf(" param %s value type %v not support ", name, typ.Kind()))
 		default:
 			return errors.New(fmt.Sprintf(" param %s value type %v not support ", name, typ.Kind()))
 		}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pipelineyml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestDoRenderTemplateTypedParams(t *testing.T) {
+	newSpec := func() *apistructs.PipelineTemplateSpec {
+		return &apistructs.PipelineTemplateSpec{
+			Name: "deploy",
+			Params: []*apistructs.PipelineParam{
+				{Name: "image", Type: apistructs.PipelineParamStringType, Required: true},
+				{Name: "replicas", Type: apistructs.PipelineParamNumberType, Default: 1},
+				{Name: "debug", Type: apistructs.PipelineParamBoolType, Default: false},
+				{Name: "env", Type: apistructs.PipelineParamEnumType, Options: []interface{}{"dev", "prod"}, Default: "dev"},
+				{Name: "hosts", Type: apistructs.PipelineParamListType},
+			},
+			Outputs: []*apistructs.PipelineOutput{
+				{Name: "target", Computed: "{{ image }}@{{ env }}"},
+			},
+			Template: `version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          commands:
+            - echo {{ image }} {{ replicas }} {{ debug }} {{ hosts|join:"," }}
+`,
+		}
+	}
+
+	yml, outputs, err := DoRenderTemplateWithFormat(map[string]interface{}{
+		"image":    "nginx",
+		"replicas": "3",
+		"debug":    "true",
+		"env":      "prod",
+		"hosts":    []interface{}{"a", "b"},
+	}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.NoError(t, err)
+	assert.Contains(t, yml, `echo nginx 3 true a,b`)
+	assert.Contains(t, outputs, apistructs.SnippetFormatOutputs{
+		PreOutputName:   "${{ outputs.deploy.target }}",
+		AfterOutputName: "nginx@prod",
+	})
+
+	// required
+	_, _, err = DoRenderTemplateWithFormat(map[string]interface{}{"image": nil}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.Error(t, err)
+
+	// 未传值的必填参数使用类型零值
+	yml, _, err = DoRenderTemplateWithFormat(map[string]interface{}{}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.NoError(t, err)
+	assert.Contains(t, yml, `echo  1 False`)
+
+	// number
+	_, _, err = DoRenderTemplateWithFormat(map[string]interface{}{"image": "nginx", "replicas": "three"}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.Error(t, err)
+
+	// enum
+	_, _, err = DoRenderTemplateWithFormat(map[string]interface{}{"image": "nginx", "env": "test"}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.Error(t, err)
+
+	// list
+	_, _, err = DoRenderTemplateWithFormat(map[string]interface{}{"image": "nginx", "hosts": "a"}, newSpec(), "deploy", apistructs.TemplateVersionV2)
+	assert.Error(t, err)
+}
+
+func TestDoRenderTemplateUntypedParams(t *testing.T) {
+	spec := &apistructs.PipelineTemplateSpec{
+		Name: "deploy",
+		Params: []*apistructs.PipelineParam{
+			{Name: "image", Default: "nginx"},
+			{Name: "hosts", Default: []interface{}{"a", "b"}},
+		},
+		Template: `version: "1.1"
+stages:
+  - stage:
+      - custom-script:
+          commands:
+            - echo {{ image }} {{ hosts|join:"," }}
+`,
+	}
+	assert.NoError(t, spec.Check())
+	yml, _, err := DoRenderTemplateWithFormat(map[string]interface{}{}, spec, "deploy", apistructs.TemplateVersionV2)
+	assert.NoError(t, err)
+	assert.Contains(t, yml, `echo nginx a,b`)
+}
+
+func TestHandleSnippetConfigLabel(t *testing.T) {
+	cfg := HandleSnippetConfigLabel(&SnippetConfig{Name: "deploy", Source: "dice"}, nil)
+	assert.Equal(t, "0", cfg.Labels[apistructs.LabelDiceSnippetScopeID])
+	assert.Equal(t, "latest", cfg.Labels[apistructs.LabelChooseSnippetVersion])
+
+	cfg = HandleSnippetConfigLabel(&SnippetConfig{Name: "deploy", Source: "dice",
+		Labels: map[string]string{apistructs.LabelChooseSnippetVersion: "^1.2"}}, nil)
+	assert.Equal(t, "^1.2", cfg.Labels[apistructs.LabelChooseSnippetVersion])
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"encoding/xml"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser/types"
+)
+
+type coberturaReport struct {
+	XMLName  xml.Name           `xml:"coverage"`
+	Packages []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name     string          `xml:"name,attr"`
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr"`
+}
+
+// e.g. condition-coverage="50% (1/2)"
+var conditionCoverageRegexp = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+// IngestCobertura will parse the given Cobertura XML data.
+// Rates are recalculated from line hits rather than trusting the report
+// attributes, so reports merged from several runs stay consistent.
+func IngestCobertura(data []byte) (*apistructs.TestCoverage, error) {
+	var report coberturaReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	cov := &apistructs.TestCoverage{Format: types.Cobertura.TPValue()}
+	for _, p := range report.Packages {
+		// several classes may share one file, e.g. inner classes
+		files := make(map[string]map[int]int64)
+		for _, c := range p.Classes {
+			name := c.Filename
+			if name == "" {
+				name = c.Name
+			}
+			if files[name] == nil {
+				files[name] = make(map[int]int64)
+			}
+			for _, l := range c.Lines {
+				if l.Hits > files[name][l.Number] {
+					files[name][l.Number] = l.Hits
+				} else if _, ok := files[name][l.Number]; !ok {
+					files[name][l.Number] = l.Hits
+				}
+				if l.Branch {
+					if m := conditionCoverageRegexp.FindStringSubmatch(l.ConditionCoverage); len(m) == 3 {
+						covered, _ := strconv.ParseInt(m[1], 10, 64)
+						valid, _ := strconv.ParseInt(m[2], 10, 64)
+						cov.BranchesCovered += covered
+						cov.BranchesValid += valid
+					}
+				}
+			}
+		}
+
+		pkg := &apistructs.TestCoveragePackage{Name: p.Name}
+		for name, lines := range files {
+			f := &apistructs.TestCoverageFile{Name: name}
+			for _, hits := range lines {
+				f.LinesValid++
+				if hits > 0 {
+					f.LinesCovered++
+				}
+			}
+			f.LineRate = rate(f.LinesCovered, f.LinesValid)
+			pkg.Files = append(pkg.Files, f)
+			pkg.LinesCovered += f.LinesCovered
+			pkg.LinesValid += f.LinesValid
+		}
+		sort.Slice(pkg.Files, func(i, j int) bool { return pkg.Files[i].Name < pkg.Files[j].Name })
+		pkg.LineRate = rate(pkg.LinesCovered, pkg.LinesValid)
+		cov.Packages = append(cov.Packages, pkg)
+		cov.LinesCovered += pkg.LinesCovered
+		cov.LinesValid += pkg.LinesValid
+	}
+	cov.LineRate = rate(cov.LinesCovered, cov.LinesValid)
+	cov.BranchRate = rate(cov.BranchesCovered, cov.BranchesValid)
+	return cov, nil
+}
+
+func rate(covered, valid int64) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return float64(covered) / float64(valid)
+}
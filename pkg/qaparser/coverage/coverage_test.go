@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const coberturaXML = `<?xml version="1.0" ?>
+<coverage line-rate="0.5" branch-rate="0.5" version="5.5">
+  <packages>
+    <package name="app">
+      <classes>
+        <class name="a.py" filename="app/a.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+            <line number="3" hits="2" branch="true" condition-coverage="50% (1/2)"/>
+            <line number="4" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+const goCoverProfile = `mode: set
+example.com/a/a.go:3.20,5.2 2 1
+example.com/a/a.go:7.20,9.2 1 0
+example.com/a/a.go:7.20,9.2 1 1
+example.com/b/b.go:3.20,5.2 3 0
+`
+
+func TestIngestCobertura(t *testing.T) {
+	cov, err := IngestCobertura([]byte(coberturaXML))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cov.LinesCovered)
+	assert.Equal(t, int64(4), cov.LinesValid)
+	assert.Equal(t, 0.5, cov.LineRate)
+	assert.Equal(t, 0.5, cov.BranchRate)
+	assert.Len(t, cov.Packages, 1)
+	assert.Equal(t, "app/a.py", cov.Packages[0].Files[0].Name)
+}
+
+func TestIngestGoCover(t *testing.T) {
+	cov, err := IngestGoCover([]byte(goCoverProfile))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cov.LinesCovered)
+	assert.Equal(t, int64(6), cov.LinesValid)
+	assert.Len(t, cov.Packages, 2)
+	assert.Equal(t, "example.com/a", cov.Packages[0].Name)
+	assert.Equal(t, 1.0, cov.Packages[0].LineRate)
+
+	_, err = IngestGoCover([]byte("mode: set\nbroken line\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser/types"
+)
+
+type goCoverBlock struct {
+	file     string
+	position string
+	stmts    int64
+}
+
+// IngestGoCover will parse the given go coverprofile data, e.g. generated by
+// `go test -coverprofile=coverage.out ./...`.
+// Coverage is counted by statements, the same as `go tool cover -func`.
+// Blocks reported more than once (e.g. -coverpkg) are merged.
+func IngestGoCover(data []byte) (*apistructs.TestCoverage, error) {
+	blocks := make(map[goCoverBlock]int64)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:line.column,line.column numberOfStatements count
+		idx := strings.LastIndex(line, ":")
+		if idx <= 0 {
+			return nil, errors.Errorf("invalid coverprofile line %d: %s", lineNo, line)
+		}
+		fields := strings.Fields(line[idx+1:])
+		if len(fields) != 3 {
+			return nil, errors.Errorf("invalid coverprofile line %d: %s", lineNo, line)
+		}
+		stmts, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid coverprofile line %d: %s", lineNo, line)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid coverprofile line %d: %s", lineNo, line)
+		}
+		b := goCoverBlock{file: line[:idx], position: fields[0], stmts: stmts}
+		if count > blocks[b] {
+			blocks[b] = count
+		} else if _, ok := blocks[b]; !ok {
+			blocks[b] = count
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*apistructs.TestCoverageFile)
+	for b, count := range blocks {
+		f, ok := files[b.file]
+		if !ok {
+			f = &apistructs.TestCoverageFile{Name: b.file}
+			files[b.file] = f
+		}
+		f.LinesValid += b.stmts
+		if count > 0 {
+			f.LinesCovered += b.stmts
+		}
+	}
+
+	pkgs := make(map[string]*apistructs.TestCoveragePackage)
+	cov := &apistructs.TestCoverage{Format: types.GoCover.TPValue()}
+	for name, f := range files {
+		f.LineRate = rate(f.LinesCovered, f.LinesValid)
+		pkgName := path.Dir(name)
+		pkg, ok := pkgs[pkgName]
+		if !ok {
+			pkg = &apistructs.TestCoveragePackage{Name: pkgName}
+			pkgs[pkgName] = pkg
+			cov.Packages = append(cov.Packages, pkg)
+		}
+		pkg.Files = append(pkg.Files, f)
+		pkg.LinesCovered += f.LinesCovered
+		pkg.LinesValid += f.LinesValid
+		cov.LinesCovered += f.LinesCovered
+		cov.LinesValid += f.LinesValid
+	}
+	for _, pkg := range cov.Packages {
+		pkg.LineRate = rate(pkg.LinesCovered, pkg.LinesValid)
+		sort.Slice(pkg.Files, func(i, j int) bool { return pkg.Files[i].Name < pkg.Files[j].Name })
+	}
+	sort.Slice(cov.Packages, func(i, j int) bool { return cov.Packages[i].Name < cov.Packages[j].Name })
+	cov.LineRate = rate(cov.LinesCovered, cov.LinesValid)
+	return cov, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/cloudstorage"
+	"github.com/erda-project/erda/pkg/qaparser"
+	"github.com/erda-project/erda/pkg/qaparser/types"
+)
+
+type CoberturaParser struct {
+}
+
+type GoCoverParser struct {
+}
+
+func init() {
+	logrus.Info("register Cobertura and GoCover Parser to manager")
+	(CoberturaParser{}).Register()
+	(GoCoverParser{}).Register()
+}
+
+func (c CoberturaParser) Register() {
+	qaparser.RegisterCoverage(c, types.Cobertura)
+}
+
+func (g GoCoverParser) Register() {
+	qaparser.RegisterCoverage(g, types.GoCover)
+}
+
+func (CoberturaParser) ParseCoverage(endpoint, ak, sk, bucket, objectName string) (*apistructs.TestCoverage, error) {
+	data, err := download(endpoint, ak, sk, bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+	return IngestCobertura(data)
+}
+
+func (GoCoverParser) ParseCoverage(endpoint, ak, sk, bucket, objectName string) (*apistructs.TestCoverage, error) {
+	data, err := download(endpoint, ak, sk, bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+	return IngestGoCover(data)
+}
+
+func download(endpoint, ak, sk, bucket, objectName string) ([]byte, error) {
+	client, err := cloudstorage.New(endpoint, ak, sk)
+	if err != nil {
+		return nil, errors.Wrap(err, "get cloud storage client")
+	}
+
+	byteArray, err := client.DownloadFile(bucket, objectName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "download filename=%s", objectName)
+	}
+	return byteArray, nil
+}
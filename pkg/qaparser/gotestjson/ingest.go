@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package gotestjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// event is the json event emitted by `go test -json`, see `go doc test2json`.
+type event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // seconds
+	Output  string
+}
+
+const (
+	actionPass   = "pass"
+	actionFail   = "fail"
+	actionSkip   = "skip"
+	actionOutput = "output"
+)
+
+// IngestFile will parse the given go test -json output file and return a
+// slice of test suites, one suite per package.
+func IngestFile(filename string) ([]*apistructs.TestSuite, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Ingest(data)
+}
+
+// Ingest will parse the given go test -json output and return a slice of test
+// suites, one suite per package. Lines that are not json events (e.g. build
+// output mixed into the stream) are ignored.
+func Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	var (
+		suites      []*apistructs.TestSuite
+		suiteByPkg  = make(map[string]*apistructs.TestSuite)
+		testByName  = make(map[string]*apistructs.Test)
+		suiteOutput = make(map[string]*strings.Builder)
+		testOutput  = make(map[string]*strings.Builder)
+		pkgFailed   = make(map[string]bool)
+	)
+
+	getSuite := func(pkg string) *apistructs.TestSuite {
+		if s, ok := suiteByPkg[pkg]; ok {
+			return s
+		}
+		s := &apistructs.TestSuite{Name: pkg, Package: pkg}
+		suiteByPkg[pkg] = s
+		suiteOutput[pkg] = &strings.Builder{}
+		suites = append(suites, s)
+		return s
+	}
+	getTest := func(pkg, name string) *apistructs.Test {
+		key := pkg + "\x00" + name
+		if t, ok := testByName[key]; ok {
+			return t
+		}
+		t := &apistructs.Test{Name: name, Classname: pkg}
+		testByName[key] = t
+		testOutput[key] = &strings.Builder{}
+		s := getSuite(pkg)
+		s.Tests = append(s.Tests, t)
+		return t
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var e event
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if e.Package == "" {
+			continue
+		}
+
+		// package level event
+		if e.Test == "" {
+			getSuite(e.Package)
+			switch e.Action {
+			case actionOutput:
+				suiteOutput[e.Package].WriteString(e.Output)
+			case actionFail:
+				pkgFailed[e.Package] = true
+			}
+			continue
+		}
+
+		t := getTest(e.Package, e.Test)
+		key := e.Package + "\x00" + e.Test
+		switch e.Action {
+		case actionOutput:
+			testOutput[key].WriteString(e.Output)
+		case actionPass:
+			t.Status = apistructs.TestStatusPassed
+			t.Duration = elapsed(e.Elapsed)
+		case actionFail:
+			t.Status = apistructs.TestStatusFailed
+			t.Duration = elapsed(e.Elapsed)
+		case actionSkip:
+			t.Status = apistructs.TestStatusSkipped
+			t.Duration = elapsed(e.Elapsed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []*apistructs.TestSuite
+	for _, s := range suites {
+		s.SystemOut = suiteOutput[s.Package].String()
+		for _, t := range s.Tests {
+			output := testOutput[s.Package+"\x00"+t.Name].String()
+			switch t.Status {
+			case apistructs.TestStatusFailed:
+				t.Error = apistructs.TestError{Message: "test failed", Body: output}
+			case "":
+				// no final action, usually the test binary panicked or timed out
+				t.Status = apistructs.TestStatusError
+				t.Error = apistructs.TestError{Message: "test did not complete", Body: output}
+			default:
+				t.SystemOut = output
+			}
+		}
+		// package failed without any test, e.g. build failed
+		if len(s.Tests) == 0 && pkgFailed[s.Package] {
+			s.Tests = append(s.Tests, &apistructs.Test{
+				Name:      s.Package,
+				Classname: s.Package,
+				Status:    apistructs.TestStatusError,
+				Error:     apistructs.TestError{Message: "package failed", Body: s.SystemOut},
+			})
+		}
+		if len(s.Tests) == 0 {
+			continue
+		}
+		su := &qaparser.Suite{TestSuite: s}
+		su.Aggregate()
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func elapsed(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
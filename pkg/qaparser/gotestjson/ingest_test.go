@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package gotestjson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+const goTestJSON = `{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"output","Package":"example.com/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK","Elapsed":0.01}
+{"Action":"run","Package":"example.com/a","Test":"TestBad"}
+{"Action":"output","Package":"example.com/a","Test":"TestBad","Output":"    a_test.go:10: boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestBad","Elapsed":0.02}
+{"Action":"run","Package":"example.com/a","Test":"TestSkip"}
+{"Action":"skip","Package":"example.com/a","Test":"TestSkip","Elapsed":0}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.05}
+{"Action":"output","Package":"example.com/b","Output":"# example.com/b\nb.go:3:1: syntax error\n"}
+{"Action":"fail","Package":"example.com/b","Elapsed":0}
+`
+
+func TestIngest(t *testing.T) {
+	suites, err := Ingest([]byte(goTestJSON))
+	assert.NoError(t, err)
+	assert.Len(t, suites, 2)
+
+	a := suites[0]
+	assert.Equal(t, "example.com/a", a.Name)
+	assert.Len(t, a.Tests, 3)
+	assert.Equal(t, apistructs.TestStatusPassed, a.Tests[0].Status)
+	assert.Equal(t, apistructs.TestStatusFailed, a.Tests[1].Status)
+	assert.Contains(t, a.Tests[1].Error.(apistructs.TestError).Body, "boom")
+	assert.Equal(t, apistructs.TestStatusSkipped, a.Tests[2].Status)
+	assert.Equal(t, 3, a.Totals.Tests)
+
+	// build failure without any test
+	b := suites[1]
+	assert.Len(t, b.Tests, 1)
+	assert.Equal(t, apistructs.TestStatusError, b.Tests[0].Status)
+}
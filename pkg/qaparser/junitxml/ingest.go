@@ -0,0 +1,178 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package junitxml
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/qaparser"
+)
+
+// IngestFile will parse the given XML file and return a slice of all contained
+// JUnit test suite definitions.
+func IngestFile(filename string) ([]*apistructs.TestSuite, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Ingest(data)
+}
+
+// Ingest will parse the given JUnit XML data and return a slice of all
+// contained test suites. Compared with surefire reports, it also handles:
+//   - nested testsuite nodes (JUnit 5, Ant)
+//   - testcases without classname (Jest)
+//   - failures without message attribute (Jest, pytest)
+//   - testsuite file attribute (pytest, Jest)
+func Ingest(data []byte) ([]*apistructs.TestSuite, error) {
+	nodes, err := qaparser.NodeParse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var suites []*apistructs.TestSuite
+	findSuites(nodes, "", &suites)
+	return suites, nil
+}
+
+func findSuites(nodes []qaparser.XmlNode, parentName string, suites *[]*apistructs.TestSuite) {
+	for _, node := range nodes {
+		switch node.XMLName.Local {
+		case "testsuite":
+			ingestSuite(node, parentName, suites)
+		case "testsuites":
+			findSuites(node.Nodes, node.Attr("name"), suites)
+		default:
+			findSuites(node.Nodes, parentName, suites)
+		}
+	}
+}
+
+func ingestSuite(root qaparser.XmlNode, parentName string, suites *[]*apistructs.TestSuite) {
+	suite := &apistructs.TestSuite{
+		Name:    root.Attr("name"),
+		Package: root.Attr("package"),
+	}
+	if suite.Name == "" {
+		suite.Name = firstNonEmpty(root.Attr("file"), parentName)
+	}
+	if suite.Package == "" {
+		suite.Package = root.Attr("file")
+	}
+
+	var children []qaparser.XmlNode
+	for _, node := range root.Nodes {
+		switch node.XMLName.Local {
+		case "testcase":
+			suite.Tests = append(suite.Tests, ingestTestcase(node, suite.Name))
+		case "testsuite":
+			children = append(children, node)
+		case "properties":
+			suite.Properties = ingestProperties(node)
+		case "system-out":
+			suite.SystemOut = string(node.Content)
+		case "system-err":
+			suite.SystemErr = string(node.Content)
+		}
+	}
+
+	if len(suite.Tests) > 0 {
+		su := &qaparser.Suite{TestSuite: suite}
+		su.Aggregate()
+		*suites = append(*suites, suite)
+	}
+	for _, child := range children {
+		ingestSuite(child, suite.Name, suites)
+	}
+}
+
+func ingestProperties(root qaparser.XmlNode) map[string]string {
+	props := make(map[string]string, len(root.Nodes))
+	for _, node := range root.Nodes {
+		if node.XMLName.Local == "property" {
+			props[node.Attr("name")] = firstNonEmpty(node.Attr("value"), string(node.Content))
+		}
+	}
+	return props
+}
+
+func ingestTestcase(root qaparser.XmlNode, suiteName string) *apistructs.Test {
+	test := apistructs.Test{
+		Name:      root.Attr("name"),
+		Classname: firstNonEmpty(root.Attr("classname"), root.Attr("class"), suiteName),
+		Duration:  duration(root.Attr("time")),
+		Status:    apistructs.TestStatusPassed,
+	}
+
+	for _, node := range root.Nodes {
+		switch node.XMLName.Local {
+		case "skipped":
+			test.Status = apistructs.TestStatusSkipped
+			if msg := firstNonEmpty(node.Attr("message"), string(node.Content)); msg != "" {
+				test.Error = apistructs.TestError{Message: msg, Type: node.Attr("type")}
+			}
+		case "failure":
+			test.Error = ingestError(node)
+			test.Status = apistructs.TestStatusFailed
+		case "error":
+			test.Error = ingestError(node)
+			test.Status = apistructs.TestStatusError
+		case "system-out":
+			test.SystemOut = string(node.Content)
+		case "system-err":
+			test.SystemErr = string(node.Content)
+		}
+	}
+
+	return &test
+}
+
+func ingestError(root qaparser.XmlNode) apistructs.TestError {
+	body := string(root.Content)
+	message := root.Attr("message")
+	if message == "" {
+		message = strings.TrimSpace(strings.SplitN(strings.TrimSpace(body), "\n", 2)[0])
+	}
+	return apistructs.TestError{
+		Body:    body,
+		Type:    root.Attr("type"),
+		Message: message,
+	}
+}
+
+func duration(t string) time.Duration {
+	// Gradle and some locales may use ',' as thousands separator
+	t = strings.ReplaceAll(strings.TrimSpace(t), ",", "")
+	if s, err := strconv.ParseFloat(t, 64); err == nil {
+		return time.Duration(s*1000000) * time.Microsecond
+	}
+	if d, err := time.ParseDuration(t); err == nil {
+		return d
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package junitxml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+const pytestXML = `<?xml version="1.0" encoding="utf-8"?>
+<testsuites>
+  <testsuite name="pytest" errors="0" failures="1" skipped="1" tests="3" time="0.12">
+    <testcase classname="tests.test_a" name="test_ok" time="0.01"/>
+    <testcase classname="tests.test_a" name="test_bad" time="0.02">
+      <failure message="assert 1 == 2">def test_bad():
+&gt;       assert 1 == 2</failure>
+    </testcase>
+    <testcase classname="tests.test_a" name="test_skip" time="0">
+      <skipped type="pytest.skip" message="not ready"/>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+const jestXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="jest tests" tests="2" failures="1">
+  <testsuite name="sum" tests="2" failures="1" time="0.5" file="src/sum.test.js">
+    <testcase classname="" name="adds" time="0.001"/>
+    <testcase classname="sum subtracts" name="subtracts" time="0.002">
+      <failure>Error: expect(received).toBe(expected)
+    at Object.toBe (src/sum.test.js:9:3)</failure>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+func TestIngestPytest(t *testing.T) {
+	suites, err := Ingest([]byte(pytestXML))
+	assert.NoError(t, err)
+	assert.Len(t, suites, 1)
+	s := suites[0]
+	assert.Len(t, s.Tests, 3)
+	assert.Equal(t, apistructs.TestStatusPassed, s.Tests[0].Status)
+	assert.Equal(t, apistructs.TestStatusFailed, s.Tests[1].Status)
+	assert.Equal(t, "assert 1 == 2", s.Tests[1].Error.(apistructs.TestError).Message)
+	assert.Equal(t, apistructs.TestStatusSkipped, s.Tests[2].Status)
+}
+
+func TestIngestJest(t *testing.T) {
+	suites, err := Ingest([]byte(jestXML))
+	assert.NoError(t, err)
+	assert.Len(t, suites, 1)
+	s := suites[0]
+	assert.Equal(t, "src/sum.test.js", s.Package)
+	assert.Equal(t, "sum", s.Tests[0].Classname)
+	assert.Equal(t, "Error: expect(received).toBe(expected)", s.Tests[1].Error.(apistructs.TestError).Message)
+}
@@ -28,8 +28,15 @@ type Parser interface {
 	Register()
 }
 
+// CoverageParser 覆盖率报告 parser
+type CoverageParser interface {
+	ParseCoverage(endpoint, ak, sk, bucket, objectName string) (*apistructs.TestCoverage, error)
+	Register()
+}
+
 type Manager struct {
-	parsers map[types.TestParserType]Parser
+	parsers         map[types.TestParserType]Parser
+	coverageParsers map[types.CoverageParserType]CoverageParser
 }
 
 func GetManager() *Manager {
@@ -38,7 +45,8 @@ func GetManager() *Manager {
 
 func init() {
 	m = Manager{
-		parsers: map[types.TestParserType]Parser{},
+		parsers:         map[types.TestParserType]Parser{},
+		coverageParsers: map[types.CoverageParserType]CoverageParser{},
 	}
 
 	logrus.Info(">>> init parser manager finished <<<")
@@ -63,3 +71,23 @@ func Register(p Parser, types ...types.TestParserType) error {
 
 	return nil
 }
+
+func (m *Manager) GetCoverageParser(t types.CoverageParserType) CoverageParser {
+
+	if _, ok := m.coverageParsers[t]; !ok {
+		logrus.Errorf(">>> not found coverage type=%s <<<", t)
+	}
+	return m.coverageParsers[t]
+}
+
+func RegisterCoverage(p CoverageParser, types ...types.CoverageParserType) error {
+	for _, t := range types {
+		if _, ok := m.coverageParsers[t]; ok {
+			return errors.Errorf("duplicated coverage type=%s", t.TPValue())
+		}
+		logrus.Infof(">>> register coverage type=%s to parser manager success <<<", t)
+		m.coverageParsers[t] = p
+	}
+
+	return nil
+}
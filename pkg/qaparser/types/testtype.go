@@ -23,8 +23,29 @@ const (
 	NGTest TestParserType = "NGTEST"
 	// 使用 junit 生成的 xml 格式进行解析
 	JUnit TestParserType = "JUNIT"
+	// 使用 go test -json 输出进行解析
+	GoTest TestParserType = "GOTEST"
+	// 使用通用 JUnit XML 格式进行解析，兼容 pytest、Jest(jest-junit)、Gradle 等生成的报告
+	XUnit  TestParserType = "XUNIT"
+	PyTest TestParserType = "PYTEST"
+	Jest   TestParserType = "JEST"
+	Gradle TestParserType = "GRADLE"
 )
 
+// 覆盖率报告 parser
+type CoverageParserType string
+
+const (
+	// 使用 Cobertura XML 格式进行解析，兼容 coverage.py、jest、gocover-cobertura 等生成的报告
+	Cobertura CoverageParserType = "COBERTURA"
+	// 使用 go test -coverprofile 生成的格式进行解析
+	GoCover CoverageParserType = "GOCOVER"
+)
+
+func (t CoverageParserType) TPValue() string {
+	return string(t)
+}
+
 func (t TestParserType) TPValue() string {
 	return string(t)
 }
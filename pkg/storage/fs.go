@@ -16,6 +16,7 @@ package storage
 import (
 	"io"
 	"os"
+	"path/filepath"
 )
 
 type FS struct{}
@@ -44,3 +45,49 @@ func (fs *FS) Write(path string, r io.Reader) error {
 func (fs *FS) Delete(path string) error {
 	return os.Remove(path)
 }
+
+// ReadRange 按范围读取，调用方负责关闭
+func (fs *FS) ReadRange(path string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length <= 0 {
+		return f, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}, nil
+}
+
+func (fs *FS) Stat(path string) (*ObjectInfo, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Path: path, Size: fi.Size(), LastModified: fi.ModTime()}, nil
+}
+
+// List 递归列举目录下的所有文件
+func (fs *FS) List(prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.Walk(prefix, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		objects = append(objects, ObjectInfo{Path: path, Size: fi.Size(), LastModified: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
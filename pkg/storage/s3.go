@@ -0,0 +1,396 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/encrypt"
+)
+
+const (
+	// DefaultS3PartSize 默认分片大小
+	DefaultS3PartSize int64 = 16 * 1024 * 1024
+	// MinS3PartSize S3 协议允许的最小分片大小（最后一个分片除外）
+	MinS3PartSize int64 = 5 * 1024 * 1024
+
+	s3ListMaxKeys = 1000
+)
+
+// s3Core minio.Core 中用到的方法，便于测试
+type s3Core interface {
+	PutObject(bucket, object string, data io.Reader, size int64, md5Base64, sha256Hex string, metadata map[string]string, sse encrypt.ServerSide) (minio.ObjectInfo, error)
+	NewMultipartUpload(bucket, object string, opts minio.PutObjectOptions) (string, error)
+	PutObjectPart(bucket, object, uploadID string, partID int, data io.Reader, size int64, md5Base64, sha256Hex string, sse encrypt.ServerSide) (minio.ObjectPart, error)
+	CompleteMultipartUpload(bucket, object, uploadID string, parts []minio.CompletePart) (string, error)
+	AbortMultipartUpload(bucket, object, uploadID string) error
+	GetObject(bucket, object string, opts minio.GetObjectOptions) (io.ReadCloser, minio.ObjectInfo, error)
+	StatObject(bucket, object string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	RemoveObject(bucket, object string) error
+	ListObjectsV2(bucket, prefix, continuationToken string, fetchOwner bool, delimiter string, maxKeys int, startAfter string) (minio.ListBucketV2Result, error)
+	PresignedGetObject(bucket, object string, expires time.Duration, reqParams url.Values) (*url.URL, error)
+	PresignedPutObject(bucket, object string, expires time.Duration) (*url.URL, error)
+	Presign(method, bucket, object string, expires time.Duration, reqParams url.Values) (*url.URL, error)
+}
+
+// CompletedPart 已上传的分片，ETag 为上传分片时响应 header 中的 ETag
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// S3 兼容 S3 协议的对象存储，例如 AWS S3、MinIO
+type S3 struct {
+	endpoint        string
+	accessKeyID     string
+	accessKeySecret string
+	bucket          string
+	region          string
+	partSize        int64
+	presignEndpoint string
+
+	core        s3Core
+	presignCore s3Core
+}
+
+type S3Option func(*S3)
+
+// WithS3Region 指定 region
+func WithS3Region(region string) S3Option {
+	return func(s *S3) {
+		s.region = region
+	}
+}
+
+// WithS3PartSize 指定分片上传的分片大小，小于 MinS3PartSize 时使用 MinS3PartSize
+func WithS3PartSize(partSize int64) S3Option {
+	return func(s *S3) {
+		if partSize < MinS3PartSize {
+			partSize = MinS3PartSize
+		}
+		s.partSize = partSize
+	}
+}
+
+// WithS3PresignEndpoint 指定生成预签名链接使用的 endpoint，例如对外暴露的公网地址，为空时使用 endpoint；
+// 预签名时需要 region，使用公网地址时应同时指定 region，避免从集群内访问公网地址查询 bucket 所在 region
+func WithS3PresignEndpoint(endpoint string) S3Option {
+	return func(s *S3) {
+		s.presignEndpoint = endpoint
+	}
+}
+
+// NewS3 endpoint 可以带 http:// 或 https:// 前缀，不带时默认使用 https
+func NewS3(endpoint, accessKeyID, accessKeySecret, bucket string, opts ...S3Option) *S3 {
+	s := S3{
+		endpoint:        endpoint,
+		accessKeyID:     accessKeyID,
+		accessKeySecret: accessKeySecret,
+		bucket:          bucket,
+		partSize:        DefaultS3PartSize,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return &s
+}
+
+func (s *S3) Type() Type {
+	return TypeS3
+}
+
+func (s *S3) Read(path string) (io.Reader, error) {
+	return s.ReadRange(path, 0, 0)
+}
+
+// ReadRange 按范围读取，调用方负责关闭
+func (s *S3) ReadRange(path string, offset, length int64) (io.ReadCloser, error) {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return nil, err
+	}
+	var opts minio.GetObjectOptions
+	if err := setS3Range(&opts, offset, length); err != nil {
+		return nil, err
+	}
+	r, _, err := core.GetObject(s.bucket, path, opts)
+	if err != nil {
+		return nil, convertS3Error("read", path, err)
+	}
+	return r, nil
+}
+
+// Write 流式分片上传，内存中最多缓存一个分片；内容不足一个分片时直接上传
+func (s *S3) Write(path string, r io.Reader) error {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, s.partSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if int64(n) < s.partSize {
+		_, err := core.PutObject(s.bucket, path, bytes.NewReader(buf[:n]), int64(n), "", "", nil, nil)
+		return err
+	}
+
+	uploadID, err := core.NewMultipartUpload(s.bucket, path, minio.PutObjectOptions{})
+	if err != nil {
+		return err
+	}
+	parts, err := s.uploadParts(core, path, uploadID, buf, n, r)
+	if err != nil {
+		_ = core.AbortMultipartUpload(s.bucket, path, uploadID)
+		return err
+	}
+	if _, err := core.CompleteMultipartUpload(s.bucket, path, uploadID, parts); err != nil {
+		_ = core.AbortMultipartUpload(s.bucket, path, uploadID)
+		return err
+	}
+	return nil
+}
+
+func (s *S3) uploadParts(core s3Core, path, uploadID string, buf []byte, n int, r io.Reader) ([]minio.CompletePart, error) {
+	var parts []minio.CompletePart
+	for partID := 1; n > 0; partID++ {
+		part, err := core.PutObjectPart(s.bucket, path, uploadID, partID, bytes.NewReader(buf[:n]), int64(n), "", "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload part %d, err: %v", partID, err)
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: partID, ETag: part.ETag})
+
+		var readErr error
+		n, readErr = io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return nil, readErr
+		}
+	}
+	return parts, nil
+}
+
+func (s *S3) Delete(path string) error {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return err
+	}
+	return core.RemoveObject(s.bucket, path)
+}
+
+func (s *S3) Stat(path string) (*ObjectInfo, error) {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return nil, err
+	}
+	info, err := core.StatObject(s.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error("stat", path, err)
+	}
+	return convertS3ObjectInfo(info), nil
+}
+
+// List 递归列举指定前缀下的所有文件
+func (s *S3) List(prefix string) ([]ObjectInfo, error) {
+	prefix = handlePath(prefix)
+	core, err := s.getCore()
+	if err != nil {
+		return nil, err
+	}
+	var objects []ObjectInfo
+	var token string
+	for {
+		result, err := core.ListObjectsV2(s.bucket, prefix, token, false, "", s3ListMaxKeys, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range result.Contents {
+			objects = append(objects, *convertS3ObjectInfo(info))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	return objects, nil
+}
+
+func (s *S3) PresignedURL(path string, expires time.Duration, filename string) (string, error) {
+	path = handlePath(path)
+	core, err := s.getPresignCore()
+	if err != nil {
+		return "", err
+	}
+	params := make(url.Values)
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	}
+	u, err := core.PresignedGetObject(s.bucket, path, expires, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignedPutURL 生成上传对象的临时地址，持有者只能在过期前覆盖该对象
+func (s *S3) PresignedPutURL(path string, expires time.Duration) (string, error) {
+	path = handlePath(path)
+	core, err := s.getPresignCore()
+	if err != nil {
+		return "", err
+	}
+	u, err := core.PresignedPutObject(s.bucket, path, expires)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// CreateMultipartUpload 创建分片上传，分片由持有 PresignedUploadPartURL 临时地址的一方上传
+func (s *S3) CreateMultipartUpload(path string) (string, error) {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return "", err
+	}
+	return core.NewMultipartUpload(s.bucket, path, minio.PutObjectOptions{})
+}
+
+// PresignedUploadPartURL 生成上传单个分片的临时地址
+func (s *S3) PresignedUploadPartURL(path, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	path = handlePath(path)
+	core, err := s.getPresignCore()
+	if err != nil {
+		return "", err
+	}
+	params := make(url.Values)
+	params.Set("partNumber", strconv.Itoa(partNumber))
+	params.Set("uploadId", uploadID)
+	u, err := core.Presign(http.MethodPut, s.bucket, path, expires, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// CompleteMultipartUpload 按分片序号合并已上传的分片
+func (s *S3) CompleteMultipartUpload(path, uploadID string, parts []CompletedPart) error {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return err
+	}
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	_, err = core.CompleteMultipartUpload(s.bucket, path, uploadID, completeParts)
+	return err
+}
+
+// AbortMultipartUpload 取消分片上传并清理已上传的分片
+func (s *S3) AbortMultipartUpload(path, uploadID string) error {
+	path = handlePath(path)
+	core, err := s.getCore()
+	if err != nil {
+		return err
+	}
+	return core.AbortMultipartUpload(s.bucket, path, uploadID)
+}
+
+func (s *S3) getCore() (s3Core, error) {
+	if s.core != nil {
+		return s.core, nil
+	}
+	endpoint, secure := parseS3Endpoint(s.endpoint)
+	client, err := minio.NewWithRegion(endpoint, s.accessKeyID, s.accessKeySecret, secure, s.region)
+	if err != nil {
+		return nil, err
+	}
+	s.core = &minio.Core{Client: client}
+	return s.core, nil
+}
+
+// getPresignCore 生成预签名链接使用的 client，未指定 presignEndpoint 时与 getCore 相同
+func (s *S3) getPresignCore() (s3Core, error) {
+	if s.presignEndpoint == "" {
+		return s.getCore()
+	}
+	if s.presignCore != nil {
+		return s.presignCore, nil
+	}
+	endpoint, secure := parseS3Endpoint(s.presignEndpoint)
+	client, err := minio.NewWithRegion(endpoint, s.accessKeyID, s.accessKeySecret, secure, s.region)
+	if err != nil {
+		return nil, err
+	}
+	s.presignCore = &minio.Core{Client: client}
+	return s.presignCore, nil
+}
+
+// parseS3Endpoint 去掉 endpoint 中的协议，返回是否使用 https
+func parseS3Endpoint(endpoint string) (string, bool) {
+	if strings.HasPrefix(endpoint, "http://") {
+		return strings.TrimPrefix(endpoint, "http://"), false
+	}
+	return strings.TrimPrefix(endpoint, "https://"), true
+}
+
+func setS3Range(opts *minio.GetObjectOptions, offset, length int64) error {
+	if offset < 0 {
+		return fmt.Errorf("invalid range offset: %d", offset)
+	}
+	switch {
+	case length > 0:
+		return opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		return opts.SetRange(offset, 0)
+	default:
+		return nil
+	}
+}
+
+// convertS3Error 将文件不存在转换为 os.ErrNotExist，调用方可以统一使用 os.IsNotExist 判断
+func convertS3Error(op, path string, err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey" {
+		return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+	}
+	return err
+}
+
+func convertS3ObjectInfo(info minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Path:         info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
+}
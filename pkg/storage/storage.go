@@ -13,7 +13,10 @@
 
 package storage
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 type Storager interface {
 	Type() Type
@@ -27,4 +30,30 @@ type Type string
 var (
 	TypeFileSystem Type = "fs"
 	TypeOSS        Type = "oss"
+	TypeS3         Type = "s3"
 )
+
+// ObjectInfo 文件元信息
+type ObjectInfo struct {
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	ContentType  string    `json:"contentType,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// RangeReader 支持按范围读取，length <= 0 表示读取到文件末尾
+type RangeReader interface {
+	ReadRange(path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Stater 支持查询文件元信息及按前缀列举
+type Stater interface {
+	Stat(path string) (*ObjectInfo, error)
+	List(prefix string) ([]ObjectInfo, error)
+}
+
+// Presigner 支持生成带签名的临时下载链接，filename 不为空时作为下载文件名
+type Presigner interface {
+	PresignedURL(path string, expires time.Duration, filename string) (string, error)
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// This program is free software: you can use, redistribute, and/or modify
+// it under the terms of the GNU Affero General Public License, version 3
+// or later ("AGPL"), as published by the Free Software Foundation.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go"
+	"github.com/minio/minio-go/pkg/encrypt"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeS3Core struct {
+	objects map[string][]byte
+	parts   map[int][]byte
+	aborted bool
+	failAt  int
+}
+
+func newFakeS3Core() *fakeS3Core {
+	return &fakeS3Core{objects: map[string][]byte{}, parts: map[int][]byte{}}
+}
+
+func (c *fakeS3Core) PutObject(bucket, object string, data io.Reader, size int64, md5Base64, sha256Hex string, metadata map[string]string, sse encrypt.ServerSide) (minio.ObjectInfo, error) {
+	b, _ := ioutil.ReadAll(data)
+	c.objects[object] = b
+	return minio.ObjectInfo{Key: object, Size: size}, nil
+}
+
+func (c *fakeS3Core) NewMultipartUpload(bucket, object string, opts minio.PutObjectOptions) (string, error) {
+	return "upload-id", nil
+}
+
+func (c *fakeS3Core) PutObjectPart(bucket, object, uploadID string, partID int, data io.Reader, size int64, md5Base64, sha256Hex string, sse encrypt.ServerSide) (minio.ObjectPart, error) {
+	if c.failAt == partID {
+		return minio.ObjectPart{}, fmt.Errorf("mock failure")
+	}
+	b, _ := ioutil.ReadAll(data)
+	c.parts[partID] = b
+	return minio.ObjectPart{PartNumber: partID, ETag: fmt.Sprintf("etag-%d", partID), Size: size}, nil
+}
+
+func (c *fakeS3Core) CompleteMultipartUpload(bucket, object, uploadID string, parts []minio.CompletePart) (string, error) {
+	var buf bytes.Buffer
+	for i, p := range parts {
+		if p.PartNumber != i+1 || p.ETag != fmt.Sprintf("etag-%d", i+1) {
+			return "", fmt.Errorf("invalid part: %v", p)
+		}
+		buf.Write(c.parts[p.PartNumber])
+	}
+	c.objects[object] = buf.Bytes()
+	return "etag", nil
+}
+
+func (c *fakeS3Core) AbortMultipartUpload(bucket, object, uploadID string) error {
+	c.aborted = true
+	return nil
+}
+
+func (c *fakeS3Core) GetObject(bucket, object string, opts minio.GetObjectOptions) (io.ReadCloser, minio.ObjectInfo, error) {
+	b, ok := c.objects[object]
+	if !ok {
+		return nil, minio.ObjectInfo{}, minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), minio.ObjectInfo{Key: object}, nil
+}
+
+func (c *fakeS3Core) StatObject(bucket, object string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	b, ok := c.objects[object]
+	if !ok {
+		return minio.ObjectInfo{}, minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}
+	}
+	return minio.ObjectInfo{Key: object, Size: int64(len(b))}, nil
+}
+
+func (c *fakeS3Core) RemoveObject(bucket, object string) error {
+	delete(c.objects, object)
+	return nil
+}
+
+func (c *fakeS3Core) ListObjectsV2(bucket, prefix, continuationToken string, fetchOwner bool, delimiter string, maxKeys int, startAfter string) (minio.ListBucketV2Result, error) {
+	// return one object per page to verify pagination
+	var keys []string
+	for k := range c.objects {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return minio.ListBucketV2Result{}, nil
+	}
+	idx := 0
+	if continuationToken != "" {
+		fmt.Sscanf(continuationToken, "%d", &idx)
+	}
+	result := minio.ListBucketV2Result{Contents: []minio.ObjectInfo{{Key: fmt.Sprintf("obj-%d", idx)}}}
+	if idx+1 < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = fmt.Sprintf("%d", idx+1)
+	}
+	return result, nil
+}
+
+func (c *fakeS3Core) PresignedGetObject(bucket, object string, expires time.Duration, reqParams url.Values) (*url.URL, error) {
+	return &url.URL{Scheme: "https", Host: "s3.example.com", Path: "/" + bucket + "/" + object, RawQuery: reqParams.Encode()}, nil
+}
+
+func (c *fakeS3Core) PresignedPutObject(bucket, object string, expires time.Duration) (*url.URL, error) {
+	return &url.URL{Scheme: "https", Host: "s3.example.com", Path: "/" + bucket + "/" + object, RawQuery: "X-Amz-Expires=" + fmt.Sprintf("%d", int(expires.Seconds()))}, nil
+}
+
+func (c *fakeS3Core) Presign(method, bucket, object string, expires time.Duration, reqParams url.Values) (*url.URL, error) {
+	return &url.URL{Scheme: "https", Host: "s3.example.com", Path: "/" + bucket + "/" + object, RawQuery: reqParams.Encode()}, nil
+}
+
+func newTestS3(core s3Core) *S3 {
+	s := NewS3("http://127.0.0.1:9000", "ak", "sk", "bucket", WithS3PartSize(MinS3PartSize))
+	s.core = core
+	return s
+}
+
+func TestS3Write(t *testing.T) {
+	for _, size := range []int64{0, 10, MinS3PartSize, MinS3PartSize*2 + 100} {
+		core := newFakeS3Core()
+		s := newTestS3(core)
+		content := bytes.Repeat([]byte("a"), int(size))
+		assert.NoError(t, s.Write("/dir/file", bytes.NewReader(content)))
+		assert.Equal(t, content, core.objects["dir/file"], "size: %d", size)
+		if size < MinS3PartSize {
+			assert.Len(t, core.parts, 0)
+		} else {
+			assert.Len(t, core.parts, int((size+MinS3PartSize-1)/MinS3PartSize))
+		}
+	}
+
+	core := newFakeS3Core()
+	core.failAt = 2
+	s := newTestS3(core)
+	err := s.Write("file", bytes.NewReader(make([]byte, MinS3PartSize*2)))
+	assert.Error(t, err)
+	assert.True(t, core.aborted)
+}
+
+func TestS3ReadNotExist(t *testing.T) {
+	s := newTestS3(newFakeS3Core())
+	_, err := s.Read("not-exist")
+	assert.True(t, os.IsNotExist(err))
+	_, err = s.Stat("not-exist")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestS3List(t *testing.T) {
+	core := newFakeS3Core()
+	core.objects["a"], core.objects["b"], core.objects["c"] = nil, nil, nil
+	objects, err := newTestS3(core).List("/")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 3)
+}
+
+func TestS3PresignedURL(t *testing.T) {
+	u, err := newTestS3(newFakeS3Core()).PresignedURL("/dir/file.txt", time.Minute, "file.txt")
+	assert.NoError(t, err)
+	assert.Contains(t, u, "/bucket/dir/file.txt")
+	assert.Contains(t, u, "response-content-disposition=inline%3B+filename%3Dfile.txt")
+
+	// 文件名中的特殊字符需要转义
+	u, err = newTestS3(newFakeS3Core()).PresignedURL("/dir/file.txt", time.Minute, "a b;c.txt")
+	assert.NoError(t, err)
+	pu, err := url.Parse(u)
+	assert.NoError(t, err)
+	assert.Equal(t, `inline; filename="a b;c.txt"`, pu.Query().Get("response-content-disposition"))
+	u, err = newTestS3(newFakeS3Core()).PresignedURL("/dir/file.txt", time.Minute, "报告.pdf")
+	assert.NoError(t, err)
+	pu, err = url.Parse(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "inline; filename*=utf-8''%E6%8A%A5%E5%91%8A.pdf", pu.Query().Get("response-content-disposition"))
+}
+
+func TestS3PresignEndpoint(t *testing.T) {
+	s := NewS3("http://minio:9000", "ak", "sk", "bucket", WithS3Region("us-east-1"),
+		WithS3PresignEndpoint("https://files.example.com"))
+	u, err := s.PresignedURL("/dir/file.txt", time.Minute, "file.txt")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u, "https://files.example.com/bucket/dir/file.txt?"), u)
+	u, err = s.PresignedPutURL("/dir/file.txt", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u, "https://files.example.com/bucket/dir/file.txt?"), u)
+
+	s = NewS3("http://minio:9000", "ak", "sk", "bucket", WithS3Region("us-east-1"))
+	u, err = s.PresignedURL("/dir/file.txt", time.Minute, "")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u, "http://minio:9000/bucket/dir/file.txt?"), u)
+}
+
+func TestS3PresignedPutURL(t *testing.T) {
+	u, err := newTestS3(newFakeS3Core()).PresignedPutURL("/dir/file.tar", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://s3.example.com/bucket/dir/file.tar?X-Amz-Expires=3600", u)
+}
+
+func TestSetS3Range(t *testing.T) {
+	for _, c := range []struct {
+		offset, length int64
+		want           string
+		wantErr        bool
+	}{
+		{0, 0, "", false},
+		{10, 0, "bytes=10-", false},
+		{0, 10, "bytes=0-9", false},
+		{5, 10, "bytes=5-14", false},
+		{-1, 0, "", true},
+	} {
+		var opts minio.GetObjectOptions
+		err := setS3Range(&opts, c.offset, c.length)
+		assert.Equal(t, c.wantErr, err != nil)
+		assert.Equal(t, c.want, opts.Header().Get("Range"))
+	}
+}
+
+func TestParseS3Endpoint(t *testing.T) {
+	endpoint, secure := parseS3Endpoint("http://minio:9000")
+	assert.Equal(t, "minio:9000", endpoint)
+	assert.False(t, secure)
+	endpoint, secure = parseS3Endpoint("s3.amazonaws.com")
+	assert.Equal(t, "s3.amazonaws.com", endpoint)
+	assert.True(t, secure)
+}
+
+func TestFSReadRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fs := NewFS()
+	path := dir + "/file"
+	assert.NoError(t, fs.Write(path, bytes.NewBufferString("0123456789")))
+
+	r, err := fs.ReadRange(path, 2, 3)
+	assert.NoError(t, err)
+	b, _ := ioutil.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "234", string(b))
+
+	info, err := fs.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), info.Size)
+
+	objects, err := fs.List(dir)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestS3PresignedMultipartUpload(t *testing.T) {
+	core := newFakeS3Core()
+	s := newTestS3(core)
+	uploadID, err := s.CreateMultipartUpload("/dir/file.tar")
+	assert.NoError(t, err)
+	assert.Equal(t, "upload-id", uploadID)
+
+	u, err := s.PresignedUploadPartURL("/dir/file.tar", uploadID, 2, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://s3.example.com/bucket/dir/file.tar?partNumber=2&uploadId=upload-id", u)
+
+	core.parts[1], core.parts[2] = []byte("a"), []byte("b")
+	assert.NoError(t, s.CompleteMultipartUpload("/dir/file.tar", uploadID,
+		[]CompletedPart{{PartNumber: 1, ETag: "etag-1"}, {PartNumber: 2, ETag: "etag-2"}}))
+	assert.Equal(t, []byte("ab"), core.objects["dir/file.tar"])
+
+	assert.NoError(t, s.AbortMultipartUpload("/dir/file.tar", uploadID))
+	assert.True(t, core.aborted)
+}